@@ -13,7 +13,7 @@ func main() {
 	var Iris *Tensor = LoadCSV("iris_dataset.csv", true)
 
 	// Split targets from the features and turn on gradient tracking
-	features, targets := Gradify(Iris.Slice("1:, :4")), Gradify(Iris.Slice("1:, 4:"))
+	features, targets := Gradify(Iris.Slice(":, :4")), Gradify(Iris.Slice(":, 4:"))
 
 	fmt.Println("Features shape: ", features.Shape, " Targets shape: ", targets.Shape)
 
@@ -1,6 +1,9 @@
 package TG
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 /*
 * @notice batching.go contains a general interface for batching within any Tensor Operation.
@@ -11,7 +14,22 @@ type IBatching interface {
 	Execute(tensors ...*Tensor) *Tensor // Closed Unary/Binary/Ternary Operations
 }
 
+/*
+* @notice BatchedOperation() applies op to each element along the 0'th axis of the provided Tensors and stacks the results.
+* @dev The first element is executed on the calling goroutine to determine the shape of each output element. The output
+* Tensor is then preallocated and the remaining elements are executed across the shared worker pool (see WorkerPool.go),
+* each writing directly into its own region of the output.
+ */
 func BatchedOperation(op IBatching, tensors ...*Tensor) *Tensor {
+	Batched_Output, _ := BatchedOperationContext(context.Background(), op, tensors...)
+	return Batched_Output
+}
+
+/*
+* @notice BatchedOperationContext() is BatchedOperation() with cancellation. Once ctx is done, no new batch elements are
+* started and ctx.Err() is returned along with a nil Tensor.
+ */
+func BatchedOperationContext(ctx context.Context, op IBatching, tensors ...*Tensor) (*Tensor, error) {
 
 	// Ensure all tensors have the same batch size
 	batchsize := tensors[0].Shape[0]
@@ -22,46 +40,55 @@ func BatchedOperation(op IBatching, tensors ...*Tensor) *Tensor {
 			panic("All tensors must have the same batch size")
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Batchify the provided operation
 	batchedOp := Batchify(op, tensors...)
 
-	// Define a struct to store the results of the batched operation
-	type result struct {
-		index  int
-		tensor *Tensor
+	// Execute the first element to determine the shape of each element of the output, then preallocate the output
+	first := batchedOp(0)
+	Batched_Output := newBatchedOutput(first, batchsize)
+
+	err := parallelForContext(ctx, batchsize-1, func(i int) {
+		Batched_Output.setBatchElement(i+1, batchedOp(i+1))
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// setup channel to collect results
-	results := make(chan result, batchsize)
+	return Batched_Output, nil
+}
 
-	// setup map to track order of batch in results The Tensor is split into individual elements and processed concurrently
-	// so to group the results back into a batched Tensor, we need to track the order of the elements in the batch
-	orderMap := make(map[int]*Tensor)
+// newBatchedOutput allocates a Tensor to hold batchsize elements shaped like first (which has a leading singleton dim) and stores first at index 0.
+func newBatchedOutput(first *Tensor, batchsize int) *Tensor {
 
-	// Launch a goroutine for each element in the batch
-	for i := 0; i < batchsize; i++ {
+	shape := append([]int{batchsize}, first.Shape[1:]...)
+	output := &Tensor{Shape: shape, Data: make([]float64, Product(shape))}
 
-		go func(index int) {
-			output := batchedOp(index)
-			results <- result{index: index, tensor: output}
-		}(i)
+	// ops that produce gradient tracked values (such as MatMulGrad()) keep their Value pointers
+	if len(first.DataReqGrad) > 0 {
+		output.DataReqGrad = make([]*Value, Product(shape))
+		output.RequireGrad = first.RequireGrad
 	}
 
-	// Collect results from the channel (out of order)
-	for i := 0; i < batchsize; i++ {
-		res := <-results
-		orderMap[res.index] = res.tensor
-	}
-	close(results)
+	output.setBatchElement(0, first)
+	return output
+}
+
+// setBatchElement copies element (which has a leading singleton dim) into index i along the 0'th axis of a preallocated batched Tensor.
+func (A *Tensor) setBatchElement(i int, element *Tensor) {
 
-	// Combine results in the tracked order
-	Batched_Output := orderMap[0]
-	for i := 1; i < batchsize; i++ {
-		Batched_Output = Batched_Output.Concat(orderMap[i], 0)
+	if !isEqual(element.Shape[1:], A.Shape[1:]) {
+		panic("Within BatchedOperation(): Every batch element must produce a Tensor of the same shape")
 	}
 
-	return Batched_Output
+	size := Product(A.Shape[1:])
+	copy(A.Data[i*size:(i+1)*size], element.Data)
+	if A.DataReqGrad != nil {
+		copy(A.DataReqGrad[i*size:(i+1)*size], element.DataReqGrad)
+	}
 }
 
 /*
@@ -111,6 +138,12 @@ type Batched_Initializer_Interface interface {
 	Execute(shape []int) *Tensor // Execute the operation and return a tensor.
 }
 
+/*
+* @notice Batched_Initializer_Operation() creates a batched Tensor by executing an initializer for each batch element.
+* @dev The output is preallocated and each element is written directly into it. Elements are initialized sequentially
+* because initializers may hold state that is not safe for concurrent use, such as the Generator of RandomInitializer,
+* and seeded samples are only reproducible when drawn in the same order.
+ */
 func Batched_Initializer_Operation(op Batched_Initializer_Interface, shape []int) *Tensor {
 
 	// Determine the shape of each element (remove the first dimension from the shape)
@@ -128,11 +161,10 @@ func Batched_Initializer_Operation(op Batched_Initializer_Interface, shape []int
 
 	}
 
-	Batched_Tensor := eachElementOp(0) // <--- Execute the operation on the first element
+	Batched_Tensor := newBatchedOutput(eachElementOp(0), shape[0]) // <--- Execute the operation on the first element
 
 	for i := 1; i < shape[0]; i++ { // <--- num batch elements
-		Batch_Element := eachElementOp(i)
-		Batched_Tensor = Batched_Tensor.Concat(Batch_Element, 0)
+		Batched_Tensor.setBatchElement(i, eachElementOp(i))
 	}
 
 	return Batched_Tensor
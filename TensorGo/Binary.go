@@ -0,0 +1,514 @@
+package TG
+
+/*
+* @notice Binary.go contains a compact, versioned binary format for Tensors, and a container format for named Tensors.
+* @dev A Tensor record is laid out as follows, with every integer little endian:
+*
+*	magic "TGTN" | version uint8 | flags uint8 | compression uint8 | dtype uint8 | rank uint32 | shape [rank]uint64 | data | crc32 uint32
+*
+* flags holds bit 0 for Batched and bit 1 for RequireGrad. data is the raw little endian float64 (or float32) elements,
+* passed through the compression codec when one is set. The checksum is a CRC-32 (IEEE) of the header and the
+* uncompressed data, so corruption is detected whether or not the data was compressed.
+* @dev A container is the magic "TGTC", a version uint8 and a count uint32, followed by count entries of a name length
+* uint16, the name, and a Tensor record. Entries are written in order of name.
+* @dev Records are read without reading past their end, so several records can be read from one stream in sequence.
+ */
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	binaryTensorMagic    = "TGTN"
+	binaryContainerMagic = "TGTC"
+	binaryVersion        = 1
+
+	binaryFlagBatched     = 1 << 0
+	binaryFlagRequireGrad = 1 << 1
+
+	binaryFloat64 = 0
+	binaryFloat32 = 1
+
+	binaryChunk   = 4096 // <--- number of elements encoded or decoded at a time
+	binaryMaxRank = 1024 // <--- bounds the rank read from a record, so a corrupt rank cannot exhaust memory
+)
+
+// Compression identifies the codec the data section of a binary Tensor record is compressed with
+type Compression uint8
+
+const (
+	CompressionNone Compression = 0
+	CompressionGzip Compression = 1
+	CompressionZstd Compression = 2 // <--- Zstd.go
+)
+
+// compressionCodec creates compressing writers and decompressing readers for a Compression
+type compressionCodec struct {
+	compress   func(w io.Writer) (io.WriteCloser, error)
+	decompress func(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	compressionMu     sync.RWMutex
+	compressionCodecs = map[Compression]compressionCodec{
+		CompressionGzip: {
+			compress: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+			decompress: func(r io.Reader) (io.ReadCloser, error) {
+				zr, err := gzip.NewReader(r)
+				if err != nil {
+					return nil, err
+				}
+				zr.Multistream(false) // <--- stop at the end of this record's data
+				return zr, nil
+			},
+		},
+		CompressionZstd: {
+			compress:   func(w io.Writer) (io.WriteCloser, error) { return newZstdWriter(w), nil },
+			decompress: func(r io.Reader) (io.ReadCloser, error) { return newZstdReader(r) },
+		},
+	}
+)
+
+/*
+* @notice RegisterCompression() registers the codec used for a Compression, replacing any previous codec.
+* @dev gzip and zstd are built in. A faster zstd implementation, such as github.com/klauspost/compress/zstd, can replace the
+* built in one for CompressionZstd.
+* @dev decompress must not read past the end of the compressed data when r is an io.ByteReader, otherwise records that
+* follow in the same stream cannot be read.
+ */
+func RegisterCompression(c Compression, compress func(w io.Writer) (io.WriteCloser, error), decompress func(r io.Reader) (io.ReadCloser, error)) {
+	if c == CompressionNone {
+		panic("Within RegisterCompression(): CompressionNone cannot have a codec")
+	}
+	compressionMu.Lock()
+	defer compressionMu.Unlock()
+	compressionCodecs[c] = compressionCodec{compress: compress, decompress: decompress}
+}
+
+// codecFor returns the registered codec for c
+func codecFor(c Compression) (compressionCodec, error) {
+	compressionMu.RLock()
+	defer compressionMu.RUnlock()
+	codec, ok := compressionCodecs[c]
+	if !ok {
+		return codec, fmt.Errorf("no codec is registered for compression %d", c)
+	}
+	return codec, nil
+}
+
+// BinaryOptions controls how Tensors are written in the binary format
+type BinaryOptions struct {
+	Compression Compression
+	Float32     bool // <--- store elements as float32, halving the size at the cost of precision
+}
+
+// ErrChecksum is returned when a binary Tensor record does not match its checksum
+var ErrChecksum = errors.New("binary tensor checksum mismatch")
+
+//===================================================================================================================== Tensor records
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+/*
+* @notice WriteBinary() writes A to w as a binary Tensor record. It returns the number of bytes written.
+* @dev Elements are encoded a chunk at a time, so no second copy of Data is made. Gradient tracked Tensors without Data are
+* written from the Scalars of DataReqGrad. Nothing is written when A does not hold one element for every position of its Shape.
+ */
+func WriteBinary(w io.Writer, A *Tensor, options BinaryOptions) (int64, error) {
+
+	values, err := storedValues(A, "WriteBinary")
+	if err != nil {
+		return 0, err
+	}
+
+	out := &countingWriter{w: w}
+	checksum := crc32.NewIEEE()
+
+	// header
+	var flags, dtype uint8
+	if A.Batched {
+		flags |= binaryFlagBatched
+	}
+	if A.RequireGrad {
+		flags |= binaryFlagRequireGrad
+	}
+	itemSize := 8
+	if options.Float32 {
+		dtype, itemSize = binaryFloat32, 4
+	}
+
+	header := append([]byte(binaryTensorMagic), binaryVersion, flags, uint8(options.Compression), dtype)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(A.Shape)))
+	for _, dim := range A.Shape {
+		header = binary.LittleEndian.AppendUint64(header, uint64(dim))
+	}
+	checksum.Write(header)
+	if _, err := out.Write(header); err != nil {
+		return out.n, err
+	}
+
+	// data, through the compression codec when one is set
+	var data io.Writer = out
+	var compressor io.WriteCloser
+	if options.Compression != CompressionNone {
+		codec, err := codecFor(options.Compression)
+		if err != nil {
+			return out.n, fmt.Errorf("WriteBinary(): %w", err)
+		}
+		if compressor, err = codec.compress(out); err != nil {
+			return out.n, fmt.Errorf("WriteBinary(): %w", err)
+		}
+		data = compressor
+	}
+
+	chunk := make([]byte, 0, binaryChunk*itemSize)
+	for start := 0; start < len(values); start += binaryChunk {
+		chunk = chunk[:0]
+		for _, value := range values[start:min(start+binaryChunk, len(values))] {
+			if options.Float32 {
+				chunk = binary.LittleEndian.AppendUint32(chunk, math.Float32bits(float32(value)))
+			} else {
+				chunk = binary.LittleEndian.AppendUint64(chunk, math.Float64bits(value))
+			}
+		}
+		checksum.Write(chunk)
+		if _, err := data.Write(chunk); err != nil {
+			return out.n, err
+		}
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return out.n, err
+		}
+	}
+
+	// trailing checksum
+	err = binary.Write(out, binary.LittleEndian, checksum.Sum32())
+	return out.n, err
+}
+
+// byteReader is the reader records are parsed from. It counts the bytes consumed.
+type byteReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+// newByteReader wraps r, reusing it when it is already a byteReader so that nothing is read ahead twice
+func newByteReader(r io.Reader) *byteReader {
+	if br, ok := r.(*byteReader); ok {
+		return br
+	}
+	if br, ok := r.(*bufio.Reader); ok {
+		return &byteReader{r: br}
+	}
+	return &byteReader{r: bufio.NewReader(r)}
+}
+
+func (b *byteReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	c, err := b.r.ReadByte()
+	if err == nil {
+		b.n++
+	}
+	return c, err
+}
+
+// readFull reads exactly len(p) bytes into p, adding them to checksum
+func readFull(r io.Reader, p []byte, checksum hash.Hash32) error {
+	if _, err := io.ReadFull(r, p); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	checksum.Write(p)
+	return nil
+}
+
+/*
+* @notice ReadBinary() reads a single binary Tensor record from r.
+* @dev When r is not a *bufio.Reader it is wrapped in one, which may read ahead of the record. To read several records
+* from one stream, pass the same *bufio.Reader to each call, or use a container.
+* @dev ErrChecksum is returned, wrapped, when the record is corrupt.
+ */
+func ReadBinary(r io.Reader) (*Tensor, error) {
+	return readBinary(newByteReader(r))
+}
+
+func readBinary(r *byteReader) (*Tensor, error) {
+
+	checksum := crc32.NewIEEE()
+
+	// header
+	header := make([]byte, len(binaryTensorMagic)+8)
+	if err := readFull(r, header, checksum); err != nil {
+		return nil, fmt.Errorf("ReadBinary(): reading header: %w", err)
+	}
+	if string(header[:len(binaryTensorMagic)]) != binaryTensorMagic {
+		return nil, fmt.Errorf("ReadBinary(): not a binary tensor record")
+	}
+	fields := header[len(binaryTensorMagic):]
+	version, flags, compression, dtype := fields[0], fields[1], Compression(fields[2]), fields[3]
+	if version != binaryVersion {
+		return nil, fmt.Errorf("ReadBinary(): unsupported version %d", version)
+	}
+	itemSize := 8
+	switch dtype {
+	case binaryFloat64:
+	case binaryFloat32:
+		itemSize = 4
+	default:
+		return nil, fmt.Errorf("ReadBinary(): unsupported dtype %d", dtype)
+	}
+
+	rank := int(binary.LittleEndian.Uint32(fields[4:]))
+	if rank > binaryMaxRank {
+		return nil, fmt.Errorf("ReadBinary(): invalid rank %d", rank)
+	}
+	shapeBytes := make([]byte, 8*rank)
+	if err := readFull(r, shapeBytes, checksum); err != nil {
+		return nil, fmt.Errorf("ReadBinary(): reading shape: %w", err)
+	}
+	shape := make([]int, rank)
+	for i := range shape {
+		dim := binary.LittleEndian.Uint64(shapeBytes[8*i:])
+		if dim > math.MaxInt32 {
+			return nil, fmt.Errorf("ReadBinary(): invalid shape")
+		}
+		shape[i] = int(dim)
+	}
+	numElements, ok := checkedProduct(shape, itemSize)
+	if !ok {
+		return nil, fmt.Errorf("ReadBinary(): invalid shape")
+	}
+
+	// data, through the compression codec when one is set
+	var data io.Reader = r
+	var decompressor io.ReadCloser
+	if compression != CompressionNone {
+		codec, err := codecFor(compression)
+		if err != nil {
+			return nil, fmt.Errorf("ReadBinary(): %w", err)
+		}
+		if decompressor, err = codec.decompress(r); err != nil {
+			return nil, fmt.Errorf("ReadBinary(): %w", err)
+		}
+		defer decompressor.Close()
+		data = decompressor
+	}
+
+	// the Tensor grows as data arrives rather than trusting the shape, so a corrupt shape fails on a short read
+	A := &Tensor{Shape: shape, Data: make([]float64, 0, min(numElements, binaryChunk))}
+	chunk := make([]byte, binaryChunk*itemSize)
+	for start := 0; start < numElements; start += binaryChunk {
+		n := min(binaryChunk, numElements-start)
+		if err := readFull(data, chunk[:n*itemSize], checksum); err != nil {
+			return nil, fmt.Errorf("ReadBinary(): reading data: %w", err)
+		}
+		for k := 0; k < n; k++ {
+			if itemSize == 4 {
+				A.Data = append(A.Data, float64(math.Float32frombits(binary.LittleEndian.Uint32(chunk[4*k:]))))
+			} else {
+				A.Data = append(A.Data, math.Float64frombits(binary.LittleEndian.Uint64(chunk[8*k:])))
+			}
+		}
+	}
+
+	// drain the compressed stream so that its trailer is consumed and verified
+	if decompressor != nil {
+		if extra, err := io.Copy(io.Discard, decompressor); err != nil || extra != 0 {
+			return nil, fmt.Errorf("ReadBinary(): invalid compressed data: %w", errors.Join(err, ErrChecksum))
+		}
+	}
+
+	var stored uint32
+	if err := binary.Read(r, binary.LittleEndian, &stored); err != nil {
+		return nil, fmt.Errorf("ReadBinary(): reading checksum: %w", err)
+	}
+	if stored != checksum.Sum32() {
+		return nil, fmt.Errorf("ReadBinary(): %w", ErrChecksum)
+	}
+
+	A.Batched = flags&binaryFlagBatched != 0
+	if flags&binaryFlagRequireGrad != 0 {
+		A.RequireGrad = true
+		A.DataReqGrad = make([]*Value, len(A.Data))
+		for i, value := range A.Data {
+			A.DataReqGrad[i] = NewValue(value, nil, "")
+		}
+	}
+	return A, nil
+}
+
+// WriteTo() writes A to w as an uncompressed float64 binary Tensor record. It implements io.WriterTo.
+func (A *Tensor) WriteTo(w io.Writer) (int64, error) {
+	return WriteBinary(w, A, BinaryOptions{})
+}
+
+// ReadFrom() replaces A with a binary Tensor record read from r. It implements io.ReaderFrom, returning the number of bytes in the record.
+func (A *Tensor) ReadFrom(r io.Reader) (int64, error) {
+	br := newByteReader(r)
+	start := br.n
+
+	B, err := readBinary(br)
+	if err != nil {
+		return br.n - start, err
+	}
+	*A = *B
+	return br.n - start, nil
+}
+
+// SaveBinary() saves a Tensor to a file in the binary format.
+func SaveBinary(A *Tensor, fileName string, options BinaryOptions) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if _, err := WriteBinary(file, A, options); err != nil {
+		panic(err)
+	}
+}
+
+// LoadBinary() loads a Tensor from a file in the binary format.
+func LoadBinary(fileName string) *Tensor {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	A, err := ReadBinary(file)
+	if err != nil {
+		panic(err)
+	}
+	return A
+}
+
+//===================================================================================================================== Containers
+
+// WriteTensors() writes named Tensors to w as a container of binary Tensor records, in order of name.
+func WriteTensors(w io.Writer, tensors map[string]*Tensor, options BinaryOptions) error {
+
+	names := make([]string, 0, len(tensors))
+	for name := range tensors {
+		if len(name) > math.MaxUint16 {
+			return fmt.Errorf("WriteTensors(): name %q is too long", name[:32])
+		}
+		if _, err := storedValues(tensors[name], "WriteBinary"); err != nil { // <--- check every Tensor before writing any
+			return fmt.Errorf("WriteTensors(): tensor %q: %w", name, err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writer := bufio.NewWriter(w)
+	header := append([]byte(binaryContainerMagic), binaryVersion)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(names)))
+	if _, err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		entry := binary.LittleEndian.AppendUint16(nil, uint16(len(name)))
+		if _, err := writer.Write(append(entry, name...)); err != nil {
+			return err
+		}
+		if _, err := WriteBinary(writer, tensors[name], options); err != nil {
+			return fmt.Errorf("WriteTensors(): tensor %q: %w", name, err)
+		}
+	}
+	return writer.Flush()
+}
+
+// ReadTensors() reads a container of named binary Tensor records from r.
+func ReadTensors(r io.Reader) (map[string]*Tensor, error) {
+
+	br := newByteReader(r)
+	discard := crc32.NewIEEE() // <--- the container header is not checksummed, each record is
+
+	header := make([]byte, len(binaryContainerMagic)+5)
+	if err := readFull(br, header, discard); err != nil {
+		return nil, fmt.Errorf("ReadTensors(): reading header: %w", err)
+	}
+	if string(header[:len(binaryContainerMagic)]) != binaryContainerMagic {
+		return nil, fmt.Errorf("ReadTensors(): not a tensor container")
+	}
+	if version := header[len(binaryContainerMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("ReadTensors(): unsupported version %d", version)
+	}
+	count := binary.LittleEndian.Uint32(header[len(binaryContainerMagic)+1:])
+
+	tensors := make(map[string]*Tensor, min(count, 64)) // <--- the count is not trusted for the allocation
+	for i := uint32(0); i < count; i++ {
+		var nameLength uint16
+		if err := binary.Read(br, binary.LittleEndian, &nameLength); err != nil {
+			return nil, fmt.Errorf("ReadTensors(): reading entry %d: %w", i, err)
+		}
+		name := make([]byte, nameLength)
+		if err := readFull(br, name, discard); err != nil {
+			return nil, fmt.Errorf("ReadTensors(): reading entry %d: %w", i, err)
+		}
+		A, err := readBinary(br)
+		if err != nil {
+			return nil, fmt.Errorf("ReadTensors(): tensor %q: %w", name, err)
+		}
+		tensors[string(name)] = A
+	}
+	return tensors, nil
+}
+
+// SaveTensors() saves named Tensors to a container file.
+func SaveTensors(tensors map[string]*Tensor, fileName string, options BinaryOptions) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if err := WriteTensors(file, tensors, options); err != nil {
+		panic(err)
+	}
+}
+
+// LoadTensors() loads named Tensors from a container file.
+func LoadTensors(fileName string) map[string]*Tensor {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	tensors, err := ReadTensors(file)
+	if err != nil {
+		panic(err)
+	}
+	return tensors
+}
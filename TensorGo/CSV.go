@@ -0,0 +1,431 @@
+package TG
+
+/*
+* @notice CSV.go contains a configurable CSV reader that turns tabular data into a [rows, columns] Tensor.
+* @dev Cells matching one of the NA tokens are missing. Missing cells are NaN unless an imputation strategy fills them.
+* @dev Columns holding any cell that is not a number are categorical. Categorical columns are label encoded, each category
+* becoming a whole number code in order of first appearance, or one-hot encoded into one 0/1 column per category. The
+* categories of each column are returned so that codes can be mapped back to strings.
+* @dev CSVReader.Next() reads a chunk of rows at a time, so files larger than memory can be streamed. Column types and one-hot
+* categories are then fixed by the first chunk, and only NaN or constant imputation is possible, as the other strategies need
+* statistics of the whole column. CSVReader.ReadAll() and ReadCSV() read every remaining row at once and support every strategy.
+ */
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ImputeStrategy chooses how missing cells are filled
+type ImputeStrategy int
+
+const (
+	ImputeNaN          ImputeStrategy = iota // <--- leave missing cells as NaN
+	ImputeConstant                           // <--- fill with CSVOptions.FillValue
+	ImputeMean                               // <--- fill with the column mean
+	ImputeMedian                             // <--- fill with the column median
+	ImputeMostFrequent                       // <--- fill with the most frequent value of the column
+)
+
+// CategoricalEncoding chooses how string columns are converted to numbers
+type CategoricalEncoding int
+
+const (
+	EncodeLabel  CategoricalEncoding = iota // <--- one column of category codes
+	EncodeOneHot                            // <--- one 0/1 column per category
+)
+
+// DefaultNATokens are the cells treated as missing when CSVOptions.NATokens is nil
+var DefaultNATokens = []string{"", "NA", "N/A", "NaN", "nan", "null", "NULL", "None"}
+
+// CSVOptions configures a CSVReader. The zero value reads comma separated data without a header.
+type CSVOptions struct {
+	Delimiter   rune                // <--- field delimiter, ',' when zero
+	Comment     rune                // <--- lines starting with this rune are skipped, none when zero
+	LazyQuotes  bool                // <--- allow quotes to appear in unquoted fields
+	Header      bool                // <--- the first row holds the column names
+	Columns     []string            // <--- names of the columns to read, in order. Every column when nil. Without a header, columns are named "0", "1", ...
+	NATokens    []string            // <--- cells that are missing, DefaultNATokens when nil
+	Impute      ImputeStrategy      // <--- how missing cells are filled
+	FillValue   float64             // <--- the fill value for ImputeConstant
+	Categorical []string            // <--- columns to treat as categorical even when every cell is a number
+	Encoding    CategoricalEncoding // <--- how categorical columns are encoded
+	Categories  map[string][]string // <--- known categories of a column, fixing its codes. Other categories found are appended.
+	ChunkSize   int                 // <--- rows returned by each call to Next(), 1024 when zero
+}
+
+// csvColumn is the state of a single selected source column
+type csvColumn struct {
+	name        string
+	source      int
+	categorical bool
+	categories  []string
+	codes       map[string]int
+}
+
+// CSVReader reads a CSV stream into Tensors
+type CSVReader struct {
+	options  CSVOptions
+	reader   *csv.Reader
+	columns  []*csvColumn
+	na       map[string]bool
+	resolved bool       // <--- column types have been fixed
+	pending  [][]string // <--- the first record, read without a header to count the columns
+}
+
+/*
+* @notice NewCSVReader() creates a CSVReader over r, reading the header when there is one.
+* @dev An error is returned when a selected column does not exist.
+ */
+func NewCSVReader(r io.Reader, options CSVOptions) (*CSVReader, error) {
+
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.NATokens == nil {
+		options.NATokens = DefaultNATokens
+	}
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = 1024
+	}
+
+	c := &CSVReader{options: options, reader: csv.NewReader(r), na: map[string]bool{}}
+	c.reader.Comma, c.reader.Comment, c.reader.LazyQuotes = options.Delimiter, options.Comment, options.LazyQuotes
+	for _, token := range options.NATokens {
+		c.na[token] = true
+	}
+
+	// the header, or the first record when there is no header, gives the column names
+	first, err := c.reader.Read()
+	if err == io.EOF {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("NewCSVReader(): %w", err)
+	}
+	names := make([]string, len(first))
+	for i := range first {
+		names[i] = strconv.Itoa(i)
+		if options.Header {
+			names[i] = strings.TrimSpace(first[i])
+		}
+	}
+	if !options.Header {
+		c.pending = [][]string{first}
+	}
+
+	// select the requested columns
+	selected := options.Columns
+	if selected == nil {
+		selected = names
+	}
+	for _, name := range selected {
+		source := slices.Index(names, name)
+		if source < 0 {
+			return nil, fmt.Errorf("NewCSVReader(): column %q does not exist", name)
+		}
+		column := &csvColumn{name: name, source: source, codes: map[string]int{}}
+		column.categorical = slices.Contains(options.Categorical, name)
+		for _, category := range options.Categories[name] {
+			column.addCategory(category)
+		}
+		c.columns = append(c.columns, column)
+	}
+	return c, nil
+}
+
+// addCategory returns the code of category, adding it when it is new
+func (column *csvColumn) addCategory(category string) int {
+	code, ok := column.codes[category]
+	if !ok {
+		code = len(column.categories)
+		column.codes[category] = code
+		column.categories = append(column.categories, category)
+	}
+	return code
+}
+
+// readRecords reads up to n records, or every remaining record when n < 0. It returns io.EOF when no records remain.
+func (c *CSVReader) readRecords(n int) ([][]string, error) {
+	records := c.pending
+	c.pending = nil
+	for n < 0 || len(records) < n {
+		record, err := c.reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, io.EOF
+	}
+	return records, nil
+}
+
+// resolve fixes the type of each column from records, and collects the categories of categorical columns
+func (c *CSVReader) resolve(records [][]string) {
+	for _, column := range c.columns {
+		for _, record := range records {
+			cell := strings.TrimSpace(record[column.source])
+			if c.na[cell] {
+				continue
+			}
+			if _, err := strconv.ParseFloat(cell, 64); err != nil {
+				column.categorical = true
+			}
+		}
+		if column.categorical {
+			for _, record := range records {
+				if cell := strings.TrimSpace(record[column.source]); !c.na[cell] {
+					column.addCategory(cell)
+				}
+			}
+		}
+	}
+	c.resolved = true
+}
+
+// width returns the number of output columns a column encodes to
+func (c *CSVReader) width(column *csvColumn) int {
+	if column.categorical && c.options.Encoding == EncodeOneHot {
+		return len(column.categories)
+	}
+	return 1
+}
+
+// ColumnNames() returns the name of each column of the Tensors read. One-hot columns are named "column=category".
+func (c *CSVReader) ColumnNames() []string {
+	names := []string{}
+	for _, column := range c.columns {
+		if column.categorical && c.options.Encoding == EncodeOneHot {
+			for _, category := range column.categories {
+				names = append(names, column.name+"="+category)
+			}
+			continue
+		}
+		names = append(names, column.name)
+	}
+	return names
+}
+
+// Categories() returns the categories of each categorical column, indexed by code.
+func (c *CSVReader) Categories() map[string][]string {
+	categories := map[string][]string{}
+	for _, column := range c.columns {
+		if column.categorical {
+			categories[column.name] = append([]string{}, column.categories...)
+		}
+	}
+	return categories
+}
+
+// encode converts records to a [rows, columns] Tensor. Missing cells are NaN. growOneHot allows new one-hot categories.
+func (c *CSVReader) encode(records [][]string, growOneHot bool) (*Tensor, error) {
+
+	numColumns := 0
+	for _, column := range c.columns {
+		numColumns += c.width(column)
+	}
+	A := &Tensor{Shape: []int{len(records), numColumns}, Data: make([]float64, len(records)*numColumns)}
+
+	offset := 0
+	for _, column := range c.columns {
+		width := c.width(column)
+		for i, record := range records {
+			if column.source >= len(record) {
+				return nil, fmt.Errorf("row has %d fields, column %q needs %d", len(record), column.name, column.source+1)
+			}
+			row := A.Data[i*numColumns+offset : i*numColumns+offset+width]
+			cell := strings.TrimSpace(record[column.source])
+
+			switch {
+			case c.na[cell]:
+				for k := range row {
+					row[k] = math.NaN()
+				}
+			case !column.categorical:
+				value, err := strconv.ParseFloat(cell, 64)
+				if err != nil {
+					return nil, fmt.Errorf("column %q: %q is not a number", column.name, cell)
+				}
+				row[0] = value
+			case c.options.Encoding == EncodeOneHot:
+				code, ok := column.codes[cell]
+				if !ok && !growOneHot {
+					return nil, fmt.Errorf("column %q: unknown category %q, pass every category in CSVOptions.Categories to stream one-hot columns", column.name, cell)
+				}
+				row[code] = 1
+			default:
+				row[0] = float64(column.addCategory(cell))
+			}
+		}
+		offset += width
+	}
+	return A, nil
+}
+
+// impute fills the missing cells of A with the configured strategy
+func (c *CSVReader) impute(A *Tensor) {
+
+	if c.options.Impute == ImputeNaN {
+		return
+	}
+	numColumns, offset := A.Shape[1], 0
+	for _, column := range c.columns {
+		width := c.width(column)
+		if width == 0 {
+			continue // <--- a one-hot column without any categories
+		}
+
+		// the fill value of each output column of this source column
+		fill := make([]float64, width)
+		switch strategy := c.options.Impute; {
+		case strategy == ImputeConstant:
+			for k := range fill {
+				fill[k] = c.options.FillValue
+			}
+		case column.categorical && width > 1:
+			counts := make([]float64, width)
+			for i := 0; i < A.Shape[0]; i++ {
+				for k := range counts {
+					counts[k] += zeroIfNaN(A.Data[i*numColumns+offset+k])
+				}
+			}
+			fill[slices.Index(counts, slices.Max(counts))] = 1
+		default:
+			lane := []float64{}
+			for i := 0; i < A.Shape[0]; i++ {
+				if value := A.Data[i*numColumns+offset]; !math.IsNaN(value) {
+					lane = append(lane, value)
+				}
+			}
+			fill[0] = imputeValue(lane, strategy, column.categorical)
+		}
+
+		for i := 0; i < A.Shape[0]; i++ {
+			row := A.Data[i*numColumns+offset : i*numColumns+offset+width]
+			if math.IsNaN(row[0]) {
+				copy(row, fill)
+			}
+		}
+		offset += width
+	}
+}
+
+func zeroIfNaN(value float64) float64 {
+	if math.IsNaN(value) {
+		return 0
+	}
+	return value
+}
+
+// imputeValue computes the fill value of a column from its present values. Categorical columns always use the most frequent code.
+func imputeValue(values []float64, strategy ImputeStrategy, categorical bool) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	if categorical {
+		strategy = ImputeMostFrequent
+	}
+	switch strategy {
+	case ImputeMean:
+		sum := 0.0
+		for _, value := range values {
+			sum += value
+		}
+		return sum / float64(len(values))
+	case ImputeMedian:
+		return QuantileReducer{Q: 0.5, Method: QuantileLinear}.Reduce(values)
+	}
+
+	// the most frequent value, the smallest on ties
+	counts := map[float64]int{}
+	best := math.Inf(1)
+	for _, value := range values {
+		counts[value]++
+	}
+	for value, count := range counts {
+		if count > counts[best] || (count == counts[best] && value < best) {
+			best = value
+		}
+	}
+	return best
+}
+
+/*
+* @notice Next() reads the next chunk of up to ChunkSize rows as a [rows, columns] Tensor. It returns io.EOF once every row has been read.
+* @dev Column types, and the categories of one-hot columns, are fixed by the first chunk unless given in CSVOptions. Only
+* ImputeNaN and ImputeConstant can be used, since the other strategies need the whole column.
+ */
+func (c *CSVReader) Next() (*Tensor, error) {
+
+	if c.options.Impute != ImputeNaN && c.options.Impute != ImputeConstant {
+		return nil, errors.New("Next(): only ImputeNaN and ImputeConstant can be used when reading in chunks, use ReadAll()")
+	}
+	records, err := c.readRecords(c.options.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	growOneHot := !c.resolved
+	if !c.resolved {
+		c.resolve(records)
+	}
+	A, err := c.encode(records, growOneHot)
+	if err != nil {
+		return nil, fmt.Errorf("Next(): %w", err)
+	}
+	c.impute(A)
+	return A, nil
+}
+
+// ReadAll() reads every remaining row as a [rows, columns] Tensor, applying any imputation strategy.
+func (c *CSVReader) ReadAll() (*Tensor, error) {
+
+	records, err := c.readRecords(-1)
+	if err == io.EOF {
+		records = [][]string{}
+	} else if err != nil {
+		return nil, fmt.Errorf("ReadAll(): %w", err)
+	}
+
+	growOneHot := !c.resolved
+	if !c.resolved {
+		c.resolve(records)
+	}
+	A, err := c.encode(records, growOneHot)
+	if err != nil {
+		return nil, fmt.Errorf("ReadAll(): %w", err)
+	}
+	c.impute(A)
+	return A, nil
+}
+
+// CSVTable is the result of reading a whole CSV
+type CSVTable struct {
+	Data       *Tensor             // <--- [rows, columns]
+	Columns    []string            // <--- the name of each column of Data
+	Categories map[string][]string // <--- the categories of each categorical column, indexed by code
+}
+
+// ReadCSV() reads an entire CSV stream into a CSVTable.
+func ReadCSV(r io.Reader, options CSVOptions) (*CSVTable, error) {
+	c, err := NewCSVReader(r, options)
+	if err != nil {
+		return nil, err
+	}
+	A, err := c.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return &CSVTable{Data: A, Columns: c.ColumnNames(), Categories: c.Categories()}, nil
+}
@@ -0,0 +1,637 @@
+package TG
+
+/*
+* @notice Clustering.go contains unsupervised clustering models for [samples, features] Tensors: KMeans, MiniBatchKMeans and
+* GaussianMixture, and the silhouette score of a clustering.
+* @dev Distances between samples and centers come from PairwiseDistances(), which computes them from dot products. The
+* assignment step of each Lloyd iteration and the E step of EM run in parallel over blocks of samples, and the results of the
+* blocks are combined in order, so a seeded Generator reproduces the same fit.
+ */
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// clusterBlock is the number of samples handled by each parallel task
+const clusterBlock = 256
+
+// Clusterer groups samples into clusters
+type Clusterer interface {
+	Fit(X *Tensor)
+	Predict(X *Tensor) *Tensor
+}
+
+//============================================================================================================================== Helpers
+
+// rowsOf returns rows [start, end) of the matrix X as a Tensor sharing its data
+func rowsOf(X *Tensor, start, end int) *Tensor {
+	f := X.Shape[1]
+	return &Tensor{Shape: []int{end - start, f}, Data: X.Data[start*f : end*f]}
+}
+
+// numBlocks returns the number of clusterBlock sized blocks covering n samples
+func numBlocks(n int) int {
+	return (n + clusterBlock - 1) / clusterBlock
+}
+
+// checkClusters panics unless there are between 1 and n clusters
+func checkClusters(k, n int, caller string) {
+	if k < 1 || k > n {
+		panic(fmt.Sprintf("Within %v(): the number of clusters must be between 1 and the number of samples %v, got %v", caller, n, k))
+	}
+}
+
+// meanVariance returns the mean of the variances of the features of X
+func meanVariance(X *Tensor) float64 {
+	return MeanReducer{}.Reduce(X.Var([]int{0}, false, false).Data)
+}
+
+/*
+* @notice kmeansPlusPlus() chooses k initial centers from the samples of X. The first is chosen uniformly, and each following
+* center is chosen with probability proportional to its squared distance from the closest center already chosen.
+ */
+func kmeansPlusPlus(X *Tensor, k int, g *Generator) *Tensor {
+	n, f := X.Shape[0], X.Shape[1]
+	centers := &Tensor{Shape: []int{k, f}, Data: make([]float64, 0, k*f)}
+
+	chosen := g.Intn(n)
+	closest := PairwiseDistances(X, rowsOf(X, chosen, chosen+1), true, false).Data
+	centers.Data = append(centers.Data, X.Data[chosen*f:(chosen+1)*f]...)
+	for c := 1; c < k; c++ {
+		if (SumReducer{}).Reduce(closest) > 0 {
+			chosen = g.Categorical(closest)
+		} else {
+			chosen = g.Intn(n) // <--- every sample coincides with a center
+		}
+		distances := PairwiseDistances(X, rowsOf(X, chosen, chosen+1), true, false).Data
+		for i := range closest {
+			closest[i] = math.Min(closest[i], distances[i])
+		}
+		centers.Data = append(centers.Data, X.Data[chosen*f:(chosen+1)*f]...)
+	}
+	return centers
+}
+
+// assignClusters returns the closest center of each sample of X and its squared distance
+func assignClusters(X *Tensor, centers *Tensor) ([]int, []float64) {
+	D := PairwiseDistances(X, centers, true, false)
+	n, k := X.Shape[0], centers.Shape[0]
+	labels, distances := make([]int, n), make([]float64, n)
+	parallelForIfLarge(n*k, numBlocks(n), func(b int) {
+		for i := b * clusterBlock; i < min((b+1)*clusterBlock, n); i++ {
+			row := D.Data[i*k : (i+1)*k]
+			labels[i] = slices.Index(row, slices.Min(row))
+			distances[i] = row[labels[i]]
+		}
+	})
+	return labels, distances
+}
+
+// nearestCenters returns the closest center of each sample of X as a Tensor of cluster indices
+func nearestCenters(X *Tensor, centers *Tensor, caller string) *Tensor {
+	if centers == nil {
+		panic("Within " + caller + "(): the model must be fit first")
+	}
+	checkFeatures(X, centers.Shape[1], caller)
+	labels, _ := assignClusters(X, centers)
+	C := intsTensor(labels)
+	C.Batched = X.Batched
+	return C
+}
+
+/*
+* @notice lloyd() runs Lloyd's algorithm from centers, which are updated in place, returning the cluster of each sample, the
+* inertia and the number of iterations.
+* @dev Each iteration assigns every sample to its closest center and moves each center to the mean of its samples, until the
+* squared distance moved by the centers is at most tol. A center left without samples is moved to the sample farthest from
+* its own center.
+ */
+func lloyd(X *Tensor, centers *Tensor, maxIter int, tol float64) ([]int, float64, int) {
+
+	n, f, k := X.Shape[0], X.Shape[1], centers.Shape[0]
+	blocks := numBlocks(n)
+	iter := 0
+	for iter < maxIter {
+		iter++
+		labels, distances := assignClusters(X, centers)
+
+		// each block sums its own samples, and the blocks are combined in order
+		sums, counts := make([][]float64, blocks), make([][]int, blocks)
+		parallelFor(blocks, func(b int) {
+			sums[b], counts[b] = make([]float64, k*f), make([]int, k)
+			for i := b * clusterBlock; i < min((b+1)*clusterBlock, n); i++ {
+				axpy(1, X.Data[i*f:(i+1)*f], sums[b][labels[i]*f:(labels[i]+1)*f])
+				counts[b][labels[i]]++
+			}
+		})
+		updated, sizes := make([]float64, k*f), make([]int, k)
+		for b := range sums {
+			axpy(1, sums[b], updated)
+			for c := range sizes {
+				sizes[c] += counts[b][c]
+			}
+		}
+
+		for c := 0; c < k; c++ {
+			center := updated[c*f : (c+1)*f]
+			if sizes[c] == 0 {
+				farthest := slices.Index(distances, slices.Max(distances))
+				copy(center, X.Data[farthest*f:(farthest+1)*f])
+				distances[farthest] = 0
+				continue
+			}
+			for j := range center {
+				center[j] /= float64(sizes[c])
+			}
+		}
+
+		shift := 0.0
+		for i := range updated {
+			shift += (updated[i] - centers.Data[i]) * (updated[i] - centers.Data[i])
+		}
+		copy(centers.Data, updated)
+		if shift <= tol {
+			break
+		}
+	}
+
+	labels, distances := assignClusters(X, centers)
+	return labels, SumReducer{}.Reduce(distances), iter
+}
+
+//============================================================================================================================== KMeans
+
+/*
+* @notice KMeans partitions samples into K clusters around centers, minimizing the inertia, the sum of squared distances from
+* each sample to its closest center.
+* @dev Fit() runs Lloyd's algorithm from NumInit k-means++ initializations and keeps the run with the lowest inertia. A run
+* converges when the centers move less than Tol times the mean variance of the features.
+ */
+type KMeans struct {
+	K         int
+	NumInit   int
+	MaxIter   int
+	Tol       float64
+	Generator *Generator // <--- the source of initialization, DefaultGenerator() when nil
+
+	Centers *Tensor // <--- [K, features]
+	Labels  *Tensor // <--- [samples], the cluster of each training sample
+	Inertia float64 // <--- the inertia of the training samples
+	NumIter int     // <--- iterations used by the best run
+}
+
+// NewKMeans() creates a KMeans with k clusters.
+func NewKMeans(k int) *KMeans {
+	return &KMeans{K: k, NumInit: 3, MaxIter: 300, Tol: 1e-4}
+}
+
+func (m *KMeans) Fit(X *Tensor) {
+	checkFeatures(X, -1, "KMeans.Fit")
+	checkClusters(m.K, X.Shape[0], "KMeans.Fit")
+	g := m.Generator
+	if g == nil {
+		g = DefaultGenerator()
+	}
+	tol := m.Tol * meanVariance(X)
+
+	m.Inertia = math.Inf(1)
+	for run := 0; run < max(1, m.NumInit); run++ {
+		centers := kmeansPlusPlus(X, m.K, g)
+		labels, inertia, iter := lloyd(X, centers, m.MaxIter, tol)
+		if inertia < m.Inertia {
+			m.Centers, m.Labels, m.Inertia, m.NumIter = centers, intsTensor(labels), inertia, iter
+		}
+	}
+}
+
+// Predict() returns the closest center of each sample as [samples].
+func (m *KMeans) Predict(X *Tensor) *Tensor {
+	return nearestCenters(X, m.Centers, "KMeans.Predict")
+}
+
+// Transform() returns the distance from each sample to each center as [samples, K].
+func (m *KMeans) Transform(X *Tensor) *Tensor {
+	if m.Centers == nil {
+		panic("Within KMeans.Transform(): the model must be fit first")
+	}
+	checkFeatures(X, m.Centers.Shape[1], "KMeans.Transform")
+	return PairwiseDistances(X, m.Centers, false, false)
+}
+
+//============================================================================================================================== MiniBatchKMeans
+
+/*
+* @notice MiniBatchKMeans approximates KMeans by updating the centers from small random batches of samples, which scales to
+* datasets too large for full Lloyd iterations and to data that arrives in pieces.
+* @dev Each sample of a batch moves its closest center towards it by 1 / (the number of samples the center has absorbed), so
+* each center is the running mean of its samples. Fit() initializes with k-means++ on a sample of the data and then takes
+* MaxIter batches. PartialFit() takes a single batch, initializing from it on the first call.
+ */
+type MiniBatchKMeans struct {
+	K         int
+	BatchSize int
+	MaxIter   int
+	Generator *Generator // <--- the source of initialization and batches, DefaultGenerator() when nil
+
+	Centers *Tensor   // <--- [K, features]
+	Counts  []float64 // <--- the number of samples absorbed by each center
+	Labels  *Tensor   // <--- [samples], the cluster of each training sample after Fit()
+	Inertia float64   // <--- the inertia of the training samples after Fit()
+}
+
+// NewMiniBatchKMeans() creates a MiniBatchKMeans with k clusters and the given batch size.
+func NewMiniBatchKMeans(k int, batchSize int) *MiniBatchKMeans {
+	if batchSize < 1 {
+		panic("Within NewMiniBatchKMeans(): batchSize must be positive")
+	}
+	return &MiniBatchKMeans{K: k, BatchSize: batchSize, MaxIter: 100}
+}
+
+func (m *MiniBatchKMeans) generator() *Generator {
+	if m.Generator == nil {
+		return DefaultGenerator()
+	}
+	return m.Generator
+}
+
+func (m *MiniBatchKMeans) Fit(X *Tensor) {
+	checkFeatures(X, -1, "MiniBatchKMeans.Fit")
+	n := X.Shape[0]
+	checkClusters(m.K, n, "MiniBatchKMeans.Fit")
+	g := m.generator()
+
+	initSize := min(n, max(3*m.BatchSize, m.K))
+	m.Centers = kmeansPlusPlus(X.IndexSelect(0, g.Choice(n, initSize, false), false), m.K, g)
+	m.Counts = make([]float64, m.K)
+	for iter := 0; iter < m.MaxIter; iter++ {
+		m.PartialFit(X.IndexSelect(0, g.Choice(n, min(n, m.BatchSize), false), false))
+	}
+
+	labels, distances := assignClusters(X, m.Centers)
+	m.Labels, m.Inertia = intsTensor(labels), SumReducer{}.Reduce(distances)
+}
+
+// PartialFit() updates the centers with one batch of samples.
+func (m *MiniBatchKMeans) PartialFit(X *Tensor) {
+	if m.Centers == nil {
+		checkFeatures(X, -1, "MiniBatchKMeans.PartialFit")
+		checkClusters(m.K, X.Shape[0], "MiniBatchKMeans.PartialFit")
+		m.Centers = kmeansPlusPlus(X, m.K, m.generator())
+		m.Counts = make([]float64, m.K)
+	}
+	f := m.Centers.Shape[1]
+	checkFeatures(X, f, "MiniBatchKMeans.PartialFit")
+
+	labels, _ := assignClusters(X, m.Centers)
+	for i, c := range labels {
+		m.Counts[c]++
+		center := m.Centers.Data[c*f : (c+1)*f]
+		eta := 1 / m.Counts[c]
+		for j := range center {
+			center[j] += eta * (X.Data[i*f+j] - center[j])
+		}
+	}
+}
+
+// Predict() returns the closest center of each sample as [samples].
+func (m *MiniBatchKMeans) Predict(X *Tensor) *Tensor {
+	return nearestCenters(X, m.Centers, "MiniBatchKMeans.Predict")
+}
+
+//============================================================================================================================== Silhouette
+
+/*
+* @notice SilhouetteSamples() returns the silhouette coefficient of each sample of X for the cluster labels, as [samples].
+* @dev The coefficient is (b - a) / max(a, b), where a is the mean distance to the other samples of the same cluster and b is
+* the mean distance to the samples of the nearest other cluster. It is near 1 for well separated clusters, and 0 for samples
+* alone in their cluster. Distances are computed a block of samples at a time, so memory grows linearly with the samples.
+ */
+func SilhouetteSamples(X *Tensor, labels *Tensor) *Tensor {
+	checkFeatures(X, -1, "SilhouetteSamples")
+	n := X.Shape[0]
+	clusters, k := classesOf(labels, n, "SilhouetteSamples")
+	if k < 2 || k >= n {
+		panic(fmt.Sprintf("Within SilhouetteSamples(): the number of clusters must be between 2 and %v, got %v", n-1, k))
+	}
+	sizes := make([]int, k)
+	for _, c := range clusters {
+		sizes[c]++
+	}
+
+	S := &Tensor{Shape: []int{n}, Data: make([]float64, n)}
+	parallelFor(numBlocks(n), func(b int) {
+		start, end := b*clusterBlock, min((b+1)*clusterBlock, n)
+		D := PairwiseDistances(rowsOf(X, start, end), X, false, false)
+		sums := make([]float64, k)
+		for i := start; i < end; i++ {
+			clear(sums)
+			for j, distance := range D.Data[(i-start)*n : (i-start+1)*n] {
+				sums[clusters[j]] += distance
+			}
+			own := clusters[i]
+			if sizes[own] == 1 {
+				continue
+			}
+			a, nearest := sums[own]/float64(sizes[own]-1), math.Inf(1)
+			for c, sum := range sums {
+				if c != own {
+					nearest = math.Min(nearest, sum/float64(sizes[c]))
+				}
+			}
+			if spread := math.Max(a, nearest); spread > 0 {
+				S.Data[i] = (nearest - a) / spread
+			}
+		}
+	})
+	return S
+}
+
+// SilhouetteScore() returns the mean silhouette coefficient of the samples of X for the cluster labels.
+func SilhouetteScore(X *Tensor, labels *Tensor) float64 {
+	return MeanReducer{}.Reduce(SilhouetteSamples(X, labels).Data)
+}
+
+//============================================================================================================================== GaussianMixture
+
+// CovarianceType chooses the form of the covariance of each mixture component
+type CovarianceType int
+
+const (
+	CovarianceFull      CovarianceType = iota // <--- a full [features, features] covariance per component
+	CovarianceDiag                            // <--- a variance per feature per component
+	CovarianceSpherical                       // <--- a single variance per component
+)
+
+/*
+* @notice GaussianMixture models samples as drawn from a mixture of K Gaussians, fit by expectation maximization.
+* @dev Fit() initializes the components from a KMeans clustering, then alternates computing the responsibility of each
+* component for each sample (E step) and refitting the weights, means and covariances to the responsibilities (M step). It
+* stops when the mean log likelihood of the samples improves by less than Tol, or after MaxIter iterations.
+* @dev RegCovar is added to the diagonal of each covariance to keep it positive definite.
+* @dev Covariances has shape [K, features, features], [K, features] or [K] for full, diagonal and spherical covariances.
+ */
+type GaussianMixture struct {
+	K          int
+	Covariance CovarianceType
+	MaxIter    int
+	Tol        float64
+	RegCovar   float64
+	Generator  *Generator // <--- the source of the KMeans initialization, DefaultGenerator() when nil
+
+	Weights     *Tensor // <--- [K], the mixing weight of each component
+	Means       *Tensor // <--- [K, features]
+	Covariances *Tensor
+	Converged   bool
+	NumIter     int
+	LowerBound  float64 // <--- the mean log likelihood of the training samples at the last iteration
+}
+
+// NewGaussianMixture() creates a GaussianMixture with k components of the given covariance type.
+func NewGaussianMixture(k int, covariance CovarianceType) *GaussianMixture {
+	return &GaussianMixture{K: k, Covariance: covariance, MaxIter: 100, Tol: 1e-3, RegCovar: 1e-6}
+}
+
+// cholesky returns the lower triangular L with L L^T = A for the symmetric row major A [n, n], or false when A is not positive definite
+func cholesky(A []float64, n int) ([]float64, bool) {
+	L := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := A[i*n+j] - dotSlices(L[i*n:i*n+j], L[j*n:j*n+j])
+			if i != j {
+				L[i*n+j] = sum / L[j*n+j]
+				continue
+			}
+			if sum <= 0 {
+				return nil, false
+			}
+			L[i*n+i] = math.Sqrt(sum)
+		}
+	}
+	return L, true
+}
+
+// weightedLogProb returns log(weight_k) + log N(x_i | mean_k, covariance_k) for every sample i and component k as [samples * K]
+func (m *GaussianMixture) weightedLogProb(X *Tensor) []float64 {
+
+	n, f, K := X.Shape[0], X.Shape[1], m.K
+	factors := make([][]float64, K) // <--- the Cholesky factor of each full covariance
+	logDets := make([]float64, K)
+	for k := 0; k < K; k++ {
+		switch m.Covariance {
+		case CovarianceFull:
+			L, ok := cholesky(m.Covariances.Data[k*f*f:(k+1)*f*f], f)
+			if !ok {
+				panic(fmt.Sprintf("Within GaussianMixture(): the covariance of component %v is not positive definite, increase RegCovar", k))
+			}
+			factors[k] = L
+			for j := 0; j < f; j++ {
+				logDets[k] += 2 * math.Log(L[j*f+j])
+			}
+		case CovarianceDiag:
+			for j := 0; j < f; j++ {
+				logDets[k] += math.Log(m.Covariances.Data[k*f+j])
+			}
+		default:
+			logDets[k] = float64(f) * math.Log(m.Covariances.Data[k])
+		}
+	}
+
+	logProb := make([]float64, n*K)
+	parallelForIfLarge(n*K*f, numBlocks(n), func(b int) {
+		diff := make([]float64, f)
+		for i := b * clusterBlock; i < min((b+1)*clusterBlock, n); i++ {
+			for k := 0; k < K; k++ {
+				for j := range diff {
+					diff[j] = X.Data[i*f+j] - m.Means.Data[k*f+j]
+				}
+
+				// the squared Mahalanobis distance of the sample from the component
+				distance := 0.0
+				switch m.Covariance {
+				case CovarianceFull:
+					L := factors[k]
+					for j := 0; j < f; j++ { // <--- forward substitution solves L z = diff in place
+						diff[j] = (diff[j] - dotSlices(L[j*f:j*f+j], diff[:j])) / L[j*f+j]
+					}
+					distance = dotSlices(diff, diff)
+				case CovarianceDiag:
+					for j, d := range diff {
+						distance += d * d / m.Covariances.Data[k*f+j]
+					}
+				default:
+					distance = dotSlices(diff, diff) / m.Covariances.Data[k]
+				}
+				logProb[i*K+k] = math.Log(m.Weights.Data[k]) - 0.5*(float64(f)*math.Log(2*math.Pi)+logDets[k]+distance)
+			}
+		}
+	})
+	return logProb
+}
+
+// estep returns the responsibility of each component for each sample as [samples * K], and the log likelihood of each sample
+func (m *GaussianMixture) estep(X *Tensor) ([]float64, []float64) {
+	n, K := X.Shape[0], m.K
+	resp := m.weightedLogProb(X)
+	logLikelihood := make([]float64, n)
+	for i := 0; i < n; i++ {
+		row := resp[i*K : (i+1)*K]
+		logLikelihood[i] = LogSumExpReducer{}.Reduce(row)
+		for k := range row {
+			row[k] = math.Exp(row[k] - logLikelihood[i])
+		}
+	}
+	return resp, logLikelihood
+}
+
+// mstep refits the weights, means and covariances to the responsibilities
+func (m *GaussianMixture) mstep(X *Tensor, resp []float64) {
+
+	n, f, K := X.Shape[0], X.Shape[1], m.K
+	m.Weights = &Tensor{Shape: []int{K}, Data: make([]float64, K)}
+	m.Means = &Tensor{Shape: []int{K, f}, Data: make([]float64, K*f)}
+	switch m.Covariance {
+	case CovarianceFull:
+		m.Covariances = &Tensor{Shape: []int{K, f, f}, Data: make([]float64, K*f*f)}
+	case CovarianceDiag:
+		m.Covariances = &Tensor{Shape: []int{K, f}, Data: make([]float64, K*f)}
+	default:
+		m.Covariances = &Tensor{Shape: []int{K}, Data: make([]float64, K)}
+	}
+
+	parallelFor(K, func(k int) {
+		total := 1e-14 // <--- keeps an empty component from dividing by zero
+		mean := m.Means.Data[k*f : (k+1)*f]
+		for i := 0; i < n; i++ {
+			total += resp[i*K+k]
+			axpy(resp[i*K+k], X.Data[i*f:(i+1)*f], mean)
+		}
+		for j := range mean {
+			mean[j] /= total
+		}
+		m.Weights.Data[k] = total / float64(n)
+
+		// accumulate the weighted scatter of the samples about the mean
+		variances := make([]float64, f)
+		var scatter []float64
+		if m.Covariance == CovarianceFull {
+			scatter = m.Covariances.Data[k*f*f : (k+1)*f*f]
+		}
+		diff := make([]float64, f)
+		for i := 0; i < n; i++ {
+			r := resp[i*K+k] / total
+			for j := range diff {
+				diff[j] = X.Data[i*f+j] - mean[j]
+				variances[j] += r * diff[j] * diff[j]
+			}
+			if scatter != nil {
+				for a := 0; a < f; a++ {
+					axpy(r*diff[a], diff, scatter[a*f:(a+1)*f])
+				}
+			}
+		}
+
+		switch m.Covariance {
+		case CovarianceFull:
+			for j := 0; j < f; j++ {
+				scatter[j*f+j] += m.RegCovar
+			}
+		case CovarianceDiag:
+			for j := range variances {
+				m.Covariances.Data[k*f+j] = variances[j] + m.RegCovar
+			}
+		default:
+			m.Covariances.Data[k] = MeanReducer{}.Reduce(variances) + m.RegCovar
+		}
+	})
+}
+
+func (m *GaussianMixture) Fit(X *Tensor) {
+	checkFeatures(X, -1, "GaussianMixture.Fit")
+	n := X.Shape[0]
+	checkClusters(m.K, n, "GaussianMixture.Fit")
+
+	// start from hard assignments to KMeans clusters
+	kmeans := &KMeans{K: m.K, NumInit: 1, MaxIter: 100, Tol: 1e-4, Generator: m.Generator}
+	kmeans.Fit(X)
+	resp := make([]float64, n*m.K)
+	for i, c := range kmeans.Labels.Data {
+		resp[i*m.K+int(c)] = 1
+	}
+	m.mstep(X, resp)
+
+	m.Converged, m.NumIter = false, 0
+	previous := math.Inf(-1)
+	for m.NumIter < m.MaxIter {
+		m.NumIter++
+		resp, logLikelihood := m.estep(X)
+		m.mstep(X, resp)
+		m.LowerBound = MeanReducer{}.Reduce(logLikelihood)
+		if math.Abs(m.LowerBound-previous) < m.Tol {
+			m.Converged = true
+			break
+		}
+		previous = m.LowerBound
+	}
+}
+
+// checkFitted panics unless the model has been fit to samples with as many features as X
+func (m *GaussianMixture) checkFitted(X *Tensor, caller string) {
+	if m.Means == nil {
+		panic("Within " + caller + "(): the model must be fit first")
+	}
+	checkFeatures(X, m.Means.Shape[1], caller)
+}
+
+// PredictProba() returns the responsibility of each component for each sample as [samples, K].
+func (m *GaussianMixture) PredictProba(X *Tensor) *Tensor {
+	m.checkFitted(X, "GaussianMixture.PredictProba")
+	resp, _ := m.estep(X)
+	return &Tensor{Shape: []int{X.Shape[0], m.K}, Data: resp, Batched: X.Batched}
+}
+
+// Predict() returns the most responsible component of each sample as [samples].
+func (m *GaussianMixture) Predict(X *Tensor) *Tensor {
+	components := make([]float64, m.K)
+	for k := range components {
+		components[k] = float64(k)
+	}
+	return mostProbable(m.PredictProba(X), components)
+}
+
+// ScoreSamples() returns the log likelihood of each sample under the mixture as [samples].
+func (m *GaussianMixture) ScoreSamples(X *Tensor) *Tensor {
+	m.checkFitted(X, "GaussianMixture.ScoreSamples")
+	_, logLikelihood := m.estep(X)
+	return &Tensor{Shape: []int{X.Shape[0]}, Data: logLikelihood, Batched: X.Batched}
+}
+
+// Score() returns the mean log likelihood of the samples of X.
+func (m *GaussianMixture) Score(X *Tensor) float64 {
+	return MeanReducer{}.Reduce(m.ScoreSamples(X).Data)
+}
+
+// numParameters returns the number of free parameters of the mixture
+func (m *GaussianMixture) numParameters() int {
+	f := m.Means.Shape[1]
+	covariance := m.K // <--- spherical
+	switch m.Covariance {
+	case CovarianceFull:
+		covariance = m.K * f * (f + 1) / 2
+	case CovarianceDiag:
+		covariance = m.K * f
+	}
+	return covariance + m.K*f + m.K - 1
+}
+
+// BIC() returns the Bayesian information criterion of the model on X. Lower is better, so it can be used to choose K.
+func (m *GaussianMixture) BIC(X *Tensor) float64 {
+	n := float64(X.Shape[0])
+	return -2*m.Score(X)*n + float64(m.numParameters())*math.Log(n)
+}
+
+// AIC() returns the Akaike information criterion of the model on X. Lower is better.
+func (m *GaussianMixture) AIC(X *Tensor) float64 {
+	return -2*m.Score(X)*float64(X.Shape[0]) + 2*float64(m.numParameters())
+}
@@ -0,0 +1,123 @@
+package TG
+
+/*
+* @notice Dataset.go contains the Dataset interface shared by every dataset loader, an in memory TensorDataset, and a
+* DataLoader that iterates over a Dataset in shuffled mini batches.
+* @dev A Dataset returns examples and labels for any set of indices at once, so a loader can decode or gather a whole batch
+* in one call. Examples are returned as a batched Tensor of shape [batch, ...example shape], and labels as a Tensor of
+* shape [batch, ...label shape].
+ */
+
+import (
+	"fmt"
+	"io"
+)
+
+// Dataset is a collection of labeled examples
+type Dataset interface {
+	Len() int
+	Get(indices []int) (examples *Tensor, labels *Tensor, err error)
+}
+
+//===================================================================================================================== TensorDataset
+
+// TensorDataset is a Dataset of examples and labels held in memory
+type TensorDataset struct {
+	X *Tensor // <--- [N, ...] examples
+	Y *Tensor // <--- [N, ...] labels
+}
+
+// NewTensorDataset() creates a TensorDataset. X and Y must have the same first dimension.
+func NewTensorDataset(X *Tensor, Y *Tensor) *TensorDataset {
+	if len(X.Shape) == 0 || len(Y.Shape) == 0 || X.Shape[0] != Y.Shape[0] {
+		panic("Within NewTensorDataset(): X and Y must have the same number of examples along their first dimension")
+	}
+	return &TensorDataset{X: X, Y: Y}
+}
+
+func (d *TensorDataset) Len() int {
+	return d.X.Shape[0]
+}
+
+func (d *TensorDataset) Get(indices []int) (*Tensor, *Tensor, error) {
+	examples, labels := d.X.IndexSelect(0, indices, false), d.Y.IndexSelect(0, indices, false)
+	examples.Batched, labels.Batched = true, true
+	return examples, labels, nil
+}
+
+//===================================================================================================================== DataLoader
+
+// DataLoader iterates over a Dataset in mini batches
+type DataLoader struct {
+	Dataset   Dataset
+	BatchSize int
+	Shuffle   bool       // <--- visit examples in a new random order each epoch
+	DropLast  bool       // <--- skip the final batch when it is smaller than BatchSize
+	Generator *Generator // <--- the source of shuffling, DefaultGenerator() when nil
+
+	order    []int
+	position int
+}
+
+// NewDataLoader() creates a DataLoader over dataset.
+func NewDataLoader(dataset Dataset, batchSize int, shuffle bool) *DataLoader {
+	if batchSize <= 0 {
+		panic("Within NewDataLoader(): batchSize must be positive")
+	}
+	return &DataLoader{Dataset: dataset, BatchSize: batchSize, Shuffle: shuffle}
+}
+
+// NumBatches() returns the number of batches in an epoch.
+func (l *DataLoader) NumBatches() int {
+	if l.DropLast {
+		return l.Dataset.Len() / l.BatchSize
+	}
+	return (l.Dataset.Len() + l.BatchSize - 1) / l.BatchSize
+}
+
+/*
+* @notice Next() returns the next batch of examples and labels. It returns io.EOF once every batch of the epoch has been returned.
+* The following call starts a new epoch, reshuffling when Shuffle is set.
+ */
+func (l *DataLoader) Next() (*Tensor, *Tensor, error) {
+
+	if l.order == nil {
+		l.order = rangeInts(l.Dataset.Len())
+		if l.Shuffle {
+			g := l.Generator
+			if g == nil {
+				g = DefaultGenerator()
+			}
+			l.order = g.Permutation(len(l.order))
+		}
+		l.position = 0
+	}
+
+	end := min(l.position+l.BatchSize, len(l.order))
+	if l.position >= len(l.order) || (l.DropLast && end-l.position < l.BatchSize) {
+		l.order = nil // <--- the next call starts a new epoch
+		return nil, nil, io.EOF
+	}
+
+	examples, labels, err := l.Dataset.Get(l.order[l.position:end])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Next(): %w", err)
+	}
+	l.position = end
+	return examples, labels, nil
+}
+
+// Reset() restarts the epoch, so the next call to Next() returns the first batch of a new epoch.
+func (l *DataLoader) Reset() {
+	l.order = nil
+}
+
+/*
+* @notice MLPInput() flattens a batch of examples of any shape to [batch, features] and turns on gradient tracking, ready for
+* Layer.Forward().
+ */
+func MLPInput(examples *Tensor) *Tensor {
+	flat := examples.Reshape([]int{examples.Shape[0], Product(examples.Shape[1:])}, false)
+	flat.Batched = true
+	return Gradify(flat)
+}
@@ -0,0 +1,485 @@
+package TG
+
+/*
+* @notice Decomposition.go contains matrix decompositions and the dimensionality reduction built on them: EigSymmetric() and
+* SVD() for 2D Tensors, PCA and TruncatedSVD.
+* @dev Both decompositions use Jacobi rotations, which are slower than the QR algorithm but simple and accurate to working
+* precision, and are applied to matrices of size features x features (PCA) or features x (components + oversamples)
+* (TruncatedSVD), never samples x samples. PCA and TruncatedSVD implement Transformer and InverseTransformer, so they can be
+* used in, and serialized with, a Pipeline.
+ */
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+//============================================================================================================================== Helpers
+
+// transposed returns the transpose of the row major matrix A [m, n]
+func transposed(A []float64, m, n int) []float64 {
+	T := make([]float64, len(A))
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			T[j*m+i] = A[i*n+j]
+		}
+	}
+	return T
+}
+
+// flipSign negates row, and reports doing so, when its element of largest magnitude is negative
+func flipSign(row []float64) bool {
+	largest := 0.0
+	for _, value := range row {
+		if math.Abs(value) > math.Abs(largest) {
+			largest = value
+		}
+	}
+	if largest >= 0 {
+		return false
+	}
+	for j := range row {
+		row[j] = -row[j]
+	}
+	return true
+}
+
+// rotate applies the plane rotation [c s; -s c] to the pair of vectors x and y in place
+func rotate(x, y []float64, c, s float64) {
+	for i := range x {
+		x[i], y[i] = c*x[i]-s*y[i], s*x[i]+c*y[i]
+	}
+}
+
+// rotationTangent returns the tangent of the smaller rotation angle solving t² + 2 zeta t - 1 = 0
+func rotationTangent(zeta float64) float64 {
+	return math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+}
+
+/*
+* @notice jacobiEigen() returns the eigenvalues of the symmetric row major matrix A [n, n] in decreasing order, and the matching
+* eigenvectors as the rows of an [n, n] matrix.
+* @dev Cyclic Jacobi: each rotation zeroes one off diagonal pair, and sweeps repeat until the off diagonal mass is negligible
+* relative to the whole matrix.
+ */
+func jacobiEigen(A []float64, n int) ([]float64, []float64) {
+
+	A = slices.Clone(A)
+	V := make([]float64, n*n) // <--- row k holds the k-th eigenvector
+	for i := 0; i < n; i++ {
+		V[i*n+i] = 1
+	}
+
+	total := dotSlices(A, A)
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += 2 * A[p*n+q] * A[p*n+q]
+			}
+		}
+		if off <= 1e-30*total {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if A[p*n+q] == 0 {
+					continue
+				}
+				t := rotationTangent((A[q*n+q] - A[p*n+p]) / (2 * A[p*n+q]))
+				c := 1 / math.Sqrt(1+t*t)
+				s := t * c
+
+				// A <- J^T A J, updating columns p and q, then rows p and q
+				for k := 0; k < n; k++ {
+					A[k*n+p], A[k*n+q] = c*A[k*n+p]-s*A[k*n+q], s*A[k*n+p]+c*A[k*n+q]
+				}
+				rotate(A[p*n:(p+1)*n], A[q*n:(q+1)*n], c, s)
+				rotate(V[p*n:(p+1)*n], V[q*n:(q+1)*n], c, s)
+			}
+		}
+	}
+
+	order := rangeInts(n)
+	slices.SortStableFunc(order, func(a, b int) int { return compareAscending(A[b*n+b], A[a*n+a]) })
+	values, vectors := make([]float64, n), make([]float64, n*n)
+	for k, index := range order {
+		values[k] = A[index*n+index]
+		copy(vectors[k*n:(k+1)*n], V[index*n:(index+1)*n])
+	}
+	return values, vectors
+}
+
+/*
+* @notice jacobiSVD() returns the thin singular value decomposition A = U diag(S) Vt of the row major matrix A [m, n], with
+* r = min(m, n), U [m, r], S [r] in decreasing order and Vt [r, n].
+* @dev One sided Jacobi: rotations of pairs of columns make every column orthogonal, after which the column norms are the
+* singular values. Columns are held as contiguous rows of the transpose. The sign of each singular pair is fixed so that the
+* largest element of each row of Vt is positive. A column of U for a zero singular value is zero.
+ */
+func jacobiSVD(A []float64, m, n int) ([]float64, []float64, []float64) {
+
+	if m < n { // <--- A^T = V S U^T
+		V, S, Ut := jacobiSVD(transposed(A, m, n), n, m)
+		U, Vt := transposed(Ut, m, m), transposed(V, n, m)
+		for k := 0; k < m; k++ {
+			if flipSign(Vt[k*n : (k+1)*n]) {
+				for i := 0; i < m; i++ {
+					U[i*m+k] = -U[i*m+k]
+				}
+			}
+		}
+		return U, S, Vt
+	}
+
+	W := transposed(A, m, n)  // <--- row j is column j of A, rotated towards column j of U S
+	V := make([]float64, n*n) // <--- row j is column j of V
+	for j := 0; j < n; j++ {
+		V[j*n+j] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		rotated := false
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				wp, wq := W[p*m:(p+1)*m], W[q*m:(q+1)*m]
+				alpha, beta, gamma := dotSlices(wp, wp), dotSlices(wq, wq), dotSlices(wp, wq)
+				if gamma == 0 || math.Abs(gamma) <= 1e-15*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+				t := rotationTangent((beta - alpha) / (2 * gamma))
+				c := 1 / math.Sqrt(1+t*t)
+				rotate(wp, wq, c, t*c)
+				rotate(V[p*n:(p+1)*n], V[q*n:(q+1)*n], c, t*c)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+
+	norms := make([]float64, n)
+	for j := range norms {
+		norms[j] = normSlice(W[j*m : (j+1)*m])
+	}
+	order := rangeInts(n)
+	slices.SortStableFunc(order, func(a, b int) int { return compareAscending(norms[b], norms[a]) })
+
+	U, S, Vt := make([]float64, m*n), make([]float64, n), make([]float64, n*n)
+	for k, j := range order {
+		S[k] = norms[j]
+		vt := Vt[k*n : (k+1)*n]
+		copy(vt, V[j*n:(j+1)*n])
+		sign := 1.0
+		if flipSign(vt) {
+			sign = -1
+		}
+		if S[k] > 0 {
+			for i := 0; i < m; i++ {
+				U[i*n+k] = sign * W[j*m+i] / S[k]
+			}
+		}
+	}
+	return U, S, Vt
+}
+
+// orthonormalColumns returns a matrix whose columns are an orthonormal basis for the columns of Y [m, l], by Gram-Schmidt
+// applied twice. Columns dependent on the ones before them are zeroed.
+func orthonormalColumns(Y *Tensor) *Tensor {
+	m, l := Y.Shape[0], Y.Shape[1]
+	Q := transposed(Y.Data, m, l)
+	for j := 0; j < l; j++ {
+		q := Q[j*m : (j+1)*m]
+		norm := normSlice(q)
+		for pass := 0; pass < 2; pass++ {
+			for i := 0; i < j; i++ {
+				axpy(-dotSlices(Q[i*m:(i+1)*m], q), Q[i*m:(i+1)*m], q)
+			}
+		}
+		if after := normSlice(q); after > 1e-10*norm {
+			for i := range q {
+				q[i] /= after
+			}
+		} else {
+			clear(q)
+		}
+	}
+	return &Tensor{Shape: []int{m, l}, Data: transposed(Q, l, m)}
+}
+
+//============================================================================================================================== EigSymmetric() and SVD()
+
+/*
+* @notice EigSymmetric() returns the eigenvalues of the symmetric matrix A [n, n] as [n] in decreasing order, and the matching
+* orthonormal eigenvectors as the rows of [n, n].
+ */
+func EigSymmetric(A *Tensor) (*Tensor, *Tensor) {
+	if len(A.Shape) != 2 || A.Shape[0] != A.Shape[1] {
+		panic(fmt.Sprintf("Within EigSymmetric(): A must be a square matrix, got shape %v", A.Shape))
+	}
+	n := A.Shape[0]
+	scale := maxAbs(A.Data)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if math.Abs(A.Data[i*n+j]-A.Data[j*n+i]) > 1e-10*scale {
+				panic("Within EigSymmetric(): A must be symmetric")
+			}
+		}
+	}
+	values, vectors := jacobiEigen(A.Data, n)
+	return &Tensor{Shape: []int{n}, Data: values}, &Tensor{Shape: []int{n, n}, Data: vectors}
+}
+
+/*
+* @notice SVD() returns the thin singular value decomposition A = U diag(S) Vt of the matrix A [m, n], with r = min(m, n),
+* U [m, r], S [r] in decreasing order and Vt [r, n]. The largest element of each row of Vt is positive.
+ */
+func SVD(A *Tensor) (*Tensor, *Tensor, *Tensor) {
+	if len(A.Shape) != 2 {
+		panic(fmt.Sprintf("Within SVD(): A must be a matrix, got shape %v", A.Shape))
+	}
+	m, n := A.Shape[0], A.Shape[1]
+	r := min(m, n)
+	U, S, Vt := jacobiSVD(A.Data, m, n)
+	return &Tensor{Shape: []int{m, r}, Data: U}, &Tensor{Shape: []int{r}, Data: S}, &Tensor{Shape: []int{r, n}, Data: Vt}
+}
+
+//============================================================================================================================== PCA
+
+/*
+* @notice PCA projects samples onto the principal axes of their covariance, the directions of greatest variance.
+* @dev Fit() centers X by its Mean_Axis(), forms the covariance Xc^T Xc / (samples - 1) with MatMul(), and takes its
+* eigenvectors. VarianceThreshold, when in (0, 1), keeps the fewest components explaining at least that fraction of the
+* variance. Otherwise NumComponents are kept, or min(samples, features) when it is 0.
+* @dev Whiten scales each projected component to unit variance.
+ */
+type PCA struct {
+	NumComponents     int
+	VarianceThreshold float64
+	Whiten            bool
+
+	Mean                   *Tensor // <--- [features]
+	Components             *Tensor // <--- [components, features], the principal axes by decreasing variance
+	ExplainedVariance      *Tensor // <--- [components], the variance along each axis
+	ExplainedVarianceRatio *Tensor // <--- [components], the fraction of the total variance along each axis
+}
+
+// NewPCA() creates a PCA keeping numComponents components, or all of them when 0.
+func NewPCA(numComponents int) *PCA {
+	return &PCA{NumComponents: numComponents}
+}
+
+// centerRows returns X with mean subtracted from each row
+func centerRows(X *Tensor, mean *Tensor) *Tensor {
+	return mapFeatures(X, func(j int, value float64) float64 { return value - mean.Data[j] })
+}
+
+func (m *PCA) Fit(X *Tensor) {
+	checkFeatures(X, -1, "PCA.Fit")
+	n, f := X.Shape[0], X.Shape[1]
+	if n < 2 {
+		panic("Within PCA.Fit(): at least 2 samples are required")
+	}
+
+	m.Mean = X.Mean_Axis(0, false)
+	Xc := centerRows(X, m.Mean)
+	covariance := MatMul(Xc.Permute([]int{1, 0}), Xc, false)
+	for i := range covariance.Data {
+		covariance.Data[i] /= float64(n - 1)
+	}
+	variances, axes := jacobiEigen(covariance.Data, f)
+
+	total := 0.0
+	for i := range variances {
+		variances[i] = math.Max(variances[i], 0) // <--- rounding can leave null directions slightly negative
+		total += variances[i]
+	}
+
+	k := min(n, f)
+	switch {
+	case m.VarianceThreshold > 0 && m.VarianceThreshold < 1:
+		explained := 0.0
+		for k = 0; k < min(n, f) && explained < m.VarianceThreshold*total; k++ {
+			explained += variances[k]
+		}
+		k = max(k, 1)
+	case m.NumComponents > min(n, f) || m.NumComponents < 0:
+		panic(fmt.Sprintf("Within PCA.Fit(): NumComponents must be between 0 and %v, got %v", min(n, f), m.NumComponents))
+	case m.NumComponents > 0:
+		k = m.NumComponents
+	}
+
+	m.Components = &Tensor{Shape: []int{k, f}, Data: axes[:k*f]}
+	for c := 0; c < k; c++ {
+		flipSign(m.Components.Data[c*f : (c+1)*f])
+	}
+	m.ExplainedVariance = &Tensor{Shape: []int{k}, Data: variances[:k]}
+	m.ExplainedVarianceRatio = &Tensor{Shape: []int{k}, Data: make([]float64, k)}
+	for c := range m.ExplainedVarianceRatio.Data {
+		if total > 0 {
+			m.ExplainedVarianceRatio.Data[c] = variances[c] / total
+		}
+	}
+}
+
+// whitening returns the scale of each component under whitening
+func (m *PCA) whitening(c int) float64 {
+	return nonZeroScale(math.Sqrt(m.ExplainedVariance.Data[c]))
+}
+
+// Transform() projects X [samples, features] onto the components, returning [samples, components].
+func (m *PCA) Transform(X *Tensor) *Tensor {
+	if m.Components == nil {
+		panic("Within PCA.Transform(): the model must be fit first")
+	}
+	checkFeatures(X, m.Components.Shape[1], "PCA.Transform")
+	Z := MatMulTransposeB(centerRows(X, m.Mean), m.Components, false)
+	if m.Whiten {
+		Z = mapFeatures(Z, func(c int, value float64) float64 { return value / m.whitening(c) })
+	}
+	Z.Batched = X.Batched
+	return Z
+}
+
+// InverseTransform() maps Z [samples, components] back to [samples, features]. Variance off the components is lost.
+func (m *PCA) InverseTransform(Z *Tensor) *Tensor {
+	if m.Components == nil {
+		panic("Within PCA.InverseTransform(): the model must be fit first")
+	}
+	checkFeatures(Z, m.Components.Shape[0], "PCA.InverseTransform")
+	if m.Whiten {
+		Z = mapFeatures(Z, func(c int, value float64) float64 { return value * m.whitening(c) })
+	}
+	X := mapFeatures(MatMul(Z, m.Components, false), func(j int, value float64) float64 { return value + m.Mean.Data[j] })
+	X.Batched = Z.Batched
+	return X
+}
+
+//============================================================================================================================== TruncatedSVD
+
+/*
+* @notice TruncatedSVD projects samples onto the top right singular vectors of X without centering it, so sparse data stays
+* sparse. It is fit with randomized SVD, touching X only through products with thin matrices, so FitSparse() accepts a
+* CSRMatrix and large dense inputs cost O(samples x features x components).
+* @dev Randomized SVD (Halko, Martinsson and Tropp) multiplies X by a random Gaussian matrix of NumComponents +
+* NumOversamples columns, sharpens the resulting basis with NumIter power iterations, and takes the exact SVD of the
+* projection of X onto it.
+ */
+type TruncatedSVD struct {
+	NumComponents  int
+	NumOversamples int
+	NumIter        int
+	Generator      *Generator `json:"-"` // <--- the source of the random projection, DefaultGenerator() when nil
+
+	Components             *Tensor // <--- [components, features]
+	SingularValues         *Tensor // <--- [components]
+	ExplainedVariance      *Tensor // <--- [components], the variance of each projected component
+	ExplainedVarianceRatio *Tensor // <--- [components], ExplainedVariance over the total variance of the features
+}
+
+// NewTruncatedSVD() creates a TruncatedSVD keeping numComponents components.
+func NewTruncatedSVD(numComponents int) *TruncatedSVD {
+	return &TruncatedSVD{NumComponents: numComponents, NumOversamples: 10, NumIter: 5}
+}
+
+/*
+* @notice fit runs randomized SVD on the samples x features matrix X given by the products mul(B) = X B and mulT(B) = X^T B,
+* then sets the explained variances from the projection Z = X Components^T and the total variance of the features.
+ */
+func (m *TruncatedSVD) fit(n, f int, mul, mulT func(B *Tensor) *Tensor, totalVariance float64, caller string) {
+
+	k := m.NumComponents
+	if k < 1 || k > min(n, f) {
+		panic(fmt.Sprintf("Within %v(): NumComponents must be between 1 and %v, got %v", caller, min(n, f), k))
+	}
+	g := m.Generator
+	if g == nil {
+		g = DefaultGenerator()
+	}
+
+	l := min(k+max(m.NumOversamples, 0), n, f)
+	Q := orthonormalColumns(mul(g.NormalTensor([]int{f, l}, 0, 1, false))) // <--- [samples, l]
+	for iter := 0; iter < m.NumIter; iter++ {
+		Q = orthonormalColumns(mul(orthonormalColumns(mulT(Q))))
+	}
+
+	// X ≈ Q Q^T X, and the right singular vectors of Q^T X are the left singular vectors of X^T Q [features, l]
+	U, S, _ := jacobiSVD(mulT(Q).Data, f, l)
+	m.Components = &Tensor{Shape: []int{k, f}, Data: transposed(U, f, l)[:k*f]}
+	for c := 0; c < k; c++ {
+		flipSign(m.Components.Data[c*f : (c+1)*f])
+	}
+	m.SingularValues = &Tensor{Shape: []int{k}, Data: S[:k]}
+
+	Z := mul(m.Components.Permute([]int{1, 0}))
+	m.ExplainedVariance = Z.Var([]int{0}, false, false)
+	m.ExplainedVarianceRatio = &Tensor{Shape: []int{k}, Data: make([]float64, k)}
+	for c, variance := range m.ExplainedVariance.Data {
+		if totalVariance > 0 {
+			m.ExplainedVarianceRatio.Data[c] = variance / totalVariance
+		}
+	}
+}
+
+func (m *TruncatedSVD) Fit(X *Tensor) {
+	checkFeatures(X, -1, "TruncatedSVD.Fit")
+	XT := X.Permute([]int{1, 0})
+	mul := func(B *Tensor) *Tensor { return MatMul(X, B, false) }
+	mulT := func(B *Tensor) *Tensor { return MatMul(XT, B, false) }
+	total := SumReducer{}.Reduce(X.Var([]int{0}, false, false).Data)
+	m.fit(X.Shape[0], X.Shape[1], mul, mulT, total, "TruncatedSVD.Fit")
+}
+
+// FitSparse() fits to the sparse matrix S [samples, features].
+func (m *TruncatedSVD) FitSparse(S *CSRMatrix) {
+	n, f := S.Shape[0], S.Shape[1]
+	ST := S.Transpose()
+	mul := func(B *Tensor) *Tensor { return SpMM(S, B) }
+	mulT := func(B *Tensor) *Tensor { return SpMM(ST, B) }
+
+	// the variance of each feature from the sums and sums of squares of its nonzeros
+	sums, squares := make([]float64, f), make([]float64, f)
+	for k, j := range S.Indices {
+		sums[j] += S.Values[k]
+		squares[j] += S.Values[k] * S.Values[k]
+	}
+	total := 0.0
+	for j := range sums {
+		mean := sums[j] / float64(n)
+		total += squares[j]/float64(n) - mean*mean
+	}
+	m.fit(n, f, mul, mulT, total, "TruncatedSVD.FitSparse")
+}
+
+// Transform() projects X [samples, features] onto the components, returning [samples, components].
+func (m *TruncatedSVD) Transform(X *Tensor) *Tensor {
+	if m.Components == nil {
+		panic("Within TruncatedSVD.Transform(): the model must be fit first")
+	}
+	checkFeatures(X, m.Components.Shape[1], "TruncatedSVD.Transform")
+	Z := MatMulTransposeB(X, m.Components, false)
+	Z.Batched = X.Batched
+	return Z
+}
+
+// TransformSparse() projects the sparse matrix S [samples, features] onto the components, returning [samples, components].
+func (m *TruncatedSVD) TransformSparse(S *CSRMatrix) *Tensor {
+	if m.Components == nil || S.Shape[1] != m.Components.Shape[1] {
+		panic("Within TruncatedSVD.TransformSparse(): S must have as many features as the model was fit on")
+	}
+	return SpMM(S, m.Components.Permute([]int{1, 0}))
+}
+
+// InverseTransform() maps Z [samples, components] back to [samples, features].
+func (m *TruncatedSVD) InverseTransform(Z *Tensor) *Tensor {
+	if m.Components == nil {
+		panic("Within TruncatedSVD.InverseTransform(): the model must be fit first")
+	}
+	checkFeatures(Z, m.Components.Shape[0], "TruncatedSVD.InverseTransform")
+	X := MatMul(Z, m.Components, false)
+	X.Batched = Z.Batched
+	return X
+}
@@ -0,0 +1,379 @@
+package TG
+
+/*
+* @notice Einsum.go implements Einstein summation, a single notation that generalizes Dot(), Outer(), MatMul(), Sum_Axis(),
+* Permute(), traces and diagonals.
+* @dev A subscript string such as "bij,bjk->bik" labels each axis of each operand with a letter. Labels that appear in the
+* output are kept, all other labels are summed over. Repeating a label within one operand takes its diagonal.
+* @dev Without "->" (implicit mode) the output contains, in alphabetical order, every label that appears exactly once.
+* @dev "..." stands for any number of leading broadcast (batch) dimensions. Ellipsis dimensions are aligned from the right
+* and size 1 dimensions broadcast, as in NumPy.
+* @dev With 3 or more operands the contraction is performed pairwise, greedily choosing the cheapest pair at each step,
+* which avoids iterating over the product of every dimension at once.
+ */
+
+import (
+	"sort"
+	"strings"
+)
+
+// einsumOperand pairs a Tensor with the label of each of its axes.
+type einsumOperand struct {
+	labels []rune
+	tensor *Tensor
+}
+
+// einsumContraction computes the contraction of a set of operands into a Tensor with the given output labels.
+type einsumContraction func(operands []einsumOperand, outLabels []rune, sizes map[rune]int) *Tensor
+
+//============================================================================================================================== Einsum()
+
+/*
+* @notice Einsum() evaluates an Einstein summation expression over any number of operands.
+* @param subscripts: comma separated axis labels for each operand, optionally followed by "->" and the output labels.
+* @param operands: the Tensors being contracted.
+* @return a new Tensor. Full contractions such as "i,i->" return a Tensor of shape [1].
+* @example Einsum("bij,bjk->bik", A, B)   // <--- batched MatMul
+* @example Einsum("ii", A)                // <--- trace
+* @example Einsum("...ij->...ji", A)      // <--- transpose the last two axes
+ */
+func Einsum(subscripts string, operands ...*Tensor) *Tensor {
+	return einsum("Einsum", subscripts, operands, contractData)
+}
+
+/*
+* @notice EinsumGrad() is the gradient tracked version of Einsum(). It operates on the DataReqGrad slices of the operands,
+* building the computational graph of every product and sum so that Backward() can be called on the result.
+ */
+func EinsumGrad(subscripts string, operands ...*Tensor) *Tensor {
+	for _, A := range operands {
+		if len(A.DataReqGrad) == 0 || len(A.DataReqGrad) != Product(A.Shape) {
+			panic("Within EinsumGrad(): Every operand must have a populated DataReqGrad slice")
+		}
+	}
+	return einsum("EinsumGrad", subscripts, operands, contractValues)
+}
+
+// einsum parses the subscripts, then contracts the operands in a greedy pairwise order using contract.
+func einsum(caller string, subscripts string, operands []*Tensor, contract einsumContraction) *Tensor {
+
+	inputs, outLabels := parseEinsum(caller, subscripts, operands)
+	sizes := einsumSizes(caller, inputs)
+
+	// operands that have not been contracted yet
+	remaining := append([]einsumOperand(nil), inputs...)
+
+	for len(remaining) > 2 {
+
+		// choose the pair whose contraction iterates over the fewest index combinations
+		bestI, bestJ, bestCost := 0, 1, -1
+		for i := 0; i < len(remaining); i++ {
+			for j := i + 1; j < len(remaining); j++ {
+				cost := 1
+				for _, label := range unionLabels(remaining[i].labels, remaining[j].labels) {
+					cost *= sizes[label]
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestI, bestJ, bestCost = i, j, cost
+				}
+			}
+		}
+
+		// keep the labels of the pair that are needed by another operand or by the output
+		var others []rune
+		others = append(others, outLabels...)
+		for k, operand := range remaining {
+			if k != bestI && k != bestJ {
+				others = append(others, operand.labels...)
+			}
+		}
+		var keep []rune
+		for _, label := range unionLabels(remaining[bestI].labels, remaining[bestJ].labels) {
+			if containsRune(others, label) {
+				keep = append(keep, label)
+			}
+		}
+
+		pair := []einsumOperand{remaining[bestI], remaining[bestJ]}
+		intermediate := einsumOperand{labels: keep, tensor: contract(pair, keep, sizes)}
+
+		remaining = append(remaining[:bestJ], remaining[bestJ+1:]...) // <--- bestJ > bestI, so remove it first
+		remaining[bestI] = intermediate
+	}
+
+	result := contract(remaining, outLabels, sizes)
+	if len(result.Shape) == 0 {
+		result.Shape = []int{1} // <--- full contractions return a single element Tensor, like Dot()
+	}
+	return result
+}
+
+//============================================================================================================================== Parsing
+
+// parseEinsum splits the subscripts into labelled operands and output labels, expanding any ellipsis.
+func parseEinsum(caller string, subscripts string, operands []*Tensor) ([]einsumOperand, []rune) {
+
+	subscripts = strings.ReplaceAll(subscripts, " ", "")
+	parts := strings.Split(subscripts, "->")
+	if len(parts) > 2 {
+		panic("Within " + caller + "(): Subscripts may contain at most one '->'")
+	}
+
+	terms := strings.Split(parts[0], ",")
+	if len(terms) != len(operands) {
+		panic("Within " + caller + "(): The number of subscript terms must match the number of operands")
+	}
+
+	// determine the number of ellipsis dimensions of each operand and overall
+	ellipsisDims := make([]int, len(terms))
+	maxEllipsis, anyEllipsis := 0, false
+	for i, term := range terms {
+		explicit := []rune(strings.Replace(term, "...", "", 1))
+		if strings.Count(term, "...") > 1 || strings.Contains(strings.Replace(term, "...", "", 1), ".") {
+			panic("Within " + caller + "(): Each term may contain at most one '...'")
+		}
+		if strings.Contains(term, "...") {
+			anyEllipsis = true
+			ellipsisDims[i] = len(operands[i].Shape) - len(explicit)
+			if ellipsisDims[i] < 0 {
+				panic("Within " + caller + "(): Operand " + term + " has more labels than dimensions")
+			}
+		} else if len(explicit) != len(operands[i].Shape) {
+			panic("Within " + caller + "(): Operand " + term + " must have one label per dimension")
+		}
+		for _, label := range explicit {
+			if !isEinsumLetter(label) {
+				panic("Within " + caller + "(): Subscript labels must be letters")
+			}
+		}
+		if ellipsisDims[i] > maxEllipsis {
+			maxEllipsis = ellipsisDims[i]
+		}
+	}
+
+	// ellipsis dimensions are labelled with runes outside of the letters a-zA-Z, aligned from the right
+	ellipsisLabels := make([]rune, maxEllipsis)
+	for i := range ellipsisLabels {
+		ellipsisLabels[i] = rune(0x100 + i)
+	}
+
+	inputs := make([]einsumOperand, len(terms))
+	for i, term := range terms {
+		inputs[i] = einsumOperand{labels: expandEllipsis(term, ellipsisLabels[maxEllipsis-ellipsisDims[i]:]), tensor: operands[i]}
+	}
+
+	// explicit output
+	if len(parts) == 2 {
+		if strings.Contains(parts[1], "...") && !anyEllipsis {
+			panic("Within " + caller + "(): Output contains '...' but no operand does")
+		}
+		outLabels := expandEllipsis(parts[1], ellipsisLabels)
+		for i, label := range outLabels {
+			if label < 0x100 && !isEinsumLetter(label) {
+				panic("Within " + caller + "(): Subscript labels must be letters")
+			}
+			if containsRune(outLabels[:i], label) {
+				panic("Within " + caller + "(): Output labels must be unique")
+			}
+			found := false
+			for _, input := range inputs {
+				found = found || containsRune(input.labels, label)
+			}
+			if !found {
+				panic("Within " + caller + "(): Output label " + string(label) + " does not appear in any operand")
+			}
+		}
+		return inputs, outLabels
+	}
+
+	// implicit output: ellipsis dimensions followed by the labels that appear exactly once, in alphabetical order
+	counts := make(map[rune]int)
+	for _, input := range inputs {
+		for _, label := range input.labels {
+			counts[label]++
+		}
+	}
+	var single []rune
+	for label, count := range counts {
+		if count == 1 && label < 0x100 {
+			single = append(single, label)
+		}
+	}
+	sort.Slice(single, func(i, j int) bool { return single[i] < single[j] })
+	return inputs, append(append([]rune(nil), ellipsisLabels...), single...)
+}
+
+// expandEllipsis converts a subscript term into runes, replacing "..." with the provided ellipsis labels
+func expandEllipsis(term string, ellipsisLabels []rune) []rune {
+	before, after, found := strings.Cut(term, "...")
+	if !found {
+		return []rune(term)
+	}
+	return append(append([]rune(before), ellipsisLabels...), []rune(after)...)
+}
+
+// einsumSizes determines the size of every label, checking that they are consistent across operands.
+func einsumSizes(caller string, inputs []einsumOperand) map[rune]int {
+	sizes := make(map[rune]int)
+	for _, input := range inputs {
+		for axis, label := range input.labels {
+			size := input.tensor.Shape[axis]
+			existing, seen := sizes[label]
+			switch {
+			case !seen || (existing == 1 && label >= 0x100):
+				sizes[label] = size
+			case existing != size && !(size == 1 && label >= 0x100): // <--- only ellipsis dims broadcast
+				panic("Within " + caller + "(): Inconsistent size for label " + string(label))
+			}
+		}
+	}
+	return sizes
+}
+
+//============================================================================================================================== Contraction
+
+/*
+* @notice einsumLoop iterates over every combination of output and summed label indices. For each output element
+* (in row major order) it calls visit once per summed combination, with the flat offset into each operand.
+* @dev Each operand gets a stride per label. A label repeated within an operand gets the sum of both axis strides (its
+* diagonal) and a broadcast size 1 axis gets a stride of 0.
+ */
+func einsumLoop(operands []einsumOperand, outLabels []rune, sizes map[rune]int, visit func(outIndex int, offsets []int)) {
+
+	// every label is either in the output or summed over
+	var summed []rune
+	for _, operand := range operands {
+		for _, label := range operand.labels {
+			if !containsRune(outLabels, label) && !containsRune(summed, label) {
+				summed = append(summed, label)
+			}
+		}
+	}
+	loopLabels := append(append([]rune(nil), outLabels...), summed...)
+
+	// compute each operand's stride along every loop label
+	strides := make([][]int, len(operands))
+	for i, operand := range operands {
+		strides[i] = make([]int, len(loopLabels))
+		axisStride := 1
+		for axis := len(operand.labels) - 1; axis >= 0; axis-- {
+			if operand.tensor.Shape[axis] != 1 || sizes[operand.labels[axis]] == 1 {
+				for l, label := range loopLabels {
+					if label == operand.labels[axis] {
+						strides[i][l] += axisStride
+					}
+				}
+			}
+			axisStride *= operand.tensor.Shape[axis]
+		}
+	}
+
+	total := 1
+	for _, label := range loopLabels {
+		total *= sizes[label]
+	}
+	numSummed := 1
+	for _, label := range summed {
+		numSummed *= sizes[label]
+	}
+	if total == 0 {
+		return
+	}
+
+	// walk an odometer over the loop labels, updating operand offsets incrementally
+	index := make([]int, len(loopLabels))
+	offsets := make([]int, len(operands))
+	for step := 0; step < total; step++ {
+		visit(step/numSummed, offsets)
+
+		for l := len(loopLabels) - 1; l >= 0; l-- {
+			index[l]++
+			for i := range operands {
+				offsets[i] += strides[i][l]
+			}
+			if index[l] < sizes[loopLabels[l]] {
+				break
+			}
+			for i := range operands {
+				offsets[i] -= strides[i][l] * index[l]
+			}
+			index[l] = 0
+		}
+	}
+}
+
+// einsumOutputShape returns the shape of a contraction with the given output labels
+func einsumOutputShape(outLabels []rune, sizes map[rune]int) []int {
+	shape := make([]int, len(outLabels))
+	for i, label := range outLabels {
+		shape[i] = sizes[label]
+	}
+	return shape
+}
+
+// contractData is the einsumContraction for the float64 Data slice.
+func contractData(operands []einsumOperand, outLabels []rune, sizes map[rune]int) *Tensor {
+	shape := einsumOutputShape(outLabels, sizes)
+	C := &Tensor{Shape: shape, Data: make([]float64, Product(shape))}
+
+	einsumLoop(operands, outLabels, sizes, func(outIndex int, offsets []int) {
+		product := 1.0
+		for i, operand := range operands {
+			product *= operand.tensor.Data[offsets[i]]
+		}
+		C.Data[outIndex] += product
+	})
+	return C
+}
+
+// contractValues is the einsumContraction for the gradient tracked DataReqGrad slice.
+func contractValues(operands []einsumOperand, outLabels []rune, sizes map[rune]int) *Tensor {
+	shape := einsumOutputShape(outLabels, sizes)
+	C := &Tensor{Shape: shape, Data: make([]float64, Product(shape)), DataReqGrad: make([]*Value, Product(shape)), RequireGrad: true}
+
+	einsumLoop(operands, outLabels, sizes, func(outIndex int, offsets []int) {
+		product := operands[0].tensor.DataReqGrad[offsets[0]]
+		for i := 1; i < len(operands); i++ {
+			product = product.Mul(operands[i].tensor.DataReqGrad[offsets[i]])
+		}
+		if C.DataReqGrad[outIndex] == nil {
+			C.DataReqGrad[outIndex] = product
+		} else {
+			C.DataReqGrad[outIndex] = C.DataReqGrad[outIndex].Add(product)
+		}
+	})
+
+	for i, value := range C.DataReqGrad {
+		if value == nil {
+			C.DataReqGrad[i] = NewValue(0, nil, "einsum") // <--- empty sums
+		}
+		C.Data[i] = C.DataReqGrad[i].Scalar
+	}
+	return C
+}
+
+//============================================================================================================================== Helper Functions for Einsum
+
+func isEinsumLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, x := range runes {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+// unionLabels returns the labels of a followed by the labels of b that are not in a, without duplicates
+func unionLabels(a, b []rune) []rune {
+	var union []rune
+	for _, label := range append(append([]rune(nil), a...), b...) {
+		if !containsRune(union, label) {
+			union = append(union, label)
+		}
+	}
+	return union
+}
@@ -0,0 +1,640 @@
+package TG
+
+/*
+* @notice Frame.go contains Frame, a lightweight table of named columns, each backed by a 1D Tensor.
+* @dev Columns are float, int or categorical. Int columns hold whole numbers. Categorical columns hold the code of each row's
+* category, an index into the column's Categories, so every column is numeric and can be fed straight into Tensor ops.
+* Missing values are NaN in every kind of column.
+* @dev Frames are immutable: every method returns a new Frame, which may share unchanged columns with the original.
+* @dev As with Tensor ops, misuse such as an unknown column name or mismatched lengths panics.
+ */
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ColumnKind is the type of the values in a Frame column
+type ColumnKind int
+
+const (
+	FloatKind ColumnKind = iota
+	IntKind
+	CategoricalKind
+)
+
+func (kind ColumnKind) String() string {
+	return [...]string{"float", "int", "categorical"}[kind]
+}
+
+// Column is a named column of a Frame
+type Column struct {
+	Name       string
+	Kind       ColumnKind
+	Values     *Tensor  // <--- a 1D Tensor with one value per row. Category codes for categorical columns.
+	Categories []string // <--- the category of each code, for categorical columns
+}
+
+// Frame is a table of equal length named columns
+type Frame struct {
+	columns []*Column
+}
+
+//===================================================================================================================== Columns
+
+// FloatColumn() creates a float column.
+func FloatColumn(name string, values []float64) *Column {
+	return &Column{Name: name, Kind: FloatKind, Values: &Tensor{Shape: []int{len(values)}, Data: append([]float64{}, values...)}}
+}
+
+// IntColumn() creates an int column.
+func IntColumn(name string, values []int) *Column {
+	data := make([]float64, len(values))
+	for i, value := range values {
+		data[i] = float64(value)
+	}
+	return &Column{Name: name, Kind: IntKind, Values: &Tensor{Shape: []int{len(values)}, Data: data}}
+}
+
+// StringColumn() creates a categorical column, coding categories in order of first appearance.
+func StringColumn(name string, values []string) *Column {
+	column := &Column{Name: name, Kind: CategoricalKind, Values: &Tensor{Shape: []int{len(values)}, Data: make([]float64, len(values))}}
+	codes := map[string]int{}
+	for i, value := range values {
+		code, ok := codes[value]
+		if !ok {
+			code = len(column.Categories)
+			codes[value] = code
+			column.Categories = append(column.Categories, value)
+		}
+		column.Values.Data[i] = float64(code)
+	}
+	return column
+}
+
+// TensorColumn() creates a float column from a Tensor, which is flattened.
+func TensorColumn(name string, A *Tensor) *Column {
+	return FloatColumn(name, A.Data)
+}
+
+// Len() returns the number of rows of the column.
+func (c *Column) Len() int {
+	return len(c.Values.Data)
+}
+
+// StringAt() returns the value of row i as a string. Missing values are "NaN".
+func (c *Column) StringAt(i int) string {
+	value := c.Values.Data[i]
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case c.Kind == CategoricalKind:
+		return c.Categories[int(value)]
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Strings() returns the category of each row of a categorical column. Missing values are "".
+func (c *Column) Strings() []string {
+	if c.Kind != CategoricalKind {
+		panic(fmt.Sprintf("Within Strings(): column %q is not categorical", c.Name))
+	}
+	strs := make([]string, c.Len())
+	for i, code := range c.Values.Data {
+		if !math.IsNaN(code) {
+			strs[i] = c.Categories[int(code)]
+		}
+	}
+	return strs
+}
+
+// take returns a copy of the column with only the rows at indices. A negative index gives a missing value.
+func (c *Column) take(indices []int) *Column {
+	data := make([]float64, len(indices))
+	for i, index := range indices {
+		data[i] = math.NaN()
+		if index >= 0 {
+			data[i] = c.Values.Data[index]
+		}
+	}
+	return &Column{Name: c.Name, Kind: c.Kind, Values: &Tensor{Shape: []int{len(data)}, Data: data}, Categories: c.Categories}
+}
+
+// compareRows orders row i before row j by value, by category name for categorical columns, with missing values last
+func (c *Column) compareRows(i, j int) int {
+	a, b := c.Values.Data[i], c.Values.Data[j]
+	if c.Kind == CategoricalKind && !math.IsNaN(a) && !math.IsNaN(b) {
+		return strings.Compare(c.Categories[int(a)], c.Categories[int(b)])
+	}
+	return compareAscending(a, b)
+}
+
+// key returns a string identifying the value of row i, comparable across columns with different category codes
+func (c *Column) key(i int) string {
+	if c.Kind == CategoricalKind {
+		if math.IsNaN(c.Values.Data[i]) {
+			return "\x00"
+		}
+		return "s" + c.Categories[int(c.Values.Data[i])]
+	}
+	return "f" + strconv.FormatFloat(c.Values.Data[i], 'g', -1, 64)
+}
+
+//===================================================================================================================== Frame
+
+// NewFrame() creates a Frame from columns, which must have unique names and the same length.
+func NewFrame(columns ...*Column) *Frame {
+	for i, column := range columns {
+		if len(column.Values.Shape) != 1 {
+			panic(fmt.Sprintf("Within NewFrame(): column %q must be 1D", column.Name))
+		}
+		if column.Len() != columns[0].Len() {
+			panic(fmt.Sprintf("Within NewFrame(): column %q has %d rows, expected %d", column.Name, column.Len(), columns[0].Len()))
+		}
+		for _, other := range columns[:i] {
+			if other.Name == column.Name {
+				panic(fmt.Sprintf("Within NewFrame(): duplicate column %q", column.Name))
+			}
+		}
+	}
+	return &Frame{columns: columns}
+}
+
+// FrameFromTensor() creates a Frame of float columns from the columns of a 2D Tensor.
+func FrameFromTensor(A *Tensor, names []string) *Frame {
+	if len(A.Shape) != 2 || A.Shape[1] != len(names) {
+		panic("Within FrameFromTensor(): A must be 2D with one column per name")
+	}
+	rows, cols := A.Shape[0], A.Shape[1]
+	columns := make([]*Column, cols)
+	for j, name := range names {
+		data := make([]float64, rows)
+		for i := range data {
+			data[i] = A.Data[i*cols+j]
+		}
+		columns[j] = &Column{Name: name, Kind: FloatKind, Values: &Tensor{Shape: []int{rows}, Data: data}}
+	}
+	return NewFrame(columns...)
+}
+
+// FrameFromCSV() creates a Frame from a CSVTable. Label encoded string columns become categorical columns.
+func FrameFromCSV(table *CSVTable) *Frame {
+	frame := FrameFromTensor(table.Data, table.Columns)
+	for _, column := range frame.columns {
+		if categories, ok := table.Categories[column.Name]; ok {
+			column.Kind, column.Categories = CategoricalKind, categories
+		}
+	}
+	return frame
+}
+
+// NumRows() returns the number of rows.
+func (f *Frame) NumRows() int {
+	if len(f.columns) == 0 {
+		return 0
+	}
+	return f.columns[0].Len()
+}
+
+// Names() returns the name of each column, in order.
+func (f *Frame) Names() []string {
+	names := make([]string, len(f.columns))
+	for i, column := range f.columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// Has() reports whether the Frame has a column called name.
+func (f *Frame) Has(name string) bool {
+	return slices.Contains(f.Names(), name)
+}
+
+// Column() returns the column called name.
+func (f *Frame) Column(name string) *Column {
+	for _, column := range f.columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	panic(fmt.Sprintf("Within Column(): column %q does not exist", name))
+}
+
+// Columns() returns every column, in order.
+func (f *Frame) Columns() []*Column {
+	return append([]*Column{}, f.columns...)
+}
+
+// Select() returns a Frame with only the named columns, in the order given.
+func (f *Frame) Select(names ...string) *Frame {
+	columns := make([]*Column, len(names))
+	for i, name := range names {
+		columns[i] = f.Column(name)
+	}
+	return NewFrame(columns...)
+}
+
+// Drop() returns a Frame without the named columns.
+func (f *Frame) Drop(names ...string) *Frame {
+	for _, name := range names {
+		f.Column(name) // <--- panics on unknown names
+	}
+	columns := []*Column{}
+	for _, column := range f.columns {
+		if !slices.Contains(names, column.Name) {
+			columns = append(columns, column)
+		}
+	}
+	return NewFrame(columns...)
+}
+
+// WithColumn() returns a Frame with column added, or replacing the column of the same name.
+func (f *Frame) WithColumn(column *Column) *Frame {
+	columns := append([]*Column{}, f.columns...)
+	for i, existing := range columns {
+		if existing.Name == column.Name {
+			columns[i] = column
+			return NewFrame(columns...)
+		}
+	}
+	return NewFrame(append(columns, column)...)
+}
+
+// Rows() returns a Frame with only the rows at indices, in the order given.
+func (f *Frame) Rows(indices []int) *Frame {
+	columns := make([]*Column, len(f.columns))
+	for i, column := range f.columns {
+		for _, index := range indices {
+			if index < 0 || index >= column.Len() {
+				panic(fmt.Sprintf("Within Rows(): row %d is out of range for %d rows", index, column.Len()))
+			}
+		}
+		columns[i] = column.take(indices)
+	}
+	return NewFrame(columns...)
+}
+
+// Head() returns the first n rows.
+func (f *Frame) Head(n int) *Frame {
+	return f.Rows(rangeInts(min(n, f.NumRows())))
+}
+
+// Filter() returns the rows where mask is nonzero. mask has one element per row, such as the output of Greater().
+func (f *Frame) Filter(mask *Tensor) *Frame {
+	if len(mask.Data) != f.NumRows() {
+		panic("Within Filter(): mask must have one element per row")
+	}
+	indices := []int{}
+	for i, keep := range mask.Data {
+		if keep != 0 && !math.IsNaN(keep) {
+			indices = append(indices, i)
+		}
+	}
+	return f.Rows(indices)
+}
+
+// SortBy() returns the rows stably sorted by the named columns in turn. Categorical columns sort by category name. Missing values go last.
+func (f *Frame) SortBy(descending bool, names ...string) *Frame {
+	return f.Rows(f.sortOrder(descending, names))
+}
+
+// sortOrder returns the row indices that stably sort the Frame by the named columns
+func (f *Frame) sortOrder(descending bool, names []string) []int {
+	columns := make([]*Column, len(names))
+	for i, name := range names {
+		columns[i] = f.Column(name)
+	}
+
+	order := rangeInts(f.NumRows())
+	slices.SortStableFunc(order, func(i, j int) int {
+		for _, column := range columns {
+			if c := column.compareRows(i, j); c != 0 {
+				if descending && !math.IsNaN(column.Values.Data[i]) && !math.IsNaN(column.Values.Data[j]) {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	})
+	return order
+}
+
+// ToTensor() returns the named columns, or every column when none are named, as a batched [rows, features] Tensor.
+// With oneHot true, categorical columns are expanded into one 0/1 column per category, otherwise their codes are used.
+func (f *Frame) ToTensor(oneHot bool, names ...string) *Tensor {
+	if len(names) == 0 {
+		names = f.Names()
+	}
+	columns := make([]*Column, len(names))
+	width := 0
+	for i, name := range names {
+		columns[i] = f.Column(name)
+		if oneHot && columns[i].Kind == CategoricalKind {
+			width += len(columns[i].Categories)
+		} else {
+			width++
+		}
+	}
+
+	rows := f.NumRows()
+	A := &Tensor{Shape: []int{rows, width}, Data: make([]float64, rows*width), Batched: true}
+	offset := 0
+	for _, column := range columns {
+		for i, value := range column.Values.Data {
+			switch {
+			case !oneHot || column.Kind != CategoricalKind:
+				A.Data[i*width+offset] = value
+			case math.IsNaN(value):
+				for k := range column.Categories {
+					A.Data[i*width+offset+k] = math.NaN()
+				}
+			default:
+				A.Data[i*width+offset+int(value)] = 1
+			}
+		}
+		if oneHot && column.Kind == CategoricalKind {
+			offset += len(column.Categories)
+		} else {
+			offset++
+		}
+	}
+	return A
+}
+
+//===================================================================================================================== Group By
+
+// GroupedFrame is a Frame split into groups of rows with equal keys
+type GroupedFrame struct {
+	frame  *Frame
+	keys   []string
+	groups [][]int // <--- the rows of each group, with groups ordered by key
+}
+
+// GroupBy() groups the rows by the values of the named key columns. Groups are ordered by key, as with SortBy().
+func (f *Frame) GroupBy(keys ...string) *GroupedFrame {
+
+	order := f.sortOrder(false, keys)
+	keyColumns := make([]*Column, len(keys))
+	for i, key := range keys {
+		keyColumns[i] = f.Column(key)
+	}
+
+	g := &GroupedFrame{frame: f, keys: keys}
+	previous := ""
+	for k, row := range order {
+		key := ""
+		for _, column := range keyColumns {
+			key += column.key(row) + "\x1f"
+		}
+		if k == 0 || key != previous {
+			g.groups = append(g.groups, []int{})
+		}
+		g.groups[len(g.groups)-1] = append(g.groups[len(g.groups)-1], row)
+		previous = key
+	}
+	return g
+}
+
+func rangeInts(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// NumGroups() returns the number of groups.
+func (g *GroupedFrame) NumGroups() int {
+	return len(g.groups)
+}
+
+// keyFrame returns a Frame with the key columns of the first row of each group
+func (g *GroupedFrame) keyFrame() *Frame {
+	first := make([]int, len(g.groups))
+	for i, group := range g.groups {
+		first[i] = group[0]
+	}
+	return g.frame.Select(g.keys...).Rows(first)
+}
+
+/*
+* @notice Aggregate() reduces the non-key float and int columns of each group, returning a Frame with one row per group.
+* @dev reduce receives the [rows, columns] Tensor of a group and must return a Tensor with one value per column.
+* Categorical columns that are not keys are dropped, so a Frame without float or int columns aggregates to its keys.
+* @dev The reduced columns are float columns. Sum(), Min() and Max() keep int columns as int columns.
+ */
+func (g *GroupedFrame) Aggregate(reduce func(group *Tensor) *Tensor) *Frame {
+	return g.aggregate(reduce, false)
+}
+
+// aggregate runs Aggregate(), keeping the kind of int columns when keepInts is true
+func (g *GroupedFrame) aggregate(reduce func(group *Tensor) *Tensor, keepInts bool) *Frame {
+
+	names := []string{}
+	for _, column := range g.frame.columns {
+		if column.Kind != CategoricalKind && !slices.Contains(g.keys, column.Name) {
+			names = append(names, column.Name)
+		}
+	}
+	if len(names) == 0 {
+		return g.keyFrame() // <--- ToTensor() would take every column
+	}
+	values := g.frame.ToTensor(false, names...)
+
+	result := &Tensor{Shape: []int{len(g.groups), len(names)}, Data: make([]float64, 0, len(g.groups)*len(names))}
+	for _, group := range g.groups {
+		reduced := reduce(values.IndexSelect(0, group, false))
+		if len(reduced.Data) != len(names) {
+			panic("Within Aggregate(): reduce must return one value per column")
+		}
+		result.Data = append(result.Data, reduced.Data...)
+	}
+
+	frame := g.keyFrame()
+	for _, column := range FrameFromTensor(result, names).columns {
+		if keepInts && g.frame.Column(column.Name).Kind == IntKind {
+			column.Kind = IntKind
+		}
+		frame = frame.WithColumn(column)
+	}
+	return frame
+}
+
+// Sum() sums each column within each group.
+func (g *GroupedFrame) Sum() *Frame {
+	return g.aggregate(func(group *Tensor) *Tensor { return group.Sum_Axis(0, false) }, true)
+}
+
+// Mean() averages each column within each group.
+func (g *GroupedFrame) Mean() *Frame {
+	return g.Aggregate(func(group *Tensor) *Tensor { return group.Mean_Axis(0, false) })
+}
+
+// Min() takes the minimum of each column within each group.
+func (g *GroupedFrame) Min() *Frame {
+	return g.aggregate(func(group *Tensor) *Tensor { return group.Min([]int{0}, false, false) }, true)
+}
+
+// Max() takes the maximum of each column within each group.
+func (g *GroupedFrame) Max() *Frame {
+	return g.aggregate(func(group *Tensor) *Tensor { return group.Max([]int{0}, false, false) }, true)
+}
+
+// Count() returns the key columns and a "count" column with the number of rows in each group.
+func (g *GroupedFrame) Count() *Frame {
+	counts := make([]int, len(g.groups))
+	for i, group := range g.groups {
+		counts[i] = len(group)
+	}
+	return g.keyFrame().WithColumn(IntColumn("count", counts))
+}
+
+//===================================================================================================================== Join
+
+// JoinKind chooses which rows a Join() keeps
+type JoinKind int
+
+const (
+	InnerJoin JoinKind = iota // <--- rows with a match in both Frames
+	LeftJoin                  // <--- every row of the left Frame, with missing values where the right Frame has no match
+)
+
+/*
+* @notice Join() joins the rows of f and other that have equal values in the column on.
+* @dev Each row of f is paired with every matching row of other, in order. Categorical keys match by category name, so the
+* two Frames may code categories differently. Columns of other, except the key, whose names are already in f get the suffix "_right".
+ */
+func (f *Frame) Join(other *Frame, on string, kind JoinKind) *Frame {
+
+	left, right := f.Column(on), other.Column(on)
+	if (left.Kind == CategoricalKind) != (right.Kind == CategoricalKind) {
+		panic(fmt.Sprintf("Within Join(): key column %q is categorical in only one Frame", on))
+	}
+
+	matches := map[string][]int{}
+	for j := 0; j < right.Len(); j++ {
+		if !math.IsNaN(right.Values.Data[j]) { // <--- missing keys never match
+			matches[right.key(j)] = append(matches[right.key(j)], j)
+		}
+	}
+
+	leftRows, rightRows := []int{}, []int{}
+	for i := 0; i < left.Len(); i++ {
+		found := []int{}
+		if !math.IsNaN(left.Values.Data[i]) {
+			found = matches[left.key(i)]
+		}
+		for _, j := range found {
+			leftRows, rightRows = append(leftRows, i), append(rightRows, j)
+		}
+		if len(found) == 0 && kind == LeftJoin {
+			leftRows, rightRows = append(leftRows, i), append(rightRows, -1)
+		}
+	}
+
+	joined := f.Rows(leftRows)
+	for _, column := range other.columns {
+		if column.Name == on {
+			continue
+		}
+		taken := column.take(rightRows)
+		if joined.Has(taken.Name) {
+			taken.Name += "_right"
+		}
+		joined = joined.WithColumn(taken)
+	}
+	return joined
+}
+
+//===================================================================================================================== Describe
+
+/*
+* @notice Describe() returns summary statistics of every float and int column: the count of present values, mean, standard
+* deviation (with ddof 1), min, quartiles and max. Missing values are ignored.
+* @return A Frame with a categorical "stat" column naming each statistic, and one column per described column.
+ */
+func (f *Frame) Describe() *Frame {
+
+	stats := []string{"count", "mean", "std", "min", "25%", "50%", "75%", "max"}
+	frame := NewFrame(StringColumn("stat", stats))
+
+	for _, column := range f.columns {
+		if column.Kind == CategoricalKind {
+			continue
+		}
+		present := []float64{}
+		for _, value := range column.Values.Data {
+			if !math.IsNaN(value) {
+				present = append(present, value)
+			}
+		}
+
+		n := float64(len(present))
+		values := make([]float64, len(stats))
+		for i := range values {
+			values[i] = math.NaN()
+		}
+		values[0] = n
+		if n > 0 {
+			mean := MeanReducer{}.Reduce(present)
+			values[1] = mean
+			if n > 1 {
+				values[2] = math.Sqrt(VarReducer{}.Reduce(present) * n / (n - 1))
+			}
+			values[3], values[7] = MinReducer{}.Reduce(present), MaxReducer{}.Reduce(present)
+			for k, q := range []float64{0.25, 0.5, 0.75} {
+				values[4+k] = QuantileReducer{Q: q, Method: QuantileLinear}.Reduce(present)
+			}
+		}
+		frame = frame.WithColumn(FloatColumn(column.Name, values))
+	}
+	return frame
+}
+
+//===================================================================================================================== Printing
+
+// String() prints the Frame as an aligned table, summarizing the middle rows of long Frames.
+func (f *Frame) String() string {
+
+	rows := rangeInts(f.NumRows())
+	if len(rows) > 20 {
+		rows = append(rows[:10:10], rows[len(rows)-10:]...)
+	}
+
+	// each cell as a string, with the header as the first row
+	cells := [][]string{append([]string{""}, f.Names()...)}
+	for _, i := range rows {
+		row := []string{strconv.Itoa(i)}
+		for _, column := range f.columns {
+			row = append(row, column.StringAt(i))
+		}
+		cells = append(cells, row)
+	}
+
+	widths := make([]int, len(cells[0]))
+	for _, row := range cells {
+		for j, cell := range row {
+			widths[j] = max(widths[j], len(cell))
+		}
+	}
+
+	var b strings.Builder
+	for r, row := range cells {
+		if r == 11 && f.NumRows() > 20 {
+			b.WriteString("...\n")
+		}
+		for j, cell := range row {
+			if j > 0 {
+				b.WriteString("  ")
+			}
+			fmt.Fprintf(&b, "%*s", widths[j], cell)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[%d rows x %d columns]", f.NumRows(), len(f.columns))
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package TG
+
+/*
+* @notice IDX.go contains a reader for the IDX format used by MNIST and Fashion-MNIST, and a loader for those datasets.
+* @dev An IDX file is two zero bytes, a dtype byte, the number of dimensions, each dimension as a big endian uint32, then the
+* big endian data. Gzipped files are detected from their magic bytes and decompressed as they are read.
+ */
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// idxItemSizes holds the size in bytes of each IDX dtype
+var idxItemSizes = map[byte]int{
+	0x08: 1, // <--- unsigned byte
+	0x09: 1, // <--- signed byte
+	0x0B: 2, // <--- short
+	0x0C: 4, // <--- int
+	0x0D: 4, // <--- float
+	0x0E: 8, // <--- double
+}
+
+/*
+* @notice ReadIDX() reads an IDX file, gzipped or not, into a Tensor with the shape stored in the file.
+* @dev Values are converted to float64 without scaling, so MNIST pixels are between 0 and 255.
+ */
+func ReadIDX(r io.Reader) (*Tensor, error) {
+
+	reader := bufio.NewReader(r)
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("ReadIDX(): %w", err)
+		}
+		defer zr.Close()
+		reader = bufio.NewReader(zr)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("ReadIDX(): reading header: %w", err)
+	}
+	dtype, rank := header[2], int(header[3])
+	itemSize, ok := idxItemSizes[dtype]
+	if header[0] != 0 || header[1] != 0 || !ok || rank == 0 {
+		return nil, fmt.Errorf("ReadIDX(): not an IDX file")
+	}
+
+	shape := make([]int, rank)
+	for i := range shape {
+		var dim uint32
+		if err := binary.Read(reader, binary.BigEndian, &dim); err != nil {
+			return nil, fmt.Errorf("ReadIDX(): reading shape: %w", err)
+		}
+		shape[i] = int(dim)
+	}
+	numElements, ok := checkedProduct(shape, itemSize)
+	if !ok {
+		return nil, fmt.Errorf("ReadIDX(): shape %v is too large", shape)
+	}
+
+	// the Tensor grows as data arrives rather than trusting the shape, so a corrupt shape fails on a short read
+	A := &Tensor{Shape: shape, Data: make([]float64, 0, min(numElements, 4096))}
+	chunk := make([]byte, 4096*itemSize)
+	for start := 0; start < numElements; start += 4096 {
+		n := min(4096, numElements-start)
+		if _, err := io.ReadFull(reader, chunk[:n*itemSize]); err != nil {
+			return nil, fmt.Errorf("ReadIDX(): reading data: %w", err)
+		}
+		for k := 0; k < n; k++ {
+			b := chunk[k*itemSize:]
+			var value float64
+			switch dtype {
+			case 0x08:
+				value = float64(b[0])
+			case 0x09:
+				value = float64(int8(b[0]))
+			case 0x0B:
+				value = float64(int16(binary.BigEndian.Uint16(b)))
+			case 0x0C:
+				value = float64(int32(binary.BigEndian.Uint32(b)))
+			case 0x0D:
+				value = float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+			default:
+				value = math.Float64frombits(binary.BigEndian.Uint64(b))
+			}
+			A.Data = append(A.Data, value)
+		}
+	}
+	return A, nil
+}
+
+// LoadIDX() loads an IDX file, gzipped or not, into a Tensor.
+func LoadIDX(fileName string) *Tensor {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	A, err := ReadIDX(file)
+	if err != nil {
+		panic(err)
+	}
+	return A
+}
+
+// openIDX opens the first of name.gz and name that exists in dir and reads it
+func openIDX(dir string, name string) (*Tensor, error) {
+	for _, candidate := range []string{name + ".gz", name} {
+		file, err := os.Open(filepath.Join(dir, candidate))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return ReadIDX(file)
+	}
+	return nil, fmt.Errorf("neither %v nor %v.gz exists in %v", name, name, dir)
+}
+
+/*
+* @notice LoadMNIST() loads the MNIST or Fashion-MNIST training or test set from the original files in dir, gzipped or not.
+* @dev The files are train-images-idx3-ubyte and train-labels-idx1-ubyte, or t10k-images-idx3-ubyte and t10k-labels-idx1-ubyte
+* for the test set. Images are scaled to [0, 1] and have shape [N, 28, 28]. Labels are the digit or class of each image, with shape [N].
+ */
+func LoadMNIST(dir string, train bool) (*TensorDataset, error) {
+
+	prefix := "t10k"
+	if train {
+		prefix = "train"
+	}
+	images, err := openIDX(dir, prefix+"-images-idx3-ubyte")
+	if err != nil {
+		return nil, fmt.Errorf("LoadMNIST(): %w", err)
+	}
+	labels, err := openIDX(dir, prefix+"-labels-idx1-ubyte")
+	if err != nil {
+		return nil, fmt.Errorf("LoadMNIST(): %w", err)
+	}
+	if len(images.Shape) != 3 || len(labels.Shape) != 1 || images.Shape[0] != labels.Shape[0] {
+		return nil, fmt.Errorf("LoadMNIST(): images %v and labels %v do not match", images.Shape, labels.Shape)
+	}
+
+	for i := range images.Data {
+		images.Data[i] /= 255
+	}
+	return NewTensorDataset(images, labels), nil
+}
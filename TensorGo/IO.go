@@ -9,10 +9,11 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 //===================================================================================================================== JSON IO
@@ -114,55 +115,30 @@ func Load_JSON(fileName string) *Tensor {
 //===================================================================================================================== CSV IO
 
 /*
-* @notice LoadCSV() loads a CSV file into a Tensor
-* @dev Data loaded from a csv is loaded as a batch of vectors
+* @notice LoadCSV() loads a numeric CSV file into a Tensor
+* @dev Data loaded from a csv is loaded as a batch of vectors. When skipHeader is true, the first row is treated as column
+* names and left out of the Tensor. Use ReadCSV() in CSV.go for column names, missing values and string columns.
  */
 func LoadCSV(csvFile string, skipHeader bool) *Tensor {
 
-	// Print cwd
-	cwd, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("Failed to get current working directory: %s", err)
-	}
-	fmt.Println("Current working directory: ", cwd)
-
 	// Open the CSV file
 	file, err := os.Open(csvFile)
 	if err != nil {
-		log.Fatalf("Failed to open CSV file: %s", err)
+		panic(err)
 	}
 	defer file.Close()
 
-	// Read the CSV file
-	reader := csv.NewReader(bufio.NewReader(file))
-	rawCSVData, err := reader.ReadAll()
+	// Read the CSV file, failing on missing or non-numeric cells
+	table, err := ReadCSV(file, CSVOptions{Header: skipHeader, NATokens: []string{}})
 	if err != nil {
-		log.Fatalf("Failed to read CSV file: %s", err)
+		panic(err)
 	}
-
-	// Create a Tensor to store the data
-	irisTensor := ZeroTensor([]int{len(rawCSVData), len(rawCSVData[0])}, true)
-
-	// Convert CSV data into Tensor
-	for i, row := range rawCSVData {
-
-		// skip header if specified
-		if i == 0 && skipHeader {
-			continue
-		}
-
-		for j, val := range row {
-			// Parse the string value into a float
-			floatVal, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				log.Fatalf("Failed to convert string to float: %s", err)
-			}
-
-			irisTensor.Data[irisTensor.Index([]int{i, j})] = floatVal
-		}
+	if len(table.Categories) > 0 {
+		panic(fmt.Sprintf("Within LoadCSV(): %v contains non-numeric columns, use ReadCSV() to encode them", csvFile))
 	}
 
-	return irisTensor
+	table.Data.Batched = true
+	return table.Data
 }
 
 /*
@@ -207,3 +183,139 @@ func SaveCSV(A *Tensor, fileName string) {
 	// Success message
 	fmt.Println(fileName, " written successfully")
 }
+
+//===================================================================================================================== Matrix Market IO
+
+/*
+* @notice ReadMatrixMarket() parses a sparse matrix in Matrix Market coordinate format (.mtx) into a COOMatrix.
+* @dev real, integer and pattern fields are supported, along with general, symmetric and skew-symmetric matrices.
+* Symmetric entries are expanded so that the returned matrix contains both triangles.
+* @dev The number of entries must match the nnz of the size line.
+ */
+func ReadMatrixMarket(r io.Reader) (*COOMatrix, error) {
+
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("ReadMatrixMarket(): empty input")
+	}
+
+	// parse the banner: %%MatrixMarket matrix coordinate <field> <symmetry>
+	banner := strings.Fields(strings.ToLower(scanner.Text()))
+	if len(banner) != 5 || banner[0] != "%%matrixmarket" || banner[1] != "matrix" || banner[2] != "coordinate" {
+		return nil, fmt.Errorf("ReadMatrixMarket(): only the coordinate matrix format is supported, got %q", scanner.Text())
+	}
+	field, symmetry := banner[3], banner[4]
+	if field != "real" && field != "integer" && field != "pattern" {
+		return nil, fmt.Errorf("ReadMatrixMarket(): unsupported field %q", field)
+	}
+	if symmetry != "general" && symmetry != "symmetric" && symmetry != "skew-symmetric" {
+		return nil, fmt.Errorf("ReadMatrixMarket(): unsupported symmetry %q", symmetry)
+	}
+
+	var S *COOMatrix
+	var nnz, entries int
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "%") {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		// the first non comment line is the size line: rows cols nnz
+		if S == nil {
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("ReadMatrixMarket(): invalid size line %q", line)
+			}
+			size := make([]int, 3)
+			for i, f := range fields {
+				var err error
+				if size[i], err = strconv.Atoi(f); err != nil {
+					return nil, fmt.Errorf("ReadMatrixMarket(): invalid size line %q: %w", line, err)
+				}
+				if size[i] < 0 {
+					return nil, fmt.Errorf("ReadMatrixMarket(): invalid size line %q", line)
+				}
+			}
+			S, nnz = &COOMatrix{Shape: []int{size[0], size[1]}}, size[2]
+			continue
+		}
+		if entries++; entries > nnz {
+			return nil, fmt.Errorf("ReadMatrixMarket(): more than the %d entries of the size line", nnz)
+		}
+
+		// each remaining line is an entry: row col [value], with 1-based indices
+		if (field == "pattern" && len(fields) < 2) || (field != "pattern" && len(fields) < 3) {
+			return nil, fmt.Errorf("ReadMatrixMarket(): invalid entry %q", line)
+		}
+		row, errRow := strconv.Atoi(fields[0])
+		col, errCol := strconv.Atoi(fields[1])
+		if errRow != nil || errCol != nil || row < 1 || row > S.Shape[0] || col < 1 || col > S.Shape[1] {
+			return nil, fmt.Errorf("ReadMatrixMarket(): invalid entry %q", line)
+		}
+		value := 1.0
+		if field != "pattern" {
+			var err error
+			if value, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return nil, fmt.Errorf("ReadMatrixMarket(): invalid entry %q: %w", line, err)
+			}
+		}
+
+		S.Rows, S.Cols, S.Values = append(S.Rows, row-1), append(S.Cols, col-1), append(S.Values, value)
+		if symmetry != "general" && row != col { // <--- mirror the entry into the upper triangle
+			if symmetry == "skew-symmetric" {
+				value = -value
+			}
+			S.Rows, S.Cols, S.Values = append(S.Rows, col-1), append(S.Cols, row-1), append(S.Values, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if S == nil {
+		return nil, fmt.Errorf("ReadMatrixMarket(): missing size line")
+	}
+	if entries != nnz {
+		return nil, fmt.Errorf("ReadMatrixMarket(): %d entries, the size line gives %d", entries, nnz)
+	}
+	return S, nil
+}
+
+// WriteMatrixMarket() writes a COOMatrix in Matrix Market coordinate real general format.
+func WriteMatrixMarket(w io.Writer, S *COOMatrix) error {
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "%%MatrixMarket matrix coordinate real general")
+	fmt.Fprintf(writer, "%d %d %d\n", S.Shape[0], S.Shape[1], S.NNZ())
+	for i, value := range S.Values {
+		fmt.Fprintf(writer, "%d %d %s\n", S.Rows[i]+1, S.Cols[i]+1, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	return writer.Flush()
+}
+
+// LoadMTX() loads a Matrix Market file into a CSRMatrix.
+func LoadMTX(fileName string) *CSRMatrix {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	S, err := ReadMatrixMarket(file)
+	if err != nil {
+		panic(err)
+	}
+	return S.ToCSR()
+}
+
+// SaveMTX() saves a CSRMatrix to a Matrix Market file.
+func SaveMTX(S *CSRMatrix, fileName string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if err := WriteMatrixMarket(file, S.ToCOO()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,164 @@
+package TG
+
+/*
+* @notice ImageFolder.go contains ImageFolder, a Dataset of PNG and JPEG images laid out as root/<class>/<image>.
+* @dev Each subdirectory of root is a class. Classes are sorted by name and labelled 0, 1, 2, ... in that order.
+* @dev Images are decoded lazily, when a batch is requested, and batches are decoded in parallel. Each image becomes a
+* [channels, height, width] Tensor with values in [0, 1], so batches are NCHW.
+ */
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // <--- registers the JPEG decoder
+	_ "image/png"  // <--- registers the PNG decoder
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImageFolder is a Dataset of image files labelled by the directory they are in
+type ImageFolder struct {
+	Root      string
+	Classes   []string       // <--- the class name of each label
+	Grayscale bool           // <--- decode images to 1 channel instead of 3
+	Transform ImageTransform // <--- applied to each decoded [C, H, W] image, such as a resize, when set
+	Generator *Generator     // <--- the source of randomness for Transform, DefaultGenerator() when nil
+
+	paths  []string
+	labels []int
+}
+
+// NewImageFolder() scans root for class subdirectories holding .png, .jpg and .jpeg files.
+func NewImageFolder(root string, grayscale bool) (*ImageFolder, error) {
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("NewImageFolder(): %w", err)
+	}
+
+	f := &ImageFolder{Root: root, Grayscale: grayscale}
+	for _, entry := range entries { // <--- ReadDir returns entries sorted by name
+		if !entry.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("NewImageFolder(): %w", err)
+		}
+
+		label := len(f.Classes)
+		f.Classes = append(f.Classes, entry.Name())
+		for _, file := range files {
+			switch strings.ToLower(filepath.Ext(file.Name())) {
+			case ".png", ".jpg", ".jpeg":
+				f.paths = append(f.paths, filepath.Join(root, entry.Name(), file.Name()))
+				f.labels = append(f.labels, label)
+			}
+		}
+	}
+	if len(f.paths) == 0 {
+		return nil, fmt.Errorf("NewImageFolder(): no images found in %v", root)
+	}
+	return f, nil
+}
+
+func (f *ImageFolder) Len() int {
+	return len(f.paths)
+}
+
+// Path() returns the file of example i.
+func (f *ImageFolder) Path(i int) string {
+	return f.paths[i]
+}
+
+// Get() decodes the images at indices in parallel. Every image must have the same shape after Transform.
+func (f *ImageFolder) Get(indices []int) (*Tensor, *Tensor, error) {
+
+	// draw a seed per image in order, so random transforms do not depend on the order images are decoded in
+	g := f.Generator
+	if g == nil {
+		g = DefaultGenerator()
+	}
+	seeds := make([]int64, len(indices))
+	if f.Transform != nil {
+		for k := range seeds {
+			seeds[k] = int64(g.Intn(1 << 52))
+		}
+	}
+
+	images := make([]*Tensor, len(indices))
+	errs := make([]error, len(indices))
+	parallelFor(len(indices), func(k int) {
+		images[k], errs[k] = f.load(indices[k], seeds[k])
+	})
+
+	labels := &Tensor{Shape: []int{len(indices)}, Data: make([]float64, len(indices)), Batched: true}
+	for k, index := range indices {
+		if errs[k] != nil {
+			return nil, nil, errs[k]
+		}
+		labels.Data[k] = float64(f.labels[index])
+	}
+	if len(images) == 0 {
+		return &Tensor{Shape: []int{0}, Data: []float64{}, Batched: true}, labels, nil
+	}
+
+	shape := images[0].Shape
+	batch := &Tensor{Shape: append([]int{len(images)}, shape...), Data: make([]float64, 0, len(images)*Product(shape)), Batched: true}
+	for k, img := range images {
+		if !isEqual(img.Shape, shape) {
+			return nil, nil, fmt.Errorf("Get(): %v has shape %v but %v has shape %v, resize images with a Transform", f.paths[indices[k]], img.Shape, f.paths[indices[0]], shape)
+		}
+		batch.Data = append(batch.Data, img.Data...)
+	}
+	return batch, labels, nil
+}
+
+// load decodes and transforms a single image, drawing randomness from a Generator seeded with seed
+func (f *ImageFolder) load(index int, seed int64) (*Tensor, error) {
+	file, err := os.Open(f.paths[index])
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %v: %w", f.paths[index], err)
+	}
+	A := imageToCHW(img, f.Grayscale)
+	if f.Transform != nil {
+		A = f.Transform.Transform(A, NewGenerator(seed))
+	}
+	return A, nil
+}
+
+// imageToCHW converts an image to a [channels, height, width] Tensor with values in [0, 1]
+func imageToCHW(img image.Image, grayscale bool) *Tensor {
+
+	bounds := img.Bounds()
+	height, width := bounds.Dy(), bounds.Dx()
+	channels := 3
+	if grayscale {
+		channels = 1
+	}
+
+	A := &Tensor{Shape: []int{channels, height, width}, Data: make([]float64, channels*height*width)}
+	plane := height * width
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixel := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			if grayscale {
+				A.Data[y*width+x] = float64(color.Gray16Model.Convert(pixel).(color.Gray16).Y) / 0xffff
+				continue
+			}
+			r, g, b, _ := pixel.RGBA()
+			A.Data[y*width+x] = float64(r) / 0xffff
+			A.Data[plane+y*width+x] = float64(g) / 0xffff
+			A.Data[2*plane+y*width+x] = float64(b) / 0xffff
+		}
+	}
+	return A
+}
@@ -0,0 +1,478 @@
+package TG
+
+/*
+* @notice IterativeSolvers.go contains iterative algorithms for solving large systems of linear equations Ax = b.
+* @dev Unlike the elimination routines in LinearSystemsOps.go, these solvers never factor A. They only require the
+* ability to apply A to a vector, which is abstracted by the LinearOperator interface. A dense matrix Tensor satisfies
+* LinearOperator directly, so either a Tensor or a matrix-free operator can be passed to each solver.
+ */
+
+import (
+	"math"
+)
+
+//============================================================================================================================== Linear Operators
+
+// LinearOperator is anything that can compute the matrix vector product Ax for a vector Tensor x.
+type LinearOperator interface {
+	Apply(x *Tensor) *Tensor
+}
+
+// Apply() lets a 2D matrix Tensor be used as a LinearOperator. It returns the vector Tensor Ax.
+func (A *Tensor) Apply(x *Tensor) *Tensor {
+
+	if len(A.Shape) != 2 || len(x.Shape) != 1 || A.Shape[1] != x.Shape[0] {
+		panic("Within Apply(): A must be a 2D matrix and x a vector of compatible length")
+	}
+
+	Ax := &Tensor{Shape: []int{A.Shape[0]}, Data: make([]float64, A.Shape[0])}
+	for row := 0; row < A.Shape[0]; row++ {
+		Ax.Data[row] = dotSlices(A.Data[row*A.Shape[1]:(row+1)*A.Shape[1]], x.Data)
+	}
+	return Ax
+}
+
+//============================================================================================================================== Preconditioners
+
+// Preconditioner approximates the action of the inverse of A, returning z = M^-1 r for a residual vector r.
+type Preconditioner interface {
+	Solve(r *Tensor) *Tensor
+}
+
+// JacobiPreconditioner scales each element of the residual by the inverse of the corresponding diagonal element of A.
+type JacobiPreconditioner struct{ invDiag []float64 }
+
+func (p *JacobiPreconditioner) Solve(r *Tensor) *Tensor {
+	z := &Tensor{Shape: []int{len(r.Data)}, Data: make([]float64, len(r.Data))}
+	for i := range r.Data {
+		z.Data[i] = r.Data[i] * p.invDiag[i]
+	}
+	return z
+}
+
+// NewJacobiPreconditioner() builds a Jacobi (diagonal) preconditioner from a square matrix Tensor.
+func NewJacobiPreconditioner(A *Tensor) *JacobiPreconditioner {
+
+	checkSquareMatrix(A, "NewJacobiPreconditioner")
+
+	n := A.Shape[0]
+	invDiag := make([]float64, n)
+	for i := 0; i < n; i++ {
+		d := A.Data[i*n+i]
+		if d == 0 {
+			panic("Within NewJacobiPreconditioner(): Zero on the diagonal of A")
+		}
+		invDiag[i] = 1 / d
+	}
+	return &JacobiPreconditioner{invDiag: invDiag}
+}
+
+/*
+* @notice IncompleteCholeskyPreconditioner stores the lower triangular factor L of the zero fill-in incomplete
+* Cholesky factorization A ~ LL^T. Solve() applies (LL^T)^-1 with a forward and a backward substitution.
+ */
+type IncompleteCholeskyPreconditioner struct {
+	n int
+	L []float64 // <--- row major n x n lower triangular factor
+}
+
+func (p *IncompleteCholeskyPreconditioner) Solve(r *Tensor) *Tensor {
+	n, L := p.n, p.L
+
+	// forward substitution: Ly = r
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sum := r.Data[i]
+		for k := 0; k < i; k++ {
+			sum -= L[i*n+k] * y[k]
+		}
+		y[i] = sum / L[i*n+i]
+	}
+
+	// back substitution: L^T z = y
+	z := &Tensor{Shape: []int{n}, Data: make([]float64, n)}
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for k := i + 1; k < n; k++ {
+			sum -= L[k*n+i] * z.Data[k]
+		}
+		z.Data[i] = sum / L[i*n+i]
+	}
+	return z
+}
+
+/*
+* @notice NewIncompleteCholeskyPreconditioner() computes the IC(0) factorization of a symmetric positive definite matrix.
+* @dev IC(0) only keeps entries of L where A is nonzero, so the factor has the same sparsity pattern as the lower triangle of A.
+* For a dense A this is the exact Cholesky factor.
+ */
+func NewIncompleteCholeskyPreconditioner(A *Tensor) *IncompleteCholeskyPreconditioner {
+
+	checkSquareMatrix(A, "NewIncompleteCholeskyPreconditioner")
+
+	n := A.Shape[0]
+	L := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			if A.Data[i*n+j] == 0 { // <--- zero fill-in
+				continue
+			}
+			sum := A.Data[i*n+j]
+			for k := 0; k < j; k++ {
+				sum -= L[i*n+k] * L[j*n+k]
+			}
+			if i == j {
+				if sum <= 0 {
+					panic("Within NewIncompleteCholeskyPreconditioner(): Matrix is not positive definite")
+				}
+				L[i*n+i] = math.Sqrt(sum)
+			} else {
+				L[i*n+j] = sum / L[j*n+j]
+			}
+		}
+	}
+	return &IncompleteCholeskyPreconditioner{n: n, L: L}
+}
+
+//============================================================================================================================== Solver Options and Results
+
+/*
+* @notice SolverOptions configures an iterative solver. The zero value is valid and uses the defaults below.
+* @param Tol: relative residual tolerance ||b - Ax|| / ||b|| for convergence (default 1e-8)
+* @param MaxIter: maximum number of iterations (default 10 * len(b))
+* @param Restart: Krylov subspace size before GMRES restarts (default min(30, len(b)))
+* @param Preconditioner: optional preconditioner M, where M^-1 approximates A^-1
+* @param X0: optional initial guess (default zero vector)
+ */
+type SolverOptions struct {
+	Tol            float64
+	MaxIter        int
+	Restart        int
+	Preconditioner Preconditioner
+	X0             *Tensor
+}
+
+// SolverResult contains the solution of an iterative solver along with information about its convergence.
+type SolverResult struct {
+	X          *Tensor
+	Iterations int
+	Residuals  []float64 // <--- relative residual norm after each iteration, starting with the initial guess
+	Converged  bool
+}
+
+// withDefaults fills in any unset options based on the size of the system.
+func (opts SolverOptions) withDefaults(n int) SolverOptions {
+	if opts.Tol <= 0 {
+		opts.Tol = 1e-8
+	}
+	if opts.MaxIter <= 0 {
+		opts.MaxIter = 10 * n
+	}
+	if opts.Restart <= 0 {
+		opts.Restart = int(math.Min(30, float64(n)))
+	}
+	return opts
+}
+
+// precondition applies M^-1 to r, or returns a copy of r when no preconditioner is set.
+func (opts SolverOptions) precondition(r *Tensor) *Tensor {
+	if opts.Preconditioner == nil {
+		return copyVector(r)
+	}
+	return opts.Preconditioner.Solve(r)
+}
+
+// initialGuess returns a copy of X0, or the zero vector if no initial guess was provided.
+func (opts SolverOptions) initialGuess(n int) *Tensor {
+	if opts.X0 == nil {
+		return ZeroTensor([]int{n}, false)
+	}
+	if len(opts.X0.Data) != n {
+		panic("Within SolverOptions: X0 must be the same length as b")
+	}
+	return opts.X0.Copy().Reshape([]int{n}, false)
+}
+
+//============================================================================================================================== Conjugate Gradient
+
+/*
+* @notice ConjugateGradient() solves Ax = b for a symmetric positive definite A using the (preconditioned) conjugate gradient method.
+* @param A: a matrix Tensor or any other LinearOperator
+* @param b: the right hand side vector Tensor
+* @param opts: tolerance, iteration limit, preconditioner and initial guess
+* @return SolverResult with the solution and convergence history
+ */
+func ConjugateGradient(A LinearOperator, b *Tensor, opts SolverOptions) SolverResult {
+
+	n := checkRightHandSide(b, "ConjugateGradient")
+	opts = opts.withDefaults(n)
+
+	x := opts.initialGuess(n)
+	r := residual(A, x, b)
+	z := opts.precondition(r)
+	p := copyVector(z)
+	rz := dotSlices(r.Data, z.Data)
+
+	bNorm := normOrOne(b.Data)
+	result := SolverResult{X: x, Residuals: []float64{normSlice(r.Data) / bNorm}}
+
+	for result.Iterations < opts.MaxIter && result.Residuals[len(result.Residuals)-1] > opts.Tol {
+
+		Ap := A.Apply(p)
+		alpha := rz / dotSlices(p.Data, Ap.Data)
+
+		axpy(alpha, p.Data, x.Data)   // x = x + alpha * p
+		axpy(-alpha, Ap.Data, r.Data) // r = r - alpha * Ap
+
+		result.Iterations++
+		result.Residuals = append(result.Residuals, normSlice(r.Data)/bNorm)
+
+		z = opts.precondition(r)
+		rzNext := dotSlices(r.Data, z.Data)
+		beta := rzNext / rz
+		rz = rzNext
+
+		for i := range p.Data { // p = z + beta * p
+			p.Data[i] = z.Data[i] + beta*p.Data[i]
+		}
+	}
+
+	result.Converged = result.Residuals[len(result.Residuals)-1] <= opts.Tol
+	return result
+}
+
+//============================================================================================================================== GMRES
+
+/*
+* @notice GMRES() solves Ax = b for a general (nonsymmetric) A using restarted GMRES(m) with right preconditioning.
+* @dev Every restart cycle builds an orthonormal Krylov basis of size opts.Restart with modified Gram-Schmidt (Arnoldi).
+* The least squares problem for the Hessenberg matrix is solved incrementally with Givens rotations, which gives the
+* residual norm at each iteration for free.
+* @param A: a matrix Tensor or any other LinearOperator
+* @param b: the right hand side vector Tensor
+* @param opts: tolerance, iteration limit, restart length, preconditioner and initial guess
+* @return SolverResult with the solution and convergence history
+ */
+func GMRES(A LinearOperator, b *Tensor, opts SolverOptions) SolverResult {
+
+	n := checkRightHandSide(b, "GMRES")
+	opts = opts.withDefaults(n)
+	m := opts.Restart
+
+	x := opts.initialGuess(n)
+	bNorm := normOrOne(b.Data)
+	r := residual(A, x, b)
+	result := SolverResult{X: x, Residuals: []float64{normSlice(r.Data) / bNorm}}
+
+	for result.Iterations < opts.MaxIter && result.Residuals[len(result.Residuals)-1] > opts.Tol {
+
+		beta := normSlice(r.Data)
+
+		V := make([][]float64, m+1) // <--- Krylov basis
+		Z := make([]*Tensor, m)     // <--- preconditioned basis vectors, x = x0 + Z y
+		H := make([][]float64, m+1) // <--- (m + 1) x m Hessenberg matrix
+		for i := range H {
+			H[i] = make([]float64, m)
+		}
+		cs, sn, g := make([]float64, m), make([]float64, m), make([]float64, m+1)
+		g[0] = beta
+
+		V[0] = make([]float64, n)
+		for i := range r.Data {
+			V[0][i] = r.Data[i] / beta
+		}
+
+		k := 0
+		for ; k < m && result.Iterations < opts.MaxIter; k++ {
+
+			// Arnoldi step on A M^-1
+			Z[k] = opts.precondition(&Tensor{Shape: []int{n}, Data: V[k]})
+			w := A.Apply(Z[k]).Data
+			for j := 0; j <= k; j++ {
+				H[j][k] = dotSlices(w, V[j])
+				axpy(-H[j][k], V[j], w)
+			}
+			H[k+1][k] = normSlice(w)
+			V[k+1] = make([]float64, n)
+			if H[k+1][k] != 0 {
+				for i := range w {
+					V[k+1][i] = w[i] / H[k+1][k]
+				}
+			}
+
+			// apply previous Givens rotations to the new column of H
+			for j := 0; j < k; j++ {
+				H[j][k], H[j+1][k] = cs[j]*H[j][k]+sn[j]*H[j+1][k], -sn[j]*H[j][k]+cs[j]*H[j+1][k]
+			}
+
+			// compute and apply a new rotation that zeros H[k+1][k]
+			denom := math.Hypot(H[k][k], H[k+1][k])
+			cs[k], sn[k] = H[k][k]/denom, H[k+1][k]/denom
+			H[k][k], H[k+1][k] = denom, 0
+			g[k], g[k+1] = cs[k]*g[k], -sn[k]*g[k]
+
+			result.Iterations++
+			result.Residuals = append(result.Residuals, math.Abs(g[k+1])/bNorm)
+			if math.Abs(g[k+1])/bNorm <= opts.Tol {
+				k++
+				break
+			}
+		}
+
+		// back substitution on the triangular system H y = g, then update x = x + Z y
+		y := make([]float64, k)
+		for i := k - 1; i >= 0; i-- {
+			sum := g[i]
+			for j := i + 1; j < k; j++ {
+				sum -= H[i][j] * y[j]
+			}
+			y[i] = sum / H[i][i]
+		}
+		for j := 0; j < k; j++ {
+			axpy(y[j], Z[j].Data, x.Data)
+		}
+
+		// recompute the true residual for the next restart cycle
+		r = residual(A, x, b)
+		result.Residuals[len(result.Residuals)-1] = normSlice(r.Data) / bNorm
+	}
+
+	result.Converged = result.Residuals[len(result.Residuals)-1] <= opts.Tol
+	return result
+}
+
+//============================================================================================================================== BiCGSTAB
+
+/*
+* @notice BiCGSTAB() solves Ax = b for a general (nonsymmetric) A using the preconditioned biconjugate gradient stabilized method.
+* @dev BiCGSTAB uses a short recurrence, so unlike GMRES its memory use does not grow with the number of iterations.
+* @param A: a matrix Tensor or any other LinearOperator
+* @param b: the right hand side vector Tensor
+* @param opts: tolerance, iteration limit, preconditioner and initial guess
+* @return SolverResult with the solution and convergence history
+ */
+func BiCGSTAB(A LinearOperator, b *Tensor, opts SolverOptions) SolverResult {
+
+	n := checkRightHandSide(b, "BiCGSTAB")
+	opts = opts.withDefaults(n)
+
+	x := opts.initialGuess(n)
+	r := residual(A, x, b)
+	rHat := copyVector(r) // <--- shadow residual
+	bNorm := normOrOne(b.Data)
+	result := SolverResult{X: x, Residuals: []float64{normSlice(r.Data) / bNorm}}
+
+	rho, alpha, omega := 1.0, 1.0, 1.0
+	v := make([]float64, n)
+	p := make([]float64, n)
+
+	for result.Iterations < opts.MaxIter && result.Residuals[len(result.Residuals)-1] > opts.Tol {
+
+		rhoNext := dotSlices(rHat.Data, r.Data)
+		if rhoNext == 0 {
+			break // <--- breakdown, the shadow residual is orthogonal to r
+		}
+		beta := (rhoNext / rho) * (alpha / omega)
+		rho = rhoNext
+
+		for i := range p { // p = r + beta * (p - omega * v)
+			p[i] = r.Data[i] + beta*(p[i]-omega*v[i])
+		}
+
+		pHat := opts.precondition(&Tensor{Shape: []int{n}, Data: p})
+		v = A.Apply(pHat).Data
+		alpha = rho / dotSlices(rHat.Data, v)
+
+		s := copyVector(r)
+		axpy(-alpha, v, s.Data) // s = r - alpha * v
+
+		if normSlice(s.Data)/bNorm <= opts.Tol { // <--- early exit on half step
+			axpy(alpha, pHat.Data, x.Data)
+			r = s
+			result.Iterations++
+			result.Residuals = append(result.Residuals, normSlice(r.Data)/bNorm)
+			break
+		}
+
+		sHat := opts.precondition(s)
+		t := A.Apply(sHat).Data
+		omega = dotSlices(t, s.Data) / dotSlices(t, t)
+
+		axpy(alpha, pHat.Data, x.Data) // x = x + alpha * pHat + omega * sHat
+		axpy(omega, sHat.Data, x.Data)
+
+		r = s
+		axpy(-omega, t, r.Data) // r = s - omega * t
+
+		result.Iterations++
+		result.Residuals = append(result.Residuals, normSlice(r.Data)/bNorm)
+
+		if omega == 0 {
+			break
+		}
+	}
+
+	result.Converged = result.Residuals[len(result.Residuals)-1] <= opts.Tol
+	return result
+}
+
+//============================================================================================================================== Helper Functions for Iterative Solvers
+
+// residual computes r = b - Ax
+func residual(A LinearOperator, x *Tensor, b *Tensor) *Tensor {
+	Ax := A.Apply(x)
+	r := &Tensor{Shape: []int{len(b.Data)}, Data: make([]float64, len(b.Data))}
+	for i := range r.Data {
+		r.Data[i] = b.Data[i] - Ax.Data[i]
+	}
+	return r
+}
+
+// checkRightHandSide ensures b is a vector (or column vector) and returns its length
+func checkRightHandSide(b *Tensor, caller string) int {
+	if len(b.Shape) != 1 && !(len(b.Shape) == 2 && b.Shape[1] == 1) {
+		panic("Within " + caller + "(): b must be a vector Tensor")
+	}
+	return len(b.Data)
+}
+
+// checkSquareMatrix ensures A is a square 2D Tensor
+func checkSquareMatrix(A *Tensor, caller string) {
+	if len(A.Shape) != 2 || A.Shape[0] != A.Shape[1] {
+		panic("Within " + caller + "(): A must be a square matrix")
+	}
+}
+
+// copyVector copies the data of a vector Tensor without allocating the gradient tracked DataReqGrad slice
+func copyVector(r *Tensor) *Tensor {
+	return &Tensor{Shape: []int{len(r.Data)}, Data: append([]float64(nil), r.Data...)}
+}
+
+// dotSlices computes the dot product of two float64 slices
+func dotSlices(a, b []float64) float64 {
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+// normSlice computes the euclidean norm of a float64 slice
+func normSlice(a []float64) float64 {
+	return math.Sqrt(dotSlices(a, a))
+}
+
+// normOrOne returns the norm of a, or 1 if a is the zero vector, so that relative residuals are well defined
+func normOrOne(a []float64) float64 {
+	if norm := normSlice(a); norm != 0 {
+		return norm
+	}
+	return 1
+}
+
+// axpy computes y = alpha * x + y in place
+func axpy(alpha float64, x, y []float64) {
+	for i := range x {
+		y[i] += alpha * x[i]
+	}
+}
@@ -0,0 +1,706 @@
+package TG
+
+/*
+* @notice LinearModels.go contains linear estimators for [samples, features] Tensors: LinearRegression (ordinary least squares),
+* ElasticNet with its special cases Ridge and Lasso, and LogisticRegression for binary and multinomial classification.
+* @dev Every model is fit with Fit(X, y) and exposes its fitted Coef and Intercept. Regressors accept targets of shape [samples],
+* giving Coef [features], or [samples, targets], giving Coef [features, targets]. Classifiers accept any label values, one per
+* sample, and predict those labels.
+* @dev Regressors fit the intercept by centering X and y, so the intercept is never penalized.
+ */
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Regressor predicts continuous targets
+type Regressor interface {
+	Fit(X *Tensor, y *Tensor)
+	Predict(X *Tensor) *Tensor
+}
+
+// Classifier predicts labels, and the probability of each class in the order of its Classes
+type Classifier interface {
+	Fit(X *Tensor, y *Tensor)
+	Predict(X *Tensor) *Tensor
+	PredictProba(X *Tensor) *Tensor
+}
+
+//============================================================================================================================== Metrics
+
+// R2Score() returns the coefficient of determination of predictions yPred of yTrue, averaged over targets. 1 is a perfect fit.
+func R2Score(yTrue *Tensor, yPred *Tensor) float64 {
+	if !isEqual(yTrue.Shape, yPred.Shape) || len(yTrue.Shape) == 0 || yTrue.Shape[0] == 0 {
+		panic("Within R2Score(): yTrue and yPred must have the same non empty shape")
+	}
+	n := yTrue.Shape[0]
+	numTargets := len(yTrue.Data) / n
+
+	total := 0.0
+	for t := 0; t < numTargets; t++ {
+		mean := 0.0
+		for i := 0; i < n; i++ {
+			mean += yTrue.Data[i*numTargets+t]
+		}
+		mean /= float64(n)
+
+		residual, variation := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			value := yTrue.Data[i*numTargets+t]
+			residual += (value - yPred.Data[i*numTargets+t]) * (value - yPred.Data[i*numTargets+t])
+			variation += (value - mean) * (value - mean)
+		}
+		switch {
+		case variation > 0:
+			total += 1 - residual/variation
+		case residual == 0:
+			total += 1 // <--- a constant target predicted exactly
+		}
+	}
+	return total / float64(numTargets)
+}
+
+// AccuracyScore() returns the fraction of labels in yPred that equal those in yTrue.
+func AccuracyScore(yTrue *Tensor, yPred *Tensor) float64 {
+	if len(yTrue.Data) != len(yPred.Data) || len(yTrue.Data) == 0 {
+		panic("Within AccuracyScore(): yTrue and yPred must have the same non zero number of labels")
+	}
+	correct := 0
+	for i := range yTrue.Data {
+		if yTrue.Data[i] == yPred.Data[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(yTrue.Data))
+}
+
+//============================================================================================================================== Helpers
+
+// targetMatrix returns the number of targets of y, which must be [n] or [n, targets]
+func targetMatrix(y *Tensor, n int, caller string) int {
+	if (len(y.Shape) != 1 && len(y.Shape) != 2) || y.Shape[0] != n {
+		panic(fmt.Sprintf("Within %v(): y must be [%v] or [%v, targets], got shape %v", caller, n, n, y.Shape))
+	}
+	return len(y.Data) / n
+}
+
+// centered returns copies of the row major X [n, f] and Y [n, t] minus their column means, and the means. Without an
+// intercept the data is copied as is and the means are zero.
+func centered(X []float64, n, f int, Y []float64, t int, fitIntercept bool) ([]float64, []float64, []float64, []float64) {
+	X, Y = slices.Clone(X), slices.Clone(Y)
+	xMean, yMean := make([]float64, f), make([]float64, t)
+	if !fitIntercept {
+		return X, Y, xMean, yMean
+	}
+	for _, c := range []struct {
+		data  []float64
+		width int
+		mean  []float64
+	}{{X, f, xMean}, {Y, t, yMean}} {
+		for i := 0; i < n; i++ {
+			for j := 0; j < c.width; j++ {
+				c.mean[j] += c.data[i*c.width+j] / float64(n)
+			}
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < c.width; j++ {
+				c.data[i*c.width+j] -= c.mean[j]
+			}
+		}
+	}
+	return X, Y, xMean, yMean
+}
+
+// linearParams packs fitted weights W [f, t] into Coef and Intercept Tensors, recovering the intercept from the centering means
+func linearParams(W []float64, xMean, yMean []float64, vector bool) (*Tensor, *Tensor) {
+	f, t := len(xMean), len(yMean)
+	intercept := &Tensor{Shape: []int{t}, Data: slices.Clone(yMean)}
+	for k := 0; k < t; k++ {
+		for j := 0; j < f; j++ {
+			intercept.Data[k] -= xMean[j] * W[j*t+k]
+		}
+	}
+	coef := &Tensor{Shape: []int{f, t}, Data: W}
+	if vector {
+		coef.Shape = []int{f}
+	}
+	return coef, intercept
+}
+
+// linearPredict computes X Coef + Intercept, as [samples] for a vector Coef and [samples, outputs] otherwise
+func linearPredict(X *Tensor, coef *Tensor, intercept *Tensor, caller string) *Tensor {
+	if coef == nil {
+		panic("Within " + caller + "(): the model must be fit first")
+	}
+	f, t := coef.Shape[0], len(intercept.Data)
+	checkFeatures(X, f, caller)
+	n := X.Shape[0]
+
+	C := &Tensor{Shape: []int{n, t}, Data: make([]float64, n*t), Batched: X.Batched}
+	for i := 0; i < n; i++ {
+		copy(C.Data[i*t:(i+1)*t], intercept.Data)
+	}
+	gemm(X.Data, coef.Data, C.Data, n, f, t)
+	if len(coef.Shape) == 1 {
+		C.Shape = []int{n}
+	}
+	return C
+}
+
+/*
+* @notice leastSquares() solves min ||A X - B|| for the row major A [m, n] and B [m, k] with a Householder QR factorization,
+* returning X [n, k].
+* @dev QR avoids squaring the condition number of A, as solving the normal equations would. A must have full column rank.
+ */
+func leastSquares(A []float64, m, n int, B []float64, k int, caller string) []float64 {
+
+	if m < n {
+		panic(fmt.Sprintf("Within %v(): %v samples are too few to fit %v coefficients, use Ridge", caller, m, n))
+	}
+	A, B = slices.Clone(A), slices.Clone(B)
+
+	// a column is dependent on the ones before it when its norm below the diagonal is negligible
+	scale := 0.0
+	for j := 0; j < n; j++ {
+		norm := 0.0
+		for i := 0; i < m; i++ {
+			norm += A[i*n+j] * A[i*n+j]
+		}
+		scale = math.Max(scale, math.Sqrt(norm))
+	}
+
+	v := make([]float64, m)
+	for j := 0; j < n; j++ {
+		norm := 0.0
+		for i := j; i < m; i++ {
+			norm += A[i*n+j] * A[i*n+j]
+		}
+		norm = math.Sqrt(norm)
+		if norm <= 1e-10*scale || norm == 0 {
+			panic(fmt.Sprintf("Within %v(): the features are linearly dependent, use Ridge", caller))
+		}
+
+		// the reflection I - 2 v v^T / v^T v maps column j below the diagonal to alpha e_j
+		alpha := -math.Copysign(norm, A[j*n+j])
+		vNorm := 0.0
+		for i := j; i < m; i++ {
+			v[i] = A[i*n+j]
+			if i == j {
+				v[i] -= alpha
+			}
+			vNorm += v[i] * v[i]
+		}
+		reflect := func(data []float64, width, column int) {
+			s := 0.0
+			for i := j; i < m; i++ {
+				s += v[i] * data[i*width+column]
+			}
+			s *= 2 / vNorm
+			for i := j; i < m; i++ {
+				data[i*width+column] -= s * v[i]
+			}
+		}
+		for c := j; c < n; c++ {
+			reflect(A, n, c)
+		}
+		for c := 0; c < k; c++ {
+			reflect(B, k, c)
+		}
+	}
+
+	// back substitute R X = Q^T B
+	X := make([]float64, n*k)
+	for c := 0; c < k; c++ {
+		for i := n - 1; i >= 0; i-- {
+			sum := B[i*k+c]
+			for j := i + 1; j < n; j++ {
+				sum -= A[i*n+j] * X[j*k+c]
+			}
+			X[i*k+c] = sum / A[i*n+i]
+		}
+	}
+	return X
+}
+
+//============================================================================================================================== LinearRegression
+
+// LinearRegression fits ordinary least squares
+type LinearRegression struct {
+	FitIntercept bool
+
+	Coef      *Tensor // <--- [features] or [features, targets]
+	Intercept *Tensor // <--- [targets]
+}
+
+// NewLinearRegression() creates a LinearRegression that fits an intercept.
+func NewLinearRegression() *LinearRegression {
+	return &LinearRegression{FitIntercept: true}
+}
+
+// Fit() solves the least squares problem with a QR factorization. It panics when the features are linearly dependent.
+func (m *LinearRegression) Fit(X *Tensor, y *Tensor) {
+	checkFeatures(X, -1, "LinearRegression.Fit")
+	n, f := X.Shape[0], X.Shape[1]
+	t := targetMatrix(y, n, "LinearRegression.Fit")
+
+	Xc, Yc, xMean, yMean := centered(X.Data, n, f, y.Data, t, m.FitIntercept)
+	W := leastSquares(Xc, n, f, Yc, t, "LinearRegression.Fit")
+	m.Coef, m.Intercept = linearParams(W, xMean, yMean, len(y.Shape) == 1)
+}
+
+func (m *LinearRegression) Predict(X *Tensor) *Tensor {
+	return linearPredict(X, m.Coef, m.Intercept, "LinearRegression.Predict")
+}
+
+// Score() returns the R2Score() of the predictions for X.
+func (m *LinearRegression) Score(X *Tensor, y *Tensor) float64 {
+	return R2Score(y, m.Predict(X))
+}
+
+//============================================================================================================================== ElasticNet, Ridge, Lasso
+
+/*
+* @notice ElasticNet fits least squares with a combined L1 and L2 penalty by coordinate descent, minimizing
+*     1 / (2 n) ||y - X w||² + Alpha L1Ratio ||w||₁ + Alpha (1 - L1Ratio) / 2 ||w||²
+* @dev L1Ratio 0 is Ridge and L1Ratio 1 is Lasso. The L1 penalty sets coefficients of uninformative features to exactly zero.
+* @dev Coordinate descent stops when no coefficient changes by more than Tol times the largest coefficient, or after MaxIter
+* sweeps over the features.
+ */
+type ElasticNet struct {
+	Alpha        float64
+	L1Ratio      float64
+	FitIntercept bool
+	MaxIter      int
+	Tol          float64
+
+	Coef      *Tensor // <--- [features] or [features, targets]
+	Intercept *Tensor // <--- [targets]
+	NumIter   int     // <--- sweeps used by the last fit, the most of any target
+}
+
+// NewElasticNet() creates an ElasticNet with penalty strength alpha and the given share of L1 penalty.
+func NewElasticNet(alpha float64, l1Ratio float64) *ElasticNet {
+	if alpha < 0 || l1Ratio < 0 || l1Ratio > 1 {
+		panic("Within NewElasticNet(): alpha must be non negative and l1Ratio between 0 and 1")
+	}
+	return &ElasticNet{Alpha: alpha, L1Ratio: l1Ratio, FitIntercept: true, MaxIter: 1000, Tol: 1e-6}
+}
+
+// NewRidge() creates an ElasticNet with only the L2 penalty.
+func NewRidge(alpha float64) *ElasticNet {
+	return NewElasticNet(alpha, 0)
+}
+
+// NewLasso() creates an ElasticNet with only the L1 penalty.
+func NewLasso(alpha float64) *ElasticNet {
+	return NewElasticNet(alpha, 1)
+}
+
+// softThreshold shrinks x towards zero by threshold
+func softThreshold(x float64, threshold float64) float64 {
+	if x > threshold {
+		return x - threshold
+	}
+	if x < -threshold {
+		return x + threshold
+	}
+	return 0
+}
+
+func (m *ElasticNet) Fit(X *Tensor, y *Tensor) {
+	checkFeatures(X, -1, "ElasticNet.Fit")
+	n, f := X.Shape[0], X.Shape[1]
+	t := targetMatrix(y, n, "ElasticNet.Fit")
+
+	Xc, Yc, xMean, yMean := centered(X.Data, n, f, y.Data, t, m.FitIntercept)
+	l1, l2 := m.Alpha*m.L1Ratio, m.Alpha*(1-m.L1Ratio)
+
+	// store features as contiguous columns for the coordinate updates
+	columns := make([][]float64, f)
+	squares := make([]float64, f) // <--- ||x_j||² / n
+	for j := range columns {
+		columns[j] = make([]float64, n)
+		for i := 0; i < n; i++ {
+			columns[j][i] = Xc[i*f+j]
+			squares[j] += Xc[i*f+j] * Xc[i*f+j] / float64(n)
+		}
+	}
+
+	W := make([]float64, f*t)
+	iters := make([]int, t)
+	parallelFor(t, func(k int) {
+		w, residual := make([]float64, f), make([]float64, n)
+		for i := range residual {
+			residual[i] = Yc[i*t+k]
+		}
+
+		iter := 0
+		for iter < m.MaxIter {
+			iter++
+			maxChange, maxWeight := 0.0, 0.0
+			for j, column := range columns {
+				if squares[j]+l2 == 0 {
+					continue // <--- a constant feature without an L2 penalty stays at zero
+				}
+				rho := dotSlices(column, residual)/float64(n) + squares[j]*w[j]
+				updated := softThreshold(rho, l1) / (squares[j] + l2)
+				if change := updated - w[j]; change != 0 {
+					axpy(-change, column, residual)
+					maxChange = math.Max(maxChange, math.Abs(change))
+				}
+				w[j] = updated
+				maxWeight = math.Max(maxWeight, math.Abs(updated))
+			}
+			if maxChange <= m.Tol*maxWeight {
+				break
+			}
+		}
+		for j := range w {
+			W[j*t+k] = w[j]
+		}
+		iters[k] = iter
+	})
+	m.NumIter = slices.Max(iters)
+	m.Coef, m.Intercept = linearParams(W, xMean, yMean, len(y.Shape) == 1)
+}
+
+func (m *ElasticNet) Predict(X *Tensor) *Tensor {
+	return linearPredict(X, m.Coef, m.Intercept, "ElasticNet.Predict")
+}
+
+// Score() returns the R2Score() of the predictions for X.
+func (m *ElasticNet) Score(X *Tensor, y *Tensor) float64 {
+	return R2Score(y, m.Predict(X))
+}
+
+//============================================================================================================================== LogisticRegression
+
+/*
+* @notice LogisticRegression is a linear classifier that minimizes the mean cross entropy plus Alpha / 2 ||Coef||².
+* @dev Two classes are fit with a sigmoid by Newton's method, giving Coef [features] and Intercept [1], where the
+* probability of Classes[1] is sigmoid(X Coef + Intercept). More classes are fit with a softmax by L-BFGS, giving Coef
+* [features, classes] and Intercept [classes].
+* @dev Fitting stops when a step changes no parameter by more than Tol (Newton's method) or no gradient entry exceeds Tol
+* (L-BFGS), or after MaxIter iterations. Alpha > 0 keeps the coefficients finite on separable data.
+ */
+type LogisticRegression struct {
+	Alpha        float64
+	FitIntercept bool
+	MaxIter      int
+	Tol          float64
+
+	Classes   []float64 // <--- the sorted labels seen by Fit()
+	Coef      *Tensor
+	Intercept *Tensor
+	NumIter   int // <--- iterations used by the last fit
+}
+
+// NewLogisticRegression() creates a LogisticRegression with a small L2 penalty.
+func NewLogisticRegression() *LogisticRegression {
+	return &LogisticRegression{Alpha: 1e-4, FitIntercept: true, MaxIter: 100, Tol: 1e-6}
+}
+
+// softplus computes log(1 + e^z) without overflow
+func softplus(z float64) float64 {
+	return math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z)))
+}
+
+// sigmoid computes 1 / (1 + e^-z) without overflow
+func sigmoid(z float64) float64 {
+	if z >= 0 {
+		return 1 / (1 + math.Exp(-z))
+	}
+	e := math.Exp(z)
+	return e / (1 + e)
+}
+
+func (m *LogisticRegression) Fit(X *Tensor, y *Tensor) {
+	checkFeatures(X, -1, "LogisticRegression.Fit")
+	n, f := X.Shape[0], X.Shape[1]
+	if len(y.Data) != n {
+		panic(fmt.Sprintf("Within LogisticRegression.Fit(): y must have one label per sample, got shape %v for %v samples", y.Shape, n))
+	}
+
+	encoder := &LabelEncoder{}
+	codes := FitTransform(encoder, y).Data
+	m.Classes = encoder.Classes
+	if len(m.Classes) < 2 {
+		panic("Within LogisticRegression.Fit(): y must have at least 2 classes")
+	}
+
+	// the design matrix Z has a trailing column of ones for the intercept, which is left out of the penalty
+	d := f
+	if m.FitIntercept {
+		d++
+	}
+	Z := make([]float64, n*d)
+	for i := 0; i < n; i++ {
+		copy(Z[i*d:], X.Data[i*f:(i+1)*f])
+		if m.FitIntercept {
+			Z[i*d+f] = 1
+		}
+	}
+
+	var W []float64
+	if len(m.Classes) == 2 {
+		W, m.NumIter = m.fitBinary(Z, n, d, f, codes)
+	} else {
+		W, m.NumIter = m.fitMultinomial(Z, n, d, f, codes)
+	}
+
+	k := len(W) / d
+	m.Coef = &Tensor{Shape: []int{f, k}, Data: W[:f*k]}
+	m.Intercept = &Tensor{Shape: []int{k}, Data: make([]float64, k)}
+	if m.FitIntercept {
+		copy(m.Intercept.Data, W[f*k:])
+	}
+	if k == 1 {
+		m.Coef.Shape = []int{f}
+	}
+}
+
+// fitBinary fits a sigmoid to labels of 0 and 1 with Newton's method and a backtracking line search
+func (m *LogisticRegression) fitBinary(Z []float64, n, d, f int, y []float64) ([]float64, int) {
+
+	loss := func(w []float64) float64 {
+		total := 0.0
+		for i := 0; i < n; i++ {
+			z := dotSlices(Z[i*d:(i+1)*d], w)
+			total += softplus(z) - y[i]*z
+		}
+		return total/float64(n) + m.Alpha/2*dotSlices(w[:f], w[:f])
+	}
+
+	w := make([]float64, d)
+	iter := 0
+	for iter < m.MaxIter {
+		iter++
+
+		// gradient and Hessian of the loss
+		gradient := &Tensor{Shape: []int{d, 1}, Data: make([]float64, d)}
+		hessian := &Tensor{Shape: []int{d, d}, Data: make([]float64, d*d)}
+		for i := 0; i < n; i++ {
+			row := Z[i*d : (i+1)*d]
+			p := sigmoid(dotSlices(row, w))
+			axpy((p-y[i])/float64(n), row, gradient.Data)
+			weight := p * (1 - p) / float64(n)
+			for a := 0; a < d; a++ {
+				axpy(weight*row[a], row, hessian.Data[a*d:(a+1)*d])
+			}
+		}
+		for j := 0; j < d; j++ {
+			if j < f {
+				gradient.Data[j] += m.Alpha * w[j]
+				hessian.Data[j*d+j] += m.Alpha
+			}
+			hessian.Data[j*d+j] += 1e-12 // <--- keeps the Hessian invertible when the classes are separated
+		}
+		step := Gaussian_Elimination(hessian, gradient, false).Data
+
+		// halve the step until the loss decreases enough
+		current, scale := loss(w), 1.0
+		candidate := make([]float64, d)
+		for ; scale > 1e-10; scale /= 2 {
+			copy(candidate, w)
+			axpy(-scale, step, candidate)
+			if loss(candidate) <= current-1e-4*scale*dotSlices(gradient.Data, step) {
+				break
+			}
+		}
+		copy(w, candidate)
+
+		maxChange := 0.0
+		for _, s := range step {
+			maxChange = math.Max(maxChange, math.Abs(scale*s))
+		}
+		if maxChange <= m.Tol {
+			break
+		}
+	}
+	return w, iter
+}
+
+// fitMultinomial fits a softmax to class codes 0, ..., K - 1 with L-BFGS, returning W [d, K]
+func (m *LogisticRegression) fitMultinomial(Z []float64, n, d, f int, codes []float64) ([]float64, int) {
+
+	K := len(m.Classes)
+	Zt := make([]float64, d*n) // <--- Z transposed, for the gradient Z^T (P - Y)
+	for i := 0; i < n; i++ {
+		for j := 0; j < d; j++ {
+			Zt[j*n+i] = Z[i*d+j]
+		}
+	}
+
+	objective := func(W []float64, gradient []float64) float64 {
+		logits := make([]float64, n*K)
+		gemm(Z, W, logits, n, d, K)
+
+		total := 0.0
+		for i := 0; i < n; i++ {
+			row := logits[i*K : (i+1)*K]
+			lse := LogSumExpReducer{}.Reduce(row)
+			total += lse - row[int(codes[i])]
+			for k := range row {
+				row[k] = math.Exp(row[k]-lse) / float64(n) // <--- row becomes (P - Y) / n
+			}
+			row[int(codes[i])] -= 1 / float64(n)
+		}
+
+		clear(gradient)
+		gemm(Zt, logits, gradient, d, n, K)
+		for j := 0; j < f*K; j++ {
+			gradient[j] += m.Alpha * W[j]
+		}
+		return total/float64(n) + m.Alpha/2*dotSlices(W[:f*K], W[:f*K])
+	}
+
+	W := make([]float64, d*K)
+	iter := lbfgs(objective, W, m.MaxIter, m.Tol)
+	return W, iter
+}
+
+/*
+* @notice lbfgs() minimizes objective from x in place with limited memory BFGS, returning the number of iterations.
+* @dev objective returns the value at x and writes the gradient into its second argument. Each step is found by a
+* backtracking line search. Iteration stops when no gradient entry exceeds tol.
+ */
+func lbfgs(objective func(x []float64, gradient []float64) float64, x []float64, maxIter int, tol float64) int {
+
+	const memory = 10
+	n := len(x)
+	gradient := make([]float64, n)
+	value := objective(x, gradient)
+
+	var sHistory, yHistory [][]float64
+	var rhoHistory []float64
+	direction, candidate, newGradient := make([]float64, n), make([]float64, n), make([]float64, n)
+	alphas := make([]float64, memory)
+
+	iter := 0
+	for iter < maxIter {
+		if maxAbs(gradient) <= tol {
+			break
+		}
+		iter++
+
+		// the two loop recursion computes the quasi Newton direction -H g
+		copy(direction, gradient)
+		for k := len(sHistory) - 1; k >= 0; k-- {
+			alphas[k] = rhoHistory[k] * dotSlices(sHistory[k], direction)
+			axpy(-alphas[k], yHistory[k], direction)
+		}
+		if last := len(sHistory) - 1; last >= 0 {
+			scale := dotSlices(sHistory[last], yHistory[last]) / dotSlices(yHistory[last], yHistory[last])
+			for i := range direction {
+				direction[i] *= scale
+			}
+		} else {
+			scale := 1 / math.Max(1, normSlice(gradient)) // <--- a cautious first step
+			for i := range direction {
+				direction[i] *= scale
+			}
+		}
+		for k := range sHistory {
+			beta := rhoHistory[k] * dotSlices(yHistory[k], direction)
+			axpy(alphas[k]-beta, sHistory[k], direction)
+		}
+		for i := range direction {
+			direction[i] = -direction[i]
+		}
+
+		slope := dotSlices(gradient, direction)
+		if slope >= 0 { // <--- not a descent direction, so restart from steepest descent
+			sHistory, yHistory, rhoHistory = nil, nil, nil
+			for i := range direction {
+				direction[i] = -gradient[i]
+			}
+			slope = -dotSlices(gradient, gradient)
+		}
+
+		step, newValue := 1.0, 0.0
+		for ; step > 1e-12; step /= 2 {
+			copy(candidate, x)
+			axpy(step, direction, candidate)
+			if newValue = objective(candidate, newGradient); newValue <= value+1e-4*step*slope {
+				break
+			}
+		}
+		if step <= 1e-12 {
+			break // <--- no further progress is possible
+		}
+
+		s, y := make([]float64, n), make([]float64, n)
+		for i := range s {
+			s[i] = candidate[i] - x[i]
+			y[i] = newGradient[i] - gradient[i]
+		}
+		if sy := dotSlices(s, y); sy > 1e-12 {
+			if len(sHistory) == memory {
+				sHistory, yHistory, rhoHistory = sHistory[1:], yHistory[1:], rhoHistory[1:]
+			}
+			sHistory, yHistory, rhoHistory = append(sHistory, s), append(yHistory, y), append(rhoHistory, 1/sy)
+		}
+		copy(x, candidate)
+		copy(gradient, newGradient)
+		value = newValue
+	}
+	return iter
+}
+
+// maxAbs returns the largest absolute value of a
+func maxAbs(a []float64) float64 {
+	largest := 0.0
+	for _, value := range a {
+		largest = math.Max(largest, math.Abs(value))
+	}
+	return largest
+}
+
+// DecisionFunction() returns the linear scores of X: [samples] for two classes and [samples, classes] otherwise.
+func (m *LogisticRegression) DecisionFunction(X *Tensor) *Tensor {
+	return linearPredict(X, m.Coef, m.Intercept, "LogisticRegression.DecisionFunction")
+}
+
+// PredictProba() returns the probability of each class for each sample as [samples, classes].
+func (m *LogisticRegression) PredictProba(X *Tensor) *Tensor {
+	scores := m.DecisionFunction(X)
+	n, K := X.Shape[0], len(m.Classes)
+	P := &Tensor{Shape: []int{n, K}, Data: make([]float64, n*K), Batched: X.Batched}
+
+	for i := 0; i < n; i++ {
+		if K == 2 {
+			p := sigmoid(scores.Data[i])
+			P.Data[2*i], P.Data[2*i+1] = 1-p, p
+			continue
+		}
+		row := scores.Data[i*K : (i+1)*K]
+		lse := LogSumExpReducer{}.Reduce(row)
+		for k, score := range row {
+			P.Data[i*K+k] = math.Exp(score - lse)
+		}
+	}
+	return P
+}
+
+// Predict() returns the most probable label of each sample as [samples].
+func (m *LogisticRegression) Predict(X *Tensor) *Tensor {
+	return mostProbable(m.PredictProba(X), m.Classes)
+}
+
+// Score() returns the AccuracyScore() of the predictions for X.
+func (m *LogisticRegression) Score(X *Tensor, y *Tensor) float64 {
+	return AccuracyScore(y, m.Predict(X))
+}
+
+// mostProbable returns the class of the largest probability in each row of P [samples, classes]
+func mostProbable(P *Tensor, classes []float64) *Tensor {
+	n, K := P.Shape[0], P.Shape[1]
+	labels := &Tensor{Shape: []int{n}, Data: make([]float64, n), Batched: P.Batched}
+	for i := 0; i < n; i++ {
+		row := P.Data[i*K : (i+1)*K]
+		labels.Data[i] = classes[slices.Index(row, slices.Max(row))]
+	}
+	return labels
+}
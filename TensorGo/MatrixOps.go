@@ -8,6 +8,10 @@ import (
 
 //===================================================================================================================== Matmul()
 
+/*
+* @notice MatMulOp computes matrix products with a cache blocked kernel, see gemm() below.
+* @dev The output Tensor is created without a DataReqGrad slice. Gradient tracked products should use MatMulGrad().
+ */
 type MatMulOp struct{}
 
 // Implementing the Execute method of IBatching interface
@@ -23,6 +27,107 @@ func (op MatMulOp) Execute(tensors ...*Tensor) *Tensor {
 	// Check that the two Tensors are compatible for matrix multiplication
 	Check_MatMul_Compatibility(A, B)
 
+	m, k, n := A.Shape[0], A.Shape[1], B.Shape[1]
+	C := &Tensor{Shape: []int{m, n}, Data: make([]float64, m*n)}
+	gemm(A.Data, B.Data, C.Data, m, k, n)
+
+	return C
+}
+
+/*
+* @notice MatMul() computes the matrix product of two 2D Tensors, or of each pair of elements of two batched Tensors.
+* @dev When batching, A is [batch, m, k] and B is either [batch, k, n] or a batch of vectors [batch, k]. The output is
+* preallocated and the row blocks of every batch element share one pool of workers, rather than computing each element
+* separately and concatenating the results.
+ */
+func MatMul(A *Tensor, B *Tensor, batching bool) *Tensor {
+
+	matmul := MatMulOp{} // Create an instance of Batched_Matmul
+
+	if !batching {
+		return matmul.Execute(A, B)
+	}
+
+	// Irregular batched shapes fall back to the general batching abstraction
+	if len(A.Shape) != 3 || (len(B.Shape) != 3 && len(B.Shape) != 2) || A.Shape[0] != B.Shape[0] {
+		return BatchedOperation(matmul, A, B)
+	}
+
+	batchSize, m, k := A.Shape[0], A.Shape[1], A.Shape[2]
+	n := 1 // <--- batch of vectors
+	if len(B.Shape) == 3 {
+		n = B.Shape[2]
+	}
+	if B.Shape[1] != k {
+		panic("Within Matmul(): 2D Tensors must be compatible for matmul")
+	}
+
+	C := &Tensor{Shape: []int{batchSize, m, n}, Data: make([]float64, batchSize*m*n)}
+	numRowBlocks := (m + gemmBlockM - 1) / gemmBlockM
+	parallelForIfLarge(batchSize*m*n*k, batchSize*numRowBlocks, func(task int) {
+		b, rowStart := task/numRowBlocks, (task%numRowBlocks)*gemmBlockM
+		gemmRows(A.Data[b*m*k:(b+1)*m*k], B.Data[b*k*n:(b+1)*k*n], C.Data[b*m*n:(b+1)*m*n], k, n, rowStart, min(rowStart+gemmBlockM, m))
+	})
+
+	return C
+}
+
+//===================================================================================================================== MatMulTransposeB()
+
+type MatMulTransposeBOp struct{}
+
+func (op MatMulTransposeBOp) Execute(tensors ...*Tensor) *Tensor {
+
+	A, B := tensors[0], tensors[1]
+
+	if len(A.Shape) != 2 || len(B.Shape) != 2 || A.Shape[1] != B.Shape[1] {
+		panic("Within MatMulTransposeB(): A must be [m, k] and B must be [n, k]")
+	}
+
+	m, k, n := A.Shape[0], A.Shape[1], B.Shape[0]
+	C := &Tensor{Shape: []int{m, n}, Data: make([]float64, m*n)}
+
+	// every element of C is the dot product of a row of A and a row of B, both of which are contiguous in memory
+	numRowBlocks := (m + gemmBlockM - 1) / gemmBlockM
+	parallelForIfLarge(m*n*k, numRowBlocks, func(block int) {
+		for i := block * gemmBlockM; i < min((block+1)*gemmBlockM, m); i++ {
+			aRow := A.Data[i*k : (i+1)*k]
+			for j := 0; j < n; j++ {
+				C.Data[i*n+j] = dotSlices(aRow, B.Data[j*k:(j+1)*k])
+			}
+		}
+	})
+	return C
+}
+
+/*
+* @notice MatMulTransposeB() computes A B^T without materializing the transpose of B. This is the fast path for products
+* such as X W^T, where both operands are stored with the contracted dimension last. There is optional batching.
+* @param A: an [m, k] Tensor
+* @param B: an [n, k] Tensor
+ */
+func MatMulTransposeB(A *Tensor, B *Tensor, batching bool) *Tensor {
+
+	op := MatMulTransposeBOp{}
+
+	if batching {
+		return BatchedOperation(op, A, B)
+	}
+	return op.Execute(A, B)
+}
+
+//===================================================================================================================== MatMulNaive()
+
+type MatMulNaiveOp struct{}
+
+func (op MatMulNaiveOp) Execute(tensors ...*Tensor) *Tensor {
+	A, B := tensors[0], tensors[1]
+
+	if len(B.Shape) == 1 {
+		B = B.Add_Singleton(0)
+	}
+	Check_MatMul_Compatibility(A, B)
+
 	C := ZeroTensor([]int{A.Shape[0], B.Shape[1]}, false)
 	var sum float64
 
@@ -40,17 +145,72 @@ func (op MatMulOp) Execute(tensors ...*Tensor) *Tensor {
 	return C
 }
 
-func MatMul(A *Tensor, B *Tensor, batching bool) *Tensor {
-
-	matmul := MatMulOp{} // Create an instance of Batched_Matmul
+// MatMulNaive() is the original triple loop matrix multiplication. It is kept as a reference implementation for
+// testing and benchmarking MatMul().
+func MatMulNaive(A *Tensor, B *Tensor, batching bool) *Tensor {
 
 	if batching {
-		// If batching is true, call BatchedOperation directly
-		return BatchedOperation(matmul, A, B)
-	} else {
-		// If batching is false, call the Execute method directly
-		return matmul.Execute(A, B)
+		return BatchedOperation(MatMulNaiveOp{}, A, B)
+	}
+	return MatMulNaiveOp{}.Execute(A, B)
+}
+
+//===================================================================================================================== GEMM Kernel
+
+// Tile sizes for the blocked kernel. A gemmBlockK x gemmBlockN tile of B (256KB) is reused across gemmBlockM rows of A.
+const (
+	gemmBlockM = 32
+	gemmBlockN = 256
+	gemmBlockK = 128
+
+	gemmParallelThreshold = 1 << 15 // <--- products with fewer multiply adds than this run on the calling goroutine
+)
+
+/*
+* @notice gemm() computes c += a b for row major a [m, k], b [k, n] and c [m, n].
+* @dev Rows of the output are split into blocks of gemmBlockM, which are distributed across a bounded pool of workers.
+ */
+func gemm(a, b, c []float64, m, k, n int) {
+	numRowBlocks := (m + gemmBlockM - 1) / gemmBlockM
+	parallelForIfLarge(m*n*k, numRowBlocks, func(block int) {
+		gemmRows(a, b, c, k, n, block*gemmBlockM, min((block+1)*gemmBlockM, m))
+	})
+}
+
+/*
+* @notice gemmRows() computes rows [rowStart, rowEnd) of c += a b.
+* @dev The loops are ordered i-p-j so the innermost loop streams contiguously through a row of b and a row of c, instead
+* of striding down a column of b. The k and n dimensions are tiled so the active tile of b stays in cache while it is
+* reused by every row in the block.
+ */
+func gemmRows(a, b, c []float64, k, n int, rowStart, rowEnd int) {
+	for kk := 0; kk < k; kk += gemmBlockK {
+		kEnd := min(kk+gemmBlockK, k)
+		for jj := 0; jj < n; jj += gemmBlockN {
+			jEnd := min(jj+gemmBlockN, n)
+			for i := rowStart; i < rowEnd; i++ {
+				cRow := c[i*n+jj : i*n+jEnd]
+				for p := kk; p < kEnd; p++ {
+					aip := a[i*k+p]
+					bRow := b[p*n+jj : p*n+jEnd]
+					for j := range cRow {
+						cRow[j] += aip * bRow[j]
+					}
+				}
+			}
+		}
+	}
+}
+
+// parallelForIfLarge runs task over [0, numTasks) in parallel only when the amount of work justifies the overhead.
+func parallelForIfLarge(work int, numTasks int, task func(int)) {
+	if work < gemmParallelThreshold {
+		for i := 0; i < numTasks; i++ {
+			task(i)
+		}
+		return
 	}
+	parallelFor(numTasks, task)
 }
 
 //===================================================================================================================== Gradient Tracked Matrix Mulitplication
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package TG
+
+// Mmap_other.go falls back to reading files into memory on platforms without mmap
+
+import (
+	"io"
+	"os"
+)
+
+func mmapFile(file *os.File) ([]byte, error) {
+	return io.ReadAll(file)
+}
+
+func munmapFile(mapped []byte) error {
+	return nil
+}
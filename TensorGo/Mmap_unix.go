@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package TG
+
+// Mmap_unix.go memory maps files on platforms that support mmap
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps file privately, so writes to the mapping are copy on write and never reach the file
+func mmapFile(file *os.File) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return []byte{}, nil
+	}
+	return syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE)
+}
+
+func munmapFile(mapped []byte) error {
+	if len(mapped) == 0 {
+		return nil
+	}
+	return syscall.Munmap(mapped)
+}
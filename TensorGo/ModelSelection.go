@@ -0,0 +1,398 @@
+package TG
+
+/*
+* @notice ModelSelection.go contains utilities for splitting data into training and test sets: TrainTestSplit(), the KFold,
+* StratifiedKFold and TimeSeriesSplit cross-validators, and CrossValidate(), which fits and scores a model on every fold.
+* @dev Splits are index Tensors into the first axis of the data. Split.Take() slices data with them into batched Tensors.
+* @dev Random splits draw from a Generator, DefaultGenerator() when nil, so a seeded Generator reproduces the same splits.
+ */
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Split holds the indices of the training and test examples of one split, as 1D Tensors
+type Split struct {
+	Train *Tensor
+	Test  *Tensor
+}
+
+// Take() returns the training and test examples of X, which are the rows of its first axis, as batched Tensors.
+func (s Split) Take(X *Tensor) (*Tensor, *Tensor) {
+	train, test := X.IndexSelect(0, tensorInts(s.Train), false), X.IndexSelect(0, tensorInts(s.Test), false)
+	train.Batched, test.Batched = true, true
+	return train, test
+}
+
+// Splitter divides examples into splits. y may be nil for splitters that do not use labels.
+type Splitter interface {
+	Split(X *Tensor, y *Tensor) []Split
+}
+
+//============================================================================================================================== Helpers
+
+// intsTensor converts indices to a 1D Tensor
+func intsTensor(indices []int) *Tensor {
+	A := &Tensor{Shape: []int{len(indices)}, Data: make([]float64, len(indices))}
+	for i, index := range indices {
+		A.Data[i] = float64(index)
+	}
+	return A
+}
+
+// tensorInts converts a Tensor of indices to ints
+func tensorInts(A *Tensor) []int {
+	indices := make([]int, len(A.Data))
+	for i, value := range A.Data {
+		indices[i] = int(value)
+	}
+	return indices
+}
+
+// numExamples returns the length of the first axis of X
+func numExamples(X *Tensor, caller string) int {
+	if len(X.Shape) == 0 {
+		panic("Within " + caller + "(): X must have a first axis of examples")
+	}
+	return X.Shape[0]
+}
+
+// classesOf returns the class of each of n examples, numbered in order of first appearance, and the number of classes
+func classesOf(y *Tensor, n int, caller string) ([]int, int) {
+	if y == nil || len(y.Data) != n {
+		panic(fmt.Sprintf("Within %v(): stratifying needs one label per example for %v examples", caller, n))
+	}
+	codes := map[float64]int{}
+	classes := make([]int, n)
+	for i, label := range y.Data {
+		code, ok := codes[label]
+		if !ok {
+			code = len(codes)
+			codes[label] = code
+		}
+		classes[i] = code
+	}
+	return classes, len(codes)
+}
+
+// complement returns the indices in [0, n) that are not in excluded, in ascending order
+func complement(n int, excluded []int) []int {
+	skip := make([]bool, n)
+	for _, index := range excluded {
+		skip[index] = true
+	}
+	rest := make([]int, 0, n-len(excluded))
+	for i := 0; i < n; i++ {
+		if !skip[i] {
+			rest = append(rest, i)
+		}
+	}
+	return rest
+}
+
+// largestRemainder shares total between groups in proportion to counts, rounding so that the shares add up to total
+func largestRemainder(counts []int, total int) []int {
+	n := 0
+	for _, count := range counts {
+		n += count
+	}
+	shares := make([]int, len(counts))
+	remainders := make([]float64, len(counts))
+	assigned := 0
+	for k, count := range counts {
+		exact := float64(count) * float64(total) / float64(n)
+		shares[k] = int(math.Floor(exact))
+		remainders[k] = exact - float64(shares[k])
+		assigned += shares[k]
+	}
+	order := rangeInts(len(counts))
+	slices.SortStableFunc(order, func(a, b int) int { return compareAscending(remainders[b], remainders[a]) })
+	for _, k := range order[:total-assigned] {
+		shares[k]++
+	}
+	return shares
+}
+
+//============================================================================================================================== TrainTestSplit()
+
+/*
+* @notice TrainTestIndices() randomly divides n examples into a training and a test set. testSize is a fraction of the examples
+* when below 1, and a number of examples otherwise.
+* @dev When stratify holds one label per example, each label is divided in the same proportion, so both sets keep the class
+* balance of the data.
+ */
+func TrainTestIndices(n int, testSize float64, stratify *Tensor, g *Generator) Split {
+
+	numTest := int(testSize)
+	if testSize < 1 {
+		numTest = int(math.Ceil(testSize * float64(n)))
+	}
+	if testSize <= 0 || numTest >= n {
+		panic(fmt.Sprintf("Within TrainTestIndices(): testSize %v must leave examples in both sets of %v", testSize, n))
+	}
+	if g == nil {
+		g = DefaultGenerator()
+	}
+
+	// walk the examples in a random order, filling the test quota of each class first
+	var classes []int
+	quotas := []int{numTest}
+	if stratify != nil {
+		var numClasses int
+		classes, numClasses = classesOf(stratify, n, "TrainTestIndices")
+		counts := make([]int, numClasses)
+		for _, class := range classes {
+			counts[class]++
+		}
+		quotas = largestRemainder(counts, numTest)
+	}
+
+	train, test := make([]int, 0, n-numTest), make([]int, 0, numTest)
+	for _, index := range g.Permutation(n) {
+		class := 0
+		if classes != nil {
+			class = classes[index]
+		}
+		if quotas[class] > 0 {
+			quotas[class]--
+			test = append(test, index)
+		} else {
+			train = append(train, index)
+		}
+	}
+	return Split{Train: intsTensor(train), Test: intsTensor(test)}
+}
+
+/*
+* @notice TrainTestSplit() randomly divides the examples of X and their labels y into training and test sets, returned as
+* XTrain, XTest, yTrain, yTest. See TrainTestIndices() for testSize.
+* @dev With stratify, the split keeps the proportion of each label of y in both sets. y may be nil when not stratifying, in
+* which case yTrain and yTest are nil.
+ */
+func TrainTestSplit(X *Tensor, y *Tensor, testSize float64, stratify bool, g *Generator) (*Tensor, *Tensor, *Tensor, *Tensor) {
+
+	n := numExamples(X, "TrainTestSplit")
+	if y != nil && numExamples(y, "TrainTestSplit") != n {
+		panic("Within TrainTestSplit(): X and y must have the same number of examples")
+	}
+	var labels *Tensor
+	if stratify {
+		labels = y
+	}
+
+	split := TrainTestIndices(n, testSize, labels, g)
+	XTrain, XTest := split.Take(X)
+	if y == nil {
+		return XTrain, XTest, nil, nil
+	}
+	yTrain, yTest := split.Take(y)
+	return XTrain, XTest, yTrain, yTest
+}
+
+//============================================================================================================================== KFold
+
+// KFold divides examples into K consecutive folds, optionally after shuffling. Each fold is the test set of one split.
+type KFold struct {
+	K         int
+	Shuffle   bool
+	Generator *Generator // <--- the source of shuffling, DefaultGenerator() when nil
+}
+
+// NewKFold() creates a KFold with k folds.
+func NewKFold(k int, shuffle bool) *KFold {
+	if k < 2 {
+		panic("Within NewKFold(): k must be at least 2")
+	}
+	return &KFold{K: k, Shuffle: shuffle}
+}
+
+// Split() returns K splits. The first n % K folds have one more example than the rest.
+func (f *KFold) Split(X *Tensor, y *Tensor) []Split {
+
+	n := numExamples(X, "KFold.Split")
+	if f.K < 2 || f.K > n {
+		panic(fmt.Sprintf("Within KFold.Split(): K must be between 2 and the number of examples %v, got %v", n, f.K))
+	}
+	order := rangeInts(n)
+	if f.Shuffle {
+		g := f.Generator
+		if g == nil {
+			g = DefaultGenerator()
+		}
+		order = g.Permutation(n)
+	}
+
+	splits := make([]Split, f.K)
+	start := 0
+	for k := range splits {
+		size := n / f.K
+		if k < n%f.K {
+			size++
+		}
+		test := slices.Clone(order[start : start+size])
+		slices.Sort(test)
+		splits[k] = Split{Train: intsTensor(complement(n, test)), Test: intsTensor(test)}
+		start += size
+	}
+	return splits
+}
+
+//============================================================================================================================== StratifiedKFold
+
+// StratifiedKFold divides examples into K folds that each keep the proportion of each label of y
+type StratifiedKFold struct {
+	K         int
+	Shuffle   bool
+	Generator *Generator // <--- the source of shuffling, DefaultGenerator() when nil
+}
+
+// NewStratifiedKFold() creates a StratifiedKFold with k folds.
+func NewStratifiedKFold(k int, shuffle bool) *StratifiedKFold {
+	if k < 2 {
+		panic("Within NewStratifiedKFold(): k must be at least 2")
+	}
+	return &StratifiedKFold{K: k, Shuffle: shuffle}
+}
+
+// Split() returns K splits. The examples of each label are dealt to the folds in turn, so fold sizes differ by at most one.
+func (f *StratifiedKFold) Split(X *Tensor, y *Tensor) []Split {
+
+	n := numExamples(X, "StratifiedKFold.Split")
+	if f.K < 2 || f.K > n {
+		panic(fmt.Sprintf("Within StratifiedKFold.Split(): K must be between 2 and the number of examples %v, got %v", n, f.K))
+	}
+	classes, numClasses := classesOf(y, n, "StratifiedKFold.Split")
+
+	order := rangeInts(n)
+	if f.Shuffle {
+		g := f.Generator
+		if g == nil {
+			g = DefaultGenerator()
+		}
+		order = g.Permutation(n)
+	}
+
+	// group the examples by label, keeping their order, then deal them out continuing from fold to fold
+	byClass := make([][]int, numClasses)
+	for _, index := range order {
+		byClass[classes[index]] = append(byClass[classes[index]], index)
+	}
+	folds := make([][]int, f.K)
+	dealt := 0
+	for _, members := range byClass {
+		for _, index := range members {
+			folds[dealt%f.K] = append(folds[dealt%f.K], index)
+			dealt++
+		}
+	}
+
+	splits := make([]Split, f.K)
+	for k, test := range folds {
+		slices.Sort(test)
+		splits[k] = Split{Train: intsTensor(complement(n, test)), Test: intsTensor(test)}
+	}
+	return splits
+}
+
+//============================================================================================================================== TimeSeriesSplit
+
+/*
+* @notice TimeSeriesSplit divides ordered examples into K splits where each test set follows its training set in time, so a
+* model is never trained on the future.
+* @dev Test sets are consecutive blocks of TestSize examples at the end of the data, n / (K + 1) when TestSize is 0. Each
+* training set is every example before its test set, less Gap examples, and at most MaxTrainSize examples when it is set.
+ */
+type TimeSeriesSplit struct {
+	K            int
+	TestSize     int
+	Gap          int
+	MaxTrainSize int
+}
+
+// NewTimeSeriesSplit() creates a TimeSeriesSplit with k splits.
+func NewTimeSeriesSplit(k int) *TimeSeriesSplit {
+	if k < 1 {
+		panic("Within NewTimeSeriesSplit(): k must be at least 1")
+	}
+	return &TimeSeriesSplit{K: k}
+}
+
+func (s *TimeSeriesSplit) Split(X *Tensor, y *Tensor) []Split {
+
+	n := numExamples(X, "TimeSeriesSplit.Split")
+	testSize := s.TestSize
+	if testSize == 0 {
+		testSize = n / (s.K + 1)
+	}
+	firstTest := n - s.K*testSize
+	if testSize < 1 || firstTest-s.Gap < 1 {
+		panic(fmt.Sprintf("Within TimeSeriesSplit.Split(): %v examples are too few for %v splits with a gap of %v", n, s.K, s.Gap))
+	}
+
+	splits := make([]Split, s.K)
+	for k := range splits {
+		testStart := firstTest + k*testSize
+		trainEnd := testStart - s.Gap
+		trainStart := 0
+		if s.MaxTrainSize > 0 {
+			trainStart = max(0, trainEnd-s.MaxTrainSize)
+		}
+		train, test := make([]int, 0, trainEnd-trainStart), make([]int, 0, testSize)
+		for i := trainStart; i < trainEnd; i++ {
+			train = append(train, i)
+		}
+		for i := testStart; i < testStart+testSize; i++ {
+			test = append(test, i)
+		}
+		splits[k] = Split{Train: intsTensor(train), Test: intsTensor(test)}
+	}
+	return splits
+}
+
+//============================================================================================================================== CrossValidate()
+
+// CVResult holds the scores of each metric on each fold, and their mean and standard deviation across folds
+type CVResult struct {
+	Scores map[string][]float64
+	Mean   map[string]float64
+	Std    map[string]float64
+}
+
+/*
+* @notice CrossValidate() runs fitScore on the training and test examples of every split of X and y made by splitter, and
+* aggregates the metrics it returns.
+* @dev fitScore should fit a new model on XTrain and yTrain and return its metrics on XTest and yTest, such as
+* {"accuracy": 0.9}. Folds run one at a time, in order. Every fold must return the same metrics.
+ */
+func CrossValidate(X *Tensor, y *Tensor, splitter Splitter, fitScore func(XTrain, yTrain, XTest, yTest *Tensor) map[string]float64) *CVResult {
+
+	splits := splitter.Split(X, y)
+	result := &CVResult{Scores: map[string][]float64{}, Mean: map[string]float64{}, Std: map[string]float64{}}
+
+	for k, split := range splits {
+		XTrain, XTest := split.Take(X)
+		var yTrain, yTest *Tensor
+		if y != nil {
+			yTrain, yTest = split.Take(y)
+		}
+
+		scores := fitScore(XTrain, yTrain, XTest, yTest)
+		if k > 0 && len(scores) != len(result.Scores) {
+			panic(fmt.Sprintf("Within CrossValidate(): fold %v returned %v metrics, but earlier folds returned %v", k, len(scores), len(result.Scores)))
+		}
+		for metric, score := range scores {
+			if k > 0 && result.Scores[metric] == nil {
+				panic(fmt.Sprintf("Within CrossValidate(): fold %v returned metric %q, which earlier folds did not", k, metric))
+			}
+			result.Scores[metric] = append(result.Scores[metric], score)
+		}
+	}
+
+	for metric, scores := range result.Scores {
+		result.Mean[metric] = MeanReducer{}.Reduce(scores)
+		result.Std[metric] = math.Sqrt(VarReducer{}.Reduce(scores))
+	}
+	return result
+}
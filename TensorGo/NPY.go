@@ -0,0 +1,434 @@
+package TG
+
+/*
+* @notice NPY.go contains readers and writers for NumPy's .npy and .npz formats, for exchanging Tensors with Python.
+* @dev A .npy file is a magic string, a format version, a header holding a Python dict literal that describes the dtype, the
+* memory order and the shape, then the raw array data. Versions 1.0, 2.0 and 3.0 are read. Files are written as version 1.0
+* unless the header is too long for it.
+* @dev Reading accepts little and big endian data, C and Fortran order, and float16/32/64, signed and unsigned integer and bool
+* dtypes. Every dtype is converted to float64. Tensors are always written as little endian float64 in C order.
+* @dev A .npz file is a zip archive of .npy files, one per named array. Only the Data and Shape of a Tensor are stored, so
+* the Batched flag is not preserved.
+ */
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const npyMagic = "\x93NUMPY"
+
+// npyMaxHeaderLength bounds the header length read from a file, far above the length of any header numpy writes
+const npyMaxHeaderLength = 1 << 20
+
+// npyHeader is the parsed header of a .npy file
+type npyHeader struct {
+	order    binary.ByteOrder
+	kind     byte // <--- 'f' float, 'i' signed integer, 'u' unsigned integer, 'b' bool
+	itemSize int
+	fortran  bool
+	shape    []int
+}
+
+var (
+	npyDescr   = regexp.MustCompile(`'descr'\s*:\s*'([<>|=])([fiub])(\d+)'`)
+	npyFortran = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+//===================================================================================================================== .npy
+
+// parseNPYHeader parses the Python dict literal of a .npy header
+func parseNPYHeader(header string) (*npyHeader, error) {
+
+	descr := npyDescr.FindStringSubmatch(header)
+	fortran := npyFortran.FindStringSubmatch(header)
+	shape := npyShape.FindStringSubmatch(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return nil, fmt.Errorf("ReadNPY(): unsupported or malformed header %q", header)
+	}
+
+	h := &npyHeader{order: binary.LittleEndian, kind: descr[2][0], fortran: fortran[1] == "True", shape: []int{}}
+	if descr[1] == ">" {
+		h.order = binary.BigEndian
+	}
+	h.itemSize, _ = strconv.Atoi(descr[3])
+
+	supported := map[byte][]int{'f': {2, 4, 8}, 'i': {1, 2, 4, 8}, 'u': {1, 2, 4, 8}, 'b': {1}}
+	found := false
+	for _, size := range supported[h.kind] {
+		found = found || size == h.itemSize
+	}
+	if !found {
+		return nil, fmt.Errorf("ReadNPY(): unsupported dtype %q", descr[2]+descr[3])
+	}
+
+	for _, dim := range strings.Split(shape[1], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		n, err := strconv.Atoi(dim)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("ReadNPY(): invalid shape %q", shape[1])
+		}
+		h.shape = append(h.shape, n)
+	}
+	return h, nil
+}
+
+// decode converts a single element stored in b to a float64
+func (h *npyHeader) decode(b []byte) float64 {
+	switch h.kind {
+	case 'f':
+		switch h.itemSize {
+		case 2:
+			return float16ToFloat64(h.order.Uint16(b))
+		case 4:
+			return float64(math.Float32frombits(h.order.Uint32(b)))
+		}
+		return math.Float64frombits(h.order.Uint64(b))
+	case 'i':
+		switch h.itemSize {
+		case 1:
+			return float64(int8(b[0]))
+		case 2:
+			return float64(int16(h.order.Uint16(b)))
+		case 4:
+			return float64(int32(h.order.Uint32(b)))
+		}
+		return float64(int64(h.order.Uint64(b)))
+	}
+
+	// unsigned integers and bools
+	switch h.itemSize {
+	case 2:
+		return float64(h.order.Uint16(b))
+	case 4:
+		return float64(h.order.Uint32(b))
+	case 8:
+		return float64(h.order.Uint64(b))
+	}
+	return float64(b[0])
+}
+
+// float16ToFloat64 converts IEEE 754 half precision bits to a float64
+func float16ToFloat64(bits uint16) float64 {
+	sign := 1.0
+	if bits&0x8000 != 0 {
+		sign = -1
+	}
+	exponent, fraction := int(bits>>10)&0x1f, float64(bits&0x3ff)
+
+	switch exponent {
+	case 0:
+		return sign * math.Ldexp(fraction, -24) // <--- subnormal
+	case 0x1f:
+		if fraction != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * math.Ldexp(1+fraction/1024, exponent-15)
+}
+
+/*
+* @notice ReadNPY() reads a single array in .npy format from r and converts it to a float64 Tensor.
+* @dev The data is decoded in chunks as it is read, so r is never buffered in full, and the Tensor only grows as data arrives,
+* so a corrupt shape cannot exhaust memory. Fortran ordered data is reordered to the row major layout used by Tensors.
+* @dev r is not read past the end of the array.
+ */
+func ReadNPY(r io.Reader) (*Tensor, error) {
+
+	// magic string, then the format version
+	preamble := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(r, preamble); err != nil {
+		return nil, fmt.Errorf("ReadNPY(): reading preamble: %w", err)
+	}
+	if string(preamble[:len(npyMagic)]) != npyMagic {
+		return nil, fmt.Errorf("ReadNPY(): not a .npy file")
+	}
+
+	// version 1.0 stores the header length in 2 bytes, versions 2.0 and 3.0 in 4 bytes
+	var headerLength int
+	switch major := preamble[len(npyMagic)]; major {
+	case 1:
+		var n uint16
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("ReadNPY(): reading header length: %w", err)
+		}
+		headerLength = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("ReadNPY(): reading header length: %w", err)
+		}
+		headerLength = int(n)
+	default:
+		return nil, fmt.Errorf("ReadNPY(): unsupported format version %d", major)
+	}
+
+	if headerLength > npyMaxHeaderLength {
+		return nil, fmt.Errorf("ReadNPY(): header length %d is too large", headerLength)
+	}
+	header := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("ReadNPY(): reading header: %w", err)
+	}
+	h, err := parseNPYHeader(string(header))
+	if err != nil {
+		return nil, err
+	}
+
+	numElements, ok := checkedProduct(h.shape, h.itemSize)
+	if !ok {
+		return nil, fmt.Errorf("ReadNPY(): shape %v is too large", h.shape)
+	}
+
+	// decode the data a chunk at a time
+	A := &Tensor{Shape: h.shape, Data: make([]float64, 0, min(numElements, 4096))}
+	chunk := make([]byte, h.itemSize*4096)
+	for start := 0; start < numElements; start += 4096 {
+		n := min(4096, numElements-start)
+		if _, err := io.ReadFull(r, chunk[:n*h.itemSize]); err != nil {
+			return nil, fmt.Errorf("ReadNPY(): reading data: %w", err)
+		}
+		for k := 0; k < n; k++ {
+			A.Data = append(A.Data, h.decode(chunk[k*h.itemSize:]))
+		}
+	}
+
+	if h.fortran && len(h.shape) > 1 {
+		A.Data = fortranToRowMajor(A.Data, h.shape)
+	}
+	return A, nil
+}
+
+// fortranToRowMajor reorders column major data of the given shape into row major order
+func fortranToRowMajor(data []float64, shape []int) []float64 {
+
+	// in column major order the first axis varies fastest
+	colStrides := make([]int, len(shape))
+	stride := 1
+	for i := range shape {
+		colStrides[i] = stride
+		stride *= shape[i]
+	}
+
+	out := make([]float64, len(data))
+	index := make([]int, len(shape))
+	for i := range out {
+		offset := 0
+		for d, k := range index {
+			offset += k * colStrides[d]
+		}
+		out[i] = data[offset]
+
+		// advance the row major multi-index
+		for d := len(index) - 1; d >= 0; d-- {
+			if index[d]++; index[d] < shape[d] {
+				break
+			}
+			index[d] = 0
+		}
+	}
+	return out
+}
+
+// npyHeaderBytes builds the preamble and header for a little endian float64 C ordered array, padded to a multiple of 64 bytes
+func npyHeaderBytes(shape []int) []byte {
+
+	dims := make([]string, len(shape))
+	for i, dim := range shape {
+		dims[i] = strconv.Itoa(dim)
+	}
+	shapeLiteral := "(" + strings.Join(dims, ", ") + ")"
+	if len(shape) == 1 {
+		shapeLiteral = "(" + dims[0] + ",)" // <--- a 1-tuple needs a trailing comma in Python
+	}
+	dict := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': %s, }", shapeLiteral)
+
+	// version 1.0 is used unless the header does not fit in its 2 byte length field
+	prefixLength, version := len(npyMagic)+2+2, byte(1)
+	if len(dict)+1+prefixLength+64 > math.MaxUint16 {
+		prefixLength, version = len(npyMagic)+2+4, 2
+	}
+	padding := (64 - (prefixLength+len(dict)+1)%64) % 64
+	header := dict + strings.Repeat(" ", padding) + "\n"
+
+	var b bytes.Buffer
+	b.WriteString(npyMagic)
+	b.Write([]byte{version, 0})
+	if version == 1 {
+		binary.Write(&b, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(&b, binary.LittleEndian, uint32(len(header)))
+	}
+	b.WriteString(header)
+	return b.Bytes()
+}
+
+// WriteNPY() writes A to w in .npy format as a little endian float64 array in C order. Nothing is written when A does not
+// hold one element for every position of its Shape.
+func WriteNPY(w io.Writer, A *Tensor) error {
+
+	values, err := storedValues(A, "WriteNPY")
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	if _, err := writer.Write(npyHeaderBytes(A.Shape)); err != nil {
+		return err
+	}
+
+	var element [8]byte
+	for _, value := range values {
+		binary.LittleEndian.PutUint64(element[:], math.Float64bits(value))
+		if _, err := writer.Write(element[:]); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// LoadNPY() loads a .npy file into a Tensor.
+func LoadNPY(fileName string) *Tensor {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	A, err := ReadNPY(file)
+	if err != nil {
+		panic(err)
+	}
+	return A
+}
+
+// SaveNPY() saves a Tensor to a .npy file.
+func SaveNPY(A *Tensor, fileName string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if err := WriteNPY(file, A); err != nil {
+		panic(err)
+	}
+}
+
+//===================================================================================================================== .npz
+
+/*
+* @notice ReadNPZ() reads every array in a .npz archive, keyed by array name (the file name without the .npy extension).
+* @dev A zip archive can only be read with random access. When r is an io.ReaderAt and io.Seeker, such as an *os.File, it
+* is read in place. Otherwise r is read into memory first.
+ */
+func ReadNPZ(r io.Reader) (map[string]*Tensor, error) {
+
+	var readerAt io.ReaderAt
+	var size int64
+	if seekable, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		var err error
+		if size, err = seekable.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
+		}
+		readerAt = seekable
+	} else {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		readerAt, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, fmt.Errorf("ReadNPZ(): %w", err)
+	}
+
+	arrays := map[string]*Tensor{}
+	for _, file := range archive.File {
+		contents, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("ReadNPZ(): %w", err)
+		}
+		A, err := ReadNPY(contents)
+		contents.Close()
+		if err != nil {
+			return nil, fmt.Errorf("ReadNPZ(): array %q: %w", file.Name, err)
+		}
+		arrays[strings.TrimSuffix(file.Name, ".npy")] = A
+	}
+	return arrays, nil
+}
+
+// WriteNPZ() writes named arrays to w as a .npz archive, in order of name. When compress is true, arrays are deflated as with numpy.savez_compressed().
+func WriteNPZ(w io.Writer, arrays map[string]*Tensor, compress bool) error {
+
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	method := zip.Store
+	if compress {
+		method = zip.Deflate
+	}
+
+	archive := zip.NewWriter(w)
+	for _, name := range names {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: method})
+		if err != nil {
+			return err
+		}
+		if err := WriteNPY(entry, arrays[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// LoadNPZ() loads every array in a .npz file, keyed by name.
+func LoadNPZ(fileName string) map[string]*Tensor {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	arrays, err := ReadNPZ(file)
+	if err != nil {
+		panic(err)
+	}
+	return arrays
+}
+
+// SaveNPZ() saves named arrays to a .npz file.
+func SaveNPZ(arrays map[string]*Tensor, fileName string, compress bool) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if err := WriteNPZ(file, arrays, compress); err != nil {
+		panic(err)
+	}
+}
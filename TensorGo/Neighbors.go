@@ -0,0 +1,674 @@
+package TG
+
+/*
+* @notice Neighbors.go contains nearest neighbor search over the rows of a [samples, features] Tensor, and the k nearest
+* neighbors classifier and regressor built on it.
+* @dev NearestNeighbors answers k nearest and radius queries by brute force, a KD-tree or a ball tree, under the euclidean,
+* manhattan or cosine distance. Queries run in parallel over blocks of rows. The trees search cosine distances as euclidean
+* distances between unit vectors, since 1 - cos(x, y) = ||x - y||² / 2 when x and y have unit length. Neighbors are ordered
+* by distance and then by index. Every algorithm computes euclidean and manhattan distances from the differences of the rows,
+* so they return the same neighbors. Cosine distances agree up to rounding.
+ */
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// queryBlock is the number of query rows handled by each parallel task
+const queryBlock = 64
+
+//============================================================================================================================== MetricDistances()
+
+// DistanceMetric chooses how the distance between two samples is measured
+type DistanceMetric int
+
+const (
+	MetricEuclidean DistanceMetric = iota
+	MetricManhattan                // <--- the sum of absolute differences
+	MetricCosine                   // <--- 1 - the cosine similarity
+)
+
+// unitRows returns X with every nonzero row scaled to unit length
+func unitRows(X *Tensor) *Tensor {
+	f := X.Shape[1]
+	U := &Tensor{Shape: []int{X.Shape[0], f}, Data: make([]float64, len(X.Data))}
+	for i := 0; i < X.Shape[0]; i++ {
+		row := X.Data[i*f : (i+1)*f]
+		norm := nonZeroScale(normSlice(row))
+		for j, value := range row {
+			U.Data[i*f+j] = value / norm
+		}
+	}
+	return U
+}
+
+type MetricDistancesOp struct{ metric DistanceMetric }
+
+func (op MetricDistancesOp) Execute(tensors ...*Tensor) *Tensor {
+
+	X, Y := tensors[0], tensors[1]
+
+	if len(X.Shape) != 2 || len(Y.Shape) != 2 || X.Shape[1] != Y.Shape[1] {
+		panic("Within MetricDistances(): X must be [m, features] and Y must be [n, features]")
+	}
+
+	switch op.metric {
+	case MetricEuclidean:
+		return PairwiseDistancesOp{}.Execute(X, Y)
+	case MetricCosine:
+		D := MatMulTransposeB(unitRows(X), unitRows(Y), false)
+		for i, similarity := range D.Data {
+			D.Data[i] = math.Min(2, math.Max(0, 1-similarity))
+		}
+		return D
+	case MetricManhattan:
+		return rowDistances(X, Y, manhattan)
+	}
+	panic(fmt.Sprintf("Within MetricDistances(): unknown metric %v", op.metric))
+}
+
+/*
+* @notice MetricDistances() computes the distance under metric between every row of X and every row of Y, returning an [m, n]
+* Tensor for X [m, features] and Y [n, features]. There is optional batching.
+* @dev Euclidean distances come from PairwiseDistances(), and cosine distances from one matrix product of the rows scaled to
+* unit length. The cosine distance of a zero row is 1.
+ */
+func MetricDistances(X *Tensor, Y *Tensor, metric DistanceMetric, batching bool) *Tensor {
+
+	op := MetricDistancesOp{metric: metric}
+
+	if batching {
+		return BatchedOperation(op, X, Y)
+	}
+	return op.Execute(X, Y)
+}
+
+// rowDistances computes distance between every row of X and every row of Y, in parallel over the rows of X
+func rowDistances(X *Tensor, Y *Tensor, distance func(x, y []float64) float64) *Tensor {
+	m, n, k := X.Shape[0], Y.Shape[0], X.Shape[1]
+	D := &Tensor{Shape: []int{m, n}, Data: make([]float64, m*n)}
+	parallelForIfLarge(m*n*k, m, func(i int) {
+		for j := 0; j < n; j++ {
+			D.Data[i*n+j] = distance(X.Data[i*k:(i+1)*k], Y.Data[j*k:(j+1)*k])
+		}
+	})
+	return D
+}
+
+// manhattan returns the sum of the absolute differences of x and y
+func manhattan(x, y []float64) float64 {
+	sum := 0.0
+	for i := range x {
+		sum += math.Abs(x[i] - y[i])
+	}
+	return sum
+}
+
+// euclidean returns the euclidean distance between x and y
+func euclidean(x, y []float64) float64 {
+	sum := 0.0
+	for i := range x {
+		sum += (x[i] - y[i]) * (x[i] - y[i])
+	}
+	return math.Sqrt(sum)
+}
+
+//============================================================================================================================== Neighbor Heap
+
+// neighborHeap keeps the k nearest candidates seen so far in a max heap, with the farthest at the root
+type neighborHeap struct {
+	k         int
+	distances []float64
+	indices   []int
+}
+
+func newNeighborHeap(k int) *neighborHeap {
+	return &neighborHeap{k: k, distances: make([]float64, 0, k), indices: make([]int, 0, k)}
+}
+
+// farther reports whether entry a is farther than entry b, breaking ties by index
+func (h *neighborHeap) farther(a, b int) bool {
+	return h.distances[a] > h.distances[b] || (h.distances[a] == h.distances[b] && h.indices[a] > h.indices[b])
+}
+
+func (h *neighborHeap) swap(a, b int) {
+	h.distances[a], h.distances[b] = h.distances[b], h.distances[a]
+	h.indices[a], h.indices[b] = h.indices[b], h.indices[a]
+}
+
+// bound returns the distance a candidate must not exceed to enter the heap
+func (h *neighborHeap) bound() float64 {
+	if len(h.indices) < h.k {
+		return math.Inf(1)
+	}
+	return h.distances[0]
+}
+
+// push offers the candidate index at distance to the heap
+func (h *neighborHeap) push(distance float64, index int) {
+	if len(h.indices) < h.k {
+		h.distances, h.indices = append(h.distances, distance), append(h.indices, index)
+		for child := len(h.indices) - 1; child > 0 && h.farther(child, (child-1)/2); child = (child - 1) / 2 {
+			h.swap(child, (child-1)/2)
+		}
+		return
+	}
+	if distance > h.distances[0] || (distance == h.distances[0] && index > h.indices[0]) {
+		return
+	}
+	h.distances[0], h.indices[0] = distance, index
+	for parent := 0; ; {
+		largest := parent
+		for _, child := range []int{2*parent + 1, 2*parent + 2} {
+			if child < len(h.indices) && h.farther(child, largest) {
+				largest = child
+			}
+		}
+		if largest == parent {
+			return
+		}
+		h.swap(parent, largest)
+		parent = largest
+	}
+}
+
+// sortNeighbors orders distances and their indices together, by distance and then by index
+func sortNeighbors(distances []float64, indices []int) {
+	order := rangeInts(len(indices))
+	slices.SortFunc(order, func(a, b int) int {
+		if c := compareAscending(distances[a], distances[b]); c != 0 {
+			return c
+		}
+		return indices[a] - indices[b]
+	})
+	sortedDistances, sortedIndices := make([]float64, len(order)), make([]int, len(order))
+	for k, o := range order {
+		sortedDistances[k], sortedIndices[k] = distances[o], indices[o]
+	}
+	copy(distances, sortedDistances)
+	copy(indices, sortedIndices)
+}
+
+//============================================================================================================================== Space Partitioning Trees
+
+// treeNode holds the points order[start:end] of a spaceTree, bounded by a box (KD-tree) or a ball (ball tree)
+type treeNode struct {
+	start, end   int
+	left, right  int // <--- child nodes, -1 for a leaf
+	lower, upper []float64
+	center       []float64
+	radius       float64
+}
+
+/*
+* @notice spaceTree is a KD-tree or ball tree over the rows of a row major [n, f] matrix.
+* @dev Each node is split at the median of the coordinate of greatest spread, until it holds at most leafSize points. A
+* KD-tree node is bounded by the box of its points, and a ball tree node by the ball about their centroid. Searches descend
+* into the nearer child first and skip any node whose bound is farther than the current candidates.
+ */
+type spaceTree struct {
+	points    []float64
+	f         int
+	manhattan bool
+	ball      bool
+	leafSize  int
+	order     []int
+	nodes     []treeNode
+}
+
+func buildTree(X *Tensor, manhattan bool, ball bool, leafSize int) *spaceTree {
+	t := &spaceTree{points: X.Data, f: X.Shape[1], manhattan: manhattan, ball: ball, leafSize: max(leafSize, 1)}
+	t.order = rangeInts(X.Shape[0])
+	t.build(0, X.Shape[0])
+	return t
+}
+
+func (t *spaceTree) point(i int) []float64 {
+	return t.points[i*t.f : (i+1)*t.f]
+}
+
+func (t *spaceTree) distance(x, y []float64) float64 {
+	if t.manhattan {
+		return manhattan(x, y)
+	}
+	return euclidean(x, y)
+}
+
+// build creates the node holding order[start:end] and its descendants, returning its position in nodes
+func (t *spaceTree) build(start, end int) int {
+
+	lower, upper := slices.Clone(t.point(t.order[start])), slices.Clone(t.point(t.order[start]))
+	for _, i := range t.order[start:end] {
+		for j, value := range t.point(i) {
+			lower[j], upper[j] = math.Min(lower[j], value), math.Max(upper[j], value)
+		}
+	}
+
+	node := treeNode{start: start, end: end, left: -1, right: -1}
+	if t.ball {
+		node.center = make([]float64, t.f)
+		for _, i := range t.order[start:end] {
+			axpy(1/float64(end-start), t.point(i), node.center)
+		}
+		for _, i := range t.order[start:end] {
+			node.radius = math.Max(node.radius, t.distance(node.center, t.point(i)))
+		}
+	} else {
+		node.lower, node.upper = lower, upper
+	}
+	id := len(t.nodes)
+	t.nodes = append(t.nodes, node)
+
+	spread, dim := 0.0, 0
+	for j := range lower {
+		if upper[j]-lower[j] > spread {
+			spread, dim = upper[j]-lower[j], j
+		}
+	}
+	if end-start <= t.leafSize || spread == 0 { // <--- identical points cannot be split
+		return id
+	}
+
+	slices.SortFunc(t.order[start:end], func(a, b int) int {
+		if c := compareAscending(t.points[a*t.f+dim], t.points[b*t.f+dim]); c != 0 {
+			return c
+		}
+		return a - b
+	})
+	mid := (start + end) / 2
+	left := t.build(start, mid)
+	right := t.build(mid, end)
+	t.nodes[id].left, t.nodes[id].right = left, right
+	return id
+}
+
+// minDistance returns a lower bound on the distance from q to the points of node id
+func (t *spaceTree) minDistance(id int, q []float64) float64 {
+	node := &t.nodes[id]
+	if t.ball {
+		return math.Max(0, t.distance(q, node.center)-node.radius)
+	}
+	sum := 0.0
+	for j, value := range q {
+		gap := math.Max(0, math.Max(node.lower[j]-value, value-node.upper[j]))
+		if t.manhattan {
+			sum += gap
+		} else {
+			sum += gap * gap
+		}
+	}
+	if t.manhattan {
+		return sum
+	}
+	return math.Sqrt(sum)
+}
+
+// nearest offers the points of node id that could be among the nearest to q to h
+func (t *spaceTree) nearest(id int, q []float64, h *neighborHeap) {
+	node := &t.nodes[id]
+	if node.left < 0 {
+		for _, i := range t.order[node.start:node.end] {
+			h.push(t.distance(q, t.point(i)), i)
+		}
+		return
+	}
+	near, far := node.left, node.right
+	nearDistance, farDistance := t.minDistance(near, q), t.minDistance(far, q)
+	if farDistance < nearDistance {
+		near, far, nearDistance, farDistance = far, near, farDistance, nearDistance
+	}
+	if nearDistance <= h.bound() {
+		t.nearest(near, q, h)
+	}
+	if farDistance <= h.bound() {
+		t.nearest(far, q, h)
+	}
+}
+
+// within passes every point of node id within radius of q to found
+func (t *spaceTree) within(id int, q []float64, radius float64, found func(distance float64, index int)) {
+	if t.minDistance(id, q) > radius {
+		return
+	}
+	node := &t.nodes[id]
+	if node.left < 0 {
+		for _, i := range t.order[node.start:node.end] {
+			if distance := t.distance(q, t.point(i)); distance <= radius {
+				found(distance, i)
+			}
+		}
+		return
+	}
+	t.within(node.left, q, radius, found)
+	t.within(node.right, q, radius, found)
+}
+
+//============================================================================================================================== NearestNeighbors
+
+// NeighborsAlgorithm chooses how NearestNeighbors searches the fitted samples
+type NeighborsAlgorithm int
+
+const (
+	AlgorithmAuto  NeighborsAlgorithm = iota // <--- a KD-tree for up to 16 features, brute force beyond
+	AlgorithmBrute                           // <--- distances to every sample, one block of queries at a time
+	AlgorithmKDTree
+	AlgorithmBallTree
+)
+
+/*
+* @notice NearestNeighbors indexes the rows of a [samples, features] Tensor for k nearest and radius queries.
+* @dev Brute force computes a block of the query to sample distances and scans it, which suits many features. The trees
+* avoid most distance computations when there are few features. Brute force computes euclidean distances from the differences
+* of the rows, as the trees do, rather than with PairwiseDistances(), so every algorithm gives the same results. Under
+* MetricCosine they agree up to rounding, and the trees need nonzero rows, which have a direction.
+ */
+type NearestNeighbors struct {
+	Metric    DistanceMetric
+	Algorithm NeighborsAlgorithm
+	LeafSize  int // <--- the most samples in a leaf of a tree
+
+	Data *Tensor // <--- [samples, features], the fitted samples
+	tree *spaceTree
+}
+
+// NewNearestNeighbors() creates a NearestNeighbors under metric, choosing the algorithm automatically.
+func NewNearestNeighbors(metric DistanceMetric) *NearestNeighbors {
+	return &NearestNeighbors{Metric: metric, LeafSize: 30}
+}
+
+func (m *NearestNeighbors) Fit(X *Tensor) {
+	checkFeatures(X, -1, "NearestNeighbors.Fit")
+	if X.Shape[0] == 0 {
+		panic("Within NearestNeighbors.Fit(): X must have at least one sample")
+	}
+	if m.Metric < MetricEuclidean || m.Metric > MetricCosine {
+		panic(fmt.Sprintf("Within NearestNeighbors.Fit(): unknown metric %v", m.Metric))
+	}
+	m.Data, m.tree = X, nil
+
+	algorithm := m.Algorithm
+	if algorithm == AlgorithmAuto {
+		algorithm = AlgorithmBrute
+		if X.Shape[1] <= 16 {
+			algorithm = AlgorithmKDTree
+		}
+	}
+	if algorithm == AlgorithmBrute {
+		return
+	}
+	points := X
+	if m.Metric == MetricCosine {
+		points = unitRows(X)
+	}
+	m.tree = buildTree(points, m.Metric == MetricManhattan, algorithm == AlgorithmBallTree, m.LeafSize)
+}
+
+// checkQuery panics unless the index is fit and X has as many features as the fitted samples
+func (m *NearestNeighbors) checkQuery(X *Tensor, caller string) {
+	if m.Data == nil {
+		panic("Within " + caller + "(): the index must be fit first")
+	}
+	checkFeatures(X, m.Data.Shape[1], caller)
+}
+
+/*
+* @notice search calls visit for every row of the queries X, in parallel over blocks of rows. visit receives the distances
+* from the row to every fitted sample when searching by brute force, or otherwise the row as the tree searches it.
+ */
+func (m *NearestNeighbors) search(X *Tensor, visit func(i int, distances []float64, query []float64)) {
+	q, n, f := X.Shape[0], m.Data.Shape[0], X.Shape[1]
+	parallelFor((q+queryBlock-1)/queryBlock, func(b int) {
+		start, end := b*queryBlock, min((b+1)*queryBlock, q)
+		block := rowsOf(X, start, end)
+		if m.tree == nil {
+			var D *Tensor
+			if m.Metric == MetricEuclidean {
+				D = rowDistances(block, m.Data, euclidean) // <--- exactly the distances the trees compute
+			} else {
+				D = MetricDistances(block, m.Data, m.Metric, false)
+			}
+			for i := start; i < end; i++ {
+				visit(i, D.Data[(i-start)*n:(i-start+1)*n], nil)
+			}
+			return
+		}
+		if m.Metric == MetricCosine {
+			block = unitRows(block)
+		}
+		for i := start; i < end; i++ {
+			visit(i, nil, block.Data[(i-start)*f:(i-start+1)*f])
+		}
+	})
+}
+
+// fromTree converts a distance searched by the tree to a distance under the metric
+func (m *NearestNeighbors) fromTree(distance float64) float64 {
+	if m.Metric == MetricCosine {
+		return math.Min(2, distance*distance/2)
+	}
+	return distance
+}
+
+/*
+* @notice KNeighbors() returns the distances from each row of X [queries, features] to its k nearest fitted samples, and
+* their indices, both as [queries, k] ordered from the nearest.
+ */
+func (m *NearestNeighbors) KNeighbors(X *Tensor, k int) (*Tensor, *Tensor) {
+	m.checkQuery(X, "NearestNeighbors.KNeighbors")
+	if k < 1 || k > m.Data.Shape[0] {
+		panic(fmt.Sprintf("Within NearestNeighbors.KNeighbors(): k must be between 1 and the %v fitted samples, got %v", m.Data.Shape[0], k))
+	}
+
+	q := X.Shape[0]
+	distances := &Tensor{Shape: []int{q, k}, Data: make([]float64, q*k), Batched: X.Batched}
+	indices := &Tensor{Shape: []int{q, k}, Data: make([]float64, q*k), Batched: X.Batched}
+	m.search(X, func(i int, row []float64, query []float64) {
+		h := newNeighborHeap(k)
+		if row != nil {
+			for j, distance := range row {
+				h.push(distance, j)
+			}
+		} else {
+			m.tree.nearest(0, query, h)
+			for j := range h.distances {
+				h.distances[j] = m.fromTree(h.distances[j])
+			}
+		}
+		sortNeighbors(h.distances, h.indices)
+		copy(distances.Data[i*k:(i+1)*k], h.distances)
+		for j, index := range h.indices {
+			indices.Data[i*k+j] = float64(index)
+		}
+	})
+	return distances, indices
+}
+
+/*
+* @notice RadiusNeighbors() returns, for each row of X [queries, features], the distances to the fitted samples within radius
+* of it and their indices, ordered from the nearest.
+ */
+func (m *NearestNeighbors) RadiusNeighbors(X *Tensor, radius float64) ([][]float64, [][]int) {
+	m.checkQuery(X, "NearestNeighbors.RadiusNeighbors")
+	if radius < 0 {
+		panic("Within NearestNeighbors.RadiusNeighbors(): radius cannot be negative")
+	}
+
+	searchRadius := radius
+	if m.Metric == MetricCosine {
+		searchRadius = math.Sqrt(2 * radius) // <--- the inverse of fromTree()
+	}
+	distances, indices := make([][]float64, X.Shape[0]), make([][]int, X.Shape[0])
+	m.search(X, func(i int, row []float64, query []float64) {
+		found := func(distance float64, index int) {
+			distances[i], indices[i] = append(distances[i], distance), append(indices[i], index)
+		}
+		if row != nil {
+			for j, distance := range row {
+				if distance <= radius {
+					found(distance, j)
+				}
+			}
+		} else {
+			m.tree.within(0, query, searchRadius, func(distance float64, index int) {
+				if distance = m.fromTree(distance); distance <= radius {
+					found(distance, index)
+				}
+			})
+		}
+		sortNeighbors(distances[i], indices[i])
+	})
+	return distances, indices
+}
+
+//============================================================================================================================== KNNClassifier and KNNRegressor
+
+// NeighborWeights chooses how much each of the k nearest neighbors counts towards a prediction
+type NeighborWeights int
+
+const (
+	WeightsUniform  NeighborWeights = iota
+	WeightsDistance                 // <--- 1 / distance, where neighbors at distance 0 outweigh all others
+)
+
+// neighborWeights returns the weight of each neighbor at the given distances
+func neighborWeights(distances []float64, weights NeighborWeights) []float64 {
+	w := make([]float64, len(distances))
+	exact := weights == WeightsDistance && slices.Contains(distances, 0)
+	for j, distance := range distances {
+		switch {
+		case weights == WeightsUniform:
+			w[j] = 1
+		case exact && distance == 0:
+			w[j] = 1
+		case !exact:
+			w[j] = 1 / distance
+		}
+	}
+	return w
+}
+
+// newIndex returns a NearestNeighbors fit to X with the given settings, checking that k neighbors can be found
+func newIndex(X *Tensor, k int, metric DistanceMetric, algorithm NeighborsAlgorithm, leafSize int, caller string) *NearestNeighbors {
+	checkFeatures(X, -1, caller)
+	if k < 1 || k > X.Shape[0] {
+		panic(fmt.Sprintf("Within %v(): K must be between 1 and the %v samples, got %v", caller, X.Shape[0], k))
+	}
+	index := &NearestNeighbors{Metric: metric, Algorithm: algorithm, LeafSize: leafSize}
+	index.Fit(X)
+	return index
+}
+
+/*
+* @notice KNNClassifier predicts the class of a sample by the vote of its K nearest training samples, each weighted by Weights.
+* PredictProba() returns the share of the vote won by each class, as [samples, classes] in the order of Classes.
+ */
+type KNNClassifier struct {
+	K         int
+	Weights   NeighborWeights
+	Metric    DistanceMetric
+	Algorithm NeighborsAlgorithm
+	LeafSize  int
+
+	Classes []float64         // <--- the sorted labels seen by Fit()
+	Index   *NearestNeighbors // <--- the training samples
+	Labels  []int             // <--- the position in Classes of the label of each training sample
+}
+
+// NewKNNClassifier() creates a KNNClassifier voting with k neighbors under the euclidean distance.
+func NewKNNClassifier(k int) *KNNClassifier {
+	return &KNNClassifier{K: k, LeafSize: 30}
+}
+
+func (m *KNNClassifier) Fit(X *Tensor, y *Tensor) {
+	m.Index = newIndex(X, m.K, m.Metric, m.Algorithm, m.LeafSize, "KNNClassifier.Fit")
+	if len(y.Data) != X.Shape[0] {
+		panic(fmt.Sprintf("Within KNNClassifier.Fit(): y must have one label per sample, got shape %v for %v samples", y.Shape, X.Shape[0]))
+	}
+	encoder := &LabelEncoder{}
+	codes := FitTransform(encoder, y).Data
+	m.Classes, m.Labels = encoder.Classes, make([]int, len(codes))
+	for i, code := range codes {
+		m.Labels[i] = int(code)
+	}
+}
+
+func (m *KNNClassifier) PredictProba(X *Tensor) *Tensor {
+	if m.Index == nil {
+		panic("Within KNNClassifier.PredictProba(): the model must be fit first")
+	}
+	D, I := m.Index.KNeighbors(X, m.K)
+	n, C := X.Shape[0], len(m.Classes)
+	P := &Tensor{Shape: []int{n, C}, Data: make([]float64, n*C), Batched: X.Batched}
+	for i := 0; i < n; i++ {
+		w := neighborWeights(D.Data[i*m.K:(i+1)*m.K], m.Weights)
+		total := SumReducer{}.Reduce(w)
+		for j, weight := range w {
+			P.Data[i*C+m.Labels[int(I.Data[i*m.K+j])]] += weight / total
+		}
+	}
+	return P
+}
+
+// Predict() returns the class winning the vote of each sample, as [samples].
+func (m *KNNClassifier) Predict(X *Tensor) *Tensor {
+	return mostProbable(m.PredictProba(X), m.Classes)
+}
+
+// Score() returns the AccuracyScore() of the predictions for X.
+func (m *KNNClassifier) Score(X *Tensor, y *Tensor) float64 {
+	return AccuracyScore(y, m.Predict(X))
+}
+
+/*
+* @notice KNNRegressor predicts the target of a sample as the mean of the targets of its K nearest training samples, each
+* weighted by Weights. y may be [samples] or [samples, targets].
+ */
+type KNNRegressor struct {
+	K         int
+	Weights   NeighborWeights
+	Metric    DistanceMetric
+	Algorithm NeighborsAlgorithm
+	LeafSize  int
+
+	Index   *NearestNeighbors // <--- the training samples
+	Targets *Tensor           // <--- the training targets
+}
+
+// NewKNNRegressor() creates a KNNRegressor averaging k neighbors under the euclidean distance.
+func NewKNNRegressor(k int) *KNNRegressor {
+	return &KNNRegressor{K: k, LeafSize: 30}
+}
+
+func (m *KNNRegressor) Fit(X *Tensor, y *Tensor) {
+	m.Index = newIndex(X, m.K, m.Metric, m.Algorithm, m.LeafSize, "KNNRegressor.Fit")
+	targetMatrix(y, X.Shape[0], "KNNRegressor.Fit")
+	m.Targets = y.Copy()
+}
+
+func (m *KNNRegressor) Predict(X *Tensor) *Tensor {
+	if m.Index == nil {
+		panic("Within KNNRegressor.Predict(): the model must be fit first")
+	}
+	D, I := m.Index.KNeighbors(X, m.K)
+	n, t := X.Shape[0], len(m.Targets.Data)/m.Targets.Shape[0]
+	Y := &Tensor{Shape: []int{n, t}, Data: make([]float64, n*t), Batched: X.Batched}
+	for i := 0; i < n; i++ {
+		w := neighborWeights(D.Data[i*m.K:(i+1)*m.K], m.Weights)
+		total := SumReducer{}.Reduce(w)
+		for j, weight := range w {
+			neighbor := int(I.Data[i*m.K+j])
+			axpy(weight/total, m.Targets.Data[neighbor*t:(neighbor+1)*t], Y.Data[i*t:(i+1)*t])
+		}
+	}
+	if len(m.Targets.Shape) == 1 {
+		Y.Shape = []int{n}
+	}
+	return Y
+}
+
+// Score() returns the R2Score() of the predictions for X.
+func (m *KNNRegressor) Score(X *Tensor, y *Tensor) float64 {
+	return R2Score(y, m.Predict(X))
+}
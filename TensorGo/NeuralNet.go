@@ -2,7 +2,6 @@ package TG
 
 import (
 	"fmt"
-	"math/rand"
 )
 
 /*
@@ -33,18 +32,27 @@ type Layer struct {
 * @dev The MLP is constructed as a linked list of Layer structs.
 * @dev The activation will be applied to each layer in the list.
 * @param layerNodeslice is used to specify the number of neurons in each layer.
+* @dev The weights and biases are drawn from the default Generator, see SetSeed() and MLPWithGenerator().
  */
 func MLP(inputFeatures int, layerNodes []int, activations []string) *Layer {
+	return MLPWithGenerator(inputFeatures, layerNodes, activations, nil)
+}
+
+/*
+* @notice MLPWithGenerator() is MLP() with the weights and biases of every layer drawn from random, so that each model can be
+* seeded on its own. A nil Generator uses the default one.
+ */
+func MLPWithGenerator(inputFeatures int, layerNodes []int, activations []string, random *Generator) *Layer {
 
 	// create the first layer
-	var layer *Layer = Linear(inputFeatures, layerNodes[0], activations[0], nil)
+	var layer *Layer = LinearWithGenerator(inputFeatures, layerNodes[0], activations[0], nil, random)
 
 	// save the first layer pointer to return
 	var firstLayer *Layer = layer
 
 	// create and link together the rest of the layers
 	for i := 1; i < len(layerNodes); i++ {
-		layer = Linear(layerNodes[i-1], layerNodes[i], activations[i], layer)
+		layer = LinearWithGenerator(layerNodes[i-1], layerNodes[i], activations[i], layer, random)
 	}
 
 	return firstLayer
@@ -55,8 +63,16 @@ func MLP(inputFeatures int, layerNodes []int, activations []string) *Layer {
 * @dev if there is a previous node input as argument, the previous node is connected behind it.
 * @param inputFeatures: The number of input features moving into the Layer
 * @param layerNodes: The number of neurons in the Layer
+* @dev The weights and biases are drawn from the default Generator, see SetSeed() and LinearWithGenerator().
  */
 func Linear(inputFeatures int, layerNeurons int, activation string, prev *Layer) *Layer {
+	return LinearWithGenerator(inputFeatures, layerNeurons, activation, prev, nil)
+}
+
+/*
+* @notice LinearWithGenerator() is Linear() with the weights and biases drawn from random. A nil Generator uses the default one.
+ */
+func LinearWithGenerator(inputFeatures int, layerNeurons int, activation string, prev *Layer, random *Generator) *Layer {
 
 	// @dev matrix eq of Perceptron; y = activation(Wx + b)
 	var Weights *Tensor = new(Tensor)
@@ -70,12 +86,15 @@ func Linear(inputFeatures int, layerNeurons int, activation string, prev *Layer)
 	Weights.DataReqGrad = make([]*Value, layerNeurons*inputFeatures)
 	Biases.DataReqGrad = make([]*Value, layerNeurons)
 
-	// Initialize the Value structs in weights and biases to random values
+	// Initialize the Value structs in weights and biases to random values from the Generator
+	if random == nil {
+		random = DefaultGenerator()
+	}
 	for i := 0; i < layerNeurons*inputFeatures; i++ {
-		Weights.DataReqGrad[i] = NewValue(rand.Float64(), nil, "") // TODO: allow custom initialization
+		Weights.DataReqGrad[i] = NewValue(random.Float64(), nil, "") // TODO: allow custom initialization
 	}
 	for i := 0; i < layerNeurons; i++ {
-		Biases.DataReqGrad[i] = NewValue(rand.Float64(), nil, "")
+		Biases.DataReqGrad[i] = NewValue(random.Float64(), nil, "")
 	}
 
 	// Set RequireGrad to true for the weights and biases
@@ -73,16 +73,18 @@ func (BroadcastArg *Tensor) Broadcast(BroadcastOnto *Tensor, op func(onto *Tenso
 	Broadcast_Arg_Copy := BroadcastArg.Copy() // we are copying the Tensor so we don't want to modify the original
 	Broadcast_Arg_Copy.Shape = append([]int{1}, BroadcastArg.Shape...)
 
-	// For the first element along the 0'th axis of Broadcast_Onto, apply the operation
-	result := BroadcastOnto.Remove_Dim(0, 0).Reshape(Broadcast_Arg_Copy.Shape, false)
-	result = op(result, Broadcast_Arg_Copy) // apply the operation to the first element along the 0'th axis
-
-	for i := 1; i < BroadcastOnto.Shape[0]; i++ {
-
-		elementResult := BroadcastOnto.Remove_Dim(0, i).Reshape(Broadcast_Arg_Copy.Shape, false)
-
-		result = result.Concat(op(elementResult, Broadcast_Arg_Copy), 0)
+	// Apply the operation to a single element along the 0'th axis of Broadcast_Onto
+	applyOp := func(i int) *Tensor {
+		element := BroadcastOnto.Remove_Dim(0, i).Reshape(append([]int{1}, BroadcastArg.Shape...), false)
+		return op(element, Broadcast_Arg_Copy)
 	}
+
+	// The first result determines the output shape, the rest are written into the preallocated output across the worker pool.
+	// As such, op must be safe for concurrent use.
+	result := newBatchedOutput(applyOp(0), BroadcastOnto.Shape[0])
+	parallelFor(BroadcastOnto.Shape[0]-1, func(i int) {
+		result.setBatchElement(i+1, applyOp(i+1))
+	})
 	return result
 }
 
@@ -232,3 +234,58 @@ func (A *Tensor) Axis_Collapsing_Operation(axis int, op Collapsing_Operation) *T
 type Collapsing_Operation interface {
 	contributeToResult(partial *Tensor, result *Tensor)
 }
+
+//============================================================================================================================== AxisLaneOperation()
+
+/*
+* @notice A LaneOperation is applied to each 1D lane of a Tensor along an axis. A lane is the sequence of elements obtained by
+* varying the index of that axis while holding every other index fixed. Sorting along an axis is an example.
+* @dev OutputLength() gives the length of each output lane for input lanes of length n, allowing ops such as TopK() to shrink
+* the axis. Apply_LaneOp() must only read lane and write out, as lanes are processed concurrently.
+ */
+type LaneOperation interface {
+	OutputLength(n int) int
+	Apply_LaneOp(lane []float64, out []float64)
+}
+
+/*
+* @notice AxisLaneOperation() applies a LaneOperation to every lane of A along axis. The output has the shape of A, except
+* that the axis has length op.OutputLength(A.Shape[axis]).
+* @dev A is viewed as [outer, axis, inner]. Lanes are copied into a contiguous buffer, passed to the op, and the result is
+* copied into the output. Chunks of lanes are distributed across the shared worker pool (see WorkerPool.go).
+ */
+func (A *Tensor) AxisLaneOperation(axis int, op LaneOperation) *Tensor {
+	if axis < 0 || axis >= len(A.Shape) {
+		panic("Within AxisLaneOperation() --- Invalid axis")
+	}
+
+	n := A.Shape[axis]
+	m := op.OutputLength(n)
+	outer := Product(A.Shape[:axis])
+	inner := Product(A.Shape[axis+1:])
+
+	shape := append([]int(nil), A.Shape...)
+	shape[axis] = m
+	C := &Tensor{Shape: shape, Data: make([]float64, outer*m*inner), Batched: A.Batched}
+
+	numLanes := outer * inner
+	chunkSize := max(1, numLanes/(4*Parallelism()))
+	numChunks := (numLanes + chunkSize - 1) / chunkSize
+
+	parallelFor(numChunks, func(chunk int) {
+		lane, out := make([]float64, n), make([]float64, m)
+
+		for l := chunk * chunkSize; l < min(numLanes, (chunk+1)*chunkSize); l++ {
+			o, i := l/inner, l%inner
+
+			for k := 0; k < n; k++ {
+				lane[k] = A.Data[(o*n+k)*inner+i]
+			}
+			op.Apply_LaneOp(lane, out)
+			for k := 0; k < m; k++ {
+				C.Data[(o*m+k)*inner+i] = out[k]
+			}
+		}
+	})
+	return C
+}
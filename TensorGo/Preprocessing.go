@@ -0,0 +1,681 @@
+package TG
+
+/*
+* @notice Preprocessing.go contains transformers that learn statistics from training data with Fit() and apply them to any data
+* with Transform(): StandardScaler, MinMaxScaler, RobustScaler, OneHotEncoder, LabelEncoder, SimpleImputer and PolynomialFeatures.
+* A Pipeline chains transformers.
+* @dev Unlike Standardize() and Normalize_Axis(), which compute their statistics on the fly, a fitted transformer keeps its
+* statistics, so the scaling of the training set can be applied to test data.
+* @dev Transformers take [samples, features] Tensors. Missing values are NaN: the scalers ignore them when fitting and pass them
+* through, and SimpleImputer fills them.
+* @dev Fitted state is held in exported fields, so transformers serialize with encoding/json. A Pipeline records the type of
+* each step, so WritePipeline() and ReadPipeline() can round trip a whole Pipeline to ship alongside a model.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Transformer learns statistics from data with Fit() and applies them with Transform()
+type Transformer interface {
+	Fit(X *Tensor)
+	Transform(X *Tensor) *Tensor
+}
+
+// InverseTransformer is a Transformer whose transform can be undone
+type InverseTransformer interface {
+	Transformer
+	InverseTransform(X *Tensor) *Tensor
+}
+
+// FitTransform() fits t to X and returns X transformed.
+func FitTransform(t Transformer, X *Tensor) *Tensor {
+	t.Fit(X)
+	return t.Transform(X)
+}
+
+//============================================================================================================================== Helpers
+
+// checkFeatures panics unless X is [samples, numFeatures]. numFeatures < 0 accepts any number of features.
+func checkFeatures(X *Tensor, numFeatures int, caller string) {
+	if len(X.Shape) != 2 {
+		panic(fmt.Sprintf("Within %v(): X must be [samples, features], got shape %v", caller, X.Shape))
+	}
+	if numFeatures >= 0 && X.Shape[1] != numFeatures {
+		panic(fmt.Sprintf("Within %v(): fit on %v features but X has %v, or not fit yet", caller, numFeatures, X.Shape[1]))
+	}
+}
+
+// presentValues returns the values of column j of X that are not NaN
+func presentValues(X *Tensor, j int) []float64 {
+	values := make([]float64, 0, X.Shape[0])
+	for i := 0; i < X.Shape[0]; i++ {
+		if value := X.Data[i*X.Shape[1]+j]; !math.IsNaN(value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// mapFeatures applies f to every element of X, passing its column
+func mapFeatures(X *Tensor, f func(j int, value float64) float64) *Tensor {
+	C := &Tensor{Shape: []int{X.Shape[0], X.Shape[1]}, Data: make([]float64, len(X.Data)), Batched: X.Batched}
+	for i, value := range X.Data {
+		C.Data[i] = f(i%X.Shape[1], value)
+	}
+	return C
+}
+
+// nonZeroScale replaces a zero or undefined scale with 1, so constant features are left unscaled
+func nonZeroScale(scale float64) float64 {
+	if scale == 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		return 1
+	}
+	return scale
+}
+
+//============================================================================================================================== StandardScaler
+
+// StandardScaler scales each feature to zero mean and unit variance
+type StandardScaler struct {
+	WithMean bool // <--- subtract the mean
+	WithStd  bool // <--- divide by the standard deviation
+
+	Mean  []float64 // <--- fitted mean of each feature
+	Scale []float64 // <--- fitted standard deviation of each feature, 1 for constant features
+}
+
+// NewStandardScaler() creates a StandardScaler that centers and scales.
+func NewStandardScaler() *StandardScaler {
+	return &StandardScaler{WithMean: true, WithStd: true}
+}
+
+func (s *StandardScaler) Fit(X *Tensor) {
+	checkFeatures(X, -1, "StandardScaler.Fit")
+	s.Mean, s.Scale = make([]float64, X.Shape[1]), make([]float64, X.Shape[1])
+	for j := range s.Mean {
+		values := presentValues(X, j)
+		s.Scale[j] = 1
+		if len(values) > 0 {
+			s.Mean[j] = MeanReducer{}.Reduce(values)
+			s.Scale[j] = nonZeroScale(math.Sqrt(VarReducer{}.Reduce(values)))
+		}
+	}
+}
+
+func (s *StandardScaler) Transform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.Mean), "StandardScaler.Transform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		if s.WithMean {
+			value -= s.Mean[j]
+		}
+		if s.WithStd {
+			value /= s.Scale[j]
+		}
+		return value
+	})
+}
+
+func (s *StandardScaler) InverseTransform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.Mean), "StandardScaler.InverseTransform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		if s.WithStd {
+			value *= s.Scale[j]
+		}
+		if s.WithMean {
+			value += s.Mean[j]
+		}
+		return value
+	})
+}
+
+//============================================================================================================================== MinMaxScaler
+
+// MinMaxScaler scales each feature linearly so that its fitted range maps to [FeatureMin, FeatureMax]
+type MinMaxScaler struct {
+	FeatureMin, FeatureMax float64
+
+	DataMin []float64 // <--- fitted minimum of each feature
+	DataMax []float64 // <--- fitted maximum of each feature
+}
+
+// NewMinMaxScaler() creates a MinMaxScaler that maps each feature to [min, max].
+func NewMinMaxScaler(min float64, max float64) *MinMaxScaler {
+	if min >= max {
+		panic("Within NewMinMaxScaler(): min must be less than max")
+	}
+	return &MinMaxScaler{FeatureMin: min, FeatureMax: max}
+}
+
+func (s *MinMaxScaler) Fit(X *Tensor) {
+	checkFeatures(X, -1, "MinMaxScaler.Fit")
+	s.DataMin, s.DataMax = make([]float64, X.Shape[1]), make([]float64, X.Shape[1])
+	for j := range s.DataMin {
+		if values := presentValues(X, j); len(values) > 0 {
+			s.DataMin[j], s.DataMax[j] = slices.Min(values), slices.Max(values)
+		}
+	}
+}
+
+// scale returns the ratio of the output range to the fitted range of feature j
+func (s *MinMaxScaler) scale(j int) float64 {
+	return (s.FeatureMax - s.FeatureMin) / nonZeroScale(s.DataMax[j]-s.DataMin[j])
+}
+
+func (s *MinMaxScaler) Transform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.DataMin), "MinMaxScaler.Transform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		return (value-s.DataMin[j])*s.scale(j) + s.FeatureMin
+	})
+}
+
+func (s *MinMaxScaler) InverseTransform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.DataMin), "MinMaxScaler.InverseTransform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		return (value-s.FeatureMin)/s.scale(j) + s.DataMin[j]
+	})
+}
+
+//============================================================================================================================== RobustScaler
+
+/*
+* @notice RobustScaler centers each feature on its median and scales it by its interquartile range, so that outliers have
+* little influence on the scaling.
+* @dev The range is between the QuantileLow and QuantileHigh quantiles, 0.25 and 0.75 by default.
+ */
+type RobustScaler struct {
+	WithCentering bool
+	WithScaling   bool
+	QuantileLow   float64
+	QuantileHigh  float64
+
+	Center []float64 // <--- fitted median of each feature
+	Scale  []float64 // <--- fitted quantile range of each feature, 1 for constant features
+}
+
+// NewRobustScaler() creates a RobustScaler that centers on the median and scales by the interquartile range.
+func NewRobustScaler() *RobustScaler {
+	return &RobustScaler{WithCentering: true, WithScaling: true, QuantileLow: 0.25, QuantileHigh: 0.75}
+}
+
+func (s *RobustScaler) Fit(X *Tensor) {
+	checkFeatures(X, -1, "RobustScaler.Fit")
+	if s.QuantileLow < 0 || s.QuantileLow >= s.QuantileHigh || s.QuantileHigh > 1 {
+		panic("Within RobustScaler.Fit(): QuantileLow and QuantileHigh must satisfy 0 <= QuantileLow < QuantileHigh <= 1")
+	}
+	s.Center, s.Scale = make([]float64, X.Shape[1]), make([]float64, X.Shape[1])
+	for j := range s.Center {
+		values := presentValues(X, j)
+		s.Scale[j] = 1
+		if len(values) > 0 {
+			s.Center[j] = QuantileReducer{Q: 0.5}.Reduce(values)
+			s.Scale[j] = nonZeroScale(QuantileReducer{Q: s.QuantileHigh}.Reduce(values) - QuantileReducer{Q: s.QuantileLow}.Reduce(values))
+		}
+	}
+}
+
+func (s *RobustScaler) Transform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.Center), "RobustScaler.Transform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		if s.WithCentering {
+			value -= s.Center[j]
+		}
+		if s.WithScaling {
+			value /= s.Scale[j]
+		}
+		return value
+	})
+}
+
+func (s *RobustScaler) InverseTransform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.Center), "RobustScaler.InverseTransform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		if s.WithScaling {
+			value *= s.Scale[j]
+		}
+		if s.WithCentering {
+			value += s.Center[j]
+		}
+		return value
+	})
+}
+
+//============================================================================================================================== SimpleImputer
+
+// SimpleImputer fills the NaNs of each feature with a statistic of the feature learned by Fit()
+type SimpleImputer struct {
+	Strategy  ImputeStrategy // <--- ImputeMean, ImputeMedian, ImputeMostFrequent or ImputeConstant
+	FillValue float64        // <--- the fill value for ImputeConstant, and for features with no values when fit
+
+	Statistics []float64 // <--- fitted fill value of each feature
+}
+
+// NewSimpleImputer() creates a SimpleImputer with the given strategy.
+func NewSimpleImputer(strategy ImputeStrategy) *SimpleImputer {
+	return &SimpleImputer{Strategy: strategy}
+}
+
+func (s *SimpleImputer) Fit(X *Tensor) {
+	checkFeatures(X, -1, "SimpleImputer.Fit")
+	if s.Strategy == ImputeNaN {
+		panic("Within SimpleImputer.Fit(): ImputeNaN does not fill missing values")
+	}
+	s.Statistics = make([]float64, X.Shape[1])
+	for j := range s.Statistics {
+		values := presentValues(X, j)
+		s.Statistics[j] = s.FillValue
+		if s.Strategy != ImputeConstant && len(values) > 0 {
+			s.Statistics[j] = imputeValue(values, s.Strategy, false)
+		}
+	}
+}
+
+func (s *SimpleImputer) Transform(X *Tensor) *Tensor {
+	checkFeatures(X, len(s.Statistics), "SimpleImputer.Transform")
+	return mapFeatures(X, func(j int, value float64) float64 {
+		if math.IsNaN(value) {
+			return s.Statistics[j]
+		}
+		return value
+	})
+}
+
+//============================================================================================================================== OneHotEncoder
+
+/*
+* @notice OneHotEncoder replaces each categorical feature with one 0/1 feature per category seen by Fit(), in sorted order.
+* Other features pass through in place.
+* @dev Columns lists the features to encode, or every feature when empty. NaNs and categories not seen by Fit() encode to all
+* zeros when IgnoreUnknown is set, and panic otherwise.
+ */
+type OneHotEncoder struct {
+	Columns       []int
+	IgnoreUnknown bool
+
+	NumFeatures int         // <--- fitted number of input features
+	Categories  [][]float64 // <--- fitted sorted categories of each input feature, nil for features that are not encoded
+}
+
+// NewOneHotEncoder() creates a OneHotEncoder for the given features, or every feature when none are given.
+func NewOneHotEncoder(columns ...int) *OneHotEncoder {
+	return &OneHotEncoder{Columns: columns}
+}
+
+func (e *OneHotEncoder) Fit(X *Tensor) {
+	checkFeatures(X, -1, "OneHotEncoder.Fit")
+	columns := e.Columns
+	if len(columns) == 0 {
+		columns = rangeInts(X.Shape[1])
+	}
+	e.NumFeatures = X.Shape[1]
+	e.Categories = make([][]float64, X.Shape[1])
+	for _, j := range columns {
+		if j < 0 || j >= X.Shape[1] {
+			panic(fmt.Sprintf("Within OneHotEncoder.Fit(): column %v out of range for %v features", j, X.Shape[1]))
+		}
+		categories := presentValues(X, j)
+		slices.Sort(categories)
+		e.Categories[j] = slices.Compact(categories)
+	}
+}
+
+// width returns the number of output features of input feature j
+func (e *OneHotEncoder) width(j int) int {
+	if e.Categories[j] == nil {
+		return 1
+	}
+	return len(e.Categories[j])
+}
+
+// NumOutputs() returns the number of features produced by Transform().
+func (e *OneHotEncoder) NumOutputs() int {
+	total := 0
+	for j := 0; j < e.NumFeatures; j++ {
+		total += e.width(j)
+	}
+	return total
+}
+
+func (e *OneHotEncoder) Transform(X *Tensor) *Tensor {
+	checkFeatures(X, e.NumFeatures, "OneHotEncoder.Transform")
+	numOutputs := e.NumOutputs()
+	C := &Tensor{Shape: []int{X.Shape[0], numOutputs}, Data: make([]float64, X.Shape[0]*numOutputs), Batched: X.Batched}
+
+	for i := 0; i < X.Shape[0]; i++ {
+		offset := i * numOutputs
+		for j := 0; j < e.NumFeatures; j++ {
+			value := X.Data[i*e.NumFeatures+j]
+			if e.Categories[j] == nil {
+				C.Data[offset] = value
+			} else if k, found := slices.BinarySearch(e.Categories[j], value); found {
+				C.Data[offset+k] = 1
+			} else if !e.IgnoreUnknown {
+				panic(fmt.Sprintf("Within OneHotEncoder.Transform(): unknown category %v in column %v", value, j))
+			}
+			offset += e.width(j)
+		}
+	}
+	return C
+}
+
+// InverseTransform() decodes each one-hot group to its category. Groups of all zeros decode to NaN.
+func (e *OneHotEncoder) InverseTransform(X *Tensor) *Tensor {
+	checkFeatures(X, e.NumOutputs(), "OneHotEncoder.InverseTransform")
+	C := &Tensor{Shape: []int{X.Shape[0], e.NumFeatures}, Data: make([]float64, X.Shape[0]*e.NumFeatures), Batched: X.Batched}
+
+	for i := 0; i < X.Shape[0]; i++ {
+		row := X.Data[i*X.Shape[1] : (i+1)*X.Shape[1]]
+		for j := 0; j < e.NumFeatures; j++ {
+			group := row[:e.width(j)]
+			row = row[e.width(j):]
+			if e.Categories[j] == nil {
+				C.Data[i*e.NumFeatures+j] = group[0]
+				continue
+			}
+			C.Data[i*e.NumFeatures+j] = math.NaN()
+			if k := slices.Index(group, slices.Max(group)); group[k] > 0 {
+				C.Data[i*e.NumFeatures+j] = e.Categories[j][k]
+			}
+		}
+	}
+	return C
+}
+
+//============================================================================================================================== LabelEncoder
+
+// LabelEncoder maps labels to 0, 1, 2, ... in sorted order. It accepts labels of any shape and keeps their shape.
+type LabelEncoder struct {
+	Classes []float64 // <--- fitted sorted labels, label k encodes to k
+}
+
+func (e *LabelEncoder) Fit(y *Tensor) {
+	classes := append([]float64(nil), y.Data...)
+	if slices.ContainsFunc(classes, math.IsNaN) {
+		panic("Within LabelEncoder.Fit(): labels cannot be NaN")
+	}
+	slices.Sort(classes)
+	e.Classes = slices.Compact(classes)
+}
+
+func (e *LabelEncoder) Transform(y *Tensor) *Tensor {
+	C := &Tensor{Shape: append([]int(nil), y.Shape...), Data: make([]float64, len(y.Data)), Batched: y.Batched}
+	for i, label := range y.Data {
+		k, found := slices.BinarySearch(e.Classes, label)
+		if !found {
+			panic(fmt.Sprintf("Within LabelEncoder.Transform(): unknown label %v", label))
+		}
+		C.Data[i] = float64(k)
+	}
+	return C
+}
+
+func (e *LabelEncoder) InverseTransform(y *Tensor) *Tensor {
+	C := &Tensor{Shape: append([]int(nil), y.Shape...), Data: make([]float64, len(y.Data)), Batched: y.Batched}
+	for i, code := range y.Data {
+		k := int(code)
+		if float64(k) != code || k < 0 || k >= len(e.Classes) {
+			panic(fmt.Sprintf("Within LabelEncoder.InverseTransform(): %v is not the code of a class", code))
+		}
+		C.Data[i] = e.Classes[k]
+	}
+	return C
+}
+
+//============================================================================================================================== PolynomialFeatures
+
+/*
+* @notice PolynomialFeatures expands the features into every product of at most Degree of them. Products are ordered by degree,
+* then lexicographically by feature, so [a, b] with Degree 2 becomes [1, a, b, a², ab, b²].
+* @dev InteractionOnly keeps only products of distinct features, and IncludeBias adds the leading column of ones.
+ */
+type PolynomialFeatures struct {
+	Degree          int
+	InteractionOnly bool
+	IncludeBias     bool
+
+	NumFeatures int     // <--- fitted number of input features
+	Powers      [][]int // <--- fitted exponent of each input feature in each output feature
+}
+
+// NewPolynomialFeatures() creates a PolynomialFeatures of the given degree with a bias column.
+func NewPolynomialFeatures(degree int) *PolynomialFeatures {
+	if degree < 1 {
+		panic("Within NewPolynomialFeatures(): degree must be at least 1")
+	}
+	return &PolynomialFeatures{Degree: degree, IncludeBias: true}
+}
+
+func (p *PolynomialFeatures) Fit(X *Tensor) {
+	checkFeatures(X, -1, "PolynomialFeatures.Fit")
+	p.NumFeatures = X.Shape[1]
+	p.Powers = nil
+	if p.IncludeBias {
+		p.Powers = append(p.Powers, make([]int, p.NumFeatures))
+	}
+
+	// combinations holds the features of a product in non decreasing order, or increasing order for interactions only
+	var extend func(combination []int, degree int)
+	extend = func(combination []int, degree int) {
+		if len(combination) == degree {
+			powers := make([]int, p.NumFeatures)
+			for _, j := range combination {
+				powers[j]++
+			}
+			p.Powers = append(p.Powers, powers)
+			return
+		}
+		start := 0
+		if len(combination) > 0 {
+			start = combination[len(combination)-1]
+			if p.InteractionOnly {
+				start++
+			}
+		}
+		for j := start; j < p.NumFeatures; j++ {
+			extend(append(combination, j), degree)
+		}
+	}
+	for degree := 1; degree <= p.Degree; degree++ {
+		extend(make([]int, 0, degree), degree)
+	}
+}
+
+func (p *PolynomialFeatures) Transform(X *Tensor) *Tensor {
+	checkFeatures(X, p.NumFeatures, "PolynomialFeatures.Transform")
+	numOutputs := len(p.Powers)
+	C := &Tensor{Shape: []int{X.Shape[0], numOutputs}, Data: make([]float64, X.Shape[0]*numOutputs), Batched: X.Batched}
+
+	parallelFor(X.Shape[0], func(i int) {
+		row := X.Data[i*p.NumFeatures : (i+1)*p.NumFeatures]
+		for k, powers := range p.Powers {
+			product := 1.0
+			for j, power := range powers {
+				for ; power > 0; power-- {
+					product *= row[j]
+				}
+			}
+			C.Data[i*numOutputs+k] = product
+		}
+	})
+	return C
+}
+
+//============================================================================================================================== Pipeline
+
+// Pipeline applies a sequence of transformers, each to the output of the one before
+type Pipeline struct {
+	Steps []Transformer
+}
+
+// NewPipeline() creates a Pipeline of steps.
+func NewPipeline(steps ...Transformer) *Pipeline {
+	return &Pipeline{Steps: steps}
+}
+
+// Fit() fits each step to the output of the steps before it.
+func (p *Pipeline) Fit(X *Tensor) {
+	for k, step := range p.Steps {
+		step.Fit(X)
+		if k < len(p.Steps)-1 {
+			X = step.Transform(X)
+		}
+	}
+}
+
+func (p *Pipeline) Transform(X *Tensor) *Tensor {
+	for _, step := range p.Steps {
+		X = step.Transform(X)
+	}
+	return X
+}
+
+// InverseTransform() undoes the steps in reverse order. Every step must be an InverseTransformer.
+func (p *Pipeline) InverseTransform(X *Tensor) *Tensor {
+	for k := len(p.Steps) - 1; k >= 0; k-- {
+		step, ok := p.Steps[k].(InverseTransformer)
+		if !ok {
+			panic(fmt.Sprintf("Within Pipeline.InverseTransform(): step %v (%T) cannot be inverted", k, p.Steps[k]))
+		}
+		X = step.InverseTransform(X)
+	}
+	return X
+}
+
+//============================================================================================================================== Serialization
+
+var (
+	transformerMu    sync.RWMutex
+	transformerTypes = map[string]func() Transformer{
+		"StandardScaler":     func() Transformer { return &StandardScaler{} },
+		"MinMaxScaler":       func() Transformer { return &MinMaxScaler{} },
+		"RobustScaler":       func() Transformer { return &RobustScaler{} },
+		"SimpleImputer":      func() Transformer { return &SimpleImputer{} },
+		"OneHotEncoder":      func() Transformer { return &OneHotEncoder{} },
+		"LabelEncoder":       func() Transformer { return &LabelEncoder{} },
+		"PolynomialFeatures": func() Transformer { return &PolynomialFeatures{} },
+		"PCA":                func() Transformer { return &PCA{} },
+		"TruncatedSVD":       func() Transformer { return &TruncatedSVD{} },
+		"Pipeline":           func() Transformer { return &Pipeline{} },
+	}
+)
+
+/*
+* @notice RegisterTransformer() registers a Transformer type under name, so Pipelines containing it can be serialized.
+* @dev create must return a new pointer to the type. Its fitted state must be held in exported fields, as it is written with
+* encoding/json.
+ */
+func RegisterTransformer(name string, create func() Transformer) {
+	transformerMu.Lock()
+	defer transformerMu.Unlock()
+	transformerTypes[name] = create
+}
+
+// transformerName returns the registered name of the type of t
+func transformerName(t Transformer) (string, bool) {
+	transformerMu.RLock()
+	defer transformerMu.RUnlock()
+	for name, create := range transformerTypes {
+		if reflect.TypeOf(create()) == reflect.TypeOf(t) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// pipelineStep is the serialized form of a step
+type pipelineStep struct {
+	Type  string
+	State json.RawMessage
+}
+
+func (p *Pipeline) MarshalJSON() ([]byte, error) {
+	steps := make([]pipelineStep, len(p.Steps))
+	for k, step := range p.Steps {
+		name, ok := transformerName(step)
+		if !ok {
+			return nil, fmt.Errorf("step %v has unregistered type %T, see RegisterTransformer()", k, step)
+		}
+		state, err := json.Marshal(step)
+		if err != nil {
+			return nil, fmt.Errorf("step %v (%v): %w", k, name, err)
+		}
+		steps[k] = pipelineStep{Type: name, State: state}
+	}
+	return json.Marshal(struct{ Steps []pipelineStep }{steps})
+}
+
+func (p *Pipeline) UnmarshalJSON(data []byte) error {
+	var serialized struct{ Steps []pipelineStep }
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return err
+	}
+
+	p.Steps = make([]Transformer, len(serialized.Steps))
+	for k, step := range serialized.Steps {
+		transformerMu.RLock()
+		create, ok := transformerTypes[step.Type]
+		transformerMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("step %v has unknown type %q, see RegisterTransformer()", k, step.Type)
+		}
+		p.Steps[k] = create()
+		if err := json.Unmarshal(step.State, p.Steps[k]); err != nil {
+			return fmt.Errorf("step %v (%v): %w", k, step.Type, err)
+		}
+	}
+	return nil
+}
+
+// WritePipeline() writes p, including the fitted state of each step, to w as JSON.
+func WritePipeline(w io.Writer, p *Pipeline) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(p); err != nil {
+		return fmt.Errorf("WritePipeline(): %w", err)
+	}
+	return nil
+}
+
+// ReadPipeline() reads a Pipeline written by WritePipeline().
+func ReadPipeline(r io.Reader) (*Pipeline, error) {
+	p := &Pipeline{}
+	if err := json.NewDecoder(r).Decode(p); err != nil {
+		return nil, fmt.Errorf("ReadPipeline(): %w", err)
+	}
+	return p, nil
+}
+
+// SavePipeline() saves p to fileName as JSON.
+func SavePipeline(p *Pipeline, fileName string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if err := WritePipeline(file, p); err != nil {
+		panic(err)
+	}
+}
+
+// LoadPipeline() loads a Pipeline saved by SavePipeline().
+func LoadPipeline(fileName string) *Pipeline {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	p, err := ReadPipeline(file)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
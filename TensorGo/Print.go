@@ -0,0 +1,293 @@
+package TG
+
+/*
+* @notice Print.go implements fmt.Stringer and fmt.Formatter for Tensors, printing Tensors of any rank NumPy style.
+* @dev Elements are printed in nested brackets, one bracket per dimension, and padded to a common width so that columns
+* line up. Tensors with more than Threshold elements are summarized: only the first and last EdgeItems of each long
+* dimension are printed, with "..." in between. The shape, batched flag and requires grad flag follow the data.
+* @dev Numbers are printed with the fewest digits (at most Precision) that represent every printed element at that
+* precision. Scientific notation is used when the magnitudes of the elements would not fit well in fixed notation.
+ */
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PrintOptions controls how Tensors are printed
+type PrintOptions struct {
+	Precision int  // <--- maximum number of digits after the decimal point
+	Threshold int  // <--- Tensors with more elements than this are summarized
+	EdgeItems int  // <--- number of items printed at the start and end of each summarized dimension
+	LineWidth int  // <--- maximum number of characters per line before wrapping
+	Suppress  bool // <--- always use fixed notation, never scientific notation
+}
+
+// DefaultPrintOptions() returns the print options the library starts with, which match NumPy's defaults.
+func DefaultPrintOptions() PrintOptions {
+	return PrintOptions{Precision: 8, Threshold: 1000, EdgeItems: 3, LineWidth: 75}
+}
+
+var (
+	printOptionsMu sync.RWMutex
+	printOptions   = DefaultPrintOptions()
+)
+
+// SetPrintOptions() sets the options used to print every Tensor. Start from GetPrintOptions() to change a single option.
+func SetPrintOptions(options PrintOptions) {
+	if options.Precision < 0 || options.Threshold < 0 || options.EdgeItems < 0 || options.LineWidth < 1 {
+		panic("Within SetPrintOptions(): options must be non-negative and LineWidth must be positive")
+	}
+	printOptionsMu.Lock()
+	defer printOptionsMu.Unlock()
+	printOptions = options
+}
+
+// GetPrintOptions() returns the options currently used to print Tensors.
+func GetPrintOptions() PrintOptions {
+	printOptionsMu.RLock()
+	defer printOptionsMu.RUnlock()
+	return printOptions
+}
+
+//============================================================================================================================== String(), Format()
+
+// String() prints A using the current print options. It implements fmt.Stringer.
+func (A *Tensor) String() string {
+	return A.format(GetPrintOptions(), 0)
+}
+
+/*
+* @notice Format() implements fmt.Formatter so that Tensors can be printed with fmt verbs.
+* @dev %v and %s print the same as String(). A precision such as %.2v overrides the Precision option.
+* %f forces fixed notation and %e forces scientific notation, also accepting a precision.
+ */
+func (A *Tensor) Format(f fmt.State, verb rune) {
+
+	options := GetPrintOptions()
+	if precision, ok := f.Precision(); ok {
+		options.Precision = precision
+	}
+
+	var notation byte
+	switch verb {
+	case 'v', 's':
+	case 'f':
+		notation = 'f'
+	case 'e':
+		notation = 'e'
+	default:
+		fmt.Fprintf(f, "%%!%c(*TG.Tensor=%v)", verb, A.Shape)
+		return
+	}
+	fmt.Fprint(f, A.format(options, notation))
+}
+
+// format prints A with the given options. notation is 'f' or 'e' to force a notation, or 0 to choose one automatically.
+func (A *Tensor) format(options PrintOptions, notation byte) string {
+
+	const prefix = "Tensor("
+
+	// a Tensor whose Data does not fill its Shape, such as the zero value Tensor, has no elements to print
+	filled := len(A.Data) == Product(A.Shape)
+
+	p := tensorPrinter{A: A, options: options, strides: rowMajorStrides(A.Shape)}
+	p.summarize = len(A.Data) > options.Threshold
+	if filled {
+		p.chooseFormat(notation)
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	switch {
+	case !filled:
+		b.WriteString("uninitialized")
+	case len(A.Shape) == 0:
+		b.WriteString(p.element(A.Data[0]))
+	case len(A.Data) == 0:
+		b.WriteString("[]")
+	default:
+		b.WriteString(p.block(0, 0, strings.Repeat(" ", len(prefix)+1)))
+	}
+
+	fmt.Fprintf(&b, ", shape=%v", A.Shape)
+	if A.Batched {
+		b.WriteString(", batched=true")
+	}
+	if A.RequireGrad {
+		b.WriteString(", requires_grad=true")
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+//============================================================================================================================== Tensor Printer
+
+// tensorPrinter holds the state of printing a single Tensor
+type tensorPrinter struct {
+	A         *Tensor
+	options   PrintOptions
+	strides   []int
+	summarize bool
+	notation  byte // <--- 'f' or 'e'
+	digits    int  // <--- digits after the decimal point
+	width     int  // <--- width every element is padded to
+}
+
+// shownIndices returns the indices of a dimension of size n that are printed. -1 marks where "..." goes.
+func (p *tensorPrinter) shownIndices(n int) []int {
+	edge := p.options.EdgeItems
+	indices := []int{}
+	if p.summarize && n > 2*edge {
+		for i := 0; i < edge; i++ {
+			indices = append(indices, i)
+		}
+		indices = append(indices, -1)
+		for i := n - edge; i < n; i++ {
+			indices = append(indices, i)
+		}
+		return indices
+	}
+	for i := 0; i < n; i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// forEachShown calls fn with every element that will be printed
+func (p *tensorPrinter) forEachShown(dim int, offset int, fn func(value float64)) {
+	if dim == len(p.A.Shape) {
+		fn(p.A.Data[offset])
+		return
+	}
+	for _, i := range p.shownIndices(p.A.Shape[dim]) {
+		if i >= 0 {
+			p.forEachShown(dim+1, offset+i*p.strides[dim], fn)
+		}
+	}
+}
+
+// chooseFormat picks the notation, the number of digits and the column width from the printed elements
+func (p *tensorPrinter) chooseFormat(notation byte) {
+
+	maxAbs, minAbs, integral := 0.0, math.Inf(1), true
+	finite := []float64{}
+	p.forEachShown(0, 0, func(value float64) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return
+		}
+		finite = append(finite, value)
+		abs := math.Abs(value)
+		maxAbs = math.Max(maxAbs, abs)
+		if abs > 0 {
+			minAbs = math.Min(minAbs, abs)
+		}
+		integral = integral && value == math.Trunc(value)
+	})
+
+	// use scientific notation for very large or very small magnitudes, or when they span too many orders of magnitude
+	p.notation = notation
+	if p.notation == 0 {
+		p.notation = 'f'
+		if !p.options.Suppress && len(finite) > 0 {
+			if maxAbs >= 1e16 || (!integral && (maxAbs >= 1e8 || minAbs < 1e-4 || maxAbs/minAbs > 1e3)) {
+				p.notation = 'e'
+			}
+		}
+	}
+
+	// the number of digits is the most any element needs, up to the precision
+	p.digits = 0
+	for _, value := range finite {
+		if p.digits == p.options.Precision {
+			break
+		}
+		s := strconv.FormatFloat(value, p.notation, p.options.Precision, 64)
+		mantissa := s
+		if p.notation == 'e' {
+			mantissa = s[:strings.IndexByte(s, 'e')]
+		}
+		if dot := strings.IndexByte(mantissa, '.'); dot >= 0 {
+			p.digits = max(p.digits, len(strings.TrimRight(mantissa[dot+1:], "0")))
+		}
+	}
+	if notation != 0 {
+		p.digits = p.options.Precision // <--- a forced notation prints the full precision
+	}
+
+	p.width = 0
+	p.forEachShown(0, 0, func(value float64) {
+		p.width = max(p.width, len(p.element(value)))
+	})
+}
+
+// element formats a single element without padding
+func (p *tensorPrinter) element(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "nan"
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+
+	s := strconv.FormatFloat(value, p.notation, p.digits, 64)
+	if p.digits == 0 && p.notation == 'f' {
+		s += "." // <--- mark whole numbers as floats, as NumPy does
+	}
+	return s
+}
+
+// block formats the sub-Tensor starting at offset along dimension dim. indent is the indentation of the next dimension.
+func (p *tensorPrinter) block(dim int, offset int, indent string) string {
+
+	var b strings.Builder
+	b.WriteString("[")
+
+	indices := p.shownIndices(p.A.Shape[dim])
+
+	// the last dimension is a row of elements, wrapped to the line width
+	if dim == len(p.A.Shape)-1 {
+		lineLength := len(indent)
+		for k, i := range indices {
+			item := "..."
+			if i >= 0 {
+				item = fmt.Sprintf("%*s", p.width, p.element(p.A.Data[offset+i]))
+			}
+			if k > 0 {
+				b.WriteString(",")
+				lineLength++
+				if lineLength+1+len(item)+len("]")*(len(p.A.Shape)-dim) > p.options.LineWidth {
+					b.WriteString("\n" + indent)
+					lineLength = len(indent)
+				} else {
+					b.WriteString(" ")
+					lineLength++
+				}
+			}
+			b.WriteString(item)
+			lineLength += len(item)
+		}
+		b.WriteString("]")
+		return b.String()
+	}
+
+	// higher dimensions separate their sub-blocks with one newline per remaining dimension
+	separator := "," + strings.Repeat("\n", len(p.A.Shape)-dim-1) + indent
+	for k, i := range indices {
+		if k > 0 {
+			b.WriteString(separator)
+		}
+		if i < 0 {
+			b.WriteString("...")
+			continue
+		}
+		b.WriteString(p.block(dim+1, offset+i*p.strides[dim], indent+" "))
+	}
+	b.WriteString("]")
+	return b.String()
+}
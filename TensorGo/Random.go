@@ -0,0 +1,458 @@
+package TG
+
+/*
+* @notice Random.go contains the Generator, a seeded source of random numbers used by every random op in the library, and the
+* distributions it samples from.
+* @dev Two Generators created with the same seed produce the same sequence of samples, so an experiment can be reproduced by
+* recording its seed. Functions that do not accept a Generator (such as RandFloat64Tensor(), Linear() and MLP()) use the default
+* Generator, which is seeded from the clock unless SetSeed() is called.
+* @dev A Generator is safe for concurrent use, but samples are only reproducible if they are drawn in the same order. For this
+* reason random initializers fill batch elements sequentially (see Batched_Initializer_Operation()).
+ */
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Generator is a seeded random number generator
+type Generator struct {
+	mu   sync.Mutex
+	rng  *rand.Rand
+	seed int64
+}
+
+// NewGenerator() creates a Generator with the given seed.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rng: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+// Seed() returns the seed the Generator was created with.
+func (g *Generator) Seed() int64 { return g.seed }
+
+// defaultGenerator is used by functions that do not accept a Generator
+var defaultGenerator = NewGenerator(time.Now().UnixNano())
+
+// DefaultGenerator() returns the Generator used by functions that do not accept one.
+func DefaultGenerator() *Generator { return defaultGenerator }
+
+// SetSeed() reseeds the default Generator, making functions that use it reproducible.
+func SetSeed(seed int64) {
+	defaultGenerator.mu.Lock()
+	defer defaultGenerator.mu.Unlock()
+	defaultGenerator.rng.Seed(seed)
+	defaultGenerator.seed = seed
+}
+
+//============================================================================================================================== Scalar Samples
+
+// Float64() returns a uniform sample from [0, 1).
+func (g *Generator) Float64() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rng.Float64()
+}
+
+// Intn() returns a uniform sample from [0, n).
+func (g *Generator) Intn(n int) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rng.Intn(n)
+}
+
+// NormFloat64() returns a sample from the standard normal distribution.
+func (g *Generator) NormFloat64() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rng.NormFloat64()
+}
+
+// ExpFloat64() returns a sample from the exponential distribution with rate 1.
+func (g *Generator) ExpFloat64() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rng.ExpFloat64()
+}
+
+// Uniform() returns a uniform sample from [lower, upper).
+func (g *Generator) Uniform(lower float64, upper float64) float64 {
+	return lower + g.Float64()*(upper-lower)
+}
+
+// Normal() returns a sample from the normal distribution with the given mean and standard deviation.
+func (g *Generator) Normal(mean float64, std float64) float64 {
+	return mean + std*g.NormFloat64()
+}
+
+/*
+* @notice TruncatedNormal() returns a sample from the normal distribution with the given mean and standard deviation,
+* conditioned to lie within [lower, upper].
+* @dev The sample is drawn by inverse transform: a uniform sample between the CDF values of the bounds is mapped back through
+* the normal quantile function. Bounds in the upper tail are mirrored into the lower tail, where the CDF keeps its precision,
+* so the bounds may hold very little probability. Only bounds holding no representable probability panic.
+ */
+func (g *Generator) TruncatedNormal(mean float64, std float64, lower float64, upper float64) float64 {
+	if lower >= upper {
+		panic("Within TruncatedNormal(): lower must be less than upper")
+	}
+
+	a, b, sign := (lower-mean)/std, (upper-mean)/std, 1.0
+	if a > 0 {
+		a, b, sign = -b, -a, -1 // <--- mirror the interval into the lower tail
+	}
+	pa, pb := normalCDF(a), normalCDF(b)
+	if pb <= pa {
+		if pb == 0 {
+			panic("Within TruncatedNormal(): the bounds hold no representable probability")
+		}
+		return g.Uniform(lower, upper) // <--- the interval is too narrow for the density to vary across it
+	}
+
+	u := pa + (1-g.Float64())*(pb-pa) // <--- within (pa, pb]
+	x := math.Max(a, math.Min(b, normalQuantile(u)))
+	return mean + sign*std*x
+}
+
+// normalCDF() is the CDF of the standard normal distribution
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+/*
+* @notice normalQuantile() inverts normalCDF().
+* @dev math.Erfcinv() loses precision for small probabilities, so its estimate is refined with Newton steps on the log of the
+* CDF, which converge without overshooting far in the lower tail.
+ */
+func normalQuantile(p float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	} else if p >= 1 {
+		return math.Inf(1)
+	}
+
+	x := -math.Sqrt2 * math.Erfcinv(2*p)
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		x = -math.Sqrt(-2 * math.Log(p)) // <--- the tail asymptote, where Erfcinv() rounds to infinity
+	}
+	for iteration := 0; iteration < 50; iteration++ {
+		cdf := normalCDF(x)
+		density := math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+		step := (math.Log(cdf) - math.Log(p)) * cdf / density
+		x -= step
+		if math.Abs(step) <= 1e-15*math.Max(1, math.Abs(x)) {
+			break
+		}
+	}
+	return x
+}
+
+// Bernoulli() returns 1 with probability p and 0 otherwise.
+func (g *Generator) Bernoulli(p float64) float64 {
+	return boolToFloat(g.Float64() < p)
+}
+
+// Exponential() returns a sample from the exponential distribution with the given rate.
+func (g *Generator) Exponential(rate float64) float64 {
+	if rate <= 0 {
+		panic("Within Exponential(): rate must be positive")
+	}
+	return g.ExpFloat64() / rate
+}
+
+/*
+* @notice Gamma() returns a sample from the gamma distribution with the given shape (k) and scale (theta).
+* @dev Uses the method of Marsaglia and Tsang. Shapes below 1 are sampled with shape + 1 and scaled by U^(1/shape).
+ */
+func (g *Generator) Gamma(shape float64, scale float64) float64 {
+	if shape <= 0 || scale <= 0 {
+		panic("Within Gamma(): shape and scale must be positive")
+	}
+	if shape < 1 {
+		return g.Gamma(shape+1, scale) * math.Pow(g.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := g.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := g.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v * scale
+		}
+	}
+}
+
+// Beta() returns a sample from the beta distribution with parameters alpha and beta, as X / (X + Y) of two gamma samples.
+func (g *Generator) Beta(alpha float64, beta float64) float64 {
+	x := g.Gamma(alpha, 1)
+	y := g.Gamma(beta, 1)
+	return x / (x + y)
+}
+
+/*
+* @notice Poisson() returns a sample from the Poisson distribution with the given mean lambda.
+* @dev Small means multiply uniform samples until their product drops below exp(-lambda) (Knuth). Large means use the
+* transformed rejection method of Hormann (PTRS), which takes a constant expected number of samples.
+ */
+func (g *Generator) Poisson(lambda float64) float64 {
+	if lambda < 0 {
+		panic("Within Poisson(): lambda must be non-negative")
+	}
+
+	if lambda < 10 {
+		limit, product, count := math.Exp(-lambda), g.Float64(), 0.0
+		for product > limit {
+			product *= g.Float64()
+			count++
+		}
+		return count
+	}
+
+	slam := math.Sqrt(lambda)
+	loglam := math.Log(lambda)
+	b := 0.931 + 2.53*slam
+	a := -0.059 + 0.02483*b
+	invalpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+
+	for {
+		u := g.Float64() - 0.5
+		v := g.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + lambda + 0.43)
+		if us >= 0.07 && v <= vr {
+			return k
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		lgam, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invalpha)-math.Log(a/(us*us)+b) <= -lambda+k*loglam-lgam {
+			return k
+		}
+	}
+}
+
+// Categorical() returns index i with probability proportional to weights[i]. Weights need not sum to 1.
+func (g *Generator) Categorical(weights []float64) int {
+
+	var total float64
+	for _, w := range weights {
+		if w < 0 {
+			panic("Within Categorical(): weights must be non-negative")
+		}
+		total += w
+	}
+	if total <= 0 {
+		panic("Within Categorical(): weights must have a positive sum")
+	}
+
+	target := g.Float64() * total
+	for i, w := range weights {
+		target -= w
+		if target < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1 // <--- rounding error left target at 0
+}
+
+//============================================================================================================================== Permutations and Choices
+
+// Permutation() returns a random permutation of [0, n).
+func (g *Generator) Permutation(n int) []int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rng.Perm(n)
+}
+
+/*
+* @notice Choice() draws size integers from [0, n). With replace set to false, each integer is drawn at most once, so
+* size must not exceed n.
+ */
+func (g *Generator) Choice(n int, size int, replace bool) []int {
+
+	if replace {
+		choices := make([]int, size)
+		for i := range choices {
+			choices[i] = g.Intn(n)
+		}
+		return choices
+	}
+
+	if size > n {
+		panic("Within Choice(): Cannot choose more elements than n without replacement")
+	}
+	return g.Permutation(n)[:size]
+}
+
+//============================================================================================================================== Random Tensors
+
+// DistributionInitializer fills a Tensor with independent samples. It implements TensorInitializer and Batched_Initializer_Interface.
+type DistributionInitializer struct {
+	sample func() float64
+}
+
+func (di *DistributionInitializer) ValueAt(index int) float64 {
+	return di.sample()
+}
+
+func (di *DistributionInitializer) Execute(shape []int) *Tensor {
+	return InitializeData(shape, di)
+}
+
+// SampleTensor() creates a Tensor of the given shape where each element is a call to sample, with optional batching.
+func SampleTensor(shape []int, sample func() float64, batching bool) *Tensor {
+
+	initializer := &DistributionInitializer{sample: sample}
+
+	var A *Tensor
+	if !batching {
+		A = InitializeData(shape, initializer) // single init
+	} else {
+		A = Batched_Initializer_Operation(initializer, shape) // batched init
+	}
+
+	A.Batched = batching // <-- set batched flag
+	return A
+}
+
+// UniformTensor() samples each element uniformly from [lower, upper).
+func (g *Generator) UniformTensor(shape []int, lower float64, upper float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Uniform(lower, upper) }, batching)
+}
+
+// NormalTensor() samples each element from the normal distribution with the given mean and standard deviation.
+func (g *Generator) NormalTensor(shape []int, mean float64, std float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Normal(mean, std) }, batching)
+}
+
+// TruncatedNormalTensor() samples each element from the normal distribution truncated to [lower, upper].
+func (g *Generator) TruncatedNormalTensor(shape []int, mean float64, std float64, lower float64, upper float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.TruncatedNormal(mean, std, lower, upper) }, batching)
+}
+
+// BernoulliTensor() sets each element to 1 with probability p and 0 otherwise.
+func (g *Generator) BernoulliTensor(shape []int, p float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Bernoulli(p) }, batching)
+}
+
+// ExponentialTensor() samples each element from the exponential distribution with the given rate.
+func (g *Generator) ExponentialTensor(shape []int, rate float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Exponential(rate) }, batching)
+}
+
+// PoissonTensor() samples each element from the Poisson distribution with mean lambda.
+func (g *Generator) PoissonTensor(shape []int, lambda float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Poisson(lambda) }, batching)
+}
+
+// GammaTensor() samples each element from the gamma distribution with the given shape parameter and scale.
+func (g *Generator) GammaTensor(shape []int, k float64, scale float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Gamma(k, scale) }, batching)
+}
+
+// BetaTensor() samples each element from the beta distribution with parameters alpha and beta.
+func (g *Generator) BetaTensor(shape []int, alpha float64, beta float64, batching bool) *Tensor {
+	return SampleTensor(shape, func() float64 { return g.Beta(alpha, beta) }, batching)
+}
+
+//============================================================================================================================== Categorical and Multinomial Tensors
+
+// probabilityRows views probs as rows of class weights, returning the number of rows and classes
+func probabilityRows(probs *Tensor, caller string) (int, int) {
+	switch len(probs.Shape) {
+	case 1:
+		return 1, probs.Shape[0]
+	case 2:
+		return probs.Shape[0], probs.Shape[1]
+	}
+	panic("Within " + caller + "(): probs must be a vector or a 2D [rows, classes] Tensor")
+}
+
+/*
+* @notice CategoricalTensor() draws numSamples class indices, with replacement, from each row of class weights in probs.
+* @param probs: A vector of class weights, or a [rows, classes] Tensor with one distribution per row. Weights need not sum to 1.
+* @return A [numSamples] Tensor for a vector of weights, or a [rows, numSamples] Tensor
+ */
+func (g *Generator) CategoricalTensor(probs *Tensor, numSamples int) *Tensor {
+
+	rows, classes := probabilityRows(probs, "CategoricalTensor")
+
+	shape := []int{rows, numSamples}
+	if len(probs.Shape) == 1 {
+		shape = []int{numSamples}
+	}
+
+	C := &Tensor{Shape: shape, Data: make([]float64, rows*numSamples)}
+	for r := 0; r < rows; r++ {
+		weights := probs.Data[r*classes : (r+1)*classes]
+		for s := 0; s < numSamples; s++ {
+			C.Data[r*numSamples+s] = float64(g.Categorical(weights))
+		}
+	}
+	return C
+}
+
+/*
+* @notice MultinomialTensor() counts how many of trials categorical draws land in each class, for each row of class weights.
+* @return A Tensor with the shape of probs, where each row sums to trials
+ */
+func (g *Generator) MultinomialTensor(trials int, probs *Tensor) *Tensor {
+
+	rows, classes := probabilityRows(probs, "MultinomialTensor")
+
+	C := &Tensor{Shape: append([]int(nil), probs.Shape...), Data: make([]float64, len(probs.Data))}
+	for r := 0; r < rows; r++ {
+		weights := probs.Data[r*classes : (r+1)*classes]
+		for t := 0; t < trials; t++ {
+			C.Data[r*classes+g.Categorical(weights)]++
+		}
+	}
+	return C
+}
+
+//============================================================================================================================== Shuffle(), Dropout()
+
+// Shuffle() returns a copy of A with its elements along the 0'th axis (its rows, or its batch elements) in a random order.
+func (A *Tensor) Shuffle(g *Generator) *Tensor {
+	C := A.IndexSelect(0, g.Permutation(A.Shape[0]), false)
+	C.Batched = A.Batched
+	return C
+}
+
+/*
+* @notice Dropout() zeroes each element of A with probability p and scales the remaining elements by 1 / (1 - p), so that
+* the expected value of each element is unchanged.
+* @dev Dropout() is gradient aware. If A has a populated DataReqGrad slice, each output Value is the input Value multiplied by
+* its mask, so dropped elements pass no gradient back.
+ */
+func (A *Tensor) Dropout(p float64, g *Generator) *Tensor {
+
+	if p < 0 || p >= 1 {
+		panic("Within Dropout(): p must be in [0, 1)")
+	}
+
+	C := newSelectionOutput(append([]int(nil), A.Shape...), A)
+	C.Batched = A.Batched
+
+	scale := 1 / (1 - p)
+	for i, value := range A.Data {
+		mask := 0.0
+		if g.Float64() >= p {
+			mask = scale
+		}
+		C.Data[i] = value * mask
+		if C.DataReqGrad != nil {
+			C.DataReqGrad[i] = A.DataReqGrad[i].Mul(NewValue(mask, nil, "dropout"))
+		}
+	}
+	return C
+}
@@ -0,0 +1,507 @@
+package TG
+
+/*
+* @notice Reductions.go contains cumulative ops along an axis (Cumsum(), Cumprod(), Cummax(), Cummin(), Diff()), reductions
+* over any set of axes (Sum(), Mean(), Var(), Std(), Prod(), Min(), Max(), LogSumExp(), PNorm(), NanSum(), NanMean()), and
+* rolling window reductions along an axis (RollingSum(), RollingMean(), RollingStd()).
+* @dev Every op is built on AxisLaneOperation() from OpAbstractions.go, so lanes are processed in parallel. With batching,
+* axes refer to the axes of each batch element.
+ */
+
+import (
+	"math"
+	"sort"
+)
+
+//============================================================================================================================== Cumulative Ops
+
+// CumulativeOp applies a running combination along an axis. The first output of each lane is its first element.
+type CumulativeOp struct {
+	axis    int
+	combine func(running float64, next float64) float64
+}
+
+func (op CumulativeOp) OutputLength(n int) int { return n }
+
+func (op CumulativeOp) Apply_LaneOp(lane []float64, out []float64) {
+	for k, value := range lane {
+		if k == 0 {
+			out[k] = value
+			continue
+		}
+		out[k] = op.combine(out[k-1], value)
+	}
+}
+
+func (op CumulativeOp) Execute(tensors ...*Tensor) *Tensor {
+	return tensors[0].AxisLaneOperation(op.axis, op)
+}
+
+// cumulative runs a CumulativeOp with optional batching
+func (A *Tensor) cumulative(op CumulativeOp, batching bool) *Tensor {
+	if batching {
+		return BatchedOperation(op, A) // batched op
+	}
+	return op.Execute(A) // single op
+}
+
+// maxPropagatingNaN returns the larger of a and b, or NaN if either is NaN
+func maxPropagatingNaN(a, b float64) float64 {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.NaN()
+	}
+	return math.Max(a, b)
+}
+
+// minPropagatingNaN returns the smaller of a and b, or NaN if either is NaN
+func minPropagatingNaN(a, b float64) float64 {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.NaN()
+	}
+	return math.Min(a, b)
+}
+
+// Cumsum() returns the running sum of A along axis.
+func (A *Tensor) Cumsum(axis int, batching bool) *Tensor {
+	return A.cumulative(CumulativeOp{axis: axis, combine: func(a, b float64) float64 { return a + b }}, batching)
+}
+
+// Cumprod() returns the running product of A along axis.
+func (A *Tensor) Cumprod(axis int, batching bool) *Tensor {
+	return A.cumulative(CumulativeOp{axis: axis, combine: func(a, b float64) float64 { return a * b }}, batching)
+}
+
+// Cummax() returns the running maximum of A along axis. Once a NaN is seen, the rest of the lane is NaN.
+func (A *Tensor) Cummax(axis int, batching bool) *Tensor {
+	return A.cumulative(CumulativeOp{axis: axis, combine: maxPropagatingNaN}, batching)
+}
+
+// Cummin() returns the running minimum of A along axis. Once a NaN is seen, the rest of the lane is NaN.
+func (A *Tensor) Cummin(axis int, batching bool) *Tensor {
+	return A.cumulative(CumulativeOp{axis: axis, combine: minPropagatingNaN}, batching)
+}
+
+//============================================================================================================================== Diff()
+
+type DiffOp struct{ axis int }
+
+func (op DiffOp) OutputLength(n int) int { return max(0, n-1) }
+
+func (op DiffOp) Apply_LaneOp(lane []float64, out []float64) {
+	for k := range out {
+		out[k] = lane[k+1] - lane[k]
+	}
+}
+
+func (op DiffOp) Execute(tensors ...*Tensor) *Tensor {
+	return tensors[0].AxisLaneOperation(op.axis, op)
+}
+
+// Diff() returns the difference between consecutive elements along axis, A[k+1] - A[k]. The axis becomes one shorter.
+func (A *Tensor) Diff(axis int, batching bool) *Tensor {
+
+	diff := DiffOp{axis: axis}
+
+	if batching {
+		return BatchedOperation(diff, A) // batched op
+	}
+	return diff.Execute(A) // single op
+}
+
+//============================================================================================================================== Reductions Over Multiple Axes
+
+// A Reducer collapses a lane of elements into a single value
+type Reducer interface {
+	Reduce(lane []float64) float64
+}
+
+// ReduceOp applies a Reducer over a set of axes. It is a LaneOperation on the single axis the reduced axes are merged into.
+type ReduceOp struct {
+	axes     []int
+	keepdims bool
+	reducer  Reducer
+}
+
+func (op ReduceOp) OutputLength(n int) int { return 1 }
+
+func (op ReduceOp) Apply_LaneOp(lane []float64, out []float64) {
+	out[0] = op.reducer.Reduce(lane)
+}
+
+/*
+* @notice Execute() reduces A over op.axes. An empty axes slice reduces over every axis.
+* @dev The reduced axes are permuted to the end of A and merged into one, so that every lane of the merged axis holds the
+* elements that reduce into one output element.
+ */
+func (op ReduceOp) Execute(tensors ...*Tensor) *Tensor {
+
+	A := tensors[0]
+
+	// determine which axes are reduced
+	reduced := make([]bool, len(A.Shape))
+	if len(op.axes) == 0 {
+		for i := range reduced {
+			reduced[i] = true
+		}
+	}
+	for _, axis := range op.axes {
+		if axis < 0 || axis >= len(A.Shape) {
+			panic("Within ReduceOp.Execute(): Axis out of bounds")
+		}
+		reduced[axis] = true
+	}
+
+	// order the kept axes first, then the reduced axes
+	permutation, keptShape, outShape := []int{}, []int{}, []int{}
+	for i, r := range reduced {
+		if !r {
+			permutation = append(permutation, i)
+			keptShape = append(keptShape, A.Shape[i])
+			outShape = append(outShape, A.Shape[i])
+		} else if op.keepdims {
+			outShape = append(outShape, 1)
+		}
+	}
+	for i, r := range reduced {
+		if r {
+			permutation = append(permutation, i)
+		}
+	}
+
+	permuted := A
+	if !sort.IntsAreSorted(permutation) {
+		permuted = A.Permute(permutation)
+	}
+	merged := &Tensor{Shape: append(append([]int(nil), keptShape...), len(A.Data)/max(1, Product(keptShape))), Data: permuted.Data}
+
+	C := merged.AxisLaneOperation(len(keptShape), op)
+	if len(outShape) == 0 {
+		outShape = []int{1} // <--- a full reduction gives a single element Tensor, like Norm()
+	}
+	C.Shape = outShape
+	C.Batched = A.Batched
+	return C
+}
+
+// reduce runs a ReduceOp with optional batching
+func (A *Tensor) reduce(axes []int, keepdims bool, reducer Reducer, batching bool) *Tensor {
+
+	reduceOp := ReduceOp{axes: axes, keepdims: keepdims, reducer: reducer}
+
+	if batching {
+		return BatchedOperation(reduceOp, A) // batched op
+	}
+	return reduceOp.Execute(A) // single op
+}
+
+//============================================================================================================================== Reducers
+
+type SumReducer struct{}
+type MeanReducer struct{}
+type VarReducer struct{}
+type ProdReducer struct{}
+type MinReducer struct{}
+type MaxReducer struct{}
+type LogSumExpReducer struct{}
+type PNormReducer struct{ P float64 }
+type NanSumReducer struct{}
+type NanMeanReducer struct{}
+
+func (r SumReducer) Reduce(lane []float64) float64 {
+	var sum float64
+	for _, value := range lane {
+		sum += value
+	}
+	return sum
+}
+
+func (r MeanReducer) Reduce(lane []float64) float64 {
+	return SumReducer{}.Reduce(lane) / float64(len(lane))
+}
+
+// Reduce computes the population variance, matching Var_All() and Var_Axis()
+func (r VarReducer) Reduce(lane []float64) float64 {
+	mean := MeanReducer{}.Reduce(lane)
+	var variance float64
+	for _, value := range lane {
+		variance += (value - mean) * (value - mean)
+	}
+	return variance / float64(len(lane))
+}
+
+func (r ProdReducer) Reduce(lane []float64) float64 {
+	product := 1.0
+	for _, value := range lane {
+		product *= value
+	}
+	return product
+}
+
+func (r MinReducer) Reduce(lane []float64) float64 {
+	min := math.Inf(1)
+	for _, value := range lane {
+		min = minPropagatingNaN(min, value)
+	}
+	return min
+}
+
+func (r MaxReducer) Reduce(lane []float64) float64 {
+	max := math.Inf(-1)
+	for _, value := range lane {
+		max = maxPropagatingNaN(max, value)
+	}
+	return max
+}
+
+// Reduce computes log(sum(exp(x))) after subtracting the maximum, so that large elements do not overflow
+func (r LogSumExpReducer) Reduce(lane []float64) float64 {
+	max := MaxReducer{}.Reduce(lane)
+	if math.IsInf(max, 0) || math.IsNaN(max) {
+		return max
+	}
+	var sum float64
+	for _, value := range lane {
+		sum += math.Exp(value - max)
+	}
+	return max + math.Log(sum)
+}
+
+// Reduce computes (sum |x|^p)^(1/p). P = +Inf gives the largest absolute value and P = 0 counts the nonzero elements.
+func (r PNormReducer) Reduce(lane []float64) float64 {
+	switch {
+	case math.IsInf(r.P, 1):
+		norm := 0.0
+		for _, value := range lane {
+			norm = maxPropagatingNaN(norm, math.Abs(value))
+		}
+		return norm
+	case r.P == 0:
+		var count float64
+		for _, value := range lane {
+			if value != 0 {
+				count++
+			}
+		}
+		return count
+	case r.P == 1:
+		var norm float64
+		for _, value := range lane {
+			norm += math.Abs(value)
+		}
+		return norm
+	case r.P == 2:
+		var norm float64
+		for _, value := range lane {
+			norm += value * value
+		}
+		return math.Sqrt(norm)
+	}
+
+	var norm float64
+	for _, value := range lane {
+		norm += math.Pow(math.Abs(value), r.P)
+	}
+	return math.Pow(norm, 1/r.P)
+}
+
+func (r NanSumReducer) Reduce(lane []float64) float64 {
+	var sum float64
+	for _, value := range lane {
+		if !math.IsNaN(value) {
+			sum += value
+		}
+	}
+	return sum
+}
+
+// Reduce averages the elements that are not NaN. A lane of only NaNs has a NaN mean.
+func (r NanMeanReducer) Reduce(lane []float64) float64 {
+	var sum, count float64
+	for _, value := range lane {
+		if !math.IsNaN(value) {
+			sum += value
+			count++
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return sum / count
+}
+
+//============================================================================================================================== Public Reductions
+
+/*
+* @notice Each of the following reduces A over the given axes. An empty (or nil) axes slice reduces over every axis.
+* @dev With keepdims set to true the reduced axes are kept with length 1, so the result can be broadcast against A.
+* Otherwise they are removed, and reducing every axis gives a Tensor of Shape [1].
+ */
+
+// Sum() sums A over axes.
+func (A *Tensor) Sum(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, SumReducer{}, batching)
+}
+
+// Mean() averages A over axes.
+func (A *Tensor) Mean(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, MeanReducer{}, batching)
+}
+
+// Var() computes the population variance of A over axes.
+func (A *Tensor) Var(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, VarReducer{}, batching)
+}
+
+// Std() computes the population standard deviation of A over axes.
+func (A *Tensor) Std(axes []int, keepdims bool, batching bool) *Tensor {
+	C := A.reduce(axes, keepdims, VarReducer{}, batching)
+	for i := range C.Data {
+		C.Data[i] = math.Sqrt(C.Data[i])
+	}
+	return C
+}
+
+// Prod() multiplies the elements of A over axes.
+func (A *Tensor) Prod(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, ProdReducer{}, batching)
+}
+
+// Min() finds the smallest element of A over axes. NaNs propagate.
+func (A *Tensor) Min(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, MinReducer{}, batching)
+}
+
+// Max() finds the largest element of A over axes. NaNs propagate.
+func (A *Tensor) Max(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, MaxReducer{}, batching)
+}
+
+// LogSumExp() computes log(sum(exp(A))) over axes without overflowing for large elements.
+func (A *Tensor) LogSumExp(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, LogSumExpReducer{}, batching)
+}
+
+// PNorm() computes the p-norm of A over axes. p = math.Inf(1) gives the max norm and p = 0 counts the nonzero elements.
+func (A *Tensor) PNorm(p float64, axes []int, keepdims bool, batching bool) *Tensor {
+	if p < 0 {
+		panic("Within PNorm(): p must be non-negative")
+	}
+	return A.reduce(axes, keepdims, PNormReducer{P: p}, batching)
+}
+
+// NanSum() sums A over axes, treating NaNs as zero.
+func (A *Tensor) NanSum(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, NanSumReducer{}, batching)
+}
+
+// NanMean() averages A over axes, ignoring NaNs.
+func (A *Tensor) NanMean(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, NanMeanReducer{}, batching)
+}
+
+//============================================================================================================================== Rolling Windows
+
+type rollingKind int
+
+const (
+	rollingSum rollingKind = iota
+	rollingMean
+	rollingStd
+)
+
+// RollingOp reduces each window of consecutive elements along an axis. Only complete windows are produced.
+type RollingOp struct {
+	axis   int
+	window int
+	kind   rollingKind
+}
+
+func (op RollingOp) OutputLength(n int) int {
+	if op.window < 1 || op.window > n {
+		panic("Within RollingOp: window must be between 1 and the length of the axis")
+	}
+	return n - op.window + 1
+}
+
+func (op RollingOp) Apply_LaneOp(lane []float64, out []float64) {
+
+	if op.kind == rollingStd {
+		for k := range out {
+			out[k] = math.Sqrt(VarReducer{}.Reduce(lane[k : k+op.window]))
+		}
+		return
+	}
+
+	// slide the sum across the lane, adding the element entering the window and removing the one leaving it. The
+	// finite elements are summed with Neumaier compensation so cancellation does not accumulate along the lane, and
+	// windows holding a NaN or Inf are summed directly so the non-finite value does not outlive its window.
+	sum, compensation, nonFinite := 0.0, 0.0, 0
+	add := func(x float64) {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return
+		}
+		t := sum + x
+		if math.Abs(sum) >= math.Abs(x) {
+			compensation += (sum - t) + x
+		} else {
+			compensation += (x - t) + sum
+		}
+		sum = t
+	}
+	for _, x := range lane[:op.window] {
+		add(x)
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			nonFinite++
+		}
+	}
+	for k := range out {
+		if k > 0 {
+			entering, leaving := lane[k+op.window-1], lane[k-1]
+			add(entering)
+			add(-leaving)
+			if math.IsNaN(entering) || math.IsInf(entering, 0) {
+				nonFinite++
+			}
+			if math.IsNaN(leaving) || math.IsInf(leaving, 0) {
+				nonFinite--
+			}
+		}
+		if nonFinite > 0 {
+			out[k] = SumReducer{}.Reduce(lane[k : k+op.window])
+		} else {
+			out[k] = sum + compensation
+		}
+		if op.kind == rollingMean {
+			out[k] /= float64(op.window)
+		}
+	}
+}
+
+func (op RollingOp) Execute(tensors ...*Tensor) *Tensor {
+	return tensors[0].AxisLaneOperation(op.axis, op)
+}
+
+// rolling runs a RollingOp with optional batching
+func (A *Tensor) rolling(op RollingOp, batching bool) *Tensor {
+	if batching {
+		return BatchedOperation(op, A) // batched op
+	}
+	return op.Execute(A) // single op
+}
+
+/*
+* @notice RollingSum(), RollingMean() and RollingStd() reduce each window of window consecutive elements along axis.
+* @dev Output element k covers elements [k, k + window) of the lane, so the axis becomes window - 1 elements shorter.
+* RollingStd() is the population standard deviation of each window.
+ */
+func (A *Tensor) RollingSum(window int, axis int, batching bool) *Tensor {
+	return A.rolling(RollingOp{axis: axis, window: window, kind: rollingSum}, batching)
+}
+
+func (A *Tensor) RollingMean(window int, axis int, batching bool) *Tensor {
+	return A.rolling(RollingOp{axis: axis, window: window, kind: rollingMean}, batching)
+}
+
+func (A *Tensor) RollingStd(window int, axis int, batching bool) *Tensor {
+	return A.rolling(RollingOp{axis: axis, window: window, kind: rollingStd}, batching)
+}
@@ -0,0 +1,340 @@
+package TG
+
+/*
+* @notice Safetensors.go contains a reader and writer for the safetensors format, used to exchange model weights with other tools.
+* @dev A safetensors file is an 8 byte little endian header length N, a JSON header of N bytes, then a byte buffer holding
+* every tensor's data back to back. The header maps each tensor name to its dtype, shape and [begin, end) byte offsets
+* into the buffer. The optional "__metadata__" entry holds free form string metadata, which is passed through untouched.
+* @dev Reading accepts the F64, F32, F16, BF16, signed and unsigned integer and BOOL dtypes, all converted to float64.
+* Tensors are written as F64 or F32.
+* @dev MmapSafetensors() maps a file into memory. F64 tensors that are aligned in the file then share the mapped memory
+* rather than being copied, so loading large checkpoints is nearly free.
+ */
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"unsafe"
+)
+
+const safetensorsMetadataKey = "__metadata__"
+
+// safetensorsEntry is the header entry of a single tensor
+type safetensorsEntry struct {
+	DType       string   `json:"dtype"`
+	Shape       []int    `json:"shape"`
+	DataOffsets [2]int64 `json:"data_offsets"`
+}
+
+// safetensorsItemSizes holds the size in bytes of each supported dtype
+var safetensorsItemSizes = map[string]int{
+	"F64": 8, "F32": 4, "F16": 2, "BF16": 2,
+	"I64": 8, "I32": 4, "I16": 2, "I8": 1,
+	"U64": 8, "U32": 4, "U16": 2, "U8": 1,
+	"BOOL": 1,
+}
+
+// hostLittleEndian is true when float64s are stored little endian in memory, which zero copy loading requires
+var hostLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+//===================================================================================================================== Reading
+
+// parseSafetensorsHeader parses the JSON header, checking every entry against the size of the data buffer
+func parseSafetensorsHeader(header []byte, dataSize int64) (map[string]safetensorsEntry, map[string]string, error) {
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(header, &raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	entries := map[string]safetensorsEntry{}
+	var metadata map[string]string
+	for name, message := range raw {
+		if name == safetensorsMetadataKey {
+			if err := json.Unmarshal(message, &metadata); err != nil {
+				return nil, nil, fmt.Errorf("invalid metadata: %w", err)
+			}
+			continue
+		}
+
+		var entry safetensorsEntry
+		if err := json.Unmarshal(message, &entry); err != nil {
+			return nil, nil, fmt.Errorf("invalid entry %q: %w", name, err)
+		}
+		itemSize, ok := safetensorsItemSizes[entry.DType]
+		if !ok {
+			return nil, nil, fmt.Errorf("tensor %q has unsupported dtype %q", name, entry.DType)
+		}
+		numElements, ok := checkedProduct(entry.Shape, itemSize) // <--- rejects negative dims and sizes that overflow
+		if !ok {
+			return nil, nil, fmt.Errorf("tensor %q has invalid shape %v", name, entry.Shape)
+		}
+		begin, end := entry.DataOffsets[0], entry.DataOffsets[1]
+		if begin < 0 || end < begin || end > dataSize || end-begin != int64(numElements*itemSize) {
+			return nil, nil, fmt.Errorf("tensor %q has invalid data offsets %v", name, entry.DataOffsets)
+		}
+		entries[name] = entry
+	}
+	return entries, metadata, nil
+}
+
+// decodeSafetensor converts the little endian data of a single tensor to a float64 Tensor
+func decodeSafetensor(entry safetensorsEntry, data []byte) *Tensor {
+
+	A := &Tensor{Shape: append([]int{}, entry.Shape...), Data: make([]float64, Product(entry.Shape))}
+	itemSize := safetensorsItemSizes[entry.DType]
+	for i := range A.Data {
+		b := data[i*itemSize:]
+		switch entry.DType {
+		case "F64":
+			A.Data[i] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		case "F32":
+			A.Data[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		case "F16":
+			A.Data[i] = float16ToFloat64(binary.LittleEndian.Uint16(b))
+		case "BF16":
+			A.Data[i] = float64(math.Float32frombits(uint32(binary.LittleEndian.Uint16(b)) << 16))
+		case "I64":
+			A.Data[i] = float64(int64(binary.LittleEndian.Uint64(b)))
+		case "I32":
+			A.Data[i] = float64(int32(binary.LittleEndian.Uint32(b)))
+		case "I16":
+			A.Data[i] = float64(int16(binary.LittleEndian.Uint16(b)))
+		case "I8":
+			A.Data[i] = float64(int8(b[0]))
+		case "U64":
+			A.Data[i] = float64(binary.LittleEndian.Uint64(b))
+		case "U32":
+			A.Data[i] = float64(binary.LittleEndian.Uint32(b))
+		case "U16":
+			A.Data[i] = float64(binary.LittleEndian.Uint16(b))
+		default: // <--- U8 and BOOL
+			A.Data[i] = float64(b[0])
+		}
+	}
+	return A
+}
+
+// readSafetensorsHeaderLength reads the 8 byte header length, rejecting lengths that cannot be a real header
+func readSafetensorsHeaderLength(prefix []byte) (int64, error) {
+	n := binary.LittleEndian.Uint64(prefix)
+	if n > 100<<20 {
+		return 0, fmt.Errorf("header length %d is too large", n)
+	}
+	return int64(n), nil
+}
+
+/*
+* @notice ReadSafetensors() reads every tensor in a safetensors stream, keyed by name, along with the file's metadata.
+* @dev Every tensor is copied into a new Tensor. Use MmapSafetensors() to load a file without copying.
+ */
+func ReadSafetensors(r io.Reader) (map[string]*Tensor, map[string]string, error) {
+
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, nil, fmt.Errorf("ReadSafetensors(): reading header length: %w", err)
+	}
+	headerLength, err := readSafetensorsHeaderLength(prefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ReadSafetensors(): %w", err)
+	}
+	header := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("ReadSafetensors(): reading header: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ReadSafetensors(): reading data: %w", err)
+	}
+
+	entries, metadata, err := parseSafetensorsHeader(header, int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("ReadSafetensors(): %w", err)
+	}
+	tensors := make(map[string]*Tensor, len(entries))
+	for name, entry := range entries {
+		tensors[name] = decodeSafetensor(entry, data[entry.DataOffsets[0]:entry.DataOffsets[1]])
+	}
+	return tensors, metadata, nil
+}
+
+//===================================================================================================================== Memory Mapping
+
+// SafetensorsFile is a memory mapped safetensors file
+type SafetensorsFile struct {
+	Tensors  map[string]*Tensor
+	Metadata map[string]string
+	mapped   []byte
+}
+
+/*
+* @notice MmapSafetensors() memory maps a safetensors file and loads its tensors.
+* @dev Aligned F64 tensors share the mapped memory on little endian hosts, so their Data is backed by the file rather than
+* copied. The mapping is private: writing to Data never changes the file. Every other tensor is converted into a new Tensor.
+* @dev The Data of shared Tensors is only valid until Close() is called. On platforms without mmap, the file is read into memory.
+ */
+func MmapSafetensors(fileName string) (*SafetensorsFile, error) {
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mapped, err := mmapFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("MmapSafetensors(): %w", err)
+	}
+	f := &SafetensorsFile{mapped: mapped}
+
+	if len(mapped) < 8 {
+		f.Close()
+		return nil, fmt.Errorf("MmapSafetensors(): file is too short")
+	}
+	headerLength, err := readSafetensorsHeaderLength(mapped[:8])
+	if err != nil || 8+headerLength > int64(len(mapped)) {
+		f.Close()
+		return nil, fmt.Errorf("MmapSafetensors(): invalid header length")
+	}
+	data := mapped[8+headerLength:]
+	entries, metadata, err := parseSafetensorsHeader(mapped[8:8+headerLength], int64(len(data)))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("MmapSafetensors(): %w", err)
+	}
+
+	f.Tensors, f.Metadata = make(map[string]*Tensor, len(entries)), metadata
+	for name, entry := range entries {
+		bytes := data[entry.DataOffsets[0]:entry.DataOffsets[1]]
+		if entry.DType == "F64" && hostLittleEndian && len(bytes) > 0 && uintptr(unsafe.Pointer(&bytes[0]))%8 == 0 {
+			f.Tensors[name] = &Tensor{
+				Shape: append([]int{}, entry.Shape...),
+				Data:  unsafe.Slice((*float64)(unsafe.Pointer(&bytes[0])), len(bytes)/8), // <--- zero copy
+			}
+			continue
+		}
+		f.Tensors[name] = decodeSafetensor(entry, bytes)
+	}
+	return f, nil
+}
+
+// Close() unmaps the file. Tensors that share the mapped memory must not be used afterwards.
+func (f *SafetensorsFile) Close() error {
+	if f.mapped == nil {
+		return nil
+	}
+	mapped := f.mapped
+	f.mapped = nil
+	return munmapFile(mapped)
+}
+
+//===================================================================================================================== Writing
+
+/*
+* @notice WriteSafetensors() writes named Tensors and optional metadata to w in the safetensors format.
+* @dev Tensors are stored in order of name as F64, or as F32 when asFloat32 is true. The header is padded with spaces so that
+* the data buffer starts 8 byte aligned, which allows F64 tensors to be memory mapped without copying.
+* @dev Gradient tracked Tensors without Data are written from the Scalars of DataReqGrad. Nothing is written when a Tensor does
+* not hold one element for every position of its Shape.
+ */
+func WriteSafetensors(w io.Writer, tensors map[string]*Tensor, metadata map[string]string, asFloat32 bool) error {
+
+	names := make([]string, 0, len(tensors))
+	values := make(map[string][]float64, len(tensors))
+	for name, A := range tensors {
+		if name == safetensorsMetadataKey {
+			return fmt.Errorf("WriteSafetensors(): %q is reserved for metadata", name)
+		}
+		stored, err := storedValues(A, "WriteSafetensors")
+		if err != nil {
+			return fmt.Errorf("WriteSafetensors(): tensor %q: %w", name, err)
+		}
+		names, values[name] = append(names, name), stored
+	}
+	sort.Strings(names)
+
+	dtype, itemSize := "F64", 8
+	if asFloat32 {
+		dtype, itemSize = "F32", 4
+	}
+
+	// the header, with each tensor's offsets into the data buffer
+	header := map[string]any{}
+	if metadata != nil {
+		header[safetensorsMetadataKey] = metadata
+	}
+	offset := int64(0)
+	for _, name := range names {
+		size := int64(len(values[name]) * itemSize)
+		header[name] = safetensorsEntry{DType: dtype, Shape: append([]int{}, tensors[name].Shape...), DataOffsets: [2]int64{offset, offset + size}}
+		offset += size
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	for (8+len(headerJSON))%8 != 0 {
+		headerJSON = append(headerJSON, ' ')
+	}
+
+	prefix := binary.LittleEndian.AppendUint64(nil, uint64(len(headerJSON)))
+	if _, err := w.Write(append(prefix, headerJSON...)); err != nil {
+		return err
+	}
+
+	// the data buffer, a chunk at a time
+	chunk := make([]byte, 0, 4096*itemSize)
+	for _, name := range names {
+		data := values[name]
+		for start := 0; start < len(data); start += 4096 {
+			chunk = chunk[:0]
+			for _, value := range data[start:min(start+4096, len(data))] {
+				if asFloat32 {
+					chunk = binary.LittleEndian.AppendUint32(chunk, math.Float32bits(float32(value)))
+				} else {
+					chunk = binary.LittleEndian.AppendUint64(chunk, math.Float64bits(value))
+				}
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadSafetensors() loads every tensor in a safetensors file, keyed by name, along with the file's metadata.
+func LoadSafetensors(fileName string) (map[string]*Tensor, map[string]string) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	tensors, metadata, err := ReadSafetensors(file)
+	if err != nil {
+		panic(err)
+	}
+	return tensors, metadata
+}
+
+// SaveSafetensors() saves named Tensors and optional metadata to a safetensors file as F64.
+func SaveSafetensors(tensors map[string]*Tensor, metadata map[string]string, fileName string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	if err := WriteSafetensors(file, tensors, metadata, false); err != nil {
+		panic(err)
+	}
+}
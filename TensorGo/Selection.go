@@ -0,0 +1,550 @@
+package TG
+
+/*
+* @notice Selection.go contains comparison ops that produce mask Tensors, and ops that select or overwrite elements of a
+* Tensor using masks or integer indices: Where(), MaskedSelect(), MaskedFill(), Take(), IndexSelect(), Gather(), Scatter()
+* and ScatterAdd().
+* @dev Masks are ordinary Tensors where 1 is true and 0 is false. Any nonzero element of a mask is treated as true.
+* Integer indices held in a Tensor (for Gather() and Scatter()) must be whole numbers and may be negative to count from the end.
+* @dev Selection ops are gradient aware. When an input has a populated DataReqGrad slice, the selected elements of the output
+* share that input's Value pointers, so calling Backward() on anything computed from the output propagates gradients back to
+* exactly the elements that were selected. Elements that come from a constant (such as MaskedFill()) get a new leaf Value.
+* Batched calls keep the Value pointers of each batch element in the same way.
+ */
+
+import (
+	"math"
+)
+
+//============================================================================================================================== Comparison Ops
+
+// boolToFloat converts a boolean into the 1 or 0 stored in a mask
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Each comparison implements the _ElementwiseOp interface from OpAbstractions.go as well as IBatching
+type EWGreater struct{}
+type EWGreaterEqual struct{}
+type EWLess struct{}
+type EWLessEqual struct{}
+type EWEqual struct{}
+type EWNotEqual struct{}
+type EWLogicalAnd struct{}
+type EWLogicalOr struct{}
+
+func (op EWGreater) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a > b)
+}
+
+func (op EWGreaterEqual) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a >= b)
+}
+
+func (op EWLess) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a < b)
+}
+
+func (op EWLessEqual) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a <= b)
+}
+
+func (op EWEqual) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a == b)
+}
+
+func (op EWNotEqual) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a != b)
+}
+
+func (op EWLogicalAnd) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a != 0 && b != 0)
+}
+
+func (op EWLogicalOr) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(a != 0 || b != 0)
+}
+
+func (op EWGreater) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWGreaterEqual) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWLess) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWLessEqual) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWEqual) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWNotEqual) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWLogicalAnd) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+func (op EWLogicalOr) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+// compare runs a comparison op with optional batching
+func compare(A *Tensor, B *Tensor, op IBatching, batching bool) *Tensor {
+	if batching {
+		return BatchedOperation(op, A, B) // batched op
+	}
+	return op.Execute(A, B) // single op
+}
+
+// Greater() returns a mask that is 1 where A > B
+func Greater(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWGreater{}, batching)
+}
+
+// GreaterEqual() returns a mask that is 1 where A >= B
+func GreaterEqual(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWGreaterEqual{}, batching)
+}
+
+// Less() returns a mask that is 1 where A < B
+func Less(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWLess{}, batching)
+}
+
+// LessEqual() returns a mask that is 1 where A <= B
+func LessEqual(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWLessEqual{}, batching)
+}
+
+// Equal() returns a mask that is 1 where A == B
+func Equal(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWEqual{}, batching)
+}
+
+// NotEqual() returns a mask that is 1 where A != B
+func NotEqual(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWNotEqual{}, batching)
+}
+
+// LogicalAnd() returns a mask that is 1 where both A and B are nonzero
+func LogicalAnd(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWLogicalAnd{}, batching)
+}
+
+// LogicalOr() returns a mask that is 1 where either A or B is nonzero
+func LogicalOr(A *Tensor, B *Tensor, batching bool) *Tensor {
+	return compare(A, B, EWLogicalOr{}, batching)
+}
+
+//============================================================================================================================== IsClose()
+
+// EWIsClose compares elements within a tolerance of |a - b| <= Atol + Rtol * |b|
+type EWIsClose struct{ Rtol, Atol float64 }
+
+func (op EWIsClose) ExecuteElementwiseOp(a, b float64) float64 {
+	return boolToFloat(math.Abs(a-b) <= op.Atol+op.Rtol*math.Abs(b))
+}
+
+func (op EWIsClose) Execute(tensors ...*Tensor) *Tensor {
+	return ElementwiseOp(tensors[0], tensors[1], op)
+}
+
+/*
+* @notice IsClose() returns a mask that is 1 where A and B are equal within a tolerance, |A - B| <= atol + rtol * |B|
+* @dev NaN elements are never close to anything, including other NaNs.
+ */
+func IsClose(A *Tensor, B *Tensor, rtol float64, atol float64, batching bool) *Tensor {
+	return compare(A, B, EWIsClose{Rtol: rtol, Atol: atol}, batching)
+}
+
+//============================================================================================================================== Unary Mask Ops
+
+// LogicalNot() returns a mask that is 1 where A is zero
+func LogicalNot(A *Tensor) *Tensor {
+	mask := &Tensor{Shape: append([]int(nil), A.Shape...), Data: make([]float64, len(A.Data)), Batched: A.Batched}
+	for i, value := range A.Data {
+		mask.Data[i] = boolToFloat(value == 0)
+	}
+	return mask
+}
+
+// IsNaN() returns a mask that is 1 where A is NaN. This is useful with MaskedFill() to clean missing data.
+func IsNaN(A *Tensor) *Tensor {
+	mask := &Tensor{Shape: append([]int(nil), A.Shape...), Data: make([]float64, len(A.Data)), Batched: A.Batched}
+	for i, value := range A.Data {
+		mask.Data[i] = boolToFloat(math.IsNaN(value))
+	}
+	return mask
+}
+
+//============================================================================================================================== Gradient Helpers
+
+// tracksGrad reports whether A has a populated DataReqGrad slice whose Values should be carried into selection outputs
+func tracksGrad(A *Tensor) bool {
+	return A.RequireGrad && len(A.DataReqGrad) == len(A.Data) && len(A.DataReqGrad) > 0 && A.DataReqGrad[0] != nil
+}
+
+// newSelectionOutput creates an output Tensor, allocating a DataReqGrad slice only when one of the sources tracks gradients
+func newSelectionOutput(shape []int, sources ...*Tensor) *Tensor {
+	C := &Tensor{Shape: shape, Data: make([]float64, Product(shape))}
+	for _, source := range sources {
+		if tracksGrad(source) {
+			C.DataReqGrad = make([]*Value, len(C.Data))
+			C.RequireGrad = true
+			break
+		}
+	}
+	return C
+}
+
+// set stores element i of source at index dst of C, sharing the source's Value when C tracks gradients
+func (C *Tensor) set(dst int, source *Tensor, i int, op string) {
+	C.Data[dst] = source.Data[i]
+	if C.DataReqGrad != nil {
+		if tracksGrad(source) {
+			C.DataReqGrad[dst] = source.DataReqGrad[i]
+		} else {
+			C.DataReqGrad[dst] = NewValue(source.Data[i], nil, op)
+		}
+	}
+}
+
+// normalizeIndex converts a possibly negative index into [0, n), panicking if it is out of bounds
+func normalizeIndex(index int, n int, caller string) int {
+	if index < 0 {
+		index += n
+	}
+	if index < 0 || index >= n {
+		panic("Within " + caller + "(): Index out of bounds")
+	}
+	return index
+}
+
+// indexAt reads element i of an index Tensor as an integer, panicking if it is not a whole number
+func indexAt(index *Tensor, i int, caller string) int {
+	value := index.Data[i]
+	if value != math.Trunc(value) {
+		panic("Within " + caller + "(): Index Tensors must contain whole numbers")
+	}
+	return int(value)
+}
+
+// checkAxis panics if axis is not a valid axis of A
+func checkAxis(A *Tensor, axis int, caller string) {
+	if axis < 0 || axis >= len(A.Shape) {
+		panic("Within " + caller + "(): Axis out of bounds")
+	}
+}
+
+//============================================================================================================================== Where()
+
+type WhereOp struct{}
+
+func (op WhereOp) Execute(tensors ...*Tensor) *Tensor {
+
+	cond, A, B := tensors[0], tensors[1], tensors[2]
+	if !Same_Shape(cond, A) || !Same_Shape(A, B) {
+		panic("Within Where(): cond, A and B must have the same shape")
+	}
+
+	C := newSelectionOutput(append([]int(nil), A.Shape...), A, B)
+	for i, c := range cond.Data {
+		if c != 0 {
+			C.set(i, A, i, "where")
+		} else {
+			C.set(i, B, i, "where")
+		}
+	}
+	return C
+}
+
+/*
+* @notice Where() returns a Tensor with the elements of A where cond is nonzero, and the elements of B elsewhere.
+* @dev Gradients flow to whichever of A or B each element was taken from.
+ */
+func Where(cond *Tensor, A *Tensor, B *Tensor, batching bool) *Tensor {
+
+	where := WhereOp{}
+
+	if batching {
+		return BatchedOperation(where, cond, A, B) // batched op
+	}
+	return where.Execute(cond, A, B) // single op
+}
+
+//============================================================================================================================== MaskedSelect(), MaskedFill()
+
+/*
+* @notice MaskedSelect() returns a 1D Tensor of the elements of A where mask is nonzero, in row major order.
+* @dev Because the length of the output depends on the mask, MaskedSelect() has no batched form.
+ */
+func MaskedSelect(A *Tensor, mask *Tensor) *Tensor {
+
+	if !Same_Shape(A, mask) {
+		panic("Within MaskedSelect(): A and mask must have the same shape")
+	}
+
+	selected := []int{}
+	for i, m := range mask.Data {
+		if m != 0 {
+			selected = append(selected, i)
+		}
+	}
+
+	C := newSelectionOutput([]int{len(selected)}, A)
+	for dst, src := range selected {
+		C.set(dst, A, src, "masked_select")
+	}
+	return C
+}
+
+type MaskedFillOp struct{ value float64 }
+
+func (op MaskedFillOp) Execute(tensors ...*Tensor) *Tensor {
+
+	A, mask := tensors[0], tensors[1]
+	if !Same_Shape(A, mask) {
+		panic("Within MaskedFill(): A and mask must have the same shape")
+	}
+
+	fill := &Tensor{Shape: []int{1}, Data: []float64{op.value}}
+
+	C := newSelectionOutput(append([]int(nil), A.Shape...), A)
+	for i, m := range mask.Data {
+		if m != 0 {
+			C.set(i, fill, 0, "masked_fill") // <--- each filled element is its own leaf Value
+		} else {
+			C.set(i, A, i, "masked_fill")
+		}
+	}
+	return C
+}
+
+// MaskedFill() returns a copy of A with value written wherever mask is nonzero. Filled elements receive no gradient.
+func (A *Tensor) MaskedFill(mask *Tensor, value float64, batching bool) *Tensor {
+
+	maskedFill := MaskedFillOp{value: value}
+
+	if batching {
+		return BatchedOperation(maskedFill, A, mask) // batched op
+	}
+	return maskedFill.Execute(A, mask) // single op
+}
+
+//============================================================================================================================== Take(), IndexSelect()
+
+/*
+* @notice Take() treats A as a flat 1D Tensor and returns the elements at the given indices, in the order given.
+* @dev Indices may repeat and may be negative to count from the end. The output has Shape [len(indices)].
+ */
+func (A *Tensor) Take(indices []int) *Tensor {
+
+	C := newSelectionOutput([]int{len(indices)}, A)
+	for dst, index := range indices {
+		C.set(dst, A, normalizeIndex(index, len(A.Data), "Take"), "take")
+	}
+	return C
+}
+
+type IndexSelectOp struct {
+	axis    int
+	indices []int
+}
+
+func (op IndexSelectOp) Execute(tensors ...*Tensor) *Tensor {
+
+	A := tensors[0]
+	checkAxis(A, op.axis, "IndexSelect")
+
+	shape := append([]int(nil), A.Shape...)
+	shape[op.axis] = len(op.indices)
+	C := newSelectionOutput(shape, A)
+
+	// view A as [outer, axis, inner] so that each selected slice is a contiguous run of inner elements
+	outer := Product(A.Shape[:op.axis])
+	inner := Product(A.Shape[op.axis+1:])
+	axisLen := A.Shape[op.axis]
+
+	dst := 0
+	for o := 0; o < outer; o++ {
+		for _, index := range op.indices {
+			src := (o*axisLen + normalizeIndex(index, axisLen, "IndexSelect")) * inner
+			for k := 0; k < inner; k++ {
+				C.set(dst, A, src+k, "index_select")
+				dst++
+			}
+		}
+	}
+	return C
+}
+
+/*
+* @notice IndexSelect() selects the given indices along an axis of A. For example, IndexSelect(0, []int{2, 0}) on a matrix
+* returns its third and first rows. Indices may repeat and may be negative.
+* @dev When batching, axis refers to the axes of each batch element.
+ */
+func (A *Tensor) IndexSelect(axis int, indices []int, batching bool) *Tensor {
+
+	indexSelect := IndexSelectOp{axis: axis, indices: indices}
+
+	if batching {
+		return BatchedOperation(indexSelect, A) // batched op
+	}
+	return indexSelect.Execute(A) // single op
+}
+
+//============================================================================================================================== Gather()
+
+// forEachIndexed calls fn for each element of index with its flat position and the flat position in A it addresses along axis
+func forEachIndexed(A *Tensor, axis int, index *Tensor, caller string, fn func(i int, target int)) {
+
+	checkAxis(A, axis, caller)
+	if len(index.Shape) != len(A.Shape) {
+		panic("Within " + caller + "(): index must have the same number of dimensions as A")
+	}
+	for d := range index.Shape {
+		if d != axis && index.Shape[d] > A.Shape[d] {
+			panic("Within " + caller + "(): index cannot be larger than A along any axis other than axis")
+		}
+	}
+
+	aStrides := rowMajorStrides(A.Shape)
+	position := make([]int, len(index.Shape))
+
+	for i := range index.Data {
+
+		// compute the flat position in A, replacing the coordinate along axis with the index value
+		target := 0
+		for d, p := range position {
+			if d == axis {
+				p = normalizeIndex(indexAt(index, i, caller), A.Shape[axis], caller)
+			}
+			target += p * aStrides[d]
+		}
+		fn(i, target)
+
+		// advance the position within index in row major order
+		for d := len(position) - 1; d >= 0; d-- {
+			position[d]++
+			if position[d] < index.Shape[d] {
+				break
+			}
+			position[d] = 0
+		}
+	}
+}
+
+type GatherOp struct{ axis int }
+
+func (op GatherOp) Execute(tensors ...*Tensor) *Tensor {
+	A, index := tensors[0], tensors[1]
+	C := newSelectionOutput(append([]int(nil), index.Shape...), A)
+	forEachIndexed(A, op.axis, index, "Gather", func(i int, target int) {
+		C.set(i, A, target, "gather")
+	})
+	return C
+}
+
+/*
+* @notice Gather() collects elements of A along an axis using an index Tensor. For a 2D A and axis 1:
+*
+*     output[i][j] = A[i][index[i][j]]
+*
+* @dev index must have the same number of dimensions as A, and the output has the shape of index. For example, with A holding
+* the log probabilities of a batch of predictions and index holding their labels with shape [batch, 1], Gather(1, labels)
+* selects the log probability of each label, which is the core of a negative log likelihood loss.
+* @dev When batching, axis refers to the axes of each batch element.
+ */
+func (A *Tensor) Gather(axis int, index *Tensor, batching bool) *Tensor {
+
+	gather := GatherOp{axis: axis}
+
+	if batching {
+		return BatchedOperation(gather, A, index) // batched op
+	}
+	return gather.Execute(A, index) // single op
+}
+
+//============================================================================================================================== Scatter(), ScatterAdd()
+
+type ScatterOp struct {
+	axis int
+	add  bool
+}
+
+func (op ScatterOp) Execute(tensors ...*Tensor) *Tensor {
+
+	A, index, src := tensors[0], tensors[1], tensors[2]
+
+	caller := "Scatter"
+	if op.add {
+		caller = "ScatterAdd"
+	}
+	if !isEqual(index.Shape, src.Shape) {
+		panic("Within " + caller + "(): index and src must have the same shape")
+	}
+
+	// start from a copy of A
+	C := newSelectionOutput(append([]int(nil), A.Shape...), A, src)
+	for i := range A.Data {
+		C.set(i, A, i, "scatter")
+	}
+
+	forEachIndexed(A, op.axis, index, caller, func(i int, target int) {
+		if !op.add {
+			C.set(target, src, i, "scatter")
+			return
+		}
+
+		C.Data[target] += src.Data[i]
+		if C.DataReqGrad != nil {
+			addend := NewValue(src.Data[i], nil, "scatter_add")
+			if tracksGrad(src) {
+				addend = src.DataReqGrad[i]
+			}
+			C.DataReqGrad[target] = C.DataReqGrad[target].Add(addend)
+		}
+	})
+	return C
+}
+
+/*
+* @notice Scatter() is the inverse of Gather(). It returns a copy of A where, for a 2D A and axis 1:
+*
+*     output[i][index[i][j]] = src[i][j]
+*
+* @dev index and src must have the same shape. If index addresses the same element more than once, the last write wins.
+* @dev When batching, axis refers to the axes of each batch element.
+ */
+func (A *Tensor) Scatter(axis int, index *Tensor, src *Tensor, batching bool) *Tensor {
+
+	scatter := ScatterOp{axis: axis}
+
+	if batching {
+		return BatchedOperation(scatter, A, index, src) // batched op
+	}
+	return scatter.Execute(A, index, src) // single op
+}
+
+// ScatterAdd() is Scatter(), except that src is added to the addressed elements. Repeated indices accumulate.
+func (A *Tensor) ScatterAdd(axis int, index *Tensor, src *Tensor, batching bool) *Tensor {
+
+	scatterAdd := ScatterOp{axis: axis, add: true}
+
+	if batching {
+		return BatchedOperation(scatterAdd, A, index, src) // batched op
+	}
+	return scatterAdd.Execute(A, index, src) // single op
+}
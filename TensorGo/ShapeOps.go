@@ -15,87 +15,389 @@ import (
 
 /*
 * @notice Slice() uses Python-like slice notation to retrieve a subset of a Tensor. A slice from each dimension of the tensor
-* is specified by a colon-separated string. For example, the string "1:3, 2:4" would retrieve a 2x2 slice from a 5x5 tensor.
-* @dev slice notation is exclusive of the index to the right of the colon.
-* @dev if an end index is not specified, the slice will extend to the end of the dimension.
+* is specified by a comma separated string. For example, the string "1:3, 2:4" would retrieve a 2x2 slice from a 5x5 tensor.
+* @dev Each comma separated item is one of:
+* - "start:stop:step" where any part may be omitted. stop is exclusive, step defaults to 1 and may be negative.
+* - a bare integer, which selects a single element and drops the dimension. Selecting a single element of every dimension
+* gives a scalar of shape [1].
+* - "...", which expands to as many ":" as are needed to cover the remaining dimensions.
+* - "None", which inserts a new axis of length 1.
+* @dev Negative indices count from the end of the dimension. Out of range slice bounds are clipped, as in Python.
+* @dev Dimensions that are not listed are taken in full.
 * @dev RequiresGrad and Batched flags are preserved in the sliced tensor.
 * @param slice: A string containing the slice notation for each dimension of the tensor.
 * @return *Tensor: A pointer to a new tensor containing the sliced data.
  */
 func (A *Tensor) Slice(slice string) *Tensor {
+	spec, err := ParseSliceSpec(slice)
+	if err != nil {
+		panic("Within Slice(): " + err.Error())
+	}
+	return A.SliceBySpec(spec)
+}
+
+// SliceBySpec() is the same as Slice(), but accepts a SliceSpec built programmatically instead of a string.
+func (A *Tensor) SliceBySpec(spec *SliceSpec) *Tensor {
+
+	view, err := spec.resolve(A.Shape)
+	if err != nil {
+		panic("Within Slice(): " + err.Error())
+	}
+
+	// Create a new tensor to store the partial data with the computed shape.
+	slicedTensor := ZeroTensor(view.shape, false)
+	slicedTensor.Batched = A.Batched
+
+	slicedTensor.RequireGrad = true
+
+	view.forEach(func(dst int, src int) {
+		if A.RequireGrad {
+			slicedTensor.DataReqGrad[dst].Scalar = A.DataReqGrad[src].Scalar
+		}
+		slicedTensor.Data[dst] = A.Data[src]
+	})
+
+	return slicedTensor
+}
+
+/*
+* @notice SetSlice() assigns B into the region of A selected by slice notation (see Slice()). A is modified in place and returned.
+* @dev B must either contain a single element, which is assigned to the whole region, or have the same shape as the
+* region (ignoring singleton dimensions).
+ */
+func (A *Tensor) SetSlice(slice string, B *Tensor) *Tensor {
+	spec, err := ParseSliceSpec(slice)
+	if err != nil {
+		panic("Within SetSlice(): " + err.Error())
+	}
+	return A.SetSliceBySpec(spec, B)
+}
+
+// SetSliceBySpec() is the same as SetSlice(), but accepts a SliceSpec built programmatically instead of a string.
+func (A *Tensor) SetSliceBySpec(spec *SliceSpec, B *Tensor) *Tensor {
+
+	view, err := spec.resolve(A.Shape)
+	if err != nil {
+		panic("Within SetSlice(): " + err.Error())
+	}
+
+	broadcastScalar := len(B.Data) == 1
+	if !broadcastScalar && !isEqual(squeezeShape(view.shape), squeezeShape(B.Shape)) {
+		panic("Within SetSlice(): B must have a single element or the same shape as the slice")
+	}
+
+	view.forEach(func(dst int, src int) {
+		value := B.Data[0]
+		if !broadcastScalar {
+			value = B.Data[dst]
+		}
+		A.Data[src] = value
+		if A.RequireGrad && len(A.DataReqGrad) == len(A.Data) {
+			A.DataReqGrad[src].Scalar = value
+		}
+	})
 
-	// Remove spaces and split the slice string by commas to handle each dimension separately.
+	return A
+}
+
+//============================================================================================================================== SliceSpec
+
+type sliceItemKind int
+
+const (
+	sliceRange sliceItemKind = iota
+	sliceIndex
+	sliceEllipsis
+	sliceNewAxis
+)
+
+// sliceItem is a single comma separated item of a slice
+type sliceItem struct {
+	kind              sliceItemKind
+	start, stop, step int
+	hasStart, hasStop bool
+	index             int
+}
+
+/*
+* @notice SliceSpec is a parsed slice that can also be built programmatically, as an alternative to slice strings.
+* @dev Each builder method appends one item and returns the SliceSpec so that calls can be chained:
+* NewSliceSpec().Range(1, 3).Index(-1).Ellipsis() is equivalent to "1:3, -1, ..."
+ */
+type SliceSpec struct {
+	items []sliceItem
+}
+
+// NewSliceSpec() creates an empty SliceSpec. An empty SliceSpec selects the entire Tensor.
+func NewSliceSpec() *SliceSpec { return &SliceSpec{} }
+
+// Index() selects a single element of a dimension and drops the dimension. Equivalent to "i".
+func (s *SliceSpec) Index(i int) *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceIndex, index: i})
+	return s
+}
+
+// Range() selects [start, stop) of a dimension. Equivalent to "start:stop".
+func (s *SliceSpec) Range(start, stop int) *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceRange, start: start, stop: stop, step: 1, hasStart: true, hasStop: true})
+	return s
+}
+
+// From() selects a dimension from start to its end. Equivalent to "start:".
+func (s *SliceSpec) From(start int) *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceRange, start: start, step: 1, hasStart: true})
+	return s
+}
+
+// To() selects a dimension from its beginning to stop. Equivalent to ":stop".
+func (s *SliceSpec) To(stop int) *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceRange, stop: stop, step: 1, hasStop: true})
+	return s
+}
+
+// All() selects an entire dimension. Equivalent to ":".
+func (s *SliceSpec) All() *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceRange, step: 1})
+	return s
+}
+
+// Every() selects every step'th element of a dimension. Equivalent to "::step".
+func (s *SliceSpec) Every(step int) *SliceSpec {
+	return s.All().WithStep(step)
+}
+
+// WithStep() sets the step of the most recently added range. For example Range(1, 9).WithStep(2) is "1:9:2".
+func (s *SliceSpec) WithStep(step int) *SliceSpec {
+	if len(s.items) == 0 || s.items[len(s.items)-1].kind != sliceRange {
+		panic("Within WithStep(): WithStep() must follow a range")
+	}
+	s.items[len(s.items)-1].step = step
+	return s
+}
+
+// Ellipsis() expands to as many full dimensions as are needed. Equivalent to "...".
+func (s *SliceSpec) Ellipsis() *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceEllipsis})
+	return s
+}
+
+// NewAxis() inserts a new dimension of length 1. Equivalent to "None".
+func (s *SliceSpec) NewAxis() *SliceSpec {
+	s.items = append(s.items, sliceItem{kind: sliceNewAxis})
+	return s
+}
+
+// ParseSliceSpec() parses a slice string (see Slice()) into a SliceSpec, returning an error describing any invalid item.
+func ParseSliceSpec(slice string) (*SliceSpec, error) {
+
+	spec := NewSliceSpec()
 	slice = strings.ReplaceAll(slice, " ", "")
-	split := strings.Split(slice, ",")
-	if len(split) != len(A.Shape) {
-		panic("Within Partial(): String slice arg must have the same number of dimensions as the tensor")
+	if slice == "" {
+		return spec, nil
 	}
 
-	// Initialize slices to store the shape of the partial tensor and the start/end indices for each dimension.
-	sliceShape := make([]int, len(A.Shape))
-	partialIndices := make([][]int, len(A.Shape))
+	for position, item := range strings.Split(slice, ",") {
+		switch {
+		case item == "":
+			return nil, fmt.Errorf("empty slice item at position %d in %q", position, slice)
+
+		case item == "...":
+			spec.Ellipsis()
 
-	// Iterate through each dimension of the tensor to parse the slice string and compute the shape and indices of the partial tensor.
-	for i, s := range split {
-		start, end := 0, A.Shape[i] // By default, use the entire dimension.
-		if s != ":" {
-			parts := strings.Split(s, ":")
+		case item == "None":
+			spec.NewAxis()
 
-			if parts[0] != "" { // If there is a start value, update start.
-				start, _ = strconv.Atoi(parts[0])
+		case strings.Contains(item, ":"):
+			parts := strings.Split(item, ":")
+			if len(parts) > 3 {
+				return nil, fmt.Errorf("too many colons in slice item %q", item)
 			}
-			if parts[1] != "" { // If there is an end value, update end.
-				end, _ = strconv.Atoi(parts[1])
+
+			// parse each of start, stop, step, which may be omitted
+			values := make([]int, 3)
+			present := make([]bool, 3)
+			for i, part := range parts {
+				if part == "" {
+					continue
+				}
+				value, err := strconv.Atoi(part)
+				if err != nil {
+					return nil, fmt.Errorf("invalid integer %q in slice item %q", part, item)
+				}
+				values[i], present[i] = value, true
+			}
+
+			step := 1
+			if present[2] {
+				if values[2] == 0 {
+					return nil, fmt.Errorf("slice step cannot be zero in slice item %q", item)
+				}
+				step = values[2]
 			}
+			spec.items = append(spec.items, sliceItem{kind: sliceRange, start: values[0], stop: values[1], step: step, hasStart: present[0], hasStop: present[1]})
+
+		default:
+			index, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice item %q", item)
+			}
+			spec.Index(index)
 		}
-		sliceShape[i] = end - start
-		partialIndices[i] = []int{start, end}
 	}
+	return spec, nil
+}
 
-	// Create a new tensor to store the partial data with the computed shape.
-	slicedTensor := ZeroTensor(sliceShape, false)
-	slicedTensor.Batched = A.Batched
-
-	slicedTensor.RequireGrad = true
+//============================================================================================================================== Resolving a SliceSpec Against a Shape
 
-	// Initialize a slice to store the current multi-dimensional index being processed.
-	tempIndex := make([]int, len(sliceShape))
+// sliceView describes the elements of a Tensor selected by a SliceSpec as a strided view of its contiguous data.
+type sliceView struct {
+	shape   []int // <--- shape of the selected region
+	strides []int // <--- step through the source Data for one step along each dimension of the region
+	offset  int   // <--- index into the source Data of the first selected element
+}
 
-	// Define a recursive anon function to fill the partial tensor.
-	// The function accepts the current dimension as a parameter.
-	var fillPartialTensor func(int)
-	fillPartialTensor = func(dim int) {
-		if dim == len(sliceShape) { // <--- This base case is reached for every element in the slice tensor.
+// resolve computes the sliceView of a SliceSpec for a Tensor of the given shape.
+func (s *SliceSpec) resolve(shape []int) (*sliceView, error) {
+
+	// count the items that consume a dimension, to determine what the ellipsis (or implicit trailing ":") covers
+	consumed, ellipses := 0, 0
+	for _, item := range s.items {
+		switch item.kind {
+		case sliceRange, sliceIndex:
+			consumed++
+		case sliceEllipsis:
+			ellipses++
+		}
+	}
+	if ellipses > 1 {
+		return nil, fmt.Errorf("a slice can only contain one ellipsis")
+	}
+	if consumed > len(shape) {
+		return nil, fmt.Errorf("too many indices for a Tensor with %d dimensions", len(shape))
+	}
 
-			// Calculate the source index in the original tensor.
-			srcIndex := make([]int, len(sliceShape))
-			for i, indices := range partialIndices {
-				srcIndex[i] = tempIndex[i] + indices[0]
+	items := make([]sliceItem, 0, len(s.items)+len(shape)-consumed)
+	for _, item := range s.items {
+		if item.kind == sliceEllipsis {
+			for i := 0; i < len(shape)-consumed; i++ {
+				items = append(items, sliceItem{kind: sliceRange, step: 1})
 			}
+			continue
+		}
+		items = append(items, item)
+	}
+	for len(items) < len(s.items)-ellipses+len(shape)-consumed { // <--- unlisted trailing dimensions are taken in full
+		items = append(items, sliceItem{kind: sliceRange, step: 1})
+	}
 
-			// Convert the multi-dimensional indices to flattened indices and use them to copy the data.
-			srcFlattenedIndex := A.Index(srcIndex)
-			dstFlattenedIndex := slicedTensor.Index(tempIndex)
+	srcStrides := rowMajorStrides(shape)
 
-			if A.RequireGrad {
-				slicedTensor.DataReqGrad[dstFlattenedIndex].Scalar = A.DataReqGrad[srcFlattenedIndex].Scalar
+	view := &sliceView{shape: []int{}, strides: []int{}}
+	dim := 0
+	for _, item := range items {
+		switch item.kind {
+		case sliceNewAxis:
+			view.shape = append(view.shape, 1)
+			view.strides = append(view.strides, 0)
+
+		case sliceIndex:
+			index := item.index
+			if index < 0 {
+				index += shape[dim]
+			}
+			if index < 0 || index >= shape[dim] {
+				return nil, fmt.Errorf("index %d is out of bounds for dimension %d with size %d", item.index, dim, shape[dim])
 			}
-			slicedTensor.Data[dstFlattenedIndex] = A.Data[srcFlattenedIndex]
+			view.offset += index * srcStrides[dim]
+			dim++
+
+		case sliceRange:
+			start, length := resolveRange(item, shape[dim])
+			view.offset += start * srcStrides[dim]
+			view.shape = append(view.shape, length)
+			view.strides = append(view.strides, item.step*srcStrides[dim])
+			dim++
+		}
+	}
+	if len(view.shape) == 0 { // <--- selecting a single element gives a scalar of shape [1], as elsewhere in the repo
+		view.shape, view.strides = []int{1}, []int{0}
+	}
+	return view, nil
+}
 
-			return
+// resolveRange applies Python slice semantics to a range item for a dimension of size n, returning the first index and the number of elements.
+func resolveRange(item sliceItem, n int) (int, int) {
+
+	// normalize a bound: negative values count from the end, then clip to [lower, upper]
+	normalize := func(value int, lower int, upper int) int {
+		if value < 0 {
+			value += n
 		}
+		return max(lower, min(value, upper))
+	}
 
-		// Recursively process each index in the current dimension.
-		for i := 0; i < sliceShape[dim]; i++ {
-			tempIndex[dim] = i
-			fillPartialTensor(dim + 1)
+	var start, stop int
+	if item.step > 0 {
+		start, stop = 0, n
+		if item.hasStart {
+			start = normalize(item.start, 0, n)
 		}
+		if item.hasStop {
+			stop = normalize(item.stop, 0, n)
+		}
+		if stop <= start {
+			return start, 0
+		}
+		return start, (stop - start + item.step - 1) / item.step
 	}
 
-	// Start the recursive process from the first dimension.
-	fillPartialTensor(0)
+	start, stop = n-1, -1
+	if item.hasStart {
+		start = normalize(item.start, -1, n-1)
+	}
+	if item.hasStop {
+		stop = normalize(item.stop, -1, n-1)
+		if item.stop < -n { // <--- a stop before the beginning includes index 0
+			stop = -1
+		}
+	}
+	if start <= stop {
+		return 0, 0
+	}
+	return start, (start - stop - item.step - 1) / -item.step
+}
 
-	return slicedTensor
+// forEach calls fn with the flat index into the region and the corresponding flat index into the source Data, in row major order.
+func (view *sliceView) forEach(fn func(dst int, src int)) {
+
+	total := Product(view.shape)
+	index := make([]int, len(view.shape))
+	src := view.offset
+
+	for dst := 0; dst < total; dst++ {
+		fn(dst, src)
+
+		for d := len(view.shape) - 1; d >= 0; d-- {
+			index[d]++
+			src += view.strides[d]
+			if index[d] < view.shape[d] {
+				break
+			}
+			src -= view.strides[d] * index[d]
+			index[d] = 0
+		}
+	}
+}
+
+// squeezeShape returns a copy of shape with all singleton dimensions removed
+func squeezeShape(shape []int) []int {
+	squeezed := []int{}
+	for _, dim := range shape {
+		if dim != 1 {
+			squeezed = append(squeezed, dim)
+		}
+	}
+	return squeezed
 }
 
 //============================================================================================================================== Reshape()
@@ -457,26 +759,19 @@ func Check_Extend_Dim_Requirements(A *Tensor, axis int, num_elements int) {
 * @returns a pointer to a new tensor with the specified axis removed
  */
 func (A *Tensor) Remove_Dim(axis_of_removal int, element_of_retrieval int) *Tensor {
-	// create an empty string to store the slice string for Partial()
-	var builder strings.Builder
 
-	// Iterate through the shape of the tensor, appending ":," if the dim is not the element of retrieval
-	// and element_of_retrieval : (element_of_retrieval + 1) if it is the element of retrieval
+	// Select the entire dimension for every axis except the axis of removal, which keeps only element_of_retrieval
+	spec := NewSliceSpec()
 	for i := range A.Shape {
 		if i == axis_of_removal {
-			// Add the specific index for the axis of removal
-			builder.WriteString(fmt.Sprintf("%d:%d,", element_of_retrieval, element_of_retrieval+1))
+			spec.Range(element_of_retrieval, element_of_retrieval+1)
 		} else {
-			// Add ':' to keep the entire dimension
-			builder.WriteString(":,")
+			spec.All()
 		}
 	}
 
-	// Remove the trailing comma
-	sliceString := strings.TrimRight(builder.String(), ",")
-
-	// Use the Slice() method to take a partial tensor
-	A_Partial := A.Slice(sliceString).Remove_Singletons()
+	// Use the SliceBySpec() method to take a partial tensor
+	A_Partial := A.SliceBySpec(spec).Remove_Singletons()
 
 	return A_Partial
 }
@@ -0,0 +1,292 @@
+package TG
+
+/*
+* @notice Sorting.go contains order based ops: Sort(), Argsort(), TopK(), Argmin(), Argmax(), Unique() and SearchSorted().
+* @dev Ops along an axis are LaneOperations (see AxisLaneOperation() in OpAbstractions.go), so each 1D lane along the axis is
+* processed independently and lanes run in parallel across the shared worker pool. With batching, axis refers to the axes
+* of each batch element.
+* @dev Sorting is stable: equal elements keep their original order, in both ascending and descending order. NaNs are always
+* placed last. Indices are returned as whole numbers stored in float64 Tensors, which can be passed directly to Gather().
+ */
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// compareAscending orders a before b when a < b, placing NaNs after every other value
+func compareAscending(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return 1
+	case math.IsNaN(b):
+		return -1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareDescending orders a before b when a > b, placing NaNs after every other value
+func compareDescending(a, b float64) int {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return compareAscending(a, b)
+	}
+	return compareAscending(b, a)
+}
+
+// orderFunc returns the comparison for the requested sort direction
+func orderFunc(descending bool) func(a, b float64) int {
+	if descending {
+		return compareDescending
+	}
+	return compareAscending
+}
+
+// argsortLane returns the indices that stably sort lane
+func argsortLane(lane []float64, descending bool) []int {
+	compare := orderFunc(descending)
+	indices := make([]int, len(lane))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(i, j int) int { return compare(lane[i], lane[j]) })
+	return indices
+}
+
+//============================================================================================================================== Sort()
+
+type SortOp struct {
+	axis       int
+	descending bool
+}
+
+func (op SortOp) OutputLength(n int) int { return n }
+
+func (op SortOp) Apply_LaneOp(lane []float64, out []float64) {
+	copy(out, lane)
+	slices.SortStableFunc(out, orderFunc(op.descending))
+}
+
+func (op SortOp) Execute(tensors ...*Tensor) *Tensor {
+	return tensors[0].AxisLaneOperation(op.axis, op)
+}
+
+// Sort() returns a copy of A with each lane along axis sorted in ascending, or descending, order.
+func (A *Tensor) Sort(axis int, descending bool, batching bool) *Tensor {
+
+	sortOp := SortOp{axis: axis, descending: descending}
+
+	if batching {
+		return BatchedOperation(sortOp, A) // batched op
+	}
+	return sortOp.Execute(A) // single op
+}
+
+//============================================================================================================================== Argsort()
+
+type ArgsortOp struct {
+	axis       int
+	descending bool
+}
+
+func (op ArgsortOp) OutputLength(n int) int { return n }
+
+func (op ArgsortOp) Apply_LaneOp(lane []float64, out []float64) {
+	for k, index := range argsortLane(lane, op.descending) {
+		out[k] = float64(index)
+	}
+}
+
+func (op ArgsortOp) Execute(tensors ...*Tensor) *Tensor {
+	return tensors[0].AxisLaneOperation(op.axis, op)
+}
+
+/*
+* @notice Argsort() returns the indices that would sort each lane of A along axis. A.Gather(axis, A.Argsort(axis, ...), ...)
+* is equivalent to A.Sort(axis, ...).
+ */
+func (A *Tensor) Argsort(axis int, descending bool, batching bool) *Tensor {
+
+	argsortOp := ArgsortOp{axis: axis, descending: descending}
+
+	if batching {
+		return BatchedOperation(argsortOp, A) // batched op
+	}
+	return argsortOp.Execute(A) // single op
+}
+
+//============================================================================================================================== TopK()
+
+type TopKIndicesOp struct {
+	axis    int
+	k       int
+	largest bool
+}
+
+func (op TopKIndicesOp) OutputLength(n int) int {
+	if op.k < 0 || op.k > n {
+		panic("Within TopK(): k must be between 0 and the length of the axis")
+	}
+	return op.k
+}
+
+func (op TopKIndicesOp) Apply_LaneOp(lane []float64, out []float64) {
+	for k, index := range argsortLane(lane, op.largest)[:op.k] {
+		out[k] = float64(index)
+	}
+}
+
+func (op TopKIndicesOp) Execute(tensors ...*Tensor) *Tensor {
+	return tensors[0].AxisLaneOperation(op.axis, op)
+}
+
+/*
+* @notice TopK() returns the k largest (or smallest) elements of each lane of A along axis, and their indices.
+* @dev Results are ordered from most to least extreme. Ties are broken by the lower index.
+* @return values: A Tensor with the shape of A except the axis has length k.
+* @return indices: The indices of values within each lane of A.
+ */
+func (A *Tensor) TopK(k int, axis int, largest bool, batching bool) (*Tensor, *Tensor) {
+
+	topK := TopKIndicesOp{axis: axis, k: k, largest: largest}
+
+	var indices *Tensor
+	if batching {
+		indices = BatchedOperation(topK, A) // batched op
+	} else {
+		indices = topK.Execute(A) // single op
+	}
+	return A.Gather(axis, indices, batching), indices
+}
+
+//============================================================================================================================== Argmin(), Argmax()
+
+type ArgExtremumOp struct {
+	axis    int
+	largest bool
+}
+
+func (op ArgExtremumOp) OutputLength(n int) int {
+	if n == 0 {
+		panic("Within Argmax()/Argmin(): Cannot take the argmax or argmin of an empty axis")
+	}
+	return 1
+}
+
+// Apply_LaneOp finds the first extreme element. As with NumPy, a NaN is treated as the extreme value.
+func (op ArgExtremumOp) Apply_LaneOp(lane []float64, out []float64) {
+	best := 0
+	for k := 1; k < len(lane) && !math.IsNaN(lane[best]); k++ {
+		if math.IsNaN(lane[k]) || (op.largest && lane[k] > lane[best]) || (!op.largest && lane[k] < lane[best]) {
+			best = k
+		}
+	}
+	out[0] = float64(best)
+}
+
+func (op ArgExtremumOp) Execute(tensors ...*Tensor) *Tensor {
+	C := tensors[0].AxisLaneOperation(op.axis, op)
+	C.Shape = append(C.Shape[:op.axis], C.Shape[op.axis+1:]...) // <--- the axis is collapsed
+	if len(C.Shape) == 0 {
+		C.Shape = []int{1} // <--- a vector collapses to a single element Tensor, like Norm()
+	}
+	return C
+}
+
+// Argmax() returns the index of the largest element of each lane of A along axis, collapsing the axis.
+func (A *Tensor) Argmax(axis int, batching bool) *Tensor {
+
+	argmax := ArgExtremumOp{axis: axis, largest: true}
+
+	if batching {
+		return BatchedOperation(argmax, A) // batched op
+	}
+	return argmax.Execute(A) // single op
+}
+
+// Argmin() returns the index of the smallest element of each lane of A along axis, collapsing the axis.
+func (A *Tensor) Argmin(axis int, batching bool) *Tensor {
+
+	argmin := ArgExtremumOp{axis: axis, largest: false}
+
+	if batching {
+		return BatchedOperation(argmin, A) // batched op
+	}
+	return argmin.Execute(A) // single op
+}
+
+//============================================================================================================================== Unique()
+
+/*
+* @notice Unique() returns the sorted unique elements of A, treating A as flat. All NaNs are treated as one value.
+* @dev Because the number of unique elements depends on the data, Unique() has no batched form.
+* @return values: A 1D Tensor of the unique elements in ascending order.
+* @return counts: A 1D Tensor with the number of times each unique element occurs in A.
+* @return inverse: A Tensor with the shape of A holding the index into values of each element, so that values.Take(inverse) rebuilds A.
+ */
+func (A *Tensor) Unique() (*Tensor, *Tensor, *Tensor) {
+
+	order := argsortLane(A.Data, false)
+
+	inverse := &Tensor{Shape: append([]int(nil), A.Shape...), Data: make([]float64, len(A.Data))}
+	uniqueValues, counts := []float64{}, []float64{}
+
+	for k, index := range order {
+		value := A.Data[index]
+		if k == 0 || compareAscending(value, A.Data[order[k-1]]) != 0 {
+			uniqueValues = append(uniqueValues, value)
+			counts = append(counts, 0)
+		}
+		counts[len(counts)-1]++
+		inverse.Data[index] = float64(len(uniqueValues) - 1)
+	}
+
+	values := &Tensor{Shape: []int{len(uniqueValues)}, Data: uniqueValues}
+	return values, &Tensor{Shape: []int{len(counts)}, Data: counts}, inverse
+}
+
+//============================================================================================================================== SearchSorted()
+
+type SearchSortedOp struct{ right bool }
+
+func (op SearchSortedOp) Execute(tensors ...*Tensor) *Tensor {
+
+	sorted, values := tensors[0], tensors[1]
+	if len(sorted.Shape) != 1 {
+		panic("Within SearchSorted(): sorted must be a vector")
+	}
+
+	C := &Tensor{Shape: append([]int(nil), values.Shape...), Data: make([]float64, len(values.Data))}
+	for i, value := range values.Data {
+		C.Data[i] = float64(sort.Search(len(sorted.Data), func(k int) bool {
+			if op.right {
+				return compareAscending(sorted.Data[k], value) > 0
+			}
+			return compareAscending(sorted.Data[k], value) >= 0
+		}))
+	}
+	return C
+}
+
+/*
+* @notice SearchSorted() finds the indices at which each element of values would be inserted into the ascending vector sorted
+* to keep it sorted. The output has the shape of values.
+* @dev With right set to false, the index of the first suitable position is returned (elements equal to the value come after it).
+* With right set to true, the index of the last suitable position is returned (elements equal to the value come before it).
+* @dev When batching, sorted has shape [batch, n] and values has shape [batch, ...].
+ */
+func SearchSorted(sorted *Tensor, values *Tensor, right bool, batching bool) *Tensor {
+
+	searchSorted := SearchSortedOp{right: right}
+
+	if batching {
+		return BatchedOperation(searchSorted, sorted, values) // batched op
+	}
+	return searchSorted.Execute(sorted, values) // single op
+}
@@ -0,0 +1,192 @@
+package TG
+
+/*
+* @notice SparseOps.go contains operations on the sparse matrix types defined in SparseTensor.go
+* @dev Arithmetic is implemented on CSRMatrix. Each sparse type also implements the LinearOperator interface from
+* IterativeSolvers.go so that it can be passed directly to ConjugateGradient(), GMRES() or BiCGSTAB().
+ */
+
+//============================================================================================================================== SpMV
+
+// Apply() computes the sparse matrix vector product Sx, returning a dense vector Tensor.
+func (S *CSRMatrix) Apply(x *Tensor) *Tensor {
+
+	if len(x.Shape) != 1 || x.Shape[0] != S.Shape[1] {
+		panic("Within Apply(): x must be a vector with length equal to the number of columns of S")
+	}
+
+	Sx := &Tensor{Shape: []int{S.Shape[0]}, Data: make([]float64, S.Shape[0])}
+	for row := 0; row < S.Shape[0]; row++ {
+		var sum float64
+		for k := S.IndPtr[row]; k < S.IndPtr[row+1]; k++ {
+			sum += S.Values[k] * x.Data[S.Indices[k]]
+		}
+		Sx.Data[row] = sum
+	}
+	return Sx
+}
+
+// Apply() computes the sparse matrix vector product Sx, returning a dense vector Tensor.
+func (S *CSCMatrix) Apply(x *Tensor) *Tensor {
+
+	if len(x.Shape) != 1 || x.Shape[0] != S.Shape[1] {
+		panic("Within Apply(): x must be a vector with length equal to the number of columns of S")
+	}
+
+	Sx := &Tensor{Shape: []int{S.Shape[0]}, Data: make([]float64, S.Shape[0])}
+	for col := 0; col < S.Shape[1]; col++ {
+		for k := S.IndPtr[col]; k < S.IndPtr[col+1]; k++ {
+			Sx.Data[S.Indices[k]] += S.Values[k] * x.Data[col]
+		}
+	}
+	return Sx
+}
+
+// Apply() computes the sparse matrix vector product Sx, returning a dense vector Tensor.
+func (S *COOMatrix) Apply(x *Tensor) *Tensor {
+
+	if len(x.Shape) != 1 || x.Shape[0] != S.Shape[1] {
+		panic("Within Apply(): x must be a vector with length equal to the number of columns of S")
+	}
+
+	Sx := &Tensor{Shape: []int{S.Shape[0]}, Data: make([]float64, S.Shape[0])}
+	for i, value := range S.Values {
+		Sx.Data[S.Rows[i]] += value * x.Data[S.Cols[i]]
+	}
+	return Sx
+}
+
+//============================================================================================================================== SpMM
+
+/*
+* @notice SpMM() multiplies a sparse CSRMatrix S by a dense matrix Tensor B, returning the dense Tensor SB.
+* @dev Each nonzero S[i, k] scales row k of B and adds it into row i of the output, so B is read contiguously.
+* @param S: an m x n CSRMatrix
+* @param B: an n x p Tensor, or a vector of length n
+ */
+func SpMM(S *CSRMatrix, B *Tensor) *Tensor {
+
+	if len(B.Shape) == 1 {
+		return S.Apply(B)
+	}
+	if len(B.Shape) != 2 || B.Shape[0] != S.Shape[1] {
+		panic("Within SpMM(): B must be 2D with rows equal to the number of columns of S")
+	}
+
+	p := B.Shape[1]
+	C := ZeroTensor([]int{S.Shape[0], p}, false)
+	for row := 0; row < S.Shape[0]; row++ {
+		outRow := C.Data[row*p : (row+1)*p]
+		for k := S.IndPtr[row]; k < S.IndPtr[row+1]; k++ {
+			axpy(S.Values[k], B.Data[S.Indices[k]*p:(S.Indices[k]+1)*p], outRow)
+		}
+	}
+	return C
+}
+
+//============================================================================================================================== Elementwise Sparse Ops
+
+/*
+* @notice SparseElementwiseOp() applies an _ElementwiseOp (see OpAbstractions.go) to two CSRMatrices of the same shape.
+* @dev The sorted column indices of each row are merged. Where only one matrix has an entry, the other contributes 0.
+* Results that are exactly zero are not stored, so ops such as multiplication do not fill in the union of both patterns.
+ */
+func SparseElementwiseOp(A *CSRMatrix, B *CSRMatrix, op _ElementwiseOp) *CSRMatrix {
+
+	if !isEqual(A.Shape, B.Shape) {
+		panic("Within SparseElementwiseOp(): Sparse matrices must have the same shape")
+	}
+
+	C := &CSRMatrix{Shape: []int{A.Shape[0], A.Shape[1]}, IndPtr: make([]int, A.Shape[0]+1)}
+
+	store := func(col int, value float64) {
+		if value != 0 {
+			C.Indices = append(C.Indices, col)
+			C.Values = append(C.Values, value)
+		}
+	}
+
+	for row := 0; row < A.Shape[0]; row++ {
+		i, iEnd := A.IndPtr[row], A.IndPtr[row+1]
+		j, jEnd := B.IndPtr[row], B.IndPtr[row+1]
+
+		for i < iEnd || j < jEnd {
+			switch {
+			case j >= jEnd || (i < iEnd && A.Indices[i] < B.Indices[j]): // <--- only A has an entry
+				store(A.Indices[i], op.ExecuteElementwiseOp(A.Values[i], 0))
+				i++
+			case i >= iEnd || B.Indices[j] < A.Indices[i]: // <--- only B has an entry
+				store(B.Indices[j], op.ExecuteElementwiseOp(0, B.Values[j]))
+				j++
+			default: // <--- both have an entry
+				store(A.Indices[i], op.ExecuteElementwiseOp(A.Values[i], B.Values[j]))
+				i++
+				j++
+			}
+		}
+		C.IndPtr[row+1] = len(C.Values)
+	}
+	return C
+}
+
+// SparseAdd() performs elementwise addition of two CSRMatrices.
+func SparseAdd(A *CSRMatrix, B *CSRMatrix) *CSRMatrix {
+	return SparseElementwiseOp(A, B, EWAddition{})
+}
+
+// SparseSubtract() performs elementwise subtraction of two CSRMatrices.
+func SparseSubtract(A *CSRMatrix, B *CSRMatrix) *CSRMatrix {
+	return SparseElementwiseOp(A, B, EWSubtraction{})
+}
+
+// SparseMultiply() performs elementwise multiplication of two CSRMatrices.
+func SparseMultiply(A *CSRMatrix, B *CSRMatrix) *CSRMatrix {
+	return SparseElementwiseOp(A, B, EWMultiplication{})
+}
+
+// Scalar_Mult() multiplies every stored entry of a CSRMatrix by a scalar, returning a new CSRMatrix.
+func (S *CSRMatrix) Scalar_Mult(scalar float64) *CSRMatrix {
+	C := S.Copy()
+	for i := range C.Values {
+		C.Values[i] *= scalar
+	}
+	return C
+}
+
+//============================================================================================================================== Copy(), Transpose(), SliceRows()
+
+// Copy() creates a deep copy of a CSRMatrix.
+func (S *CSRMatrix) Copy() *CSRMatrix {
+	return &CSRMatrix{
+		Shape:   []int{S.Shape[0], S.Shape[1]},
+		IndPtr:  append([]int(nil), S.IndPtr...),
+		Indices: append([]int(nil), S.Indices...),
+		Values:  append([]float64(nil), S.Values...),
+	}
+}
+
+// Transpose() returns the transpose of a CSRMatrix. The CSC form of S has exactly the layout of the CSR form of S^T.
+func (S *CSRMatrix) Transpose() *CSRMatrix {
+	C := S.ToCSC()
+	return &CSRMatrix{Shape: []int{S.Shape[1], S.Shape[0]}, IndPtr: C.IndPtr, Indices: C.Indices, Values: C.Values}
+}
+
+// SliceRows() returns a new CSRMatrix containing rows [start, end) of S.
+func (S *CSRMatrix) SliceRows(start int, end int) *CSRMatrix {
+
+	if start < 0 || end > S.Shape[0] || start > end {
+		panic("Within SliceRows(): Row range out of bounds")
+	}
+
+	first, last := S.IndPtr[start], S.IndPtr[end]
+	C := &CSRMatrix{
+		Shape:   []int{end - start, S.Shape[1]},
+		IndPtr:  make([]int, end-start+1),
+		Indices: append([]int(nil), S.Indices[first:last]...),
+		Values:  append([]float64(nil), S.Values[first:last]...),
+	}
+	for row := start; row <= end; row++ {
+		C.IndPtr[row-start] = S.IndPtr[row] - first
+	}
+	return C
+}
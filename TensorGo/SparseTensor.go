@@ -0,0 +1,221 @@
+package TG
+
+/*
+* @notice SparseTensor.go contains sparse matrix types and the conversions between them and the dense Tensor.
+* @dev Three storage formats are supported:
+* - COOMatrix (coordinate) stores a (row, col, value) triplet per nonzero. It is the easiest format to build incrementally.
+* - CSRMatrix (compressed sparse row) stores the nonzeros of each row contiguously. It is the format used for arithmetic.
+* - CSCMatrix (compressed sparse column) stores the nonzeros of each column contiguously.
+* @dev For a CSRMatrix, the nonzeros of row i are Values[IndPtr[i]:IndPtr[i+1]], with column indices Indices[IndPtr[i]:IndPtr[i+1]].
+* CSCMatrix has the same layout with the roles of rows and columns swapped. This means the CSC form of a matrix is the CSR form
+* of its transpose, which is used below to avoid writing each conversion twice.
+ */
+
+import (
+	"sort"
+)
+
+// COOMatrix stores a sparse matrix as a list of (row, col, value) triplets. Duplicate entries are summed on conversion.
+type COOMatrix struct {
+	Shape  []int
+	Rows   []int
+	Cols   []int
+	Values []float64
+}
+
+// CSRMatrix stores a sparse matrix in compressed sparse row format. Column indices within each row are sorted.
+type CSRMatrix struct {
+	Shape   []int
+	IndPtr  []int
+	Indices []int
+	Values  []float64
+}
+
+// CSCMatrix stores a sparse matrix in compressed sparse column format. Row indices within each column are sorted.
+type CSCMatrix struct {
+	Shape   []int
+	IndPtr  []int
+	Indices []int
+	Values  []float64
+}
+
+//============================================================================================================================== COO Construction
+
+// NewCOOMatrix() creates a COOMatrix from triplets, checking that every index is within the bounds of shape.
+func NewCOOMatrix(shape []int, rows []int, cols []int, values []float64) *COOMatrix {
+
+	if len(shape) != 2 {
+		panic("Within NewCOOMatrix(): Sparse matrices must be 2D")
+	}
+	if len(rows) != len(cols) || len(rows) != len(values) {
+		panic("Within NewCOOMatrix(): rows, cols and values must be the same length")
+	}
+	for i := range rows {
+		if rows[i] < 0 || rows[i] >= shape[0] || cols[i] < 0 || cols[i] >= shape[1] {
+			panic("Within NewCOOMatrix(): Index out of bounds")
+		}
+	}
+
+	return &COOMatrix{Shape: []int{shape[0], shape[1]}, Rows: rows, Cols: cols, Values: values}
+}
+
+// NNZ() returns the number of stored entries.
+func (S *COOMatrix) NNZ() int { return len(S.Values) }
+func (S *CSRMatrix) NNZ() int { return len(S.Values) }
+func (S *CSCMatrix) NNZ() int { return len(S.Values) }
+
+//============================================================================================================================== Dense --> Sparse
+
+// ToCOO() converts a 2D dense Tensor into a COOMatrix containing only its nonzero elements.
+func (A *Tensor) ToCOO() *COOMatrix {
+
+	if len(A.Shape) != 2 {
+		panic("Within ToCOO(): Tensor must be 2D to convert to a sparse matrix")
+	}
+
+	S := &COOMatrix{Shape: []int{A.Shape[0], A.Shape[1]}}
+	for i, value := range A.Data {
+		if value != 0 {
+			S.Rows = append(S.Rows, i/A.Shape[1])
+			S.Cols = append(S.Cols, i%A.Shape[1])
+			S.Values = append(S.Values, value)
+		}
+	}
+	return S
+}
+
+// ToCSR() converts a 2D dense Tensor into a CSRMatrix.
+func (A *Tensor) ToCSR() *CSRMatrix {
+	return A.ToCOO().ToCSR()
+}
+
+// ToCSC() converts a 2D dense Tensor into a CSCMatrix.
+func (A *Tensor) ToCSC() *CSCMatrix {
+	return A.ToCOO().ToCSC()
+}
+
+//============================================================================================================================== Sparse --> Dense
+
+// ToDense() converts a COOMatrix into a dense Tensor. Duplicate entries are summed.
+func (S *COOMatrix) ToDense() *Tensor {
+	A := ZeroTensor(S.Shape, false)
+	for i, value := range S.Values {
+		A.Data[S.Rows[i]*S.Shape[1]+S.Cols[i]] += value
+	}
+	return A
+}
+
+// ToDense() converts a CSRMatrix into a dense Tensor.
+func (S *CSRMatrix) ToDense() *Tensor {
+	A := ZeroTensor(S.Shape, false)
+	for row := 0; row < S.Shape[0]; row++ {
+		for k := S.IndPtr[row]; k < S.IndPtr[row+1]; k++ {
+			A.Data[row*S.Shape[1]+S.Indices[k]] = S.Values[k]
+		}
+	}
+	return A
+}
+
+// ToDense() converts a CSCMatrix into a dense Tensor.
+func (S *CSCMatrix) ToDense() *Tensor {
+	return S.ToCSR().ToDense()
+}
+
+//============================================================================================================================== Sparse <--> Sparse
+
+/*
+* @notice ToCSR() converts a COOMatrix into a CSRMatrix.
+* @dev Triplets are bucketed by row with a counting sort, then each row is sorted by column and duplicate entries are summed.
+ */
+func (S *COOMatrix) ToCSR() *CSRMatrix {
+
+	numRows := S.Shape[0]
+
+	// count the entries in each row, then prefix sum the counts into row pointers
+	indPtr := make([]int, numRows+1)
+	for _, row := range S.Rows {
+		indPtr[row+1]++
+	}
+	for row := 0; row < numRows; row++ {
+		indPtr[row+1] += indPtr[row]
+	}
+
+	// scatter the triplets into their rows
+	indices := make([]int, len(S.Values))
+	values := make([]float64, len(S.Values))
+	next := append([]int(nil), indPtr[:numRows]...)
+	for i, row := range S.Rows {
+		indices[next[row]] = S.Cols[i]
+		values[next[row]] = S.Values[i]
+		next[row]++
+	}
+
+	// sort each row by column and sum duplicates, compacting the arrays as we go
+	C := &CSRMatrix{Shape: []int{S.Shape[0], S.Shape[1]}, IndPtr: make([]int, numRows+1)}
+	for row := 0; row < numRows; row++ {
+		rowEntries := sparseRow{indices: indices[indPtr[row]:indPtr[row+1]], values: values[indPtr[row]:indPtr[row+1]]}
+		sort.Sort(rowEntries)
+
+		for k := range rowEntries.indices {
+			if len(C.Indices) > C.IndPtr[row] && C.Indices[len(C.Indices)-1] == rowEntries.indices[k] {
+				C.Values[len(C.Values)-1] += rowEntries.values[k] // <--- duplicate entry
+				continue
+			}
+			C.Indices = append(C.Indices, rowEntries.indices[k])
+			C.Values = append(C.Values, rowEntries.values[k])
+		}
+		C.IndPtr[row+1] = len(C.Values)
+	}
+	return C
+}
+
+// ToCSC() converts a COOMatrix into a CSCMatrix by building the CSR form of its transpose.
+func (S *COOMatrix) ToCSC() *CSCMatrix {
+	T := (&COOMatrix{Shape: []int{S.Shape[1], S.Shape[0]}, Rows: S.Cols, Cols: S.Rows, Values: S.Values}).ToCSR()
+	return &CSCMatrix{Shape: []int{S.Shape[0], S.Shape[1]}, IndPtr: T.IndPtr, Indices: T.Indices, Values: T.Values}
+}
+
+// ToCOO() converts a CSRMatrix into a COOMatrix.
+func (S *CSRMatrix) ToCOO() *COOMatrix {
+	C := &COOMatrix{
+		Shape:  []int{S.Shape[0], S.Shape[1]},
+		Rows:   make([]int, 0, S.NNZ()),
+		Cols:   append([]int(nil), S.Indices...),
+		Values: append([]float64(nil), S.Values...),
+	}
+	for row := 0; row < S.Shape[0]; row++ {
+		for k := S.IndPtr[row]; k < S.IndPtr[row+1]; k++ {
+			C.Rows = append(C.Rows, row)
+		}
+	}
+	return C
+}
+
+// ToCSC() converts a CSRMatrix into a CSCMatrix.
+func (S *CSRMatrix) ToCSC() *CSCMatrix {
+	return S.ToCOO().ToCSC()
+}
+
+// ToCOO() converts a CSCMatrix into a COOMatrix.
+func (S *CSCMatrix) ToCOO() *COOMatrix {
+	T := (&CSRMatrix{Shape: []int{S.Shape[1], S.Shape[0]}, IndPtr: S.IndPtr, Indices: S.Indices, Values: S.Values}).ToCOO()
+	return &COOMatrix{Shape: []int{S.Shape[0], S.Shape[1]}, Rows: T.Cols, Cols: T.Rows, Values: T.Values}
+}
+
+// ToCSR() converts a CSCMatrix into a CSRMatrix.
+func (S *CSCMatrix) ToCSR() *CSRMatrix {
+	return S.ToCOO().ToCSR()
+}
+
+// sparseRow implements sort.Interface to sort the entries of a single row by column index
+type sparseRow struct {
+	indices []int
+	values  []float64
+}
+
+func (r sparseRow) Len() int           { return len(r.indices) }
+func (r sparseRow) Less(i, j int) bool { return r.indices[i] < r.indices[j] }
+func (r sparseRow) Swap(i, j int) {
+	r.indices[i], r.indices[j] = r.indices[j], r.indices[i]
+	r.values[i], r.values[j] = r.values[j], r.values[i]
+}
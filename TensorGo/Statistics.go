@@ -0,0 +1,392 @@
+package TG
+
+/*
+* @notice Statistics.go contains descriptive statistics: quantiles, histograms, covariance and correlation matrices,
+* skewness, kurtosis and weighted moments.
+* @dev Statistics over all elements implement ScalarCollapsingOp (see OpAbstractions.go), in the same way as Var_All().
+* Statistics over axes are Reducers (see Reductions.go), so they accept any set of axes and a keepdims option.
+* @dev Moments are population moments (normalized by n), matching Var_All() and Var_Axis(). Skewness and kurtosis are the
+* biased Fisher-Pearson estimates.
+ */
+
+import (
+	"math"
+	"slices"
+)
+
+//============================================================================================================================== Quantiles
+
+// QuantileMethod selects how a quantile that falls between two sorted elements is computed
+type QuantileMethod int
+
+const (
+	QuantileLinear   QuantileMethod = iota // <--- linearly interpolate between the two elements
+	QuantileLower                          // <--- take the lower element
+	QuantileHigher                         // <--- take the higher element
+	QuantileNearest                        // <--- take the nearer element, rounding ties to the even index
+	QuantileMidpoint                       // <--- average the two elements
+)
+
+// QuantileReducer computes the Q'th quantile of a lane. A lane containing a NaN has a NaN quantile.
+type QuantileReducer struct {
+	Q      float64
+	Method QuantileMethod
+}
+
+func (r QuantileReducer) Reduce(lane []float64) float64 {
+
+	if len(lane) == 0 {
+		return math.NaN()
+	}
+
+	sorted := append([]float64(nil), lane...)
+	slices.SortFunc(sorted, compareAscending)
+	if math.IsNaN(sorted[len(sorted)-1]) {
+		return math.NaN()
+	}
+
+	// the quantile sits at a fractional position between sorted[lower] and sorted[lower + 1]
+	position := r.Q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	higher := min(lower+1, len(sorted)-1)
+	fraction := position - float64(lower)
+
+	switch r.Method {
+	case QuantileLower:
+		return sorted[lower]
+	case QuantileHigher:
+		if fraction == 0 {
+			return sorted[lower]
+		}
+		return sorted[higher]
+	case QuantileNearest:
+		return sorted[int(math.RoundToEven(position))]
+	case QuantileMidpoint:
+		if fraction == 0 {
+			return sorted[lower]
+		}
+		return (sorted[lower] + sorted[higher]) / 2
+	}
+	return sorted[lower] + fraction*(sorted[higher]-sorted[lower])
+}
+
+/*
+* @notice Quantile() computes the q'th quantile (0 <= q <= 1) of A over axes, using method to choose between the two sorted
+* elements the quantile falls between. An empty axes slice reduces over every axis.
+ */
+func (A *Tensor) Quantile(q float64, axes []int, keepdims bool, method QuantileMethod, batching bool) *Tensor {
+	if q < 0 || q > 1 {
+		panic("Within Quantile(): q must be between 0 and 1")
+	}
+	return A.reduce(axes, keepdims, QuantileReducer{Q: q, Method: method}, batching)
+}
+
+// Percentile() computes the p'th percentile (0 <= p <= 100) of A over axes. See Quantile().
+func (A *Tensor) Percentile(p float64, axes []int, keepdims bool, method QuantileMethod, batching bool) *Tensor {
+	if p < 0 || p > 100 {
+		panic("Within Percentile(): p must be between 0 and 100")
+	}
+	return A.Quantile(p/100, axes, keepdims, method, batching)
+}
+
+// Median() computes the median of A over axes, averaging the two middle elements of even length lanes.
+func (A *Tensor) Median(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.Quantile(0.5, axes, keepdims, QuantileLinear, batching)
+}
+
+//============================================================================================================================== Histogram(), Bincount()
+
+/*
+* @notice Histogram() counts the elements of A that fall into each of bins equal width bins spanning [lower, upper].
+* @dev If lower == upper, the range is taken from the smallest and largest elements of A. Every bin is half open [a, b),
+* except the last, which also includes upper. NaNs and elements outside the range are not counted.
+* @return counts: A Tensor of Shape [bins]
+* @return edges: A Tensor of Shape [bins + 1] holding the bin edges
+ */
+func (A *Tensor) Histogram(bins int, lower float64, upper float64) (*Tensor, *Tensor) {
+
+	if bins < 1 {
+		panic("Within Histogram(): bins must be at least 1")
+	}
+
+	if lower == upper {
+		lower, upper = math.Inf(1), math.Inf(-1)
+		for _, value := range A.Data {
+			if !math.IsNaN(value) {
+				lower, upper = math.Min(lower, value), math.Max(upper, value)
+			}
+		}
+		if lower > upper { // <--- no elements to take the range from
+			lower, upper = 0, 1
+		}
+		if lower == upper { // <--- widen a range of one value, as NumPy does
+			lower, upper = lower-0.5, upper+0.5
+		}
+	}
+	if lower > upper {
+		panic("Within Histogram(): lower must not be greater than upper")
+	}
+
+	edges := &Tensor{Shape: []int{bins + 1}, Data: make([]float64, bins+1)}
+	for i := range edges.Data {
+		edges.Data[i] = lower + (upper-lower)*float64(i)/float64(bins)
+	}
+	edges.Data[bins] = upper // <--- avoid rounding the last edge below the largest element
+	return A.HistogramEdges(edges), edges
+}
+
+/*
+* @notice HistogramEdges() counts the elements of A that fall into the bins given by an ascending vector of edges.
+* @dev Bin i is [edges[i], edges[i+1]), except the last, which also includes its right edge. The output has Shape [len(edges) - 1].
+ */
+func (A *Tensor) HistogramEdges(edges *Tensor) *Tensor {
+
+	if len(edges.Shape) != 1 || len(edges.Data) < 2 {
+		panic("Within HistogramEdges(): edges must be a vector of at least 2 elements")
+	}
+
+	bins := len(edges.Data) - 1
+	counts := &Tensor{Shape: []int{bins}, Data: make([]float64, bins)}
+
+	positions := SearchSorted(edges, A, true, false)
+	for i, position := range positions.Data {
+		bin := int(position) - 1
+		if bin == bins && A.Data[i] == edges.Data[bins] {
+			bin = bins - 1 // <--- the last bin includes its right edge
+		}
+		if bin >= 0 && bin < bins && !math.IsNaN(A.Data[i]) {
+			counts.Data[bin]++
+		}
+	}
+	return counts
+}
+
+/*
+* @notice Bincount() counts the occurrences of each non-negative integer in A, treating A as flat.
+* @dev If weights is not nil, it must have the shape of A and each occurrence adds its weight instead of 1.
+* @return A vector of length max(max(A) + 1, minLength)
+ */
+func (A *Tensor) Bincount(weights *Tensor, minLength int) *Tensor {
+
+	if weights != nil && !Same_Shape(A, weights) {
+		panic("Within Bincount(): weights must have the same shape as A")
+	}
+
+	length := minLength
+	for i := range A.Data {
+		value := indexAt(A, i, "Bincount")
+		if value < 0 {
+			panic("Within Bincount(): A must contain non-negative integers")
+		}
+		length = max(length, value+1)
+	}
+
+	counts := &Tensor{Shape: []int{length}, Data: make([]float64, length)}
+	for i, value := range A.Data {
+		if weights != nil {
+			counts.Data[int(value)] += weights.Data[i]
+		} else {
+			counts.Data[int(value)]++
+		}
+	}
+	return counts
+}
+
+//============================================================================================================================== Cov(), Corrcoef()
+
+/*
+* @notice Cov() computes the covariance matrix of X, a [samples, features] Tensor. Entry [i, j] is the covariance of
+* features i and j, normalized by samples - ddof. Use ddof = 1 for the unbiased sample covariance.
+* @return A [features, features] Tensor
+ */
+func Cov(X *Tensor, ddof int) *Tensor {
+
+	if len(X.Shape) != 2 {
+		panic("Within Cov(): X must be a 2D [samples, features] Tensor")
+	}
+	samples, features := X.Shape[0], X.Shape[1]
+	if samples-ddof <= 0 {
+		panic("Within Cov(): samples - ddof must be positive")
+	}
+
+	// center each feature, then C = Xc^T Xc / (samples - ddof)
+	means := X.Mean([]int{0}, false, false)
+	centered := &Tensor{Shape: []int{samples, features}, Data: make([]float64, len(X.Data))}
+	for i, value := range X.Data {
+		centered.Data[i] = value - means.Data[i%features]
+	}
+
+	C := MatMul(centered.Permute([]int{1, 0}), centered, false)
+	for i := range C.Data {
+		C.Data[i] /= float64(samples - ddof)
+	}
+	return C
+}
+
+// Corrcoef() computes the Pearson correlation matrix of X, a [samples, features] Tensor. Constant features have NaN correlations.
+func Corrcoef(X *Tensor) *Tensor {
+
+	C := Cov(X, 0)
+	features := C.Shape[0]
+
+	stds := make([]float64, features)
+	for i := range stds {
+		stds[i] = math.Sqrt(C.Data[i*features+i])
+	}
+	for i := 0; i < features; i++ {
+		for j := 0; j < features; j++ {
+			C.Data[i*features+j] = math.Max(-1, math.Min(1, C.Data[i*features+j]/(stds[i]*stds[j]))) // <--- clip rounding error
+		}
+	}
+	return C
+}
+
+//============================================================================================================================== Skewness and Kurtosis of All Elements
+
+// CentralMomentAllOperation computes the order'th central moment over the entire tensor.
+type CentralMomentAllOperation struct {
+	mean  float64
+	order float64
+	n     int
+}
+
+// Apply sums (x - mean)^order over a chunk of the tensor's data for a go routine.
+func (m CentralMomentAllOperation) Apply(A *Tensor, start, end int) float64 {
+	var sum float64
+	for i := start; i < end; i++ {
+		sum += math.Pow(A.Data[i]-m.mean, m.order)
+	}
+	return sum
+}
+
+// CombineResults sums the chunks and divides by the number of elements.
+func (m CentralMomentAllOperation) CombineResults(results []float64) float64 {
+	return SumAllOperation{}.CombineResults(results) / float64(m.n)
+}
+
+// centralMoment computes the order'th central moment of every element of A
+func (A *Tensor) centralMoment(mean float64, order float64) float64 {
+	return A.ScalarCollapseOp(CentralMomentAllOperation{mean: mean, order: order, n: len(A.Data)})
+}
+
+// Skew_All() computes the skewness of all elements of A, m3 / m2^1.5.
+func (A *Tensor) Skew_All() float64 {
+	mean := SumReducer{}.Reduce(A.Data) / float64(len(A.Data))
+	return A.centralMoment(mean, 3) / math.Pow(A.centralMoment(mean, 2), 1.5)
+}
+
+// Kurtosis_All() computes the excess kurtosis of all elements of A, m4 / m2^2 - 3, which is 0 for a normal distribution.
+func (A *Tensor) Kurtosis_All() float64 {
+	mean := SumReducer{}.Reduce(A.Data) / float64(len(A.Data))
+	m2 := A.centralMoment(mean, 2)
+	return A.centralMoment(mean, 4)/(m2*m2) - 3
+}
+
+//============================================================================================================================== Skewness and Kurtosis Over Axes
+
+type SkewReducer struct{}
+type KurtosisReducer struct{}
+
+// centralMoments returns the 2nd, 3rd and 4th central moments of a lane
+func centralMoments(lane []float64) (float64, float64, float64) {
+	mean := MeanReducer{}.Reduce(lane)
+	var m2, m3, m4 float64
+	for _, value := range lane {
+		d := value - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	n := float64(len(lane))
+	return m2 / n, m3 / n, m4 / n
+}
+
+func (r SkewReducer) Reduce(lane []float64) float64 {
+	m2, m3, _ := centralMoments(lane)
+	return m3 / math.Pow(m2, 1.5)
+}
+
+func (r KurtosisReducer) Reduce(lane []float64) float64 {
+	m2, _, m4 := centralMoments(lane)
+	return m4/(m2*m2) - 3
+}
+
+// Skew() computes the skewness of A over axes.
+func (A *Tensor) Skew(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, SkewReducer{}, batching)
+}
+
+// Kurtosis() computes the excess kurtosis of A over axes.
+func (A *Tensor) Kurtosis(axes []int, keepdims bool, batching bool) *Tensor {
+	return A.reduce(axes, keepdims, KurtosisReducer{}, batching)
+}
+
+//============================================================================================================================== Weighted Mean and Variance
+
+// WeightedMomentOp computes the weighted mean, or weighted population variance, of each lane along an axis.
+type WeightedMomentOp struct {
+	axis     int
+	weights  []float64
+	variance bool
+}
+
+func (op WeightedMomentOp) OutputLength(n int) int {
+	if n != len(op.weights) {
+		panic("Within WeightedMean()/WeightedVar(): weights must have one element per element of the axis")
+	}
+	return 1
+}
+
+func (op WeightedMomentOp) Apply_LaneOp(lane []float64, out []float64) {
+
+	var sum, total float64
+	for k, value := range lane {
+		sum += op.weights[k] * value
+		total += op.weights[k]
+	}
+	mean := sum / total
+
+	if !op.variance {
+		out[0] = mean
+		return
+	}
+
+	var variance float64
+	for k, value := range lane {
+		variance += op.weights[k] * (value - mean) * (value - mean)
+	}
+	out[0] = variance / total
+}
+
+func (op WeightedMomentOp) Execute(tensors ...*Tensor) *Tensor {
+	C := tensors[0].AxisLaneOperation(op.axis, op)
+	C.Shape = append(C.Shape[:op.axis], C.Shape[op.axis+1:]...) // <--- the axis is collapsed
+	if len(C.Shape) == 0 {
+		C.Shape = []int{1} // <--- a vector collapses to a scalar
+	}
+	return C
+}
+
+// weightedMoment runs a WeightedMomentOp with optional batching
+func (A *Tensor) weightedMoment(weights *Tensor, axis int, variance bool, batching bool) *Tensor {
+
+	if len(weights.Shape) != 1 {
+		panic("Within WeightedMean()/WeightedVar(): weights must be a vector")
+	}
+	op := WeightedMomentOp{axis: axis, weights: weights.Data, variance: variance}
+
+	if batching {
+		return BatchedOperation(op, A) // batched op
+	}
+	return op.Execute(A) // single op
+}
+
+// WeightedMean() computes sum(w * x) / sum(w) along axis, collapsing it. weights is a vector with one weight per element of the axis.
+func (A *Tensor) WeightedMean(weights *Tensor, axis int, batching bool) *Tensor {
+	return A.weightedMoment(weights, axis, false, batching)
+}
+
+// WeightedVar() computes sum(w * (x - mean)^2) / sum(w) along axis, collapsing it, where mean is the weighted mean.
+func (A *Tensor) WeightedVar(weights *Tensor, axis int, batching bool) *Tensor {
+	return A.weightedMoment(weights, axis, true, batching)
+}
@@ -100,20 +100,21 @@ func RangeTensor(shape []int, batching bool) *Tensor {
 type RandomInitializer struct {
 	min    float64
 	max    float64
-	random *Random
+	random *Generator
 }
 
 func (ri *RandomInitializer) ValueAt(index int) float64 { // <-- sets each element
-	return ri.random.RandInRangeFloat(ri.min, ri.max)
+	return ri.random.Uniform(ri.min, ri.max)
 }
 
 func (ri *RandomInitializer) Execute(shape []int) *Tensor { // <--- Execute() from Batched_Initializer_Operation() in batching.go
 	return InitializeData(shape, ri)
 }
 
+// RandFloat64Tensor() samples each element uniformly from [lower, upper) using the default Generator (see Random.go).
 func RandFloat64Tensor(shape []int, lower float64, upper float64, batching bool) *Tensor {
 
-	random := NewRandom() // <--- NewRandom() is a function from utils.go
+	random := DefaultGenerator() // <--- DefaultGenerator() is a function from Random.go
 	ri := &RandomInitializer{min: lower, max: upper, random: random}
 
 	var A *Tensor
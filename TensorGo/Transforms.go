@@ -0,0 +1,403 @@
+package TG
+
+/*
+* @notice Transforms.go contains image transforms for preprocessing and augmentation, and conversions between image.Image and Tensor.
+* @dev Images are [channels, height, width] Tensors with values in [0, 1]. Transforms also accept [height, width] images, which
+* are treated as a single channel and keep their rank.
+* @dev Every transform implements ImageTransform. Random transforms draw their parameters from the Generator they are given,
+* so a seeded Generator reproduces the same augmentation. Compose() chains transforms into a pipeline.
+* @dev ApplyTransform() with batching runs a transform on each image of an [N, C, H, W] batch in parallel. A seed for each
+* image is drawn from the Generator in order beforehand, and each image is transformed with its own Generator, so results do
+* not depend on scheduling.
+ */
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// ImageTransform transforms a single image
+type ImageTransform interface {
+	Transform(image *Tensor, g *Generator) *Tensor
+}
+
+// TransformFunc adapts a function to an ImageTransform
+type TransformFunc func(image *Tensor, g *Generator) *Tensor
+
+func (f TransformFunc) Transform(image *Tensor, g *Generator) *Tensor {
+	return f(image, g)
+}
+
+// ImagePipeline is a sequence of image transforms applied in order
+type ImagePipeline []ImageTransform
+
+// Compose() chains transforms into an ImagePipeline.
+func Compose(transforms ...ImageTransform) ImagePipeline {
+	return ImagePipeline(transforms)
+}
+
+func (p ImagePipeline) Transform(image *Tensor, g *Generator) *Tensor {
+	for _, t := range p {
+		image = t.Transform(image, g)
+	}
+	return image
+}
+
+//============================================================================================================================== ApplyTransform()
+
+/*
+* @notice ApplyTransform() applies t to the image A, or with batching to each image of the batch A, drawing randomness from g.
+* @dev g is DefaultGenerator() when nil. A batch is [N, C, H, W] or [N, H, W]. Each image is sliced from the batch with its
+* full shape, so singleton channel, height or width dims are kept. Every image must transform to the same shape.
+* @dev Batching does not go through BatchedOperation(): Batchify() removes every singleton dim from a batch element, which
+* would turn a [N, 1, H, W] batch into [H, W] images, and an op is not told which element it runs on, which the Generator of
+* each image needs.
+ */
+func (A *Tensor) ApplyTransform(t ImageTransform, g *Generator, batching bool) *Tensor {
+
+	if g == nil {
+		g = DefaultGenerator()
+	}
+	if !batching {
+		return t.Transform(A, g)
+	}
+
+	// a Generator for each image, seeded in order
+	n := A.Shape[0]
+	generators := make([]*Generator, n)
+	for i := range generators {
+		generators[i] = NewGenerator(int64(g.Intn(1 << 52)))
+	}
+
+	imageShape := A.Shape[1:]
+	size := Product(imageShape)
+	images := make([]*Tensor, n)
+	parallelFor(n, func(i int) {
+		image := &Tensor{Shape: append([]int(nil), imageShape...), Data: A.Data[i*size : (i+1)*size]}
+		images[i] = t.Transform(image, generators[i])
+	})
+
+	// stack the transformed images, copying them in case a transform returned its input
+	C := &Tensor{Shape: []int{n}, Batched: A.Batched}
+	for _, image := range images {
+		if !isEqual(image.Shape, images[0].Shape) {
+			panic("Within ApplyTransform(): the images of a batch must transform to the same shape")
+		}
+		C.Data = append(C.Data, image.Data...)
+	}
+	if n > 0 {
+		C.Shape = append(C.Shape, images[0].Shape...)
+	}
+	return C
+}
+
+//============================================================================================================================== Helpers
+
+// imageDims returns the channels, height and width of an image
+func imageDims(A *Tensor, caller string) (int, int, int) {
+	switch len(A.Shape) {
+	case 2:
+		return 1, A.Shape[0], A.Shape[1]
+	case 3:
+		return A.Shape[0], A.Shape[1], A.Shape[2]
+	}
+	panic("Within " + caller + "(): images must be [channels, height, width] or [height, width]")
+}
+
+// newImage allocates an image with the rank of like
+func newImage(like *Tensor, channels, height, width int) *Tensor {
+	shape := []int{channels, height, width}
+	if len(like.Shape) == 2 {
+		shape = []int{height, width}
+	}
+	return &Tensor{Shape: shape, Data: make([]float64, channels*height*width)}
+}
+
+// mapPixels builds an image of the given size where each pixel (c, y, x) is src(c, y, x)
+func mapPixels(A *Tensor, height, width int, src func(plane []float64, y, x int) float64) *Tensor {
+	channels, h, w := imageDims(A, "Transform")
+	C := newImage(A, channels, height, width)
+	for c := 0; c < channels; c++ {
+		plane := A.Data[c*h*w : (c+1)*h*w]
+		out := C.Data[c*height*width : (c+1)*height*width]
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				out[y*width+x] = src(plane, y, x)
+			}
+		}
+	}
+	return C
+}
+
+// bilinear samples plane at the fractional position (y, x), returning fill outside of the image
+func bilinear(plane []float64, h, w int, y, x float64, fill float64) float64 {
+	if y < -1 || y > float64(h) || x < -1 || x > float64(w) {
+		return fill
+	}
+	y0, x0 := int(math.Floor(y)), int(math.Floor(x))
+	dy, dx := y-float64(y0), x-float64(x0)
+
+	at := func(yy, xx int) float64 {
+		if yy < 0 || yy >= h || xx < 0 || xx >= w {
+			return fill
+		}
+		return plane[yy*w+xx]
+	}
+	top := at(y0, x0)*(1-dx) + at(y0, x0+1)*dx
+	bottom := at(y0+1, x0)*(1-dx) + at(y0+1, x0+1)*dx
+	return top*(1-dy) + bottom*dy
+}
+
+// clampUnit clamps a pixel value to [0, 1]
+func clampUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
+//============================================================================================================================== Geometric Transforms
+
+// InterpolationMethod chooses how pixels are sampled between source pixels
+type InterpolationMethod int
+
+const (
+	InterpolationNearest InterpolationMethod = iota
+	InterpolationBilinear
+)
+
+// Resize resizes an image to Height x Width
+type Resize struct {
+	Height, Width int
+	Method        InterpolationMethod
+}
+
+func (t Resize) Transform(A *Tensor, g *Generator) *Tensor {
+	_, h, w := imageDims(A, "Resize")
+	scaleY, scaleX := float64(h)/float64(t.Height), float64(w)/float64(t.Width)
+
+	return mapPixels(A, t.Height, t.Width, func(plane []float64, y, x int) float64 {
+		// pixel centers are aligned, as with PyTorch's align_corners=False
+		srcY, srcX := (float64(y)+0.5)*scaleY-0.5, (float64(x)+0.5)*scaleX-0.5
+		if t.Method == InterpolationNearest {
+			yy := min(h-1, int(math.Floor(float64(y)*scaleY)))
+			xx := min(w-1, int(math.Floor(float64(x)*scaleX)))
+			return plane[yy*w+xx]
+		}
+		// clamp to the edge so that borders are not blended with the fill
+		srcY, srcX = math.Max(0, math.Min(float64(h-1), srcY)), math.Max(0, math.Min(float64(w-1), srcX))
+		return bilinear(plane, h, w, srcY, srcX, 0)
+	})
+}
+
+// crop returns the Height x Width region of A starting at (top, left)
+func crop(A *Tensor, top, left, height, width int) *Tensor {
+	_, h, w := imageDims(A, "Crop")
+	if top < 0 || left < 0 || top+height > h || left+width > w {
+		panic("Within Crop(): the crop must fit inside the image")
+	}
+	return mapPixels(A, height, width, func(plane []float64, y, x int) float64 {
+		return plane[(top+y)*w+left+x]
+	})
+}
+
+// CenterCrop crops the center Height x Width region of an image
+type CenterCrop struct{ Height, Width int }
+
+func (t CenterCrop) Transform(A *Tensor, g *Generator) *Tensor {
+	_, h, w := imageDims(A, "CenterCrop")
+	return crop(A, (h-t.Height)/2, (w-t.Width)/2, t.Height, t.Width)
+}
+
+// RandomCrop crops a random Height x Width region of an image, after padding each side with Padding zeros
+type RandomCrop struct{ Height, Width, Padding int }
+
+func (t RandomCrop) Transform(A *Tensor, g *Generator) *Tensor {
+	if t.Padding > 0 {
+		A = Pad{Top: t.Padding, Bottom: t.Padding, Left: t.Padding, Right: t.Padding}.Transform(A, g)
+	}
+	_, h, w := imageDims(A, "RandomCrop")
+	if t.Height > h || t.Width > w {
+		panic("Within RandomCrop(): the crop must fit inside the image")
+	}
+	return crop(A, g.Intn(h-t.Height+1), g.Intn(w-t.Width+1), t.Height, t.Width)
+}
+
+// Pad pads each side of an image with Value
+type Pad struct {
+	Top, Bottom, Left, Right int
+	Value                    float64
+}
+
+func (t Pad) Transform(A *Tensor, g *Generator) *Tensor {
+	_, h, w := imageDims(A, "Pad")
+	return mapPixels(A, h+t.Top+t.Bottom, w+t.Left+t.Right, func(plane []float64, y, x int) float64 {
+		yy, xx := y-t.Top, x-t.Left
+		if yy < 0 || yy >= h || xx < 0 || xx >= w {
+			return t.Value
+		}
+		return plane[yy*w+xx]
+	})
+}
+
+// RandomHorizontalFlip mirrors an image left to right with probability P. P = 1 always flips.
+type RandomHorizontalFlip struct{ P float64 }
+
+func (t RandomHorizontalFlip) Transform(A *Tensor, g *Generator) *Tensor {
+	if g.Float64() >= t.P {
+		return A.Copy()
+	}
+	_, h, w := imageDims(A, "RandomHorizontalFlip")
+	return mapPixels(A, h, w, func(plane []float64, y, x int) float64 { return plane[y*w+w-1-x] })
+}
+
+// RandomVerticalFlip mirrors an image top to bottom with probability P. P = 1 always flips.
+type RandomVerticalFlip struct{ P float64 }
+
+func (t RandomVerticalFlip) Transform(A *Tensor, g *Generator) *Tensor {
+	if g.Float64() >= t.P {
+		return A.Copy()
+	}
+	_, h, w := imageDims(A, "RandomVerticalFlip")
+	return mapPixels(A, h, w, func(plane []float64, y, x int) float64 { return plane[(h-1-y)*w+x] })
+}
+
+// Rotate rotates an image counterclockwise by Degrees about its center, keeping its size. Uncovered pixels are set to Fill.
+type Rotate struct {
+	Degrees float64
+	Fill    float64
+}
+
+func (t Rotate) Transform(A *Tensor, g *Generator) *Tensor {
+	_, h, w := imageDims(A, "Rotate")
+	theta := t.Degrees * math.Pi / 180
+	cos, sin := math.Cos(theta), math.Sin(theta)
+	centerY, centerX := float64(h-1)/2, float64(w-1)/2
+
+	return mapPixels(A, h, w, func(plane []float64, y, x int) float64 {
+		// rotate each output pixel back to find its source, with y pointing down
+		dy, dx := float64(y)-centerY, float64(x)-centerX
+		srcX := cos*dx - sin*dy + centerX
+		srcY := sin*dx + cos*dy + centerY
+		return bilinear(plane, h, w, srcY, srcX, t.Fill)
+	})
+}
+
+// RandomRotation rotates an image by an angle drawn uniformly from [-MaxDegrees, MaxDegrees]
+type RandomRotation struct {
+	MaxDegrees float64
+	Fill       float64
+}
+
+func (t RandomRotation) Transform(A *Tensor, g *Generator) *Tensor {
+	return Rotate{Degrees: g.Uniform(-t.MaxDegrees, t.MaxDegrees), Fill: t.Fill}.Transform(A, g)
+}
+
+//============================================================================================================================== Color Transforms
+
+// Normalize subtracts Mean and divides by Std per channel. A single Mean and Std apply to every channel.
+type Normalize struct{ Mean, Std []float64 }
+
+func (t Normalize) Transform(A *Tensor, g *Generator) *Tensor {
+	channels, h, w := imageDims(A, "Normalize")
+	if (len(t.Mean) != 1 && len(t.Mean) != channels) || len(t.Std) != len(t.Mean) {
+		panic("Within Normalize(): Mean and Std must have one value, or one value per channel")
+	}
+	C := newImage(A, channels, h, w)
+	for c := 0; c < channels; c++ {
+		mean, std := t.Mean[min(c, len(t.Mean)-1)], t.Std[min(c, len(t.Std)-1)]
+		for i := c * h * w; i < (c+1)*h*w; i++ {
+			C.Data[i] = (A.Data[i] - mean) / std
+		}
+	}
+	return C
+}
+
+/*
+* @notice ColorJitter randomly changes the brightness, contrast and saturation of an image.
+* @dev Each factor is drawn uniformly from [1 - x, 1 + x] for a setting x. Brightness scales every pixel, contrast blends each
+* pixel with the mean gray level, and saturation blends each pixel with its own gray level (3 channel images only).
+* Results are clamped to [0, 1].
+ */
+type ColorJitter struct{ Brightness, Contrast, Saturation float64 }
+
+func (t ColorJitter) Transform(A *Tensor, g *Generator) *Tensor {
+	channels, h, w := imageDims(A, "ColorJitter")
+	plane := h * w
+	C := A.Copy()
+	C.DataReqGrad, C.RequireGrad = nil, false
+
+	// gray returns the luma of pixel i
+	gray := func(i int) float64 {
+		if channels != 3 {
+			return C.Data[i]
+		}
+		return 0.299*C.Data[i] + 0.587*C.Data[plane+i] + 0.114*C.Data[2*plane+i]
+	}
+
+	if t.Brightness > 0 {
+		factor := g.Uniform(1-t.Brightness, 1+t.Brightness)
+		for i := range C.Data {
+			C.Data[i] = clampUnit(C.Data[i] * factor)
+		}
+	}
+	if t.Contrast > 0 {
+		factor := g.Uniform(1-t.Contrast, 1+t.Contrast)
+		mean := 0.0
+		for i := 0; i < plane; i++ {
+			mean += gray(i)
+		}
+		mean /= float64(plane)
+		for i := range C.Data {
+			C.Data[i] = clampUnit(mean + factor*(C.Data[i]-mean))
+		}
+	}
+	if t.Saturation > 0 && channels == 3 {
+		factor := g.Uniform(1-t.Saturation, 1+t.Saturation)
+		for i := 0; i < plane; i++ {
+			luma := gray(i)
+			for c := 0; c < 3; c++ {
+				C.Data[c*plane+i] = clampUnit(luma + factor*(C.Data[c*plane+i]-luma))
+			}
+		}
+	}
+	return C
+}
+
+//============================================================================================================================== Conversions
+
+// ImageToTensor() converts an image to a [channels, height, width] Tensor with values in [0, 1]. Color images have 3 channels.
+func ImageToTensor(img image.Image, grayscale bool) *Tensor {
+	return imageToCHW(img, grayscale)
+}
+
+/*
+* @notice TensorToImage() converts a [channels, height, width] or [height, width] Tensor with values in [0, 1] to an image.
+* @dev 1 channel images become *image.Gray, 3 channel images become *image.RGBA and 4 channel images become *image.NRGBA
+* with the fourth channel as alpha. Values are clamped to [0, 1].
+ */
+func TensorToImage(A *Tensor) image.Image {
+	channels, h, w := imageDims(A, "TensorToImage")
+	plane := h * w
+	level := func(i int) uint8 { return uint8(math.Round(clampUnit(A.Data[i]) * 255)) }
+
+	switch channels {
+	case 1:
+		img := image.NewGray(image.Rect(0, 0, w, h))
+		for i := 0; i < plane; i++ {
+			img.Pix[i] = level(i)
+		}
+		return img
+	case 3:
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for i := 0; i < plane; i++ {
+			img.SetRGBA(i%w, i/w, color.RGBA{R: level(i), G: level(plane + i), B: level(2*plane + i), A: 255})
+		}
+		return img
+	case 4:
+		img := image.NewNRGBA(image.Rect(0, 0, w, h))
+		for i := 0; i < plane; i++ {
+			img.SetNRGBA(i%w, i/w, color.NRGBA{R: level(i), G: level(plane + i), B: level(2*plane + i), A: level(3*plane + i)})
+		}
+		return img
+	}
+	panic("Within TensorToImage(): images must have 1, 3 or 4 channels")
+}
@@ -3,10 +3,14 @@ package TG
 // utils.go contains helper functions for this projects
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 	"time"
 )
 
+// Random is a clock seeded random number generator.
+// Deprecated: Use Generator from Random.go, which can be seeded for reproducibility.
 type Random struct {
 	rnd *rand.Rand
 }
@@ -33,6 +37,37 @@ func Product(shape []int) int {
 	return product
 }
 
+// checkedProduct returns the number of elements in shape. It reports false if a dim is negative, or if the elements would
+// take more than math.MaxInt bytes at itemSize bytes each. Readers use it before trusting a shape read from a file.
+func checkedProduct(shape []int, itemSize int) (int, bool) {
+	product := 1
+	for _, dim := range append([]int{itemSize}, shape...) {
+		if dim < 0 || (dim > 0 && product > math.MaxInt/dim) {
+			return 0, false
+		}
+		product *= dim
+	}
+	return product / itemSize, true
+}
+
+// storedValues returns the elements of A for the writers. Gradient tracked Tensors built without Data, such as the weights
+// of Linear(), hold their elements in the Scalars of DataReqGrad. It returns an error unless A holds one element for every
+// position of its Shape.
+func storedValues(A *Tensor, caller string) ([]float64, error) {
+	n := Product(A.Shape)
+	if len(A.Data) == n {
+		return A.Data, nil
+	}
+	if len(A.Data) == 0 && len(A.DataReqGrad) == n {
+		values := make([]float64, n)
+		for i, value := range A.DataReqGrad {
+			values[i] = value.Scalar
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%v(): a Tensor of Shape %v must hold %d elements, it holds %d", caller, A.Shape, n, max(len(A.Data), len(A.DataReqGrad)))
+}
+
 // This function checks if two tensors are of
 // the same shape. It returns a boolean
 func Same_Shape(A *Tensor, B *Tensor) bool {
@@ -100,3 +135,14 @@ func SameVectorSize(t1 *Tensor, t2 *Tensor) bool {
 	}
 	return true
 }
+
+// rowMajorStrides returns the number of elements to skip to move one index along each dimension of a contiguous Tensor with the given shape
+func rowMajorStrides(shape []int) []int {
+	strides := make([]int, len(shape))
+	stride := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= shape[i]
+	}
+	return strides
+}
@@ -299,17 +299,9 @@ func (op ArgmaxVectorOp) Execute(tensors ...*Tensor) *Tensor {
 
 	A := tensors[0]
 
-	var max float64 = -math.MaxFloat64
-
-	for i := 0; i < len(A.Data); i++ {
-		if A.Data[i] > max {
-			max = A.Data[i]
-		}
-	}
-
-	// create a tensor with one element to store the argmax product
+	// create a tensor with one element to store the index of the maximum element
 	argmaxTensor := ZeroTensor([]int{1}, false)
-	argmaxTensor.Data[0] = max
+	ArgExtremumOp{largest: true}.Apply_LaneOp(A.Data, argmaxTensor.Data)
 
 	return argmaxTensor
 }
@@ -317,6 +309,7 @@ func (op ArgmaxVectorOp) Execute(tensors ...*Tensor) *Tensor {
 /*
 * @notice ArgmaxVector() is a function that is used to compute the argmax of a vector Tensor. It returns a single element Tensor
 * with the index of the maximum element in the vector.
+* @dev ArgmaxVector() used to return the maximum value rather than its index. Use Max() for the value.
 * @param A: The vector Tensor to compute the argmax of.
 * @param batching: A boolean that indicates whether the Tensor is being used as a batch of Tensors or not.
  */
@@ -330,3 +323,71 @@ func ArgmaxVector(A *Tensor, batching bool) *Tensor {
 	}
 	return argmax.Execute(A) // single op
 }
+
+//============================================================================================================================== PairwiseDistances()
+
+type PairwiseDistancesOp struct{ squared bool }
+
+// pairwiseRecompute is the fraction of x·x + y·y below which a squared distance from the expansion has cancelled too far to
+// keep, and is recomputed from the differences of the rows
+const pairwiseRecompute = 1e-4
+
+func (op PairwiseDistancesOp) Execute(tensors ...*Tensor) *Tensor {
+
+	X, Y := tensors[0], tensors[1]
+
+	if len(X.Shape) != 2 || len(Y.Shape) != 2 || X.Shape[1] != Y.Shape[1] {
+		panic("Within PairwiseDistances(): X must be [m, features] and Y must be [n, features]")
+	}
+	m, n, k := X.Shape[0], Y.Shape[0], X.Shape[1]
+
+	// center the rows of X and Y on their common mean, so rows far from the origin do not lose the expansion below to rounding
+	mean := ZeroTensor([]int{k}, false)
+	for _, A := range []*Tensor{X, Y} {
+		for i, value := range A.Data {
+			mean.Data[i%k] += value / float64(m+n)
+		}
+	}
+	X, Y = centerRows(X, mean), centerRows(Y, mean)
+
+	// ||x - y||² = x·x - 2 x·y + y·y, where the cross terms are the dot products of every pair of rows
+	D := MatMulTransposeB(X, Y, false)
+	yNorms := make([]float64, n)
+	for j := range yNorms {
+		yNorms[j] = dotSlices(Y.Data[j*k:(j+1)*k], Y.Data[j*k:(j+1)*k])
+	}
+	parallelForIfLarge(m*n, m, func(i int) {
+		x := X.Data[i*k : (i+1)*k]
+		xNorm := dotSlices(x, x)
+		row := D.Data[i*n : (i+1)*n]
+		for j := range row {
+			distance := xNorm - 2*row[j] + yNorms[j]
+			if distance < pairwiseRecompute*(xNorm+yNorms[j]) {
+				distance = euclidean(x, Y.Data[j*k:(j+1)*k]) // <--- near pairs cancel, so subtract them directly
+				distance *= distance
+			}
+			row[j] = math.Max(0, distance)
+			if !op.squared {
+				row[j] = math.Sqrt(row[j])
+			}
+		}
+	})
+	return D
+}
+
+/*
+* @notice PairwiseDistances() computes the euclidean distance between every row of X and every row of Y, returning an [m, n]
+* Tensor for X [m, features] and Y [n, features]. With squared, the squared distances are returned. There is optional batching.
+* @dev Distances are computed from dot products, as the norms of the rows and one matrix product, rather than by subtracting
+* every pair of rows. The rows are first centered on their common mean, and pairs so close that the dot products cancel are
+* recomputed from their differences, so distances keep their precision far from the origin.
+ */
+func PairwiseDistances(X *Tensor, Y *Tensor, squared bool, batching bool) *Tensor {
+
+	op := PairwiseDistancesOp{squared: squared}
+
+	if batching {
+		return BatchedOperation(op, X, Y)
+	}
+	return op.Execute(X, Y)
+}
@@ -0,0 +1,98 @@
+package TG
+
+/*
+* @notice WorkerPool.go contains the shared pool of goroutines used to parallelize batched operations and other kernels.
+* @dev The pool is a global semaphore of helper slots. A call to parallelFor() always does work on the calling goroutine and
+* borrows as many free helper slots as it can use. Because helpers are only borrowed when free, nested parallel calls (such
+* as a batched op whose elements run MatMul) can never deadlock, and the total number of busy goroutines across the whole
+* library stays bounded by the parallelism level.
+ */
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// helpers holds the semaphore of helper slots. It is swapped atomically by SetParallelism().
+var helpers atomic.Pointer[chan struct{}]
+
+func init() {
+	SetParallelism(0)
+}
+
+/*
+* @notice SetParallelism() sets the maximum number of goroutines used to execute parallel operations.
+* @param n: the parallelism level. n <= 0 resets it to runtime.GOMAXPROCS(0). n == 1 runs everything on the calling goroutine.
+ */
+func SetParallelism(n int) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	sem := make(chan struct{}, n-1) // <--- the calling goroutine is the n'th worker
+	helpers.Store(&sem)
+}
+
+// Parallelism() returns the current maximum number of goroutines used to execute parallel operations.
+func Parallelism() int {
+	return cap(*helpers.Load()) + 1
+}
+
+// parallelFor calls task(i) for each i in [0, numTasks), returning once all tasks are done.
+func parallelFor(numTasks int, task func(int)) {
+	parallelForContext(context.Background(), numTasks, task)
+}
+
+/*
+* @notice parallelForContext calls task(i) for each i in [0, numTasks) across the shared pool.
+* @dev Tasks are claimed from an atomic counter, so workers that finish early keep taking tasks. Once ctx is done no new
+* tasks are started and ctx.Err() is returned. A panic inside a task is recovered and re-raised on the calling goroutine.
+ */
+func parallelForContext(ctx context.Context, numTasks int, task func(int)) error {
+
+	sem := *helpers.Load()
+
+	var wg sync.WaitGroup
+	var next int64 = -1
+	var panicOnce sync.Once
+	var panicValue interface{}
+
+	work := func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicOnce.Do(func() { panicValue = r })
+				atomic.StoreInt64(&next, int64(numTasks)) // <--- stop handing out tasks
+			}
+		}()
+		for i := int(atomic.AddInt64(&next, 1)); i < numTasks; i = int(atomic.AddInt64(&next, 1)) {
+			if ctx.Err() != nil {
+				return
+			}
+			task(i)
+		}
+	}
+
+	// borrow free helper slots, never more than there are tasks for
+borrow:
+	for h := 1; h < numTasks; h++ {
+		select {
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				work()
+			}()
+		default:
+			break borrow // <--- pool is saturated
+		}
+	}
+
+	work()
+	wg.Wait()
+
+	if panicValue != nil {
+		panic(panicValue)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,1203 @@
+package TG
+
+/*
+* @notice Zstd.go contains a standard library only implementation of the Zstandard format (RFC 8878), the codec behind
+* CompressionZstd.
+* @dev The decoder reads any frame that does not need a dictionary: raw, RLE and compressed blocks, Huffman coded literals and
+* FSE coded sequences. It verifies the content checksum when the frame has one, and reads exactly the bytes of one frame, so
+* records that follow in the same stream can be read.
+* @dev The encoder finds matches with a hash table over a 1 MiB window, stores literals raw and codes sequences with the
+* predefined FSE tables. It compresses less than the reference encoder, but writes standard frames with a content checksum,
+* which any zstd decoder reads.
+ */
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+const (
+	zstdMagic          = 0xFD2FB528
+	zstdMaxBlockSize   = 1 << 17
+	zstdMaxWindowLog   = 27 // <--- the largest window a frame may ask the decoder to keep, as in the reference decoder
+	zstdWindowLog      = 20 // <--- the window of the frames the encoder writes
+	zstdMinMatch       = 4
+	zstdHashLog        = 16
+	zstdSkippableMagic = 0x184D2A50 // <--- skippable frames use the 16 magic numbers from here
+)
+
+var errZstdCorrupt = errors.New("corrupt zstd data")
+
+// the codes of literal lengths, match lengths and offsets are decoded with one FSE table each, in this order
+const (
+	zstdLiteralLengths = iota
+	zstdOffsets
+	zstdMatchLengths
+)
+
+var (
+	zstdMaxSymbol   = [3]int{35, 31, 52}
+	zstdMaxTableLog = [3]uint8{9, 8, 9}
+
+	zstdLiteralLengthBase = [36]uint32{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		16, 18, 20, 22, 24, 28, 32, 40, 48, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536,
+	}
+	zstdLiteralLengthBits = [36]uint8{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+	}
+	zstdMatchLengthBase = [53]uint32{
+		3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34,
+		35, 37, 39, 41, 43, 47, 51, 59, 67, 83, 99, 131, 259, 515, 1027, 2051, 4099, 8195, 16387, 32771, 65539,
+	}
+	zstdMatchLengthBits = [53]uint8{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 4, 5, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+	}
+
+	// the predefined distributions of each code, with their accuracy logs
+	zstdPredefinedCounts = [3][]int16{
+		{4, 3, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 3, 2, 1, 1, 1, 1, 1, -1, -1, -1, -1},
+		{1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1},
+		{1, 4, 3, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+			1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1, -1, -1},
+	}
+	zstdPredefinedLog = [3]uint8{6, 5, 6}
+
+	zstdPredefinedTables   [3]*zstdFSETable
+	zstdPredefinedEncoders [3]*zstdFSEEncoder
+)
+
+func init() {
+	for k := range zstdPredefinedCounts {
+		table, err := buildZstdFSETable(zstdPredefinedCounts[k], zstdPredefinedLog[k])
+		if err != nil {
+			panic(err)
+		}
+		zstdPredefinedTables[k] = table
+		zstdPredefinedEncoders[k] = buildZstdFSEEncoder(zstdPredefinedCounts[k], zstdPredefinedLog[k])
+	}
+}
+
+//===================================================================================================================== XXH64
+
+// xxh64 computes the 64 bit xxHash of a stream with seed 0, whose low 32 bits are a frame's content checksum
+type xxh64 struct {
+	v     [4]uint64
+	total uint64
+	buf   [32]byte
+	n     int
+}
+
+const (
+	xxhPrime1 uint64 = 11400714785074694791
+	xxhPrime2 uint64 = 14029467366897019727
+	xxhPrime3 uint64 = 1609587929392839161
+	xxhPrime4 uint64 = 9650029242287828579
+	xxhPrime5 uint64 = 2870177450012600261
+)
+
+func newXXH64() *xxh64 {
+	p1, p2 := xxhPrime1, xxhPrime2 // <--- variables, so the seed's additions wrap around
+	return &xxh64{v: [4]uint64{p1 + p2, p2, 0, -p1}}
+}
+
+func xxhRound(acc, input uint64) uint64 {
+	return bits.RotateLeft64(acc+input*xxhPrime2, 31) * xxhPrime1
+}
+
+func (x *xxh64) Write(p []byte) {
+	x.total += uint64(len(p))
+	if x.n > 0 {
+		k := copy(x.buf[x.n:], p)
+		x.n, p = x.n+k, p[k:]
+		if x.n < 32 {
+			return
+		}
+		x.stripe(x.buf[:])
+		x.n = 0
+	}
+	for ; len(p) >= 32; p = p[32:] {
+		x.stripe(p)
+	}
+	x.n = copy(x.buf[:], p)
+}
+
+func (x *xxh64) stripe(p []byte) {
+	for i := range x.v {
+		x.v[i] = xxhRound(x.v[i], binary.LittleEndian.Uint64(p[8*i:]))
+	}
+}
+
+func (x *xxh64) Sum64() uint64 {
+	var h uint64
+	if x.total >= 32 {
+		h = bits.RotateLeft64(x.v[0], 1) + bits.RotateLeft64(x.v[1], 7) + bits.RotateLeft64(x.v[2], 12) + bits.RotateLeft64(x.v[3], 18)
+		for _, v := range x.v {
+			h = (h^xxhRound(0, v))*xxhPrime1 + xxhPrime4
+		}
+	} else {
+		h = xxhPrime5
+	}
+	h += x.total
+
+	p := x.buf[:x.n]
+	for ; len(p) >= 8; p = p[8:] {
+		h = bits.RotateLeft64(h^xxhRound(0, binary.LittleEndian.Uint64(p)), 27)*xxhPrime1 + xxhPrime4
+	}
+	if len(p) >= 4 {
+		h = bits.RotateLeft64(h^uint64(binary.LittleEndian.Uint32(p))*xxhPrime1, 23)*xxhPrime2 + xxhPrime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		h = bits.RotateLeft64(h^uint64(b)*xxhPrime5, 11) * xxhPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+	return h
+}
+
+//===================================================================================================================== Bitstreams
+
+// zstdBackwardBits reads an FSE or Huffman bitstream, which is read from its last byte towards its first
+type zstdBackwardBits struct {
+	data []byte
+	pos  int // <--- the number of bits not yet read, negative once more bits were read than the stream holds
+}
+
+// newZstdBackwardBits starts reading data below the marker bit, the highest set bit of its last byte
+func newZstdBackwardBits(data []byte) (*zstdBackwardBits, error) {
+	if len(data) == 0 || data[len(data)-1] == 0 {
+		return nil, errZstdCorrupt
+	}
+	return &zstdBackwardBits{data: data, pos: 8*(len(data)-1) + bits.Len8(data[len(data)-1]) - 1}, nil
+}
+
+// load returns the little endian word starting at byte i, padded with zeros past the end of the stream
+func (b *zstdBackwardBits) load(i int) uint64 {
+	if i+8 <= len(b.data) {
+		return binary.LittleEndian.Uint64(b.data[i:])
+	}
+	var word uint64
+	for k := len(b.data) - 1; k >= i; k-- {
+		word = word<<8 | uint64(b.data[k])
+	}
+	return word
+}
+
+// peek returns the next n <= 56 bits without reading them. Bits before the start of the stream are zeros.
+func (b *zstdBackwardBits) peek(n uint8) uint64 {
+	start := b.pos - int(n)
+	if start >= 0 {
+		return b.load(start/8) >> (start % 8) & (1<<n - 1)
+	}
+	if b.pos <= 0 {
+		return 0
+	}
+	return (b.load(0) & (1<<b.pos - 1)) << -start
+}
+
+func (b *zstdBackwardBits) read(n uint8) uint64 {
+	value := b.peek(n)
+	b.pos -= int(n)
+	return value
+}
+
+// zstdBitWriter writes a bitstream for zstdBackwardBits, so the last bits written are the first read
+type zstdBitWriter struct {
+	out       []byte
+	container uint64
+	n         uint8
+}
+
+// add writes the low n <= 32 bits of value
+func (w *zstdBitWriter) add(value uint64, n uint8) {
+	w.container |= (value & (1<<n - 1)) << w.n
+	for w.n += n; w.n >= 8; w.n -= 8 {
+		w.out = append(w.out, byte(w.container))
+		w.container >>= 8
+	}
+}
+
+// close writes the marker bit and pads the last byte
+func (w *zstdBitWriter) close() []byte {
+	w.add(1, 1)
+	if w.n > 0 {
+		w.out = append(w.out, byte(w.container))
+	}
+	return w.out
+}
+
+//===================================================================================================================== FSE Tables
+
+type zstdFSEEntry struct {
+	symbol uint8
+	nbBits uint8
+	base   uint16
+}
+
+// zstdFSETable decodes symbols: a state gives a symbol, and the next state is base plus nbBits bits of the stream
+type zstdFSETable struct {
+	log     uint8
+	entries []zstdFSEEntry
+}
+
+/*
+* @notice readZstdFSECounts reads an FSE table description from the start of data. It returns the normalized count of each
+* symbol, where -1 stands for a probability below 1, the accuracy log, and the number of bytes read.
+ */
+func readZstdFSECounts(data []byte, maxSymbol int, maxLog uint8) ([]int16, uint8, int, error) {
+	bitPos := 0
+	peek := func(n int) int {
+		value := 0
+		for i := 0; i < n; i++ {
+			if k := (bitPos + i) / 8; k < len(data) {
+				value |= int(data[k]>>((bitPos+i)%8)&1) << i
+			}
+		}
+		return value
+	}
+
+	if len(data) == 0 {
+		return nil, 0, 0, errZstdCorrupt
+	}
+	log := data[0]&0xF + 5
+	if log > maxLog {
+		return nil, 0, 0, errZstdCorrupt
+	}
+	bitPos = 4
+
+	remaining, threshold, nbBits := 1<<log+1, 1<<log, int(log)+1
+	counts := []int16{}
+	for remaining > 1 {
+		if len(counts) > maxSymbol {
+			return nil, 0, 0, errZstdCorrupt
+		}
+
+		// small values take one bit less than large ones
+		smallValues := 2*threshold - 1 - remaining
+		value := peek(nbBits - 1)
+		if value < smallValues {
+			bitPos += nbBits - 1
+		} else {
+			if value = peek(nbBits); value >= threshold {
+				value -= smallValues
+			}
+			bitPos += nbBits
+		}
+		count := value - 1
+		remaining -= max(count, -count)
+		if remaining < 1 {
+			return nil, 0, 0, errZstdCorrupt
+		}
+		counts = append(counts, int16(count))
+
+		// a zero count is followed by 2 bit flags repeating it
+		for repeat := 3; count == 0 && repeat == 3; {
+			repeat = peek(2)
+			bitPos += 2
+			for k := 0; k < repeat; k++ {
+				counts = append(counts, 0)
+			}
+			if len(counts) > maxSymbol+1 {
+				return nil, 0, 0, errZstdCorrupt
+			}
+		}
+		for remaining < threshold {
+			nbBits--
+			threshold >>= 1
+		}
+	}
+	if bitPos > 8*len(data) {
+		return nil, 0, 0, errZstdCorrupt
+	}
+	return counts, log, (bitPos + 7) / 8, nil
+}
+
+// spreadZstdSymbols lays the symbols out over the states of a table, as both the decoder and the encoder must
+func spreadZstdSymbols(counts []int16, log uint8) ([]uint8, error) {
+	size := 1 << log
+	symbols := make([]uint8, size)
+	high := size - 1
+	for s, c := range counts {
+		if c == -1 {
+			symbols[high] = uint8(s)
+			high--
+		}
+	}
+
+	step, mask, pos := size>>1+size>>3+3, size-1, 0
+	for s, c := range counts {
+		for i := 0; i < int(c); i++ {
+			symbols[pos] = uint8(s)
+			for pos = (pos + step) & mask; pos > high; pos = (pos + step) & mask {
+			}
+		}
+	}
+	if pos != 0 {
+		return nil, errZstdCorrupt
+	}
+	return symbols, nil
+}
+
+// buildZstdFSETable builds the decoding table of normalized counts
+func buildZstdFSETable(counts []int16, log uint8) (*zstdFSETable, error) {
+	symbols, err := spreadZstdSymbols(counts, log)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make([]int, len(counts))
+	for s, c := range counts {
+		next[s] = max(int(c), 1) // <--- a count of -1 holds a single state
+	}
+	table := &zstdFSETable{log: log, entries: make([]zstdFSEEntry, len(symbols))}
+	for u, s := range symbols {
+		n := next[s]
+		next[s]++
+		nbBits := int(log) + 1 - bits.Len(uint(n))
+		table.entries[u] = zstdFSEEntry{symbol: s, nbBits: uint8(nbBits), base: uint16(n<<nbBits - len(symbols))}
+	}
+	return table, nil
+}
+
+// rleZstdFSETable returns a table that always decodes symbol without reading any bits
+func rleZstdFSETable(symbol uint8) *zstdFSETable {
+	return &zstdFSETable{entries: []zstdFSEEntry{{symbol: symbol}}}
+}
+
+// zstdFSEEncoder encodes symbols into the states of an FSE table
+type zstdFSEEncoder struct {
+	log            uint8
+	stateTable     []uint16
+	deltaNbBits    []uint32
+	deltaFindState []int32
+}
+
+// buildZstdFSEEncoder builds the encoding table of normalized counts, which must be valid
+func buildZstdFSEEncoder(counts []int16, log uint8) *zstdFSEEncoder {
+	symbols, err := spreadZstdSymbols(counts, log)
+	if err != nil {
+		panic(err)
+	}
+	size := len(symbols)
+
+	e := &zstdFSEEncoder{log: log, stateTable: make([]uint16, size), deltaNbBits: make([]uint32, len(counts)),
+		deltaFindState: make([]int32, len(counts))}
+	start := make([]int, len(counts)+1)
+	for s, c := range counts {
+		start[s+1] = start[s] + max(int(c), 1)
+	}
+	next := append([]int(nil), start...)
+	for u, s := range symbols {
+		e.stateTable[next[s]] = uint16(size + u)
+		next[s]++
+	}
+
+	for s, c := range counts {
+		switch {
+		case c == 0:
+			e.deltaNbBits[s] = uint32(log+1)<<16 - uint32(size)
+		case c == -1 || c == 1:
+			e.deltaNbBits[s] = uint32(log)<<16 - uint32(size)
+			e.deltaFindState[s] = int32(start[s] - 1)
+		default:
+			maxBitsOut := uint32(log) + 1 - uint32(bits.Len(uint(c-1)))
+			e.deltaNbBits[s] = maxBitsOut<<16 - uint32(c)<<maxBitsOut
+			e.deltaFindState[s] = int32(start[s] - int(c))
+		}
+	}
+	return e
+}
+
+// init returns the state that starts encoding with symbol, without writing any bits
+func (e *zstdFSEEncoder) init(symbol uint8) uint32 {
+	nbBits := (e.deltaNbBits[symbol] + 1<<15) >> 16
+	value := nbBits<<16 - e.deltaNbBits[symbol]
+	return uint32(e.stateTable[int32(value>>nbBits)+e.deltaFindState[symbol]])
+}
+
+// encode writes the bits that lead from state to symbol, and moves to symbol's state
+func (e *zstdFSEEncoder) encode(state uint32, symbol uint8, w *zstdBitWriter) uint32 {
+	nbBits := (state + e.deltaNbBits[symbol]) >> 16
+	w.add(uint64(state), uint8(nbBits))
+	return uint32(e.stateTable[int32(state>>nbBits)+e.deltaFindState[symbol]])
+}
+
+// flush writes the final state, which the decoder reads first
+func (e *zstdFSEEncoder) flush(state uint32, w *zstdBitWriter) {
+	w.add(uint64(state), e.log)
+}
+
+//===================================================================================================================== Huffman Literals
+
+type zstdHuffmanEntry struct {
+	symbol uint8
+	nbBits uint8
+}
+
+// zstdHuffman decodes Huffman coded literals, indexed by the next maxBits bits of the stream
+type zstdHuffman struct {
+	maxBits uint8
+	entries []zstdHuffmanEntry
+}
+
+// readZstdHuffman reads a Huffman tree description from the start of data, returning the table and the bytes read
+func readZstdHuffman(data []byte) (*zstdHuffman, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+
+	var weights []uint8
+	header := int(data[0])
+	size := 1 + header
+	if header < 128 {
+
+		// the weights are FSE coded, with two states taking turns
+		if len(data) < size {
+			return nil, 0, errZstdCorrupt
+		}
+		counts, log, used, err := readZstdFSECounts(data[1:size], 12, 6)
+		if err != nil {
+			return nil, 0, err
+		}
+		table, err := buildZstdFSETable(counts, log)
+		if err != nil {
+			return nil, 0, err
+		}
+		stream, err := newZstdBackwardBits(data[1+used : size])
+		if err != nil {
+			return nil, 0, err
+		}
+		states := [2]uint64{stream.read(log), stream.read(log)}
+		for turn := 0; ; turn ^= 1 {
+			if len(weights) > 254 {
+				return nil, 0, errZstdCorrupt
+			}
+			entry := table.entries[states[turn]]
+			weights = append(weights, entry.symbol)
+			states[turn] = uint64(entry.base) + stream.read(entry.nbBits)
+			if stream.pos < 0 {
+				weights = append(weights, table.entries[states[turn^1]].symbol) // <--- the stream is used up
+				break
+			}
+		}
+	} else {
+
+		// the weights are stored directly, 4 bits each
+		count := header - 127
+		size = 1 + (count+1)/2
+		if len(data) < size {
+			return nil, 0, errZstdCorrupt
+		}
+		for i := 0; i < count; i++ {
+			weights = append(weights, data[1+i/2]>>(4*(1-i%2))&0xF)
+		}
+	}
+
+	h, err := buildZstdHuffman(weights)
+	return h, size, err
+}
+
+// buildZstdHuffman builds the decoding table of the weights of every symbol but the last, whose weight is implied
+func buildZstdHuffman(weights []uint8) (*zstdHuffman, error) {
+	total := 0
+	for _, w := range weights {
+		if w > 11 {
+			return nil, errZstdCorrupt
+		}
+		if w > 0 {
+			total += 1 << (w - 1)
+		}
+	}
+	maxBits := bits.Len(uint(total))
+	if total == 0 || maxBits > 11 {
+		return nil, errZstdCorrupt
+	}
+	rest := 1<<maxBits - total
+	if rest&(rest-1) != 0 {
+		return nil, errZstdCorrupt
+	}
+	weights = append(weights, uint8(bits.Len(uint(rest))))
+
+	// symbols take 2^(weight - 1) consecutive entries, from the lowest weight up and by symbol within a weight
+	h := &zstdHuffman{maxBits: uint8(maxBits), entries: make([]zstdHuffmanEntry, 0, 1<<maxBits)}
+	for w := 1; w <= maxBits; w++ {
+		for s, sw := range weights {
+			if int(sw) == w {
+				for k := 0; k < 1<<(w-1); k++ {
+					h.entries = append(h.entries, zstdHuffmanEntry{symbol: uint8(s), nbBits: uint8(maxBits + 1 - w)})
+				}
+			}
+		}
+	}
+	return h, nil
+}
+
+// decode fills out from one Huffman coded stream, which must be used up exactly
+func (h *zstdHuffman) decode(data []byte, out []byte) error {
+	stream, err := newZstdBackwardBits(data)
+	if err != nil {
+		return err
+	}
+	for i := range out {
+		entry := h.entries[stream.peek(h.maxBits)]
+		out[i] = entry.symbol
+		stream.pos -= int(entry.nbBits)
+	}
+	if stream.pos != 0 {
+		return errZstdCorrupt
+	}
+	return nil
+}
+
+//===================================================================================================================== Decoder
+
+// zstdReader decompresses a single zstd frame
+type zstdReader struct {
+	r io.Reader
+
+	window      int
+	contentSize int64 // <--- -1 when the frame does not store it
+	decoded     int64
+	checksum    *xxh64
+	hasChecksum bool
+
+	history []byte // <--- the decoded bytes later blocks may refer back to, ending with those not yet returned by Read
+	unread  int    // <--- the start in history of the bytes not yet returned
+	last    bool
+	err     error
+
+	huffman  *zstdHuffman     // <--- kept between blocks for treeless literals
+	tables   [3]*zstdFSETable // <--- kept between blocks for repeated tables
+	offsets  [3]int           // <--- the repeat offsets
+	block    []byte
+	literals []byte
+}
+
+// newZstdReader reads the header of the frame at the start of r. Skippable frames before it are skipped.
+func newZstdReader(r io.Reader) (*zstdReader, error) {
+	z := &zstdReader{r: r, contentSize: -1, checksum: newXXH64(), offsets: [3]int{1, 4, 8}}
+
+	var scratch [8]byte
+	for {
+		if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+			return nil, err
+		}
+		magic := binary.LittleEndian.Uint32(scratch[:])
+		if magic == zstdMagic {
+			break
+		}
+		if magic&^0xF != zstdSkippableMagic {
+			return nil, fmt.Errorf("not a zstd frame")
+		}
+		if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(binary.LittleEndian.Uint32(scratch[:]))); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := io.ReadFull(r, scratch[:1]); err != nil {
+		return nil, err
+	}
+	descriptor := scratch[0]
+	singleSegment := descriptor&0x20 != 0
+	z.hasChecksum = descriptor&0x04 != 0
+	if descriptor&0x08 != 0 {
+		return nil, errZstdCorrupt // <--- the reserved bit
+	}
+
+	if !singleSegment {
+		if _, err := io.ReadFull(r, scratch[:1]); err != nil {
+			return nil, err
+		}
+		windowLog := 10 + int(scratch[0]>>3)
+		if windowLog > zstdMaxWindowLog {
+			return nil, fmt.Errorf("zstd window of 2^%d bytes is too large", windowLog)
+		}
+		z.window = 1<<windowLog + (1<<windowLog)/8*int(scratch[0]&7)
+	}
+
+	dictionarySize := [4]int{0, 1, 2, 4}[descriptor&3]
+	if _, err := io.ReadFull(r, scratch[:dictionarySize]); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(append(scratch[:dictionarySize:dictionarySize], 0, 0, 0, 0)) != 0 {
+		return nil, fmt.Errorf("zstd dictionaries are not supported")
+	}
+
+	contentSizeBytes := [4]int{0, 2, 4, 8}[descriptor>>6]
+	if contentSizeBytes == 0 && singleSegment {
+		contentSizeBytes = 1
+	}
+	if contentSizeBytes > 0 {
+		clear(scratch[:])
+		if _, err := io.ReadFull(r, scratch[:contentSizeBytes]); err != nil {
+			return nil, err
+		}
+		z.contentSize = int64(binary.LittleEndian.Uint64(scratch[:]))
+		if contentSizeBytes == 2 {
+			z.contentSize += 256
+		}
+		if z.contentSize < 0 {
+			return nil, errZstdCorrupt
+		}
+	}
+	if singleSegment {
+		if z.contentSize > 1<<zstdMaxWindowLog {
+			return nil, fmt.Errorf("zstd window of %d bytes is too large", z.contentSize)
+		}
+		z.window = int(z.contentSize)
+	}
+	return z, nil
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	for z.unread == len(z.history) {
+		if z.err != nil {
+			return 0, z.err
+		}
+		if z.last {
+			if z.err = z.finish(); z.err == nil {
+				z.err = io.EOF
+			}
+			continue
+		}
+		z.err = z.nextBlock()
+	}
+	n := copy(p, z.history[z.unread:])
+	z.unread += n
+	return n, nil
+}
+
+func (z *zstdReader) Close() error { return nil }
+
+// finish checks the size and checksum of the frame once its last block is decoded
+func (z *zstdReader) finish() error {
+	if z.contentSize >= 0 && z.decoded != z.contentSize {
+		return errZstdCorrupt
+	}
+	if z.hasChecksum {
+		var stored [4]byte
+		if _, err := io.ReadFull(z.r, stored[:]); err != nil {
+			return err
+		}
+		if binary.LittleEndian.Uint32(stored[:]) != uint32(z.checksum.Sum64()) {
+			return fmt.Errorf("zstd content checksum mismatch")
+		}
+	}
+	return nil
+}
+
+// nextBlock decodes the next block onto the end of the history
+func (z *zstdReader) nextBlock() error {
+
+	// keep only the window of history that the block may refer back to
+	if drop := len(z.history) - z.window; drop > 0 {
+		z.history = z.history[:copy(z.history, z.history[drop:])]
+		z.unread = len(z.history)
+	}
+
+	var header [3]byte
+	if _, err := io.ReadFull(z.r, header[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	fields := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	z.last = fields&1 != 0
+	size := fields >> 3
+	if size > min(z.window, zstdMaxBlockSize) {
+		return errZstdCorrupt
+	}
+
+	start := len(z.history)
+	switch blockType := fields >> 1 & 3; blockType {
+	case 0: // <--- raw
+		z.history = append(z.history, make([]byte, size)...)
+		if _, err := io.ReadFull(z.r, z.history[start:]); err != nil {
+			return unexpectedEOF(err)
+		}
+	case 1: // <--- a single byte repeated size times
+		var value [1]byte
+		if _, err := io.ReadFull(z.r, value[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		for i := 0; i < size; i++ {
+			z.history = append(z.history, value[0])
+		}
+	case 2: // <--- compressed
+		z.block = append(z.block[:0], make([]byte, size)...)
+		if _, err := io.ReadFull(z.r, z.block); err != nil {
+			return unexpectedEOF(err)
+		}
+		if err := z.decompressBlock(z.block); err != nil {
+			return err
+		}
+	default:
+		return errZstdCorrupt
+	}
+
+	z.checksum.Write(z.history[start:])
+	z.decoded += int64(len(z.history) - start)
+	if z.contentSize >= 0 && z.decoded > z.contentSize {
+		return errZstdCorrupt
+	}
+	return nil
+}
+
+// unexpectedEOF turns an io.EOF in the middle of a frame into io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readLiterals decodes the literals section at the start of block, returning the literals and the bytes read
+func (z *zstdReader) readLiterals(block []byte) ([]byte, int, error) {
+	if len(block) == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	literalsType, sizeFormat := block[0]&3, block[0]>>2&3
+
+	if literalsType < 2 {
+
+		// raw or RLE literals, with a 1, 2 or 3 byte header
+		size, headerSize := int(block[0]>>3), 1
+		if sizeFormat == 1 && len(block) >= 2 {
+			size, headerSize = int(block[0]>>4)|int(block[1])<<4, 2
+		} else if sizeFormat == 3 && len(block) >= 3 {
+			size, headerSize = int(block[0]>>4)|int(block[1])<<4|int(block[2])<<12, 3
+		} else if sizeFormat&1 == 1 {
+			return nil, 0, errZstdCorrupt
+		}
+		if literalsType == 0 {
+			if len(block) < headerSize+size {
+				return nil, 0, errZstdCorrupt
+			}
+			return block[headerSize : headerSize+size], headerSize + size, nil
+		}
+		if len(block) < headerSize+1 || size > zstdMaxBlockSize {
+			return nil, 0, errZstdCorrupt
+		}
+		z.literals = z.literals[:0]
+		for i := 0; i < size; i++ {
+			z.literals = append(z.literals, block[headerSize])
+		}
+		return z.literals, headerSize + 1, nil
+	}
+
+	// Huffman coded literals in 1 or 4 streams, with a new tree or the previous one
+	headerSize := [4]int{3, 3, 4, 5}[sizeFormat]
+	if len(block) < headerSize {
+		return nil, 0, errZstdCorrupt
+	}
+	var fields uint64
+	for i := headerSize - 1; i >= 0; i-- {
+		fields = fields<<8 | uint64(block[i])
+	}
+	sizeBits := [4]uint{10, 10, 14, 18}[sizeFormat]
+	size := int(fields >> 4 & (1<<sizeBits - 1))
+	compressedSize := int(fields >> (4 + sizeBits) & (1<<sizeBits - 1))
+	if size > zstdMaxBlockSize || len(block) < headerSize+compressedSize {
+		return nil, 0, errZstdCorrupt
+	}
+
+	data := block[headerSize : headerSize+compressedSize]
+	if literalsType == 2 {
+		h, n, err := readZstdHuffman(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		z.huffman, data = h, data[n:]
+	} else if z.huffman == nil {
+		return nil, 0, errZstdCorrupt
+	}
+
+	z.literals = append(z.literals[:0], make([]byte, size)...)
+	if sizeFormat == 0 {
+		return z.literals, headerSize + compressedSize, z.huffman.decode(data, z.literals)
+	}
+
+	// a jump table gives the sizes of the first three streams, each regenerating a quarter of the literals
+	segment := (size + 3) / 4
+	if len(data) < 6 || 3*segment > size {
+		return nil, 0, errZstdCorrupt
+	}
+	rest := data[6:]
+	for k := 0; k < 4; k++ {
+		streamSize := len(rest)
+		if k < 3 {
+			streamSize = int(binary.LittleEndian.Uint16(data[2*k:]))
+		}
+		if streamSize > len(rest) {
+			return nil, 0, errZstdCorrupt
+		}
+		out := z.literals[k*segment : min((k+1)*segment, size)]
+		if err := z.huffman.decode(rest[:streamSize], out); err != nil {
+			return nil, 0, err
+		}
+		rest = rest[streamSize:]
+	}
+	return z.literals, headerSize + compressedSize, nil
+}
+
+// readTable reads the table of one code for the sequences section, in the given mode
+func (z *zstdReader) readTable(kind int, mode byte, data []byte) (int, error) {
+	switch mode {
+	case 0: // <--- predefined
+		z.tables[kind] = zstdPredefinedTables[kind]
+		return 0, nil
+	case 1: // <--- a single symbol
+		if len(data) == 0 || int(data[0]) > zstdMaxSymbol[kind] {
+			return 0, errZstdCorrupt
+		}
+		z.tables[kind] = rleZstdFSETable(data[0])
+		return 1, nil
+	case 2: // <--- FSE coded
+		counts, log, n, err := readZstdFSECounts(data, zstdMaxSymbol[kind], zstdMaxTableLog[kind])
+		if err != nil {
+			return 0, err
+		}
+		if z.tables[kind], err = buildZstdFSETable(counts, log); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	if z.tables[kind] == nil { // <--- the table of the previous block
+		return 0, errZstdCorrupt
+	}
+	return 0, nil
+}
+
+// decompressBlock decodes a compressed block onto the end of the history
+func (z *zstdReader) decompressBlock(block []byte) error {
+
+	literals, n, err := z.readLiterals(block)
+	if err != nil {
+		return err
+	}
+	block = block[n:]
+
+	// the number of sequences takes 1 to 3 bytes
+	if len(block) == 0 {
+		return errZstdCorrupt
+	}
+	numSequences, headerSize := int(block[0]), 1
+	if block[0] >= 128 && block[0] < 255 && len(block) >= 2 {
+		numSequences, headerSize = int(block[0]-128)<<8|int(block[1]), 2
+	} else if block[0] == 255 && len(block) >= 3 {
+		numSequences, headerSize = int(block[1])|int(block[2])<<8+0x7F00, 3
+	} else if block[0] >= 128 {
+		return errZstdCorrupt
+	}
+	if numSequences == 0 {
+		if len(literals) > zstdMaxBlockSize {
+			return errZstdCorrupt
+		}
+		z.history = append(z.history, literals...)
+		return nil
+	}
+
+	if len(block) < headerSize+1 || block[headerSize]&3 != 0 {
+		return errZstdCorrupt
+	}
+	modes, pos := block[headerSize], headerSize+1
+	for kind := zstdLiteralLengths; kind <= zstdMatchLengths; kind++ {
+		n, err := z.readTable(kind, modes>>(6-2*kind)&3, block[pos:])
+		if err != nil {
+			return err
+		}
+		pos += n
+	}
+
+	stream, err := newZstdBackwardBits(block[pos:])
+	if err != nil {
+		return err
+	}
+	llTable, ofTable, mlTable := z.tables[zstdLiteralLengths], z.tables[zstdOffsets], z.tables[zstdMatchLengths]
+	llState, ofState, mlState := stream.read(llTable.log), stream.read(ofTable.log), stream.read(mlTable.log)
+
+	start := len(z.history)
+	for i := 0; i < numSequences; i++ {
+		llCode, ofCode, mlCode := llTable.entries[llState].symbol, ofTable.entries[ofState].symbol, mlTable.entries[mlState].symbol
+		offsetValue := 1<<ofCode + int(stream.read(ofCode))
+		matchLength := int(zstdMatchLengthBase[mlCode]) + int(stream.read(zstdMatchLengthBits[mlCode]))
+		literalLength := int(zstdLiteralLengthBase[llCode]) + int(stream.read(zstdLiteralLengthBits[llCode]))
+
+		// offset values up to 3 pick a repeat offset, shifted by one when there are no literals
+		offset := offsetValue - 3
+		if offsetValue <= 3 {
+			index := offsetValue - 1
+			if literalLength == 0 {
+				index++
+			}
+			if index == 3 {
+				offset = z.offsets[0] - 1
+			} else {
+				offset = z.offsets[index]
+			}
+			if index > 0 {
+				if index != 1 {
+					z.offsets[2] = z.offsets[1]
+				}
+				z.offsets[0], z.offsets[1] = offset, z.offsets[0]
+			}
+		} else {
+			z.offsets = [3]int{offset, z.offsets[0], z.offsets[1]}
+		}
+
+		if i < numSequences-1 {
+			entry := llTable.entries[llState]
+			llState = uint64(entry.base) + stream.read(entry.nbBits)
+			entry = mlTable.entries[mlState]
+			mlState = uint64(entry.base) + stream.read(entry.nbBits)
+			entry = ofTable.entries[ofState]
+			ofState = uint64(entry.base) + stream.read(entry.nbBits)
+		}
+
+		// copy the literals, then the match, which may overlap the bytes it produces
+		if literalLength > len(literals) {
+			return errZstdCorrupt
+		}
+		z.history = append(z.history, literals[:literalLength]...)
+		literals = literals[literalLength:]
+		if offset <= 0 || offset > len(z.history) || offset > max(z.window, 1) {
+			return errZstdCorrupt
+		}
+		for from := len(z.history) - offset; matchLength > 0; {
+			n := min(offset, matchLength)
+			z.history = append(z.history, z.history[from:from+n]...)
+			from, matchLength = from+n, matchLength-n
+		}
+		if len(z.history)-start > zstdMaxBlockSize {
+			return errZstdCorrupt
+		}
+	}
+	if stream.pos != 0 {
+		return errZstdCorrupt
+	}
+
+	z.history = append(z.history, literals...)
+	if len(z.history)-start > zstdMaxBlockSize {
+		return errZstdCorrupt
+	}
+	return nil
+}
+
+//===================================================================================================================== Encoder
+
+// zstdSequence is literalLength literals followed by matchLength bytes copied from offset bytes back
+type zstdSequence struct {
+	literalLength, matchLength, offset int
+}
+
+// zstdWriter compresses everything written to it into a single zstd frame, finished by Close()
+type zstdWriter struct {
+	w        io.Writer
+	checksum *xxh64
+
+	history []byte // <--- the window before the pending block, followed by the pending block
+	pending int    // <--- the start of the pending block in history
+	base    int    // <--- the position in the stream of history[0]
+	hashes  []int  // <--- for each hash of 4 bytes, 1 + the position in the stream where they were last seen
+
+	wroteHeader bool
+	closed      bool
+	err         error
+	literals    []byte
+	sequences   []zstdSequence
+}
+
+func newZstdWriter(w io.Writer) *zstdWriter {
+	return &zstdWriter{w: w, checksum: newXXH64(), hashes: make([]int, 1<<zstdHashLog)}
+}
+
+func (z *zstdWriter) Write(p []byte) (int, error) {
+	if z.closed {
+		return 0, errors.New("write to a closed zstd writer")
+	}
+	written := 0
+	for len(p) > 0 && z.err == nil {
+
+		// a full block is only written once more data follows, so the last block can be flagged as such
+		if len(z.history)-z.pending == zstdMaxBlockSize {
+			z.err = z.writeBlock(false)
+			continue
+		}
+		n := min(len(p), zstdMaxBlockSize-(len(z.history)-z.pending))
+		z.history = append(z.history, p[:n]...)
+		z.checksum.Write(p[:n])
+		p, written = p[n:], written+n
+	}
+	return written, z.err
+}
+
+// Close() writes the last block and the content checksum. It does not close the underlying writer.
+func (z *zstdWriter) Close() error {
+	if z.closed || z.err != nil {
+		return z.err
+	}
+	z.closed = true
+	if z.err = z.writeBlock(true); z.err != nil {
+		return z.err
+	}
+	_, z.err = z.w.Write(binary.LittleEndian.AppendUint32(nil, uint32(z.checksum.Sum64())))
+	return z.err
+}
+
+// writeBlock writes the pending block, compressed when that makes it smaller
+func (z *zstdWriter) writeBlock(last bool) error {
+
+	var out []byte
+	if !z.wroteHeader {
+		// no content size, a content checksum and the window descriptor of zstdWindowLog
+		out = append(binary.LittleEndian.AppendUint32(out, zstdMagic), 0x04, byte(zstdWindowLog-10)<<3)
+		z.wroteHeader = true
+	}
+
+	block := z.history[z.pending:]
+	blockType, content := 0, block
+	if compressed := z.compressBlock(); compressed != nil && len(compressed) < len(block) {
+		blockType, content = 2, compressed
+	} else if len(block) > 1 && allEqual(block) {
+		blockType, content = 1, block[:1]
+	}
+	fields := len(block)
+	if blockType == 2 {
+		fields = len(content)
+	}
+	fields = fields<<3 | blockType<<1
+	if last {
+		fields |= 1
+	}
+	out = append(out, byte(fields), byte(fields>>8), byte(fields>>16))
+	if _, err := z.w.Write(append(out, content...)); err != nil {
+		return err
+	}
+
+	// slide the window once the history holds two of them
+	z.pending = len(z.history)
+	if drop := len(z.history) - 1<<zstdWindowLog; len(z.history) >= 2<<zstdWindowLog {
+		z.history = z.history[:copy(z.history, z.history[drop:])]
+		z.base += drop
+		z.pending -= drop
+	}
+	return nil
+}
+
+// allEqual reports whether every byte of b is the same
+func allEqual(b []byte) bool {
+	for _, c := range b {
+		if c != b[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// zstdHash hashes the 4 bytes at the start of b
+func zstdHash(b []byte) int {
+	return int(binary.LittleEndian.Uint32(b) * 2654435761 >> (32 - zstdHashLog))
+}
+
+// compressBlock finds matches for the pending block and codes them, returning nil when there are none
+func (z *zstdWriter) compressBlock() []byte {
+
+	src, start, end := z.history, z.pending, len(z.history)
+	z.literals, z.sequences = z.literals[:0], z.sequences[:0]
+	anchor := start
+	for i := start; i+zstdMinMatch <= end; {
+		h := zstdHash(src[i:])
+		candidate := z.hashes[h] - 1 - z.base
+		z.hashes[h] = z.base + i + 1
+		if candidate < 0 || i-candidate > 1<<zstdWindowLog || binary.LittleEndian.Uint32(src[candidate:]) != binary.LittleEndian.Uint32(src[i:]) {
+			i++
+			continue
+		}
+
+		length := zstdMinMatch
+		for i+length < end && src[candidate+length] == src[i+length] {
+			length++
+		}
+		z.sequences = append(z.sequences, zstdSequence{literalLength: i - anchor, matchLength: length, offset: i - candidate})
+		z.literals = append(z.literals, src[anchor:i]...)
+		for k := i + 1; k < i+length && k+zstdMinMatch <= end; k++ {
+			z.hashes[zstdHash(src[k:])] = z.base + k + 1
+		}
+		i += length
+		anchor = i
+	}
+	if len(z.sequences) == 0 {
+		return nil
+	}
+	z.literals = append(z.literals, src[anchor:end]...)
+
+	// raw literals, with a 1, 2 or 3 byte header
+	var out []byte
+	switch size := len(z.literals); {
+	case size < 32:
+		out = append(out, byte(size<<3))
+	case size < 4096:
+		out = append(out, byte(size&0xF)<<4|1<<2, byte(size>>4))
+	default:
+		out = append(out, byte(size&0xF)<<4|3<<2, byte(size>>4), byte(size>>12))
+	}
+	out = append(out, z.literals...)
+
+	// the number of sequences, then predefined tables for all three codes
+	switch n := len(z.sequences); {
+	case n < 128:
+		out = append(out, byte(n))
+	case n < 0x7F00:
+		out = append(out, byte(n>>8)+128, byte(n))
+	default:
+		out = append(out, 255, byte(n-0x7F00), byte((n-0x7F00)>>8))
+	}
+	out = append(out, 0)
+
+	// the sequences are coded from the last, as the decoder reads the stream backwards
+	llEncoder, ofEncoder, mlEncoder := zstdPredefinedEncoders[zstdLiteralLengths], zstdPredefinedEncoders[zstdOffsets], zstdPredefinedEncoders[zstdMatchLengths]
+	w := &zstdBitWriter{out: out}
+	var llState, ofState, mlState uint32
+	for i := len(z.sequences) - 1; i >= 0; i-- {
+		s := z.sequences[i]
+		llCode := zstdLengthCode(zstdLiteralLengthBase[:], s.literalLength)
+		mlCode := zstdLengthCode(zstdMatchLengthBase[:], s.matchLength)
+		offsetValue := s.offset + 3
+		ofCode := uint8(bits.Len(uint(offsetValue)) - 1)
+
+		if i == len(z.sequences)-1 {
+			llState, ofState, mlState = llEncoder.init(llCode), ofEncoder.init(ofCode), mlEncoder.init(mlCode)
+		} else {
+			ofState = ofEncoder.encode(ofState, ofCode, w)
+			mlState = mlEncoder.encode(mlState, mlCode, w)
+			llState = llEncoder.encode(llState, llCode, w)
+		}
+		w.add(uint64(s.literalLength)-uint64(zstdLiteralLengthBase[llCode]), zstdLiteralLengthBits[llCode])
+		w.add(uint64(s.matchLength)-uint64(zstdMatchLengthBase[mlCode]), zstdMatchLengthBits[mlCode])
+		w.add(uint64(offsetValue)-1<<ofCode, ofCode)
+	}
+	mlEncoder.flush(mlState, w)
+	ofEncoder.flush(ofState, w)
+	llEncoder.flush(llState, w)
+	return w.close()
+}
+
+// zstdLengthCode returns the code of a literal or match length, the last code whose base is at most length
+func zstdLengthCode(base []uint32, length int) uint8 {
+	code := len(base) - 1
+	for int(base[code]) > length {
+		code--
+	}
+	return uint8(code)
+}
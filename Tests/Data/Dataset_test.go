@@ -0,0 +1,167 @@
+package TG
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// writes an unsigned byte IDX file, gzipped when compress is true
+func writeIDX(t *testing.T, fileName string, shape []int, data []byte, compress bool) {
+	var raw bytes.Buffer
+	raw.Write([]byte{0, 0, 0x08, byte(len(shape))})
+	for _, dim := range shape {
+		binary.Write(&raw, binary.BigEndian, uint32(dim))
+	}
+	raw.Write(data)
+
+	contents := raw.Bytes()
+	if compress {
+		var zipped bytes.Buffer
+		zw := gzip.NewWriter(&zipped)
+		zw.Write(contents)
+		zw.Close()
+		contents = zipped.Bytes()
+	}
+	if err := os.WriteFile(fileName, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_LoadMNIST(t *testing.T) {
+
+	dir := t.TempDir()
+	pixels := make([]byte, 5*28*28)
+	for i := range pixels {
+		pixels[i] = byte(i % 256)
+	}
+	writeIDX(t, filepath.Join(dir, "train-images-idx3-ubyte.gz"), []int{5, 28, 28}, pixels, true)
+	writeIDX(t, filepath.Join(dir, "train-labels-idx1-ubyte"), []int{5}, []byte{3, 1, 4, 1, 5}, false)
+
+	dataset, err := LoadMNIST(dir, true)
+	if err != nil {
+		t.Fatalf("LoadMNIST() failed: %v", err)
+	}
+	if dataset.Len() != 5 || dataset.X.Shape[1] != 28 || dataset.X.Shape[2] != 28 || dataset.X.Data[255] != 1 || dataset.Y.Data[4] != 5 {
+		t.Errorf("LoadMNIST() failed. Actual Output: %v %v", dataset.X.Shape, dataset.Y.Data)
+	}
+
+	if _, err := LoadMNIST(dir, false); err == nil {
+		t.Errorf("LoadMNIST() of a missing test set should fail")
+	}
+}
+
+func Test_ReadIDX_Corrupt(t *testing.T) {
+
+	for name, file := range map[string][]byte{
+		"a rank of 0":               {0, 0, 0x08, 0},
+		"a shape without data":      {0, 0, 0x08, 1, 0xff, 0xff, 0xff, 0xff, 1, 2, 3},
+		"an overflowing shape":      {0, 0, 0x0E, 3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"a huge shape without data": {0, 0, 0x08, 3, 0, 0, 1, 0, 0, 1, 0, 0, 0, 1, 0, 0, 1, 2, 3},
+	} {
+		if _, err := ReadIDX(bytes.NewReader(file)); err == nil {
+			t.Errorf("ReadIDX() of %v should fail", name)
+		}
+	}
+}
+
+func Test_DataLoader(t *testing.T) {
+
+	X := RangeTensor([]int{10, 2}, false)
+	Y := RangeTensor([]int{10}, false)
+	loader := NewDataLoader(NewTensorDataset(X, Y), 4, true)
+	loader.Generator = NewGenerator(7)
+
+	for epoch := 0; epoch < 2; epoch++ {
+		seen, batches := map[float64]bool{}, 0
+		for {
+			x, y, err := loader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next() failed: %v", err)
+			}
+			batches++
+			if !x.Batched || x.Shape[1] != 2 || x.Shape[0] != y.Shape[0] {
+				t.Fatalf("Next() failed. Actual Output: %v %v", x.Shape, y.Shape)
+			}
+			for k, label := range y.Data {
+				seen[label] = true
+				if x.Data[2*k] != 2*label {
+					t.Errorf("Next() returned mismatched examples and labels")
+				}
+			}
+		}
+		if batches != 3 || len(seen) != 10 || loader.NumBatches() != 3 {
+			t.Errorf("DataLoader epoch %v failed. %v batches, %v examples", epoch, batches, len(seen))
+		}
+	}
+
+	loader.DropLast = true
+	if loader.NumBatches() != 2 {
+		t.Errorf("NumBatches() with DropLast failed")
+	}
+}
+
+func Test_MLPInput_Forward(t *testing.T) {
+
+	x, _, _ := NewTensorDataset(RandFloat64Tensor([]int{6, 2, 3}, 0, 1, false), RangeTensor([]int{6}, false)).Get([]int{0, 1, 2})
+	mlp := MLP(6, []int{4, 2}, []string{"relu", "relu"})
+
+	output := mlp.Forward(MLPInput(x))
+	if len(output.DataReqGrad) != 6 {
+		t.Errorf("Forward() on MLPInput() failed. Actual Output: %v", output.Shape)
+	}
+}
+
+func Test_ImageFolder(t *testing.T) {
+
+	root := t.TempDir()
+	for _, class := range []string{"dogs", "cats"} {
+		os.MkdirAll(filepath.Join(root, class), 0755)
+		for i := 0; i < 2; i++ {
+			img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+			img.Set(1, 2, color.RGBA{R: 255, G: 0, B: 51, A: 255})
+			file, _ := os.Create(filepath.Join(root, class, string(rune('a'+i))+".png"))
+			png.Encode(file, img)
+			file.Close()
+		}
+	}
+	os.WriteFile(filepath.Join(root, "cats", "notes.txt"), []byte("not an image"), 0644)
+
+	folder, err := NewImageFolder(root, false)
+	if err != nil {
+		t.Fatalf("NewImageFolder() failed: %v", err)
+	}
+	if folder.Len() != 4 || folder.Classes[0] != "cats" || folder.Classes[1] != "dogs" {
+		t.Fatalf("NewImageFolder() failed. Actual Output: %v %v", folder.Len(), folder.Classes)
+	}
+
+	x, y, err := folder.Get([]int{0, 3})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if len(x.Shape) != 4 || x.Shape[0] != 2 || x.Shape[1] != 3 || x.Shape[2] != 3 || x.Shape[3] != 4 || y.Data[0] != 0 || y.Data[1] != 1 {
+		t.Fatalf("Get() failed. Actual Output: %v %v", x.Shape, y.Data)
+	}
+
+	// the pixel at row 2, column 1 of the red, green and blue planes
+	if x.Data[2*4+1] != 1 || x.Data[12+2*4+1] != 0 || x.Data[24+2*4+1] != 0.2 {
+		t.Errorf("Get() pixel values failed. Actual Output: %v", x.Data[:36])
+	}
+
+	gray, _ := NewImageFolder(root, true)
+	if x, _, _ := gray.Get([]int{1}); x.Shape[1] != 1 {
+		t.Errorf("Grayscale ImageFolder failed. Actual Output: %v", x.Shape)
+	}
+}
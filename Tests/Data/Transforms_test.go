@@ -0,0 +1,189 @@
+package TG
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// returns a [channels, height, width] image whose pixels count up from 0
+func rampImage(channels, height, width int) *Tensor {
+	A := ZeroTensor([]int{channels, height, width}, false)
+	for i := range A.Data {
+		A.Data[i] = float64(i)
+	}
+	return A
+}
+
+func sameData(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_Transforms_Geometric(t *testing.T) {
+	A := rampImage(1, 2, 3) // <--- [[0 1 2] [3 4 5]]
+
+	if C := (RandomHorizontalFlip{P: 1}).Transform(A, NewGenerator(0)); !sameData(C.Data, []float64{2, 1, 0, 5, 4, 3}) {
+		t.Errorf("horizontal flip: %v", C.Data)
+	}
+	if C := (RandomVerticalFlip{P: 1}).Transform(A, NewGenerator(0)); !sameData(C.Data, []float64{3, 4, 5, 0, 1, 2}) {
+		t.Errorf("vertical flip: %v", C.Data)
+	}
+	if C := (RandomHorizontalFlip{P: 0}).Transform(A, NewGenerator(0)); !sameData(C.Data, A.Data) {
+		t.Errorf("flip with P = 0 changed the image: %v", C.Data)
+	}
+
+	C := Pad{Top: 1, Left: 1, Value: -1}.Transform(A, nil)
+	if !isShape(C.Shape, []int{1, 3, 4}) || !sameData(C.Data, []float64{-1, -1, -1, -1, -1, 0, 1, 2, -1, 3, 4, 5}) {
+		t.Errorf("pad: %v %v", C.Shape, C.Data)
+	}
+
+	C = CenterCrop{Height: 2, Width: 1}.Transform(A, nil)
+	if !sameData(C.Data, []float64{1, 4}) {
+		t.Errorf("center crop: %v", C.Data)
+	}
+
+	C = Resize{Height: 4, Width: 6, Method: InterpolationNearest}.Transform(A, nil)
+	if !isShape(C.Shape, []int{1, 4, 6}) || C.Data[0] != 0 || C.Data[1] != 0 || C.Data[23] != 5 {
+		t.Errorf("nearest resize: %v", C.Data)
+	}
+	C = Resize{Height: 1, Width: 3, Method: InterpolationBilinear}.Transform(A, nil)
+	if !sameData(C.Data, []float64{1.5, 2.5, 3.5}) {
+		t.Errorf("bilinear resize: %v", C.Data)
+	}
+
+	// a quarter turn counterclockwise of a square image
+	S := rampImage(1, 3, 3)
+	C = Rotate{Degrees: 90}.Transform(S, nil)
+	if !sameData(C.Data, []float64{2, 5, 8, 1, 4, 7, 0, 3, 6}) {
+		t.Errorf("rotate: %v", C.Data)
+	}
+}
+
+func Test_Transforms_Color(t *testing.T) {
+	A := ConstTensor([]int{2, 2, 2}, 0.5, false)
+	C := Normalize{Mean: []float64{0.5, 0}, Std: []float64{1, 0.5}}.Transform(A, nil)
+	if !sameData(C.Data, []float64{0, 0, 0, 0, 1, 1, 1, 1}) {
+		t.Errorf("normalize: %v", C.Data)
+	}
+
+	rgb := NewGenerator(3).UniformTensor([]int{3, 4, 4}, 0, 1, false)
+	C = ColorJitter{Brightness: 0.5, Contrast: 0.5, Saturation: 0.5}.Transform(rgb, NewGenerator(1))
+	for _, v := range C.Data {
+		if v < 0 || v > 1 {
+			t.Fatalf("jittered value %v outside of [0, 1]", v)
+		}
+	}
+	if sameData(C.Data, rgb.Data) {
+		t.Errorf("color jitter left the image unchanged")
+	}
+}
+
+func Test_Transforms_Seeded(t *testing.T) {
+	pipeline := Compose(
+		RandomCrop{Height: 4, Width: 4, Padding: 1},
+		RandomHorizontalFlip{P: 0.5},
+		RandomRotation{MaxDegrees: 15},
+		ColorJitter{Brightness: 0.2},
+	)
+	batch := NewGenerator(5).UniformTensor([]int{8, 3, 4, 4}, 0, 1, false)
+	batch.Batched = true
+
+	first := batch.ApplyTransform(pipeline, NewGenerator(42), true)
+	second := batch.ApplyTransform(pipeline, NewGenerator(42), true)
+	other := batch.ApplyTransform(pipeline, NewGenerator(43), true)
+
+	if !isShape(first.Shape, []int{8, 3, 4, 4}) || !first.Batched {
+		t.Fatalf("batched transform returned shape %v", first.Shape)
+	}
+	if !sameData(first.Data, second.Data) {
+		t.Errorf("the same seed produced different augmentations")
+	}
+	if sameData(first.Data, other.Data) {
+		t.Errorf("different seeds produced the same augmentations")
+	}
+}
+
+func Test_Transforms_SingleChannelBatch(t *testing.T) {
+	batch := ZeroTensor([]int{4, 1, 6, 6}, false)
+	batch.Batched = true
+	C := batch.ApplyTransform(Resize{Height: 3, Width: 2}, nil, true)
+	if !isShape(C.Shape, []int{4, 1, 3, 2}) {
+		t.Errorf("expected shape [4 1 3 2], got %v", C.Shape)
+	}
+}
+
+func Test_Transforms_SingletonDimsBatch(t *testing.T) {
+
+	// singleton height, width and channel dims are kept, so each image transforms exactly as it would alone
+	cases := []struct {
+		shape     []int
+		transform ImageTransform
+		expected  []int
+	}{
+		{[]int{2, 3, 1, 4}, Resize{Height: 2, Width: 4}, []int{2, 3, 2, 4}},
+		{[]int{2, 3, 2, 1}, RandomHorizontalFlip{P: 1}, []int{2, 3, 2, 1}},
+		{[]int{2, 1, 3, 1}, RandomVerticalFlip{P: 1}, []int{2, 1, 3, 1}},
+		{[]int{3, 1, 4}, RandomVerticalFlip{P: 1}, []int{3, 1, 4}},
+	}
+	for _, c := range cases {
+		batch := RangeTensor(c.shape, false)
+		C := batch.ApplyTransform(c.transform, nil, true)
+		if !isShape(C.Shape, c.expected) {
+			t.Errorf("batch %v: expected shape %v, got %v", c.shape, c.expected, C.Shape)
+			continue
+		}
+
+		size := Product(c.shape[1:])
+		for i := 0; i < c.shape[0]; i++ {
+			image := &Tensor{Shape: c.shape[1:], Data: batch.Data[i*size : (i+1)*size]}
+			single := c.transform.Transform(image, NewGenerator(0))
+			if !sameData(C.Data[i*len(single.Data):(i+1)*len(single.Data)], single.Data) {
+				t.Errorf("batch %v: image %v differs from transforming it alone", c.shape, i)
+			}
+		}
+	}
+}
+
+func Test_ImageTensorConversion(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{G: 51, B: 102, A: 255})
+
+	A := ImageToTensor(img, false)
+	if !isShape(A.Shape, []int{3, 1, 2}) || !sameData(A.Data, []float64{1, 0, 0, 0.2, 0, 0.4}) {
+		t.Fatalf("ImageToTensor: %v %v", A.Shape, A.Data)
+	}
+
+	back := TensorToImage(A)
+	if r, g, b, _ := back.At(1, 0).RGBA(); r != 0 || g>>8 != 51 || b>>8 != 102 {
+		t.Errorf("TensorToImage round trip: %v %v %v", r>>8, g>>8, b>>8)
+	}
+
+	gray := TensorToImage(ConstTensor([]int{2, 2}, 1, false))
+	if _, ok := gray.(*image.Gray); !ok || gray.Bounds().Dx() != 2 {
+		t.Errorf("2D Tensors should become *image.Gray, got %T", gray)
+	}
+}
+
+func isShape(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package TG
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func sampleFrame() *Frame {
+	return NewFrame(
+		StringColumn("city", []string{"paris", "oslo", "paris", "rome", "oslo"}),
+		IntColumn("year", []int{2020, 2021, 2021, 2020, 2020}),
+		FloatColumn("temp", []float64{12.5, 4, 14, 18, math.NaN()}),
+	)
+}
+
+func checkValues(t *testing.T, name string, expected []float64, actual []float64) {
+	if len(expected) != len(actual) {
+		t.Errorf("%v failed. Expected: %v --- Actual: %v", name, expected, actual)
+		return
+	}
+	for i := range expected {
+		if expected[i] != actual[i] && !(math.IsNaN(expected[i]) && math.IsNaN(actual[i])) && math.Abs(expected[i]-actual[i]) > 1e-9 {
+			t.Errorf("%v failed. Expected: %v --- Actual: %v", name, expected, actual)
+			return
+		}
+	}
+}
+
+func Test_Frame_SelectFilterSort(t *testing.T) {
+
+	f := sampleFrame()
+	if f.NumRows() != 5 || strings.Join(f.Names(), ",") != "city,year,temp" {
+		t.Fatalf("NewFrame() failed. Actual Output: %v", f.Names())
+	}
+
+	selected := f.Select("temp", "city")
+	if strings.Join(selected.Names(), ",") != "temp,city" || strings.Join(f.Drop("year").Names(), ",") != "city,temp" {
+		t.Errorf("Select()/Drop() failed. Actual Output: %v", selected.Names())
+	}
+
+	// rows with temp > 10
+	warm := f.Filter(Greater(f.Column("temp").Values, ConstTensor([]int{5}, 10, false), false))
+	if strings.Join(warm.Column("city").Strings(), ",") != "paris,paris,rome" {
+		t.Errorf("Filter() failed. Actual Output: %v", warm.Column("city").Strings())
+	}
+
+	// categorical columns sort by name, ties keep their order, missing values go last
+	sorted := f.SortBy(false, "city", "temp")
+	if strings.Join(sorted.Column("city").Strings(), ",") != "oslo,oslo,paris,paris,rome" {
+		t.Errorf("SortBy() failed. Actual Output: %v", sorted.Column("city").Strings())
+	}
+	checkValues(t, "SortBy()", []float64{4, math.NaN(), 12.5, 14, 18}, sorted.Column("temp").Values.Data)
+	checkValues(t, "SortBy() descending", []float64{18, 14, 12.5, 4, math.NaN()}, f.SortBy(true, "temp").Column("temp").Values.Data)
+}
+
+func Test_Frame_GroupBy(t *testing.T) {
+
+	f := sampleFrame()
+	grouped := f.GroupBy("city")
+	if grouped.NumGroups() != 3 {
+		t.Fatalf("GroupBy() failed. Actual Output: %v groups", grouped.NumGroups())
+	}
+
+	sums := grouped.Sum()
+	if strings.Join(sums.Column("city").Strings(), ",") != "oslo,paris,rome" || strings.Join(sums.Names(), ",") != "city,year,temp" {
+		t.Errorf("GroupBy().Sum() failed. Actual Output: %v %v", sums.Names(), sums.Column("city").Strings())
+	}
+	checkValues(t, "GroupBy().Sum()", []float64{math.NaN(), 26.5, 18}, sums.Column("temp").Values.Data)
+	checkValues(t, "GroupBy().Mean()", []float64{2020.5, 2020.5, 2020}, grouped.Mean().Column("year").Values.Data)
+	checkValues(t, "GroupBy().Max()", []float64{2021, 2021, 2020}, grouped.Max().Column("year").Values.Data)
+	checkValues(t, "GroupBy().Count()", []float64{2, 2, 1}, grouped.Count().Column("count").Values.Data)
+
+	// Sum(), Min() and Max() keep int columns, Mean() makes them float
+	if sums.Column("year").Kind != IntKind || grouped.Max().Column("year").Kind != IntKind || grouped.Mean().Column("year").Kind != FloatKind {
+		t.Errorf("GroupBy() aggregation changed the kind of an int column")
+	}
+
+	// a Frame with only categorical columns aggregates to its keys
+	categorical := f.Select("city").WithColumn(StringColumn("label", []string{"a", "b", "a", "b", "a"})).GroupBy("city").Sum()
+	if strings.Join(categorical.Names(), ",") != "city" || categorical.NumRows() != 3 {
+		t.Errorf("GroupBy().Sum() of categorical columns failed. Actual Output: %v", categorical.Names())
+	}
+
+	// multiple keys
+	checkValues(t, "GroupBy() two keys", []float64{1, 1, 1, 1, 1}, f.GroupBy("city", "year").Count().Column("count").Values.Data)
+}
+
+func Test_Frame_Join(t *testing.T) {
+
+	f := sampleFrame()
+	countries := NewFrame(
+		StringColumn("city", []string{"rome", "paris", "berlin"}),
+		StringColumn("country", []string{"italy", "france", "germany"}),
+		IntColumn("year", []int{1, 2, 3}),
+	)
+
+	inner := f.Join(countries, "city", InnerJoin)
+	if inner.NumRows() != 3 || strings.Join(inner.Column("country").Strings(), ",") != "france,france,italy" {
+		t.Errorf("Join() inner failed. Actual Output: %v", inner.Column("country").Strings())
+	}
+	if strings.Join(inner.Names(), ",") != "city,year,temp,country,year_right" {
+		t.Errorf("Join() column names failed. Actual Output: %v", inner.Names())
+	}
+
+	left := f.Join(countries, "city", LeftJoin)
+	if left.NumRows() != 5 || strings.Join(left.Column("country").Strings(), ",") != "france,,france,italy," {
+		t.Errorf("Join() left failed. Actual Output: %v", left.Column("country").Strings())
+	}
+}
+
+func Test_Frame_DescribeAndToTensor(t *testing.T) {
+
+	f := sampleFrame()
+	described := f.Describe()
+	if strings.Join(described.Names(), ",") != "stat,year,temp" {
+		t.Fatalf("Describe() failed. Actual Output: %v", described.Names())
+	}
+	// temp present values: 12.5, 4, 14, 18
+	checkValues(t, "Describe()", []float64{4, 12.125, math.Sqrt(104.1875 / 3), 4, 10.375, 13.25, 15, 18}, described.Column("temp").Values.Data)
+
+	A := f.ToTensor(true, "city", "temp")
+	if A.Shape[0] != 5 || A.Shape[1] != 4 || !A.Batched {
+		t.Fatalf("ToTensor() failed. Actual Output: %v", A.Shape)
+	}
+	checkValues(t, "ToTensor()", []float64{1, 0, 0, 12.5, 0, 1, 0, 4}, A.Data[:8])
+	checkValues(t, "ToTensor() codes", []float64{0, 1, 0, 2, 1}, f.ToTensor(false, "city").Data)
+
+	if !strings.Contains(f.String(), "paris  2020  12.5") {
+		t.Errorf("String() failed. Actual Output:\n%v", f.String())
+	}
+}
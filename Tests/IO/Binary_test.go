@@ -0,0 +1,220 @@
+package TG
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func Test_Binary_RoundTrip(t *testing.T) {
+
+	A := RandFloat64Tensor([]int{3, 50, 40}, -1, 1, false)
+	A.Batched = true
+
+	for _, options := range []BinaryOptions{{}, {Compression: CompressionGzip}, {Compression: CompressionZstd}} {
+		var buf bytes.Buffer
+		written, err := WriteBinary(&buf, A, options)
+		if err != nil || written != int64(buf.Len()) {
+			t.Fatalf("WriteBinary() failed: %v, wrote %v of %v bytes", err, written, buf.Len())
+		}
+		B, err := ReadBinary(&buf)
+		if err != nil || !sameTensor(B, A.Shape, A.Data) || !B.Batched {
+			t.Errorf("Binary round trip with %+v failed: %v", options, err)
+		}
+	}
+
+	// float32 storage rounds each element to float32 precision
+	var buf bytes.Buffer
+	WriteBinary(&buf, A, BinaryOptions{Float32: true})
+	B, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatalf("ReadBinary() of float32 data failed: %v", err)
+	}
+	for i := range A.Data {
+		if B.Data[i] != float64(float32(A.Data[i])) {
+			t.Fatalf("Float32 round trip failed at %v: %v vs %v", i, B.Data[i], A.Data[i])
+		}
+	}
+}
+
+func Test_Binary_WriterToReaderFrom(t *testing.T) {
+
+	A := Gradify(RangeTensor([]int{2, 3}, false))
+
+	// several records can be read from one stream in sequence
+	var buf bytes.Buffer
+	n1, _ := A.WriteTo(&buf)
+	n2, _ := RangeTensor([]int{4}, false).WriteTo(&buf)
+
+	reader := bufio.NewReader(&buf)
+	var B, C Tensor
+	read1, err1 := B.ReadFrom(reader)
+	read2, err2 := C.ReadFrom(reader)
+	if err1 != nil || err2 != nil || read1 != n1 || read2 != n2 {
+		t.Fatalf("ReadFrom() failed: %v %v, read %v and %v bytes of %v and %v", err1, err2, read1, read2, n1, n2)
+	}
+	if !sameTensor(&B, []int{2, 3}, A.Data) || !B.RequireGrad || len(B.DataReqGrad) != 6 || !sameTensor(&C, []int{4}, []float64{0, 1, 2, 3}) {
+		t.Errorf("ReadFrom() failed. Actual Output: %v %v", B.String(), C.String())
+	}
+}
+
+func Test_Binary_Checksum(t *testing.T) {
+
+	A := RangeTensor([]int{10}, false)
+	for _, options := range []BinaryOptions{{}, {Compression: CompressionGzip}, {Compression: CompressionZstd}} {
+		var buf bytes.Buffer
+		WriteBinary(&buf, A, options)
+
+		// flip a bit in the data section of an uncompressed record, or in the gzip trailer or zstd content checksum of a
+		// compressed one
+		corrupt := buf.Bytes()
+		if options.Compression == CompressionZstd {
+			corrupt[len(corrupt)-5] ^= 0x10
+		} else {
+			corrupt[len(corrupt)-9] ^= 0x10
+		}
+		if _, err := ReadBinary(bytes.NewReader(corrupt)); !errors.Is(err, ErrChecksum) {
+			t.Errorf("ReadBinary() of corrupt data with %+v should fail with ErrChecksum, got %v", options, err)
+		}
+	}
+
+	if _, err := ReadBinary(bytes.NewReader([]byte("TGTN\x01\x00\x07\x00"))); err == nil {
+		t.Errorf("ReadBinary() with an unregistered compression should fail")
+	}
+}
+
+func Test_Binary_CorruptHeader(t *testing.T) {
+
+	var buf bytes.Buffer
+	WriteBinary(&buf, RangeTensor([]int{2, 3, 4}, false), BinaryOptions{})
+	record := buf.Bytes()
+
+	// a flipped byte in the first dim claims billions of elements, more than the record holds
+	huge := bytes.Clone(record)
+	huge[15] = 0xff
+	if _, err := ReadBinary(bytes.NewReader(huge)); err == nil {
+		t.Errorf("ReadBinary() with a corrupt dim should fail")
+	}
+
+	// dims whose product overflows, and a corrupt rank, are rejected before anything is allocated
+	overflow := bytes.Clone(record)
+	for i := 0; i < 3; i++ {
+		copy(overflow[12+8*i:], []byte{0xff, 0xff, 0xff, 0x7f})
+	}
+	rank := bytes.Clone(record)
+	copy(rank[8:], []byte{0xff, 0xff, 0xff, 0xff})
+	for name, corrupt := range map[string][]byte{"overflowing shape": overflow, "corrupt rank": rank} {
+		if _, err := ReadBinary(bytes.NewReader(corrupt)); err == nil {
+			t.Errorf("ReadBinary() with a %v should fail", name)
+		}
+	}
+
+	// a container claiming billions of entries fails on its first missing entry
+	if _, err := ReadTensors(bytes.NewReader([]byte("TGTC\x01\xff\xff\xff\xff"))); err == nil {
+		t.Errorf("ReadTensors() with a corrupt count should fail")
+	}
+}
+
+func Test_Binary_GradientTracked(t *testing.T) {
+
+	// Linear() weights hold their values in DataReqGrad rather than Data
+	W := Linear(3, 2, "relu", nil).Weights
+	var buf bytes.Buffer
+	if _, err := WriteBinary(&buf, W, BinaryOptions{}); err != nil {
+		t.Fatalf("WriteBinary() of Linear() weights failed: %v", err)
+	}
+	B, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatalf("ReadBinary() of Linear() weights failed: %v", err)
+	}
+	if !B.RequireGrad || len(B.DataReqGrad) != len(W.DataReqGrad) {
+		t.Fatalf("ReadBinary() of Linear() weights lost gradient tracking")
+	}
+	for i, value := range W.DataReqGrad {
+		if B.Data[i] != value.Scalar || B.DataReqGrad[i].Scalar != value.Scalar {
+			t.Fatalf("binary round trip of Linear() weights failed. Actual Output: %v", B.Data)
+		}
+	}
+
+	// Tensors whose Data does not match their Shape are rejected before anything is written
+	mismatched := &Tensor{Shape: []int{2, 3}, Data: make([]float64, 4)}
+	buf.Reset()
+	if _, err := WriteBinary(&buf, mismatched, BinaryOptions{}); err == nil || buf.Len() != 0 {
+		t.Errorf("WriteBinary() of a mismatched Tensor should fail without writing, got %v", err)
+	}
+	if err := WriteTensors(&buf, map[string]*Tensor{"a": OnesTensor([]int{2}, false), "b": mismatched}, BinaryOptions{}); err == nil || buf.Len() != 0 {
+		t.Errorf("WriteTensors() with a mismatched Tensor should fail without writing, got %v", err)
+	}
+}
+
+func Test_Binary_Zstd(t *testing.T) {
+
+	// repetitive data compresses well
+	A := ZeroTensor([]int{100, 100}, false)
+	for i := range A.Data {
+		A.Data[i] = float64(i % 7)
+	}
+	var buf bytes.Buffer
+	written, err := WriteBinary(&buf, A, BinaryOptions{Compression: CompressionZstd})
+	if err != nil || written > int64(len(A.Data)) {
+		t.Fatalf("WriteBinary() with zstd failed: %v, wrote %v bytes for %v elements", err, written, len(A.Data))
+	}
+
+	// a frame written by the reference zstd encoder at level 19, with Huffman coded literals and FSE coded sequences,
+	// in the record of RangeTensor([]int{256})
+	frame, _ := hex.DecodeString("28b52ffd6400075d09004653392c40578603c39ca6280a301986395445d13519fdb50a604800000000000000634146220000" +
+		"2111262c26792605380028002800f4096d4297d024f4082d4287d020f407ed4177d01cf406dd924606c6854505c5844404c4834303c3824202c281" +
+		"4141110c08040094a5243238bc7bc3bb36bc5bc3bb34bc3bc3bb32bc1bc3bb30bcfbc2bb2ebcdbc2bb2cbcbbc2bb2a740a8d12b61610a79fadf5c3" +
+		"e9676bf970fad95a3d9c7eb6160fa79fadb5c3e9676be970fad95ac6bb39bc1b459a7eb61612a79fad75c4e9676b1971fad95a459c7eb61611a79f" +
+		"ad35c4e9676b0971fad95a419c7e0180ffa810d01703e00f103cbe19761080000208400001082080000410800002084000a543d1a174281d940ea5" +
+		"43d1a174d85b03401a03208d01908ce5abb5ae229e1a0db19f269b")
+	R := RangeTensor([]int{256}, false)
+	buf.Reset()
+	WriteBinary(&buf, R, BinaryOptions{Compression: CompressionZstd})
+	record := buf.Bytes()
+	reference := append(append(bytes.Clone(record[:20]), frame...), record[len(record)-4:]...) // <--- header, data, checksum
+	if B, err := ReadBinary(bytes.NewReader(reference)); err != nil || !sameTensor(B, R.Shape, R.Data) {
+		t.Errorf("ReadBinary() of a reference zstd frame failed: %v", err)
+	}
+
+	// a frame cut short is reported rather than read as zeros
+	if _, err := ReadBinary(bytes.NewReader(reference[:len(reference)-40])); err == nil {
+		t.Errorf("ReadBinary() of a truncated zstd frame should fail")
+	}
+}
+
+func Test_Binary_Container(t *testing.T) {
+
+	tensors := map[string]*Tensor{
+		"layer1.weight": RangeTensor([]int{4, 3}, false),
+		"layer1.bias":   OnesTensor([]int{3}, false),
+		"scalar":        {Shape: []int{}, Data: []float64{2.5}},
+	}
+
+	for _, compression := range []Compression{CompressionGzip, CompressionZstd} {
+		var buf bytes.Buffer
+		if err := WriteTensors(&buf, tensors, BinaryOptions{Compression: compression}); err != nil {
+			t.Fatalf("WriteTensors() failed: %v", err)
+		}
+		loaded, err := ReadTensors(&buf)
+		if err != nil || len(loaded) != 3 {
+			t.Fatalf("ReadTensors() with compression %v failed: %v", compression, err)
+		}
+		for name, A := range tensors {
+			if !sameTensor(loaded[name], A.Shape, A.Data) {
+				t.Errorf("Container round trip of %v with compression %v failed", name, compression)
+			}
+		}
+	}
+
+	fileName := filepath.Join(t.TempDir(), "model.tgt")
+	SaveTensors(tensors, fileName, BinaryOptions{})
+	if loaded := LoadTensors(fileName); !sameTensor(loaded["layer1.weight"], []int{4, 3}, tensors["layer1.weight"].Data) {
+		t.Errorf("SaveTensors()/LoadTensors() failed")
+	}
+}
@@ -0,0 +1,124 @@
+package TG
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+const csvData = `height;color;"weight, kg"
+1.5;red;60
+NA;blue;72.5
+1.8;red;
+1.7;"green";80
+`
+
+func Test_ReadCSV(t *testing.T) {
+
+	table, err := ReadCSV(strings.NewReader(csvData), CSVOptions{Delimiter: ';', Header: true})
+	if err != nil {
+		t.Fatalf("ReadCSV() failed: %v", err)
+	}
+
+	nan := math.NaN()
+	if !sameTensor(table.Data, []int{4, 3}, []float64{1.5, 0, 60, nan, 1, 72.5, 1.8, 0, nan, 1.7, 2, 80}) {
+		t.Errorf("ReadCSV() failed. Actual Output: %v", table.Data.Data)
+	}
+	if strings.Join(table.Columns, "|") != "height|color|weight, kg" {
+		t.Errorf("ReadCSV() columns failed. Actual Output: %v", table.Columns)
+	}
+	if strings.Join(table.Categories["color"], "|") != "red|blue|green" || len(table.Categories) != 1 {
+		t.Errorf("ReadCSV() categories failed. Actual Output: %v", table.Categories)
+	}
+}
+
+func Test_ReadCSV_ImputeAndOneHot(t *testing.T) {
+
+	options := CSVOptions{Delimiter: ';', Header: true, Impute: ImputeMean, Encoding: EncodeOneHot, Columns: []string{"weight, kg", "color"}}
+	table, err := ReadCSV(strings.NewReader(csvData), options)
+	if err != nil {
+		t.Fatalf("ReadCSV() failed: %v", err)
+	}
+
+	// the missing weight is the mean of 60, 72.5 and 80
+	expected := []float64{
+		60, 1, 0, 0,
+		72.5, 0, 1, 0,
+		212.5 / 3, 1, 0, 0,
+		80, 0, 0, 1,
+	}
+	if !sameTensor(table.Data, []int{4, 4}, expected) {
+		t.Errorf("ReadCSV() one-hot failed. Actual Output: %v", table.Data.Data)
+	}
+	if strings.Join(table.Columns, "|") != "weight, kg|color=red|color=blue|color=green" {
+		t.Errorf("ReadCSV() one-hot columns failed. Actual Output: %v", table.Columns)
+	}
+
+	// median and constant fills
+	table, _ = ReadCSV(strings.NewReader(csvData), CSVOptions{Delimiter: ';', Header: true, Impute: ImputeMedian, Columns: []string{"height"}})
+	if table.Data.Data[1] != 1.7 {
+		t.Errorf("ImputeMedian failed. Actual Output: %v", table.Data.Data)
+	}
+	table, _ = ReadCSV(strings.NewReader(csvData), CSVOptions{Delimiter: ';', Header: true, Impute: ImputeConstant, FillValue: -1, Columns: []string{"height"}})
+	if table.Data.Data[1] != -1 {
+		t.Errorf("ImputeConstant failed. Actual Output: %v", table.Data.Data)
+	}
+
+	if _, err := ReadCSV(strings.NewReader(csvData), CSVOptions{Delimiter: ';', Header: true, Columns: []string{"age"}}); err == nil {
+		t.Errorf("ReadCSV() of a missing column should fail")
+	}
+}
+
+func Test_CSVReader_Chunks(t *testing.T) {
+
+	var b strings.Builder
+	for i := 0; i < 10; i++ {
+		b.WriteString([]string{"a", "b"}[i%2] + "," + strings.Repeat("1", i+1) + "\n")
+	}
+
+	reader, err := NewCSVReader(strings.NewReader(b.String()), CSVOptions{ChunkSize: 4, Categorical: []string{"0"}})
+	if err != nil {
+		t.Fatalf("NewCSVReader() failed: %v", err)
+	}
+
+	rows := [][]int{}
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() failed: %v", err)
+		}
+		rows = append(rows, chunk.Shape)
+		if chunk.Data[0] != 0 || chunk.Data[2] != 1 {
+			t.Errorf("Next() label encoding failed. Actual Output: %v", chunk.Data)
+		}
+	}
+	if len(rows) != 3 || rows[0][0] != 4 || rows[2][0] != 2 || rows[2][1] != 2 {
+		t.Errorf("Next() chunking failed. Actual Output: %v", rows)
+	}
+
+	// strategies that need the whole column cannot be used in chunks
+	reader, _ = NewCSVReader(strings.NewReader(b.String()), CSVOptions{Impute: ImputeMean})
+	if _, err := reader.Next(); err == nil {
+		t.Errorf("Next() with ImputeMean should fail")
+	}
+}
+
+func Test_LoadCSV_Header(t *testing.T) {
+
+	fileName := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(fileName, []byte("a,b\n1,2\n3,4\n"), 0644)
+
+	// the header is not loaded as a row of zeros
+	A := LoadCSV(fileName, true)
+	if !sameTensor(A, []int{2, 2}, []float64{1, 2, 3, 4}) || !A.Batched {
+		t.Errorf("LoadCSV() failed. Actual Output: %v %v", A.Shape, A.Data)
+	}
+}
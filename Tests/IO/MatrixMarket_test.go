@@ -0,0 +1,69 @@
+package TG
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func Test_MatrixMarket(t *testing.T) {
+
+	// symmetric files only store the lower triangle
+	mtx := `%%MatrixMarket matrix coordinate real symmetric
+% a comment
+3 3 4
+1 1 2.0
+2 1 -1
+2 2 2
+3 3 1.5
+`
+	S, err := ReadMatrixMarket(strings.NewReader(mtx))
+	if err != nil {
+		t.Fatalf("ReadMatrixMarket() failed: %v", err)
+	}
+
+	A := S.ToDense()
+	if A.Get([]int{0, 1}) != -1 || A.Get([]int{1, 0}) != -1 || A.Get([]int{2, 2}) != 1.5 || S.NNZ() != 5 {
+		t.Errorf("ReadMatrixMarket() failed. Actual Output: %v", A.Data)
+	}
+
+	// writing then reading should reproduce the same matrix
+	var buf bytes.Buffer
+	if err := WriteMatrixMarket(&buf, S); err != nil {
+		t.Fatalf("WriteMatrixMarket() failed: %v", err)
+	}
+	S2, err := ReadMatrixMarket(&buf)
+	if err != nil {
+		t.Fatalf("ReadMatrixMarket() failed: %v", err)
+	}
+	B := S2.ToDense()
+	for i := range A.Data {
+		if A.Data[i] != B.Data[i] {
+			t.Errorf("Matrix Market round trip failed. Expected Output: %v --- Actual Output: %v", A.Data, B.Data)
+			break
+		}
+	}
+
+	// malformed input should return an error rather than panic
+	if _, err := ReadMatrixMarket(strings.NewReader("%%MatrixMarket matrix array real general\n2 2\n")); err == nil {
+		t.Errorf("ReadMatrixMarket() should reject array format")
+	}
+	if _, err := ReadMatrixMarket(strings.NewReader("%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n")); err == nil {
+		t.Errorf("ReadMatrixMarket() should reject out of bounds entries")
+	}
+
+	// the size line must be non negative and match the number of entries
+	header := "%%MatrixMarket matrix coordinate real general\n"
+	for name, body := range map[string]string{
+		"negative rows":    "-2 2 0\n",
+		"negative nnz":     "2 2 -1\n",
+		"missing entries":  "2 2 2\n1 1 1\n",
+		"too many entries": "2 2 1\n1 1 1\n2 2 1\n",
+	} {
+		if _, err := ReadMatrixMarket(strings.NewReader(header + body)); err == nil {
+			t.Errorf("ReadMatrixMarket() should reject %v", name)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package TG
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// builds a version 1.0 .npy file with the given header dict and raw data
+func npyBytes(dict string, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&b, binary.LittleEndian, uint16(len(dict)+1))
+	b.WriteString(dict + "\n")
+	b.Write(data)
+	return b.Bytes()
+}
+
+func sameTensor(A *Tensor, shape []int, data []float64) bool {
+	if len(A.Shape) != len(shape) || len(A.Data) != len(data) {
+		return false
+	}
+	for i := range shape {
+		if A.Shape[i] != shape[i] {
+			return false
+		}
+	}
+	for i := range data {
+		if A.Data[i] != data[i] && !(math.IsNaN(A.Data[i]) && math.IsNaN(data[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_NPY_RoundTrip(t *testing.T) {
+
+	A := RangeTensor([]int{2, 3, 4}, false)
+	A.Data[3] = math.NaN()
+
+	var buf bytes.Buffer
+	if err := WriteNPY(&buf, A); err != nil {
+		t.Fatalf("WriteNPY() failed: %v", err)
+	}
+	if buf.Len()%64 != len(A.Data)*8%64 {
+		t.Errorf("WriteNPY() failed. The header is not padded to a multiple of 64 bytes")
+	}
+
+	B, err := ReadNPY(&buf)
+	if err != nil {
+		t.Fatalf("ReadNPY() failed: %v", err)
+	}
+	if !sameTensor(B, A.Shape, A.Data) {
+		t.Errorf("NPY round trip failed. Actual Output: %v %v", B.Shape, B.Data)
+	}
+
+	// scalars and vectors use the Python tuple forms () and (n,)
+	for _, shape := range [][]int{{}, {5}} {
+		C := &Tensor{Shape: shape, Data: make([]float64, Product(shape))}
+		buf.Reset()
+		WriteNPY(&buf, C)
+		if D, err := ReadNPY(&buf); err != nil || !sameTensor(D, shape, C.Data) {
+			t.Errorf("NPY round trip of shape %v failed: %v", shape, err)
+		}
+	}
+}
+
+func Test_NPY_GradientTracked(t *testing.T) {
+
+	// Linear() weights hold their values in DataReqGrad rather than Data
+	W := Linear(3, 2, "relu", nil).Weights
+	var buf bytes.Buffer
+	if err := WriteNPY(&buf, W); err != nil {
+		t.Fatalf("WriteNPY() of Linear() weights failed: %v", err)
+	}
+	B, err := ReadNPY(&buf)
+	if err != nil {
+		t.Fatalf("ReadNPY() of Linear() weights failed: %v", err)
+	}
+	for i, value := range W.DataReqGrad {
+		if B.Data[i] != value.Scalar {
+			t.Fatalf("NPY round trip of Linear() weights failed. Actual Output: %v", B.Data)
+		}
+	}
+
+	// a Tensor whose Data does not match its Shape is rejected before anything is written
+	buf.Reset()
+	if err := WriteNPY(&buf, &Tensor{Shape: []int{2, 3}, Data: make([]float64, 4)}); err == nil || buf.Len() != 0 {
+		t.Errorf("WriteNPY() of a mismatched Tensor should fail without writing, got %v", err)
+	}
+}
+
+func Test_NPY_Corrupt(t *testing.T) {
+
+	// a shape whose size overflows is rejected, and a huge shape without the data fails when the data runs out
+	for _, dict := range []string{
+		"{'descr': '<f8', 'fortran_order': False, 'shape': (4611686018427387904, 4), }",
+		"{'descr': '<f8', 'fortran_order': False, 'shape': (1099511627776,), }",
+	} {
+		if _, err := ReadNPY(bytes.NewReader(npyBytes(dict, make([]byte, 16)))); err == nil {
+			t.Errorf("ReadNPY() failed. Expected an error for the header %s", dict)
+		}
+	}
+
+	// a version 2.0 header length is checked before it is allocated
+	corrupt := []byte("\x93NUMPY\x02\x00\xff\xff\xff\xff")
+	if _, err := ReadNPY(bytes.NewReader(corrupt)); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("ReadNPY() failed. Expected a header length error, got %v", err)
+	}
+
+	// the reader is not read past the end of an array, so arrays can be read back to back
+	var buf bytes.Buffer
+	WriteNPY(&buf, RangeTensor([]int{3}, false))
+	WriteNPY(&buf, RangeTensor([]int{2, 2}, false))
+	first, err1 := ReadNPY(&buf)
+	second, err2 := ReadNPY(&buf)
+	if err1 != nil || err2 != nil || !sameTensor(first, []int{3}, []float64{0, 1, 2}) || !sameTensor(second, []int{2, 2}, []float64{0, 1, 2, 3}) {
+		t.Errorf("ReadNPY() failed. Consecutive arrays were not read back: %v %v", err1, err2)
+	}
+}
+
+func Test_NPY_Dtypes(t *testing.T) {
+
+	// big endian int32 in Fortran order: the matrix [[1, 2, 3], [4, 5, 6]] is stored column by column
+	data := new(bytes.Buffer)
+	binary.Write(data, binary.BigEndian, []int32{1, 4, 2, 5, 3, -6})
+	A, err := ReadNPY(bytes.NewReader(npyBytes("{'descr': '>i4', 'fortran_order': True, 'shape': (2, 3), }", data.Bytes())))
+	if err != nil || !sameTensor(A, []int{2, 3}, []float64{1, 2, 3, 4, 5, -6}) {
+		t.Errorf("ReadNPY() of >i4 Fortran failed: %v %v", err, A)
+	}
+
+	// little endian float32
+	data.Reset()
+	binary.Write(data, binary.LittleEndian, []float32{0.5, -2})
+	A, err = ReadNPY(bytes.NewReader(npyBytes("{'descr': '<f4', 'fortran_order': False, 'shape': (2,), }", data.Bytes())))
+	if err != nil || !sameTensor(A, []int{2}, []float64{0.5, -2}) {
+		t.Errorf("ReadNPY() of <f4 failed: %v %v", err, A)
+	}
+
+	// float16, uint8 and bool
+	data.Reset()
+	binary.Write(data, binary.LittleEndian, []uint16{0x3c00, 0xc000, 0x7c00})
+	A, err = ReadNPY(bytes.NewReader(npyBytes("{'descr': '<f2', 'fortran_order': False, 'shape': (3,), }", data.Bytes())))
+	if err != nil || !sameTensor(A, []int{3}, []float64{1, -2, math.Inf(1)}) {
+		t.Errorf("ReadNPY() of <f2 failed: %v %v", err, A)
+	}
+	A, err = ReadNPY(bytes.NewReader(npyBytes("{'descr': '|u1', 'fortran_order': False, 'shape': (2,), }", []byte{255, 7})))
+	if err != nil || !sameTensor(A, []int{2}, []float64{255, 7}) {
+		t.Errorf("ReadNPY() of |u1 failed: %v %v", err, A)
+	}
+	A, err = ReadNPY(bytes.NewReader(npyBytes("{'descr': '|b1', 'fortran_order': False, 'shape': (2,), }", []byte{1, 0})))
+	if err != nil || !sameTensor(A, []int{2}, []float64{1, 0}) {
+		t.Errorf("ReadNPY() of |b1 failed: %v %v", err, A)
+	}
+
+	// unsupported dtypes and truncated data are errors
+	if _, err := ReadNPY(bytes.NewReader(npyBytes("{'descr': '<c16', 'fortran_order': False, 'shape': (1,), }", make([]byte, 16)))); err == nil {
+		t.Errorf("ReadNPY() of a complex dtype should fail")
+	}
+	if _, err := ReadNPY(bytes.NewReader(npyBytes("{'descr': '<f8', 'fortran_order': False, 'shape': (2,), }", make([]byte, 8)))); err == nil {
+		t.Errorf("ReadNPY() of truncated data should fail")
+	}
+	if _, err := ReadNPY(strings.NewReader("not numpy")); err == nil {
+		t.Errorf("ReadNPY() of a non .npy input should fail")
+	}
+}
+
+func Test_NPZ(t *testing.T) {
+
+	arrays := map[string]*Tensor{
+		"weights": RangeTensor([]int{3, 2}, false),
+		"bias":    OnesTensor([]int{2}, false),
+	}
+
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := WriteNPZ(&buf, arrays, compress); err != nil {
+			t.Fatalf("WriteNPZ() failed: %v", err)
+		}
+
+		// a bytes.Buffer is not seekable so it is read into memory first
+		loaded, err := ReadNPZ(&buf)
+		if err != nil {
+			t.Fatalf("ReadNPZ() failed: %v", err)
+		}
+		if len(loaded) != 2 || !sameTensor(loaded["weights"], []int{3, 2}, arrays["weights"].Data) || !sameTensor(loaded["bias"], []int{2}, []float64{1, 1}) {
+			t.Errorf("NPZ round trip failed with compress = %v", compress)
+		}
+	}
+
+	// files are read in place
+	fileName := filepath.Join(t.TempDir(), "arrays.npz")
+	SaveNPZ(arrays, fileName, true)
+	if loaded := LoadNPZ(fileName); !sameTensor(loaded["weights"], []int{3, 2}, arrays["weights"].Data) {
+		t.Errorf("SaveNPZ()/LoadNPZ() failed")
+	}
+}
@@ -0,0 +1,138 @@
+package TG
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// builds a safetensors file from a header and a data buffer
+func safetensorsBytes(header string, data []byte) []byte {
+	b := binary.LittleEndian.AppendUint64(nil, uint64(len(header)))
+	return append(append(b, header...), data...)
+}
+
+func Test_Safetensors_RoundTrip(t *testing.T) {
+
+	tensors := map[string]*Tensor{
+		"encoder.weight": RangeTensor([]int{3, 4}, false),
+		"encoder.bias":   OnesTensor([]int{4}, false),
+	}
+	metadata := map[string]string{"format": "pt", "epoch": "12"}
+
+	var buf bytes.Buffer
+	if err := WriteSafetensors(&buf, tensors, metadata, false); err != nil {
+		t.Fatalf("WriteSafetensors() failed: %v", err)
+	}
+	if headerLength := binary.LittleEndian.Uint64(buf.Bytes()); (8+headerLength)%8 != 0 {
+		t.Errorf("WriteSafetensors() failed. The data buffer is not 8 byte aligned")
+	}
+
+	loaded, loadedMetadata, err := ReadSafetensors(&buf)
+	if err != nil {
+		t.Fatalf("ReadSafetensors() failed: %v", err)
+	}
+	for name, A := range tensors {
+		if !sameTensor(loaded[name], A.Shape, A.Data) {
+			t.Errorf("Safetensors round trip of %v failed", name)
+		}
+	}
+	if len(loadedMetadata) != 2 || loadedMetadata["epoch"] != "12" {
+		t.Errorf("Safetensors metadata round trip failed. Actual Output: %v", loadedMetadata)
+	}
+
+	// F32 storage
+	buf.Reset()
+	WriteSafetensors(&buf, map[string]*Tensor{"x": {Shape: []int{2}, Data: []float64{0.1, 2}}}, nil, true)
+	loaded, _, err = ReadSafetensors(&buf)
+	if err != nil || !sameTensor(loaded["x"], []int{2}, []float64{float64(float32(0.1)), 2}) {
+		t.Errorf("Safetensors F32 round trip failed: %v", err)
+	}
+}
+
+func Test_Safetensors_GradientTracked(t *testing.T) {
+
+	// Linear() weights hold their values in DataReqGrad rather than Data
+	W := Linear(3, 2, "relu", nil).Weights
+	var buf bytes.Buffer
+	if err := WriteSafetensors(&buf, map[string]*Tensor{"w": W}, nil, false); err != nil {
+		t.Fatalf("WriteSafetensors() of Linear() weights failed: %v", err)
+	}
+	loaded, _, err := ReadSafetensors(&buf)
+	if err != nil {
+		t.Fatalf("ReadSafetensors() of Linear() weights failed: %v", err)
+	}
+	for i, value := range W.DataReqGrad {
+		if loaded["w"].Data[i] != value.Scalar {
+			t.Fatalf("Safetensors round trip of Linear() weights failed. Actual Output: %v", loaded["w"].Data)
+		}
+	}
+
+	// Tensors whose Data does not match their Shape are rejected before anything is written
+	mismatched := &Tensor{Shape: []int{2, 3}, Data: make([]float64, 4)}
+	buf.Reset()
+	if err := WriteSafetensors(&buf, map[string]*Tensor{"a": OnesTensor([]int{2}, false), "b": mismatched}, nil, false); err == nil || buf.Len() != 0 {
+		t.Errorf("WriteSafetensors() with a mismatched Tensor should fail without writing, got %v", err)
+	}
+}
+
+func Test_Safetensors_Dtypes(t *testing.T) {
+
+	// BF16 [1, -2], then I32 [[7], [-3]]
+	data := binary.LittleEndian.AppendUint16(nil, 0x3f80)
+	data = binary.LittleEndian.AppendUint16(data, 0xc000)
+	data = binary.LittleEndian.AppendUint32(data, 7)
+	data = binary.LittleEndian.AppendUint32(data, uint32(0xfffffffd))
+	header := `{"a":{"dtype":"BF16","shape":[2],"data_offsets":[0,4]},"b":{"dtype":"I32","shape":[2,1],"data_offsets":[4,12]}}`
+
+	loaded, metadata, err := ReadSafetensors(bytes.NewReader(safetensorsBytes(header, data)))
+	if err != nil || metadata != nil {
+		t.Fatalf("ReadSafetensors() failed: %v", err)
+	}
+	if !sameTensor(loaded["a"], []int{2}, []float64{1, -2}) || !sameTensor(loaded["b"], []int{2, 1}, []float64{7, -3}) {
+		t.Errorf("ReadSafetensors() failed. Actual Output: %v %v", loaded["a"], loaded["b"])
+	}
+
+	// offsets that do not match the shape, or run past the buffer, are errors
+	bad := `{"a":{"dtype":"F32","shape":[2],"data_offsets":[0,4]}}`
+	if _, _, err := ReadSafetensors(bytes.NewReader(safetensorsBytes(bad, make([]byte, 8)))); err == nil {
+		t.Errorf("ReadSafetensors() with mismatched offsets should fail")
+	}
+	bad = `{"a":{"dtype":"F32","shape":[2],"data_offsets":[4,12]}}`
+	if _, _, err := ReadSafetensors(bytes.NewReader(safetensorsBytes(bad, make([]byte, 8)))); err == nil {
+		t.Errorf("ReadSafetensors() with out of range offsets should fail")
+	}
+
+	// a shape whose size overflows must not wrap around to the size of the data
+	bad = `{"a":{"dtype":"F64","shape":[3,6148914691236517206],"data_offsets":[0,16]}}`
+	if _, _, err := ReadSafetensors(bytes.NewReader(safetensorsBytes(bad, make([]byte, 16)))); err == nil {
+		t.Errorf("ReadSafetensors() with an overflowing shape should fail")
+	}
+}
+
+func Test_Safetensors_Mmap(t *testing.T) {
+
+	tensors := map[string]*Tensor{"w": RangeTensor([]int{64, 8}, false), "b": RangeTensor([]int{8}, false)}
+	fileName := filepath.Join(t.TempDir(), "model.safetensors")
+	SaveSafetensors(tensors, map[string]string{"note": "test"}, fileName)
+
+	f, err := MmapSafetensors(fileName)
+	if err != nil {
+		t.Fatalf("MmapSafetensors() failed: %v", err)
+	}
+	if !sameTensor(f.Tensors["w"], []int{64, 8}, tensors["w"].Data) || !sameTensor(f.Tensors["b"], []int{8}, tensors["b"].Data) || f.Metadata["note"] != "test" {
+		t.Errorf("MmapSafetensors() failed")
+	}
+
+	// the mapping is private, so writes do not reach the file
+	f.Tensors["w"].Data[0] = 100
+	if err := f.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	if loaded, _ := LoadSafetensors(fileName); loaded["w"].Data[0] != 0 {
+		t.Errorf("Writing to a mapped Tensor changed the file")
+	}
+}
@@ -1,6 +1,7 @@
 package TG
 
 import (
+	"path/filepath"
 	"testing"
 
 	. "github.com/Holindauer/Tensor-Go/TensorGo"
@@ -12,10 +13,11 @@ func Test_SaveLoad(t *testing.T) {
 	A := RandFloat64Tensor([]int{2, 3}, 0, 1, false)
 
 	// Save the Tensor to a JSON file
-	A.Save_JSON("A.json")
+	path := filepath.Join(t.TempDir(), "A.json")
+	A.Save_JSON(path)
 
 	// Load the Tensor from the JSON file
-	B := Load_JSON("A.json")
+	B := Load_JSON(path)
 
 	// asert that A sums to the same value as B.
 	if A.Sum_All() != B.Sum_All() {
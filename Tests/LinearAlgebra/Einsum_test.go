@@ -0,0 +1,108 @@
+package TG
+
+// Einsum_test.go contains tests for Einsum.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// checks that A has the expected shape and agrees with B elementwise within 1e-9
+func checkEinsum(t *testing.T, subscripts string, A *Tensor, shape []int, B *Tensor) {
+	if len(A.Shape) != len(shape) {
+		t.Errorf("Einsum(%q) failed. Expected Shape: %v --- Actual Output: %v", subscripts, shape, A.Shape)
+		return
+	}
+	for i := range shape {
+		if A.Shape[i] != shape[i] {
+			t.Errorf("Einsum(%q) failed. Expected Shape: %v --- Actual Output: %v", subscripts, shape, A.Shape)
+			return
+		}
+	}
+	for i := range B.Data {
+		if math.Abs(A.Data[i]-B.Data[i]) > 1e-9 {
+			t.Errorf("Einsum(%q) failed. Expected Output: %v --- Actual Output: %v", subscripts, B.Data, A.Data)
+			return
+		}
+	}
+}
+
+func Test_Einsum(t *testing.T) {
+
+	A := RandFloat64Tensor([]int{3, 4}, -1, 1, false)
+	B := RandFloat64Tensor([]int{4, 5}, -1, 1, false)
+	x := RandFloat64Tensor([]int{4}, -1, 1, false)
+	y := RandFloat64Tensor([]int{4}, -1, 1, false)
+
+	checkEinsum(t, "ij,jk->ik", Einsum("ij,jk->ik", A, B), []int{3, 5}, MatMul(A, B, false))
+	checkEinsum(t, "ij,jk", Einsum("ij,jk", A, B), []int{3, 5}, MatMul(A, B, false)) // <--- implicit output
+	checkEinsum(t, "i,i->", Einsum("i,i->", x, y), []int{1}, Dot(x, y, false))
+	checkEinsum(t, "i,j->ij", Einsum("i,j->ij", x, y), []int{4, 4}, Outer(x.Copy(), y.Copy(), false))
+	checkEinsum(t, "ij->ji", Einsum("ij->ji", A), []int{4, 3}, A.Permute([]int{1, 0}))
+	checkEinsum(t, "ij->j", Einsum("ij->j", A), []int{4}, A.Sum_Axis(0, false))
+
+	/// @notice repeated indices take the trace and diagonal
+	S := RangeTensor([]int{3, 3}, false)
+	if Einsum("ii", S).Data[0] != 12 {
+		t.Errorf("Einsum(\"ii\") failed. Expected Output: 12 --- Actual Output: %v", Einsum("ii", S).Data)
+	}
+	diag := Einsum("ii->i", S)
+	if diag.Data[0] != 0 || diag.Data[1] != 4 || diag.Data[2] != 8 {
+		t.Errorf("Einsum(\"ii->i\") failed. Expected Output: [0 4 8] --- Actual Output: %v", diag.Data)
+	}
+
+	/// @notice batched and ellipsis contractions
+	bA := RandFloat64Tensor([]int{2, 3, 4}, -1, 1, true)
+	bB := RandFloat64Tensor([]int{2, 4, 5}, -1, 1, true)
+	checkEinsum(t, "bij,bjk->bik", Einsum("bij,bjk->bik", bA, bB), []int{2, 3, 5}, MatMul(bA, bB, true))
+	checkEinsum(t, "...ij,...jk->...ik", Einsum("...ij,...jk->...ik", bA, bB), []int{2, 3, 5}, MatMul(bA, bB, true))
+	checkEinsum(t, "...ij,jk", Einsum("...ij,jk", bA, B), []int{2, 3, 5}, Einsum("bij,jk->bik", bA, B))
+
+	/// @notice 3 operand contraction gives the same result as sequential products
+	C := RandFloat64Tensor([]int{5, 2}, -1, 1, false)
+	checkEinsum(t, "ij,jk,kl->il", Einsum("ij,jk,kl->il", A, B, C), []int{3, 2}, MatMul(MatMul(A, B, false), C, false))
+}
+
+func Test_Einsum_Errors(t *testing.T) {
+
+	A := RangeTensor([]int{3, 4}, false)
+	B := RangeTensor([]int{3, 4}, false)
+
+	for _, subscripts := range []string{"ij,jk->ik", "ijk->i", "ij->ix", "ij,ij->ii", "i1->i"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Einsum(%q) should have panicked", subscripts)
+				}
+			}()
+			if subscripts == "ijk->i" || subscripts == "i1->i" {
+				Einsum(subscripts, A)
+			} else {
+				Einsum(subscripts, A, B)
+			}
+		}()
+	}
+}
+
+func Test_EinsumGrad(t *testing.T) {
+
+	A := Gradify(RandFloat64Tensor([]int{2, 3}, -1, 1, false))
+	B := Gradify(RandFloat64Tensor([]int{3, 2}, -1, 1, false))
+
+	// the trace of AB, whose gradient wrt A[i, j] is B[j, i]
+	loss := EinsumGrad("ij,ji->", A, B)
+	loss.DataReqGrad[0].Backward()
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			if math.Abs(A.DataReqGrad[i*3+j].Grad-B.Data[j*2+i]) > 1e-9 {
+				t.Errorf("EinsumGrad() failed. Expected gradient %v --- Actual Output: %v", B.Data[j*2+i], A.DataReqGrad[i*3+j].Grad)
+			}
+		}
+	}
+	if math.Abs(loss.Data[0]-Einsum("ij,ji->", A, B).Data[0]) > 1e-9 {
+		t.Errorf("EinsumGrad() failed. Expected Output: %v --- Actual Output: %v", Einsum("ij,ji->", A, B).Data[0], loss.Data[0])
+	}
+}
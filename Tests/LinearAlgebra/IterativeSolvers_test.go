@@ -0,0 +1,117 @@
+package TG
+
+// IterativeSolvers_test.go contains tests for IterativeSolvers.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// builds the n x n tridiagonal matrix with 4 on the diagonal and the specified off diagonals
+func tridiagonal(n int, lower, upper float64) *Tensor {
+	A := ZeroTensor([]int{n, n}, false)
+	for i := 0; i < n; i++ {
+		A.Data[i*n+i] = 4
+		if i > 0 {
+			A.Data[i*n+i-1] = lower
+		}
+		if i < n-1 {
+			A.Data[i*n+i+1] = upper
+		}
+	}
+	return A
+}
+
+// checks that x solves Ax = b within tol
+func checkSolution(t *testing.T, name string, A LinearOperator, x *Tensor, b *Tensor, tol float64) {
+	Ax := A.Apply(x)
+	for i := range b.Data {
+		if math.Abs(Ax.Data[i]-b.Data[i]) > tol {
+			t.Errorf("%v failed. Expected Ax[%v] = %v --- Actual Output: %v", name, i, b.Data[i], Ax.Data[i])
+			return
+		}
+	}
+}
+
+// laplacianOperator applies the 1D laplacian [-1, 2, -1] without storing a matrix
+type laplacianOperator struct{}
+
+func (op laplacianOperator) Apply(x *Tensor) *Tensor {
+	n := len(x.Data)
+	y := ZeroTensor([]int{n}, false)
+	for i := 0; i < n; i++ {
+		y.Data[i] = 2 * x.Data[i]
+		if i > 0 {
+			y.Data[i] -= x.Data[i-1]
+		}
+		if i < n-1 {
+			y.Data[i] -= x.Data[i+1]
+		}
+	}
+	return y
+}
+
+func Test_ConjugateGradient(t *testing.T) {
+
+	/// @notice symmetric positive definite system with and without preconditioning
+	A := tridiagonal(20, -1, -1)
+	b := RangeTensor([]int{20}, false)
+
+	result := ConjugateGradient(A, b, SolverOptions{})
+	if !result.Converged {
+		t.Errorf("ConjugateGradient() did not converge. Residuals: %v", result.Residuals)
+	}
+	checkSolution(t, "ConjugateGradient()", A, result.X, b, 1e-6)
+
+	if len(result.Residuals) != result.Iterations+1 {
+		t.Errorf("ConjugateGradient() failed. Expected %v residuals --- Actual Output: %v", result.Iterations+1, len(result.Residuals))
+	}
+
+	for _, M := range []Preconditioner{NewJacobiPreconditioner(A), NewIncompleteCholeskyPreconditioner(A)} {
+		result = ConjugateGradient(A, b, SolverOptions{Preconditioner: M})
+		checkSolution(t, "Preconditioned ConjugateGradient()", A, result.X, b, 1e-6)
+	}
+
+	/// @notice matrix free operator
+	result = ConjugateGradient(laplacianOperator{}, OnesTensor([]int{10}, false), SolverOptions{Tol: 1e-10})
+	checkSolution(t, "Matrix free ConjugateGradient()", laplacianOperator{}, result.X, OnesTensor([]int{10}, false), 1e-8)
+}
+
+func Test_GMRES(t *testing.T) {
+
+	// nonsymmetric system
+	A := tridiagonal(30, -2, 1)
+	b := OnesTensor([]int{30}, false)
+
+	result := GMRES(A, b, SolverOptions{Restart: 5})
+	if !result.Converged {
+		t.Errorf("GMRES() did not converge. Residuals: %v", result.Residuals)
+	}
+	checkSolution(t, "GMRES()", A, result.X, b, 1e-6)
+
+	result = GMRES(A, b, SolverOptions{Preconditioner: NewJacobiPreconditioner(A)})
+	checkSolution(t, "Preconditioned GMRES()", A, result.X, b, 1e-6)
+
+	// iteration limit should be respected
+	result = GMRES(A, b, SolverOptions{MaxIter: 2, Restart: 5})
+	if result.Iterations != 2 || result.Converged {
+		t.Errorf("GMRES() failed. Expected 2 unconverged iterations --- Actual Output: %v", result.Iterations)
+	}
+}
+
+func Test_BiCGSTAB(t *testing.T) {
+
+	A := tridiagonal(30, -2, 1)
+	b := RangeTensor([]int{30}, false)
+
+	result := BiCGSTAB(A, b, SolverOptions{})
+	if !result.Converged {
+		t.Errorf("BiCGSTAB() did not converge. Residuals: %v", result.Residuals)
+	}
+	checkSolution(t, "BiCGSTAB()", A, result.X, b, 1e-6)
+
+	result = BiCGSTAB(A, b, SolverOptions{Preconditioner: NewJacobiPreconditioner(A), X0: OnesTensor([]int{30}, false)})
+	checkSolution(t, "Preconditioned BiCGSTAB()", A, result.X, b, 1e-6)
+}
@@ -0,0 +1,46 @@
+package TG
+
+// MatMul_bench_test.go compares the blocked MatMul() kernel against the original MatMulNaive() implementation.
+// Run with: go test -bench=MatMul -run=^$ ./Tests/LinearAlgebra/
+
+import (
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// benchmarks matmul on A [m, k] x B [k, n], or on a batch of them when batch > 0
+func benchmarkMatMul(b *testing.B, matmul func(A *Tensor, B *Tensor, batching bool) *Tensor, batch, m, k, n int) {
+	var A, B *Tensor
+	if batch > 0 {
+		A = RandFloat64Tensor([]int{batch, m, k}, -1, 1, true)
+		B = RandFloat64Tensor([]int{batch, k, n}, -1, 1, true)
+	} else {
+		A = RandFloat64Tensor([]int{m, k}, -1, 1, false)
+		B = RandFloat64Tensor([]int{k, n}, -1, 1, false)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matmul(A, B, batch > 0)
+	}
+}
+
+func Benchmark_MatMul_Square(b *testing.B)      { benchmarkMatMul(b, MatMul, 0, 256, 256, 256) }
+func Benchmark_MatMulNaive_Square(b *testing.B) { benchmarkMatMul(b, MatMulNaive, 0, 256, 256, 256) }
+func Benchmark_MatMul_TallSkinny(b *testing.B)  { benchmarkMatMul(b, MatMul, 0, 4096, 64, 16) }
+func Benchmark_MatMulNaive_TallSkinny(b *testing.B) {
+	benchmarkMatMul(b, MatMulNaive, 0, 4096, 64, 16)
+}
+func Benchmark_MatMul_Batched(b *testing.B)      { benchmarkMatMul(b, MatMul, 32, 64, 64, 64) }
+func Benchmark_MatMulNaive_Batched(b *testing.B) { benchmarkMatMul(b, MatMulNaive, 32, 64, 64, 64) }
+
+func Benchmark_MatMulTransposeB_Square(b *testing.B) {
+	A := RandFloat64Tensor([]int{256, 256}, -1, 1, false)
+	B := RandFloat64Tensor([]int{256, 256}, -1, 1, false)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MatMulTransposeB(A, B, false)
+	}
+}
@@ -3,6 +3,7 @@ package TG
 // matrix_test.go contains tests for the matrix.go file
 
 import (
+	"math"
 	"testing"
 
 	. "github.com/Holindauer/Tensor-Go/TensorGo"
@@ -49,3 +50,67 @@ func Test_Augment_Matrix(t *testing.T) {
 		t.Error("Augment_Matrix() failed")
 	}
 }
+
+// checks that two tensors agree elementwise within 1e-9
+func closeTensors(A *Tensor, B *Tensor) bool {
+	if len(A.Data) != len(B.Data) {
+		return false
+	}
+	for i := range A.Data {
+		if A.Data[i]-B.Data[i] > 1e-9 || B.Data[i]-A.Data[i] > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_MatMul_Blocked(t *testing.T) {
+
+	// shapes that are not multiples of the tile sizes, including ones large enough to run in parallel
+	shapes := [][3]int{{1, 1, 1}, {7, 3, 5}, {33, 130, 257}, {300, 20, 2}, {2, 500, 300}}
+
+	for _, shape := range shapes {
+		A := RandFloat64Tensor([]int{shape[0], shape[1]}, -1, 1, false)
+		B := RandFloat64Tensor([]int{shape[1], shape[2]}, -1, 1, false)
+
+		C := MatMul(A, B, false)
+		if C.Shape[0] != shape[0] || C.Shape[1] != shape[2] || !closeTensors(C, MatMulNaive(A, B, false)) {
+			t.Errorf("MatMul() failed for shape %v", shape)
+		}
+
+		// A B^T with B stored as [n, k]
+		B_T := B.Permute([]int{1, 0})
+		if !closeTensors(MatMulTransposeB(A, B_T, false), C) {
+			t.Errorf("MatMulTransposeB() failed for shape %v", shape)
+		}
+	}
+
+	/// @notice batched matrix and matrix vector products
+	A := RandFloat64Tensor([]int{4, 40, 50}, -1, 1, true)
+	B := RandFloat64Tensor([]int{4, 50, 60}, -1, 1, true)
+	C := MatMul(A, B, true)
+	if C.Shape[0] != 4 || C.Shape[1] != 40 || C.Shape[2] != 60 || !closeTensors(C, MatMulNaive(A, B, true)) {
+		t.Errorf("MatMul() batched failed. Shape: %v", C.Shape)
+	}
+
+	x := RandFloat64Tensor([]int{4, 50}, -1, 1, true)
+	if !closeTensors(MatMul(A, x, true), MatMulNaive(A, x, true)) {
+		t.Errorf("MatMul() batched matrix vector failed")
+	}
+}
+
+func Test_MatMul_NonFinite(t *testing.T) {
+
+	// zeros in A multiply the Inf and NaN in B, which must still give NaN as in MatMulNaive()
+	A := ZeroTensor([]int{2, 2}, false)
+	A.Data[1] = 1
+	B := ZeroTensor([]int{2, 2}, false)
+	B.Data[0], B.Data[1] = math.Inf(1), math.NaN()
+
+	C, naive := MatMul(A, B, false), MatMulNaive(A, B, false)
+	for i := range C.Data {
+		if !math.IsNaN(C.Data[i]) || !math.IsNaN(naive.Data[i]) {
+			t.Fatalf("expected every element to be NaN, got %v and %v from MatMulNaive()", C.Data, naive.Data)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package TG
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// returns n samples of 2 features drawn around each of the centers with standard deviation std, and the center of each sample
+func blobs(n int, centers [][]float64, std float64, seed int64) (*Tensor, *Tensor) {
+	g := NewGenerator(seed)
+	X := ZeroTensor([]int{n * len(centers), 2}, false)
+	y := ZeroTensor([]int{n * len(centers)}, false)
+	for i := range y.Data {
+		c := i % len(centers)
+		X.Data[2*i], X.Data[2*i+1] = centers[c][0]+g.Normal(0, std), centers[c][1]+g.Normal(0, std)
+		y.Data[i] = float64(c)
+	}
+	return X, y
+}
+
+// reports whether two labelings group the samples identically, up to renaming the clusters
+func sameClusters(a, b *Tensor) bool {
+	forward, backward := map[float64]float64{}, map[float64]float64{}
+	for i := range a.Data {
+		if f, ok := forward[a.Data[i]]; ok && f != b.Data[i] {
+			return false
+		}
+		if r, ok := backward[b.Data[i]]; ok && r != a.Data[i] {
+			return false
+		}
+		forward[a.Data[i]], backward[b.Data[i]] = b.Data[i], a.Data[i]
+	}
+	return true
+}
+
+var blobCenters = [][]float64{{0, 6}, {6, 0}, {-6, -6}}
+
+func Test_PairwiseDistances(t *testing.T) {
+	X := matrix([]float64{0, 0}, []float64{3, 4})
+	Y := matrix([]float64{0, 0}, []float64{6, 8}, []float64{3, 0})
+
+	D := PairwiseDistances(X, Y, false, false)
+	if !isShape(D.Shape, []int{2, 3}) || !approxEqual(D.Data, []float64{0, 10, 3, 5, 5, 4}, 1e-12) {
+		t.Errorf("unexpected distances %v %v", D.Shape, D.Data)
+	}
+	if squared := PairwiseDistances(X, Y, true, false); !approxEqual(squared.Data, []float64{0, 100, 9, 25, 25, 16}, 1e-9) {
+		t.Errorf("unexpected squared distances %v", squared.Data)
+	}
+
+	// far from the origin the distances keep their precision, including those between nearly equal rows
+	g := NewGenerator(1)
+	far, near := g.NormalTensor([]int{40, 3}, 0, 1, false), g.NormalTensor([]int{30, 3}, 0, 1, false)
+	copy(near.Data[:3], far.Data[:3])
+	near.Data[0] += 1e-6
+	for _, A := range []*Tensor{far, near} {
+		for i := range A.Data {
+			A.Data[i] += 1e7
+		}
+	}
+	D = PairwiseDistances(far, near, false, false)
+	for i := 0; i < 40; i++ {
+		for j := 0; j < 30; j++ {
+			expected := 0.0
+			for f := 0; f < 3; f++ {
+				expected += (far.Data[3*i+f] - near.Data[3*j+f]) * (far.Data[3*i+f] - near.Data[3*j+f])
+			}
+			if expected = math.Sqrt(expected); math.Abs(D.Data[30*i+j]-expected) > 1e-9*math.Max(expected, 1e-6) {
+				t.Fatalf("distance %v between rows %v and %v should be %v", D.Data[30*i+j], i, j, expected)
+			}
+		}
+	}
+}
+
+func Test_KMeans(t *testing.T) {
+	X, y := blobs(100, blobCenters, 1, 1)
+	model := NewKMeans(3)
+	model.Generator = NewGenerator(2)
+	model.Fit(X)
+
+	if !isShape(model.Centers.Shape, []int{3, 2}) || !sameClusters(model.Labels, y) {
+		t.Fatalf("expected the clusters to recover the blobs")
+	}
+	for c := 0; c < 3; c++ {
+		found := false
+		for _, center := range blobCenters {
+			found = found || approxEqual(model.Centers.Data[2*c:2*c+2], center, 0.3)
+		}
+		if !found {
+			t.Errorf("center %v is far from every blob", model.Centers.Data[2*c:2*c+2])
+		}
+	}
+
+	// the inertia is the sum of squared distances to the closest center
+	distances := model.Transform(X)
+	inertia := 0.0
+	for i, c := range model.Labels.Data {
+		inertia += distances.Data[3*i+int(c)] * distances.Data[3*i+int(c)]
+	}
+	if math.Abs(inertia-model.Inertia) > 1e-6*model.Inertia {
+		t.Errorf("expected an inertia of %v, got %v", inertia, model.Inertia)
+	}
+	if !approxEqual(model.Predict(X).Data, model.Labels.Data, 0) {
+		t.Errorf("Predict() disagrees with the training labels")
+	}
+
+	// the same seed gives the same fit
+	again := NewKMeans(3)
+	again.Generator = NewGenerator(2)
+	again.Fit(X)
+	if !approxEqual(again.Centers.Data, model.Centers.Data, 0) {
+		t.Errorf("the same seed gave different centers")
+	}
+}
+
+func Test_MiniBatchKMeans(t *testing.T) {
+	X, y := blobs(200, blobCenters, 1, 3)
+	model := NewMiniBatchKMeans(3, 32)
+	model.Generator = NewGenerator(4)
+	model.Fit(X)
+
+	if !sameClusters(model.Labels, y) {
+		t.Errorf("expected the clusters to recover the blobs")
+	}
+
+	// partial fits on a stream of batches
+	stream := NewMiniBatchKMeans(3, 32)
+	stream.Generator = NewGenerator(5)
+	for start := 0; start < 600; start += 60 {
+		stream.PartialFit(X.IndexSelect(0, rangeOf(start, start+60), false))
+	}
+	if !sameClusters(stream.Predict(X), y) {
+		t.Errorf("expected partial fits to recover the blobs")
+	}
+}
+
+func rangeOf(start, end int) []int {
+	indices := make([]int, end-start)
+	for i := range indices {
+		indices[i] = start + i
+	}
+	return indices
+}
+
+func Test_Silhouette(t *testing.T) {
+	X := matrix([]float64{0}, []float64{1}, []float64{10}, []float64{12})
+	labels := ZeroTensor([]int{4}, false)
+	copy(labels.Data, []float64{0, 0, 1, 1})
+
+	// sample 0: a = 1, b = (10 + 12) / 2 = 11, s = 10 / 11
+	S := SilhouetteSamples(X, labels)
+	expected := []float64{10.0 / 11, 1 - 1/10.0, 1 - 2/9.5, 1 - 2/11.5}
+	if !approxEqual(S.Data, expected, 1e-12) {
+		t.Errorf("expected silhouettes %v, got %v", expected, S.Data)
+	}
+
+	blobsX, y := blobs(50, blobCenters, 1, 6)
+	if score := SilhouetteScore(blobsX, y); score < 0.7 {
+		t.Errorf("expected a high silhouette for separated blobs, got %v", score)
+	}
+}
+
+func Test_GaussianMixture(t *testing.T) {
+	X, y := blobs(150, blobCenters, 1, 7)
+
+	for _, covariance := range []CovarianceType{CovarianceFull, CovarianceDiag, CovarianceSpherical} {
+		model := NewGaussianMixture(3, covariance)
+		model.Generator = NewGenerator(8)
+		model.Fit(X)
+
+		if !model.Converged || !sameClusters(model.Predict(X), y) {
+			t.Errorf("covariance %v: expected a converged fit recovering the blobs", covariance)
+		}
+		if math.Abs(SumReducer{}.Reduce(model.Weights.Data)-1) > 1e-9 {
+			t.Errorf("covariance %v: weights %v do not sum to 1", covariance, model.Weights.Data)
+		}
+		if math.Abs(model.Score(X)-model.LowerBound) > 1e-2 {
+			t.Errorf("covariance %v: score %v far from the lower bound %v", covariance, model.Score(X), model.LowerBound)
+		}
+
+		P := model.PredictProba(X)
+		for i := 0; i < X.Shape[0]; i++ {
+			if math.Abs(P.Data[3*i]+P.Data[3*i+1]+P.Data[3*i+2]-1) > 1e-9 {
+				t.Fatalf("covariance %v: responsibilities of sample %v do not sum to 1", covariance, i)
+			}
+		}
+	}
+
+	// a full covariance captures correlated features, so the BIC prefers it to a diagonal one
+	g := NewGenerator(9)
+	correlated := ZeroTensor([]int{400, 2}, false)
+	for i := 0; i < 400; i++ {
+		z := g.Normal(0, 1)
+		correlated.Data[2*i], correlated.Data[2*i+1] = 2*z, 2*z+g.Normal(0, 0.1)
+	}
+	full := NewGaussianMixture(1, CovarianceFull)
+	full.Fit(correlated)
+	covariance := full.Covariances.Data
+	if !isShape(full.Covariances.Shape, []int{1, 2, 2}) || math.Abs(covariance[1]-covariance[2]) > 1e-12 || covariance[1] < 3 {
+		t.Errorf("expected a symmetric covariance with a strong correlation, got %v", covariance)
+	}
+	diag := NewGaussianMixture(1, CovarianceDiag)
+	diag.Fit(correlated)
+	if full.BIC(correlated) >= diag.BIC(correlated) {
+		t.Errorf("the full covariance should have the lower BIC on correlated data")
+	}
+}
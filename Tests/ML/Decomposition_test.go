@@ -0,0 +1,189 @@
+package TG
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// returns the [n, f] product of n x r and r x f Gaussian matrices, of rank r
+func lowRank(n, f, r int, seed int64) *Tensor {
+	g := NewGenerator(seed)
+	return MatMul(g.NormalTensor([]int{n, r}, 0, 1, false), g.NormalTensor([]int{r, f}, 0, 1, false), false)
+}
+
+// checks that the rows of A are orthonormal
+func checkOrthonormalRows(t *testing.T, A *Tensor, name string) {
+	t.Helper()
+	gram := MatMulTransposeB(A, A, false)
+	for i := 0; i < A.Shape[0]; i++ {
+		for j := 0; j < A.Shape[0]; j++ {
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			if math.Abs(gram.Data[i*A.Shape[0]+j]-expected) > 1e-9 {
+				t.Fatalf("the rows of %v are not orthonormal: %v", name, gram.Data)
+			}
+		}
+	}
+}
+
+func Test_EigSymmetric(t *testing.T) {
+	B := NewGenerator(1).NormalTensor([]int{5, 5}, 0, 1, false)
+	A := MatMulTransposeB(B, B, false) // <--- symmetric positive semidefinite
+
+	values, vectors := EigSymmetric(A)
+	checkOrthonormalRows(t, vectors, "the eigenvectors")
+	for k := 0; k < 5; k++ {
+		if k > 0 && values.Data[k] > values.Data[k-1] {
+			t.Errorf("eigenvalues %v are not decreasing", values.Data)
+		}
+		v := &Tensor{Shape: []int{5}, Data: vectors.Data[5*k : 5*k+5]}
+		Av := MatMul(A, v, false)
+		for i := 0; i < 5; i++ {
+			if math.Abs(Av.Data[i]-values.Data[k]*v.Data[i]) > 1e-9 {
+				t.Fatalf("A v != lambda v for eigenpair %v", k)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic on a nonsymmetric matrix")
+		}
+	}()
+	EigSymmetric(B)
+}
+
+func Test_SVD(t *testing.T) {
+	for _, shape := range [][]int{{6, 4}, {4, 6}} {
+		A := NewGenerator(2).NormalTensor(shape, 0, 1, false)
+		U, S, Vt := SVD(A)
+		r := min(shape[0], shape[1])
+		if !isShape(U.Shape, []int{shape[0], r}) || !isShape(S.Shape, []int{r}) || !isShape(Vt.Shape, []int{r, shape[1]}) {
+			t.Fatalf("unexpected shapes %v %v %v", U.Shape, S.Shape, Vt.Shape)
+		}
+		checkOrthonormalRows(t, Vt, "Vt")
+		checkOrthonormalRows(t, U.Permute([]int{1, 0}), "U^T")
+
+		// U diag(S) Vt reconstructs A
+		US := U.Copy()
+		for i := 0; i < shape[0]; i++ {
+			for k := 0; k < r; k++ {
+				US.Data[i*r+k] *= S.Data[k]
+			}
+		}
+		if !approxEqual(MatMul(US, Vt, false).Data, A.Data, 1e-9) {
+			t.Errorf("U S Vt does not reconstruct the %v matrix", shape)
+		}
+		for k := 0; k < r; k++ {
+			largest := 0.0
+			for _, value := range Vt.Data[k*shape[1] : (k+1)*shape[1]] {
+				if math.Abs(value) > math.Abs(largest) {
+					largest = value
+				}
+			}
+			if largest < 0 || (k > 0 && S.Data[k] > S.Data[k-1]) {
+				t.Errorf("expected decreasing singular values with positive leading elements, got %v", S.Data)
+			}
+		}
+	}
+}
+
+func Test_PCA(t *testing.T) {
+	// samples spread along (3, 4) / 5 with a little noise off it
+	g := NewGenerator(3)
+	X := ZeroTensor([]int{200, 2}, false)
+	for i := 0; i < 200; i++ {
+		z, noise := g.Normal(0, 5), g.Normal(0, 0.1)
+		X.Data[2*i], X.Data[2*i+1] = 1+0.6*z-0.8*noise, 2+0.8*z+0.6*noise
+	}
+
+	pca := NewPCA(0)
+	pca.Fit(X)
+	if !approxEqual(pca.Components.Data[:2], []float64{0.6, 0.8}, 1e-3) {
+		t.Errorf("expected the first axis near (0.6, 0.8), got %v", pca.Components.Data[:2])
+	}
+	if math.Abs(pca.ExplainedVarianceRatio.Data[0]+pca.ExplainedVarianceRatio.Data[1]-1) > 1e-12 || pca.ExplainedVarianceRatio.Data[0] < 0.99 {
+		t.Errorf("unexpected explained variance ratios %v", pca.ExplainedVarianceRatio.Data)
+	}
+
+	// with every component the transform is invertible, and the projected variances are the explained variances
+	Z := pca.Transform(X)
+	if !approxEqual(pca.InverseTransform(Z).Data, X.Data, 1e-9) {
+		t.Errorf("the inverse transform does not reconstruct X")
+	}
+	if variance := Z.Var_Axis(0, false).Data[0] * 200 / 199; math.Abs(variance-pca.ExplainedVariance.Data[0]) > 1e-9 {
+		t.Errorf("projected variance %v differs from the explained variance %v", variance, pca.ExplainedVariance.Data[0])
+	}
+
+	// a variance threshold keeps only the first axis, and whitening gives it unit variance
+	reduced := &PCA{VarianceThreshold: 0.95, Whiten: true}
+	reduced.Fit(X)
+	W := reduced.Transform(X)
+	if !isShape(W.Shape, []int{200, 1}) || math.Abs(W.Var_Axis(0, false).Data[0]*200/199-1) > 1e-9 {
+		t.Errorf("expected one whitened component, got %v with variance %v", W.Shape, W.Var_Axis(0, false).Data)
+	}
+	if reconstruction := reduced.InverseTransform(W); !approxEqual(reconstruction.Data, X.Data, 0.5) {
+		t.Errorf("one component should reconstruct X up to the noise")
+	}
+}
+
+func Test_TruncatedSVD(t *testing.T) {
+	X := lowRank(60, 20, 3, 4)
+	_, S, Vt := SVD(X)
+
+	model := NewTruncatedSVD(3)
+	model.Generator = NewGenerator(5)
+	model.Fit(X)
+	if !approxEqual(model.SingularValues.Data, S.Data[:3], 1e-8) || !approxEqual(model.Components.Data, Vt.Data[:60], 1e-8) {
+		t.Errorf("randomized SVD disagrees with SVD(): %v vs %v", model.SingularValues.Data, S.Data[:3])
+	}
+	if !approxEqual(model.InverseTransform(model.Transform(X)).Data, X.Data, 1e-8) {
+		t.Errorf("three components should reconstruct a rank 3 matrix")
+	}
+	ratio := SumReducer{}.Reduce(model.ExplainedVarianceRatio.Data)
+	if ratio > 1+1e-9 || ratio < 0.9 {
+		t.Errorf("unexpected explained variance ratios %v", model.ExplainedVarianceRatio.Data)
+	}
+
+	// a sparse matrix gives the same fit as its dense form
+	sparse := NewGenerator(6).UniformTensor([]int{40, 15}, 0, 1, false)
+	for i, value := range sparse.Data {
+		if value < 0.8 {
+			sparse.Data[i] = 0
+		}
+	}
+	dense, fromSparse := NewTruncatedSVD(4), NewTruncatedSVD(4)
+	dense.Generator, fromSparse.Generator = NewGenerator(7), NewGenerator(7)
+	dense.Fit(sparse)
+	fromSparse.FitSparse(sparse.ToCSR())
+	if !approxEqual(dense.Components.Data, fromSparse.Components.Data, 1e-9) ||
+		!approxEqual(dense.ExplainedVarianceRatio.Data, fromSparse.ExplainedVarianceRatio.Data, 1e-9) {
+		t.Errorf("sparse and dense fits differ")
+	}
+	if !approxEqual(fromSparse.TransformSparse(sparse.ToCSR()).Data, dense.Transform(sparse).Data, 1e-9) {
+		t.Errorf("sparse and dense transforms differ")
+	}
+}
+
+func Test_PCA_Pipeline(t *testing.T) {
+	X := lowRank(30, 5, 2, 8)
+	pipeline := NewPipeline(NewStandardScaler(), NewPCA(2))
+	pipeline.Fit(X)
+
+	var buffer bytes.Buffer
+	if err := WritePipeline(&buffer, pipeline); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadPipeline(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !approxEqual(loaded.Transform(X).Data, pipeline.Transform(X).Data, 1e-12) {
+		t.Errorf("the loaded pipeline transforms differently")
+	}
+}
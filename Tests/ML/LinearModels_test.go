@@ -0,0 +1,192 @@
+package TG
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// returns n samples of 3 features with y = 2 x0 - 3 x1 + 0 x2 + 5 plus optional noise
+func linearData(n int, noise float64, seed int64) (*Tensor, *Tensor) {
+	g := NewGenerator(seed)
+	X := g.NormalTensor([]int{n, 3}, 0, 1, false)
+	y := ZeroTensor([]int{n}, false)
+	for i := 0; i < n; i++ {
+		y.Data[i] = 2*X.Data[3*i] - 3*X.Data[3*i+1] + 5 + g.Normal(0, noise)
+	}
+	return X, y
+}
+
+func Test_LinearRegression(t *testing.T) {
+	X, y := linearData(50, 0, 1)
+	model := NewLinearRegression()
+	model.Fit(X, y)
+
+	if !approxEqual(model.Coef.Data, []float64{2, -3, 0}, 1e-9) || math.Abs(model.Intercept.Data[0]-5) > 1e-9 {
+		t.Errorf("expected coefficients [2 -3 0] and intercept 5, got %v and %v", model.Coef.Data, model.Intercept.Data)
+	}
+	if score := model.Score(X, y); math.Abs(score-1) > 1e-12 {
+		t.Errorf("expected a perfect R2, got %v", score)
+	}
+
+	// two targets at once
+	Y := ZeroTensor([]int{50, 2}, false)
+	for i := 0; i < 50; i++ {
+		Y.Data[2*i], Y.Data[2*i+1] = y.Data[i], -y.Data[i]
+	}
+	model.Fit(X, Y)
+	if !isShape(model.Coef.Shape, []int{3, 2}) || math.Abs(model.Coef.Data[1]+2) > 1e-9 || math.Abs(model.Intercept.Data[1]+5) > 1e-9 {
+		t.Errorf("unexpected multi target fit %v %v", model.Coef.Data, model.Intercept.Data)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic on linearly dependent features")
+		}
+	}()
+	model.Fit(X.Concat(X.Slice(":, 0:1"), 1), y)
+}
+
+func Test_Ridge_MatchesClosedForm(t *testing.T) {
+	X, y := linearData(40, 0.5, 2)
+	alpha := 0.7
+	model := NewRidge(alpha)
+	model.Fit(X, y)
+
+	// with centered data, the ridge solution satisfies (Xc^T Xc / n + alpha I) w = Xc^T yc / n
+	n := 40.0
+	Xc, yc := X.Copy(), y.Copy()
+	for j := 0; j < 3; j++ {
+		mean := 0.0
+		for i := 0; i < 40; i++ {
+			mean += X.Data[3*i+j] / n
+		}
+		for i := 0; i < 40; i++ {
+			Xc.Data[3*i+j] -= mean
+		}
+	}
+	yMean := y.Mean([]int{}, false, false).Data[0]
+	for i := range yc.Data {
+		yc.Data[i] -= yMean
+	}
+	for j := 0; j < 3; j++ {
+		lhs, rhs := 0.0, 0.0
+		for k := 0; k < 3; k++ {
+			gram := 0.0
+			for i := 0; i < 40; i++ {
+				gram += Xc.Data[3*i+j] * Xc.Data[3*i+k] / n
+			}
+			if j == k {
+				gram += alpha
+			}
+			lhs += gram * model.Coef.Data[k]
+		}
+		for i := 0; i < 40; i++ {
+			rhs += Xc.Data[3*i+j] * yc.Data[i] / n
+		}
+		if math.Abs(lhs-rhs) > 1e-5 {
+			t.Errorf("ridge normal equation %v not satisfied: %v != %v", j, lhs, rhs)
+		}
+	}
+}
+
+func Test_Lasso_Sparsity(t *testing.T) {
+	X, y := linearData(100, 0.1, 3)
+	model := NewLasso(0.1)
+	model.Fit(X, y)
+
+	if model.Coef.Data[2] != 0 {
+		t.Errorf("the uninformative feature should have a zero coefficient, got %v", model.Coef.Data)
+	}
+	if math.Abs(model.Coef.Data[0]-1.9) > 0.1 || math.Abs(model.Coef.Data[1]+2.9) > 0.1 {
+		t.Errorf("expected shrunken coefficients near [1.9 -2.9], got %v", model.Coef.Data)
+	}
+	if score := model.Score(X, y); score < 0.98 {
+		t.Errorf("expected a high R2, got %v", score)
+	}
+}
+
+func Test_LogisticRegression_Binary(t *testing.T) {
+	g := NewGenerator(4)
+	X := g.NormalTensor([]int{200, 2}, 0, 1, false)
+	y := ZeroTensor([]int{200}, false)
+	for i := 0; i < 200; i++ {
+		// labels are 3 and 7, drawn with probability sigmoid(2 x0 - x1 + 0.5)
+		p := 1 / (1 + math.Exp(-(2*X.Data[2*i] - X.Data[2*i+1] + 0.5)))
+		y.Data[i] = 3
+		if g.Float64() < p {
+			y.Data[i] = 7
+		}
+	}
+
+	model := NewLogisticRegression()
+	model.Fit(X, y)
+	if !approxEqual(model.Classes, []float64{3, 7}, 0) || !isShape(model.Coef.Shape, []int{2}) {
+		t.Fatalf("unexpected classes %v or coefficient shape %v", model.Classes, model.Coef.Shape)
+	}
+	if model.Coef.Data[0] < 1 || model.Coef.Data[1] > -0.3 {
+		t.Errorf("coefficients %v far from [2 -1]", model.Coef.Data)
+	}
+
+	P := model.PredictProba(X)
+	for i := 0; i < 200; i++ {
+		if math.Abs(P.Data[2*i]+P.Data[2*i+1]-1) > 1e-12 {
+			t.Fatalf("probabilities of sample %v do not sum to 1", i)
+		}
+	}
+	if accuracy := model.Score(X, y); accuracy < 0.75 {
+		t.Errorf("expected an accuracy above 0.75, got %v", accuracy)
+	}
+}
+
+func Test_LogisticRegression_Multinomial(t *testing.T) {
+	// three well separated clusters
+	g := NewGenerator(5)
+	centers := [][]float64{{0, 4}, {4, 0}, {-4, -4}}
+	X := ZeroTensor([]int{150, 2}, false)
+	y := ZeroTensor([]int{150}, false)
+	for i := 0; i < 150; i++ {
+		c := i % 3
+		X.Data[2*i], X.Data[2*i+1] = centers[c][0]+g.Normal(0, 1), centers[c][1]+g.Normal(0, 1)
+		y.Data[i] = float64(c)
+	}
+
+	model := NewLogisticRegression()
+	model.Fit(X, y)
+	if !isShape(model.Coef.Shape, []int{2, 3}) || !isShape(model.Intercept.Shape, []int{3}) {
+		t.Fatalf("unexpected shapes %v %v", model.Coef.Shape, model.Intercept.Shape)
+	}
+	if accuracy := model.Score(X, y); accuracy < 0.97 {
+		t.Errorf("expected an accuracy above 0.97, got %v", accuracy)
+	}
+
+	test := matrix([]float64{0, 5}, []float64{5, 0}, []float64{-5, -5})
+	if predictions := model.Predict(test); !approxEqual(predictions.Data, []float64{0, 1, 2}, 0) {
+		t.Errorf("expected predictions [0 1 2], got %v", predictions.Data)
+	}
+}
+
+func Test_LinearModels_CrossValidate(t *testing.T) {
+	X, y := linearData(60, 0.1, 6)
+	result := CrossValidate(X, y, NewKFold(5, true), func(XTrain, yTrain, XTest, yTest *Tensor) map[string]float64 {
+		model := NewRidge(0.01)
+		model.Fit(XTrain, yTrain)
+		return map[string]float64{"r2": model.Score(XTest, yTest)}
+	})
+	if result.Mean["r2"] < 0.99 {
+		t.Errorf("expected a cross validated R2 above 0.99, got %v", result.Mean["r2"])
+	}
+}
+
+func isShape(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package TG
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func ints(A *Tensor) []int {
+	indices := make([]int, len(A.Data))
+	for i, value := range A.Data {
+		indices[i] = int(value)
+	}
+	return indices
+}
+
+// checks that train and test partition [0, n)
+func checkPartition(t *testing.T, split Split, n int) {
+	t.Helper()
+	all := append(ints(split.Train), ints(split.Test)...)
+	slices.Sort(all)
+	for i, index := range all {
+		if i != index || len(all) != n {
+			t.Fatalf("train %v and test %v do not partition %v examples", split.Train.Data, split.Test.Data, n)
+		}
+	}
+}
+
+func Test_TrainTestSplit(t *testing.T) {
+	X := RangeTensor([]int{10, 2}, false)
+	y := RangeTensor([]int{10}, false)
+
+	XTrain, XTest, yTrain, yTest := TrainTestSplit(X, y, 0.3, false, NewGenerator(1))
+	if XTrain.Shape[0] != 7 || XTest.Shape[0] != 3 || !XTrain.Batched || yTest.Shape[0] != 3 {
+		t.Fatalf("unexpected shapes %v %v %v", XTrain.Shape, XTest.Shape, yTest.Shape)
+	}
+	for i := 0; i < 7; i++ {
+		if XTrain.Data[2*i] != 2*yTrain.Data[i] {
+			t.Errorf("examples and labels were split differently")
+		}
+	}
+
+	// the same seed gives the same split
+	again := TrainTestIndices(10, 0.3, nil, NewGenerator(1))
+	if !slices.Equal(again.Test.Data, yTest.Data) {
+		t.Errorf("the same seed gave test sets %v and %v", again.Test.Data, yTest.Data)
+	}
+}
+
+func Test_TrainTestSplit_Stratified(t *testing.T) {
+	labels := ZeroTensor([]int{20}, false)
+	for i := 15; i < 20; i++ {
+		labels.Data[i] = 1 // <--- 15 of class 0 and 5 of class 1
+	}
+	split := TrainTestIndices(20, 0.2, labels, NewGenerator(3))
+	checkPartition(t, split, 20)
+
+	ones := 0
+	for _, index := range ints(split.Test) {
+		ones += int(labels.Data[index])
+	}
+	if len(split.Test.Data) != 4 || ones != 1 {
+		t.Errorf("expected 3 examples of class 0 and 1 of class 1 in the test set, got %v", split.Test.Data)
+	}
+}
+
+func Test_KFold(t *testing.T) {
+	X := ZeroTensor([]int{7, 1}, false)
+	splits := NewKFold(3, false).Split(X, nil)
+	if len(splits) != 3 {
+		t.Fatalf("expected 3 splits, got %v", len(splits))
+	}
+	expected := [][]int{{0, 1, 2}, {3, 4}, {5, 6}}
+	for k, split := range splits {
+		checkPartition(t, split, 7)
+		if !slices.Equal(ints(split.Test), expected[k]) {
+			t.Errorf("fold %v: expected test %v, got %v", k, expected[k], split.Test.Data)
+		}
+	}
+
+	shuffled := &KFold{K: 3, Shuffle: true, Generator: NewGenerator(5)}
+	seen := []int{}
+	for _, split := range shuffled.Split(X, nil) {
+		checkPartition(t, split, 7)
+		seen = append(seen, ints(split.Test)...)
+	}
+	slices.Sort(seen)
+	if !slices.Equal(seen, []int{0, 1, 2, 3, 4, 5, 6}) {
+		t.Errorf("every example should be tested exactly once, got %v", seen)
+	}
+}
+
+func Test_StratifiedKFold(t *testing.T) {
+	y := ZeroTensor([]int{9}, false)
+	copy(y.Data, []float64{0, 0, 0, 0, 0, 0, 1, 1, 1})
+	for _, split := range NewStratifiedKFold(3, true).Split(y, y) {
+		checkPartition(t, split, 9)
+		ones := 0
+		for _, index := range ints(split.Test) {
+			ones += int(y.Data[index])
+		}
+		if len(split.Test.Data) != 3 || ones != 1 {
+			t.Errorf("each fold should hold 2 of class 0 and 1 of class 1, got %v", split.Test.Data)
+		}
+	}
+}
+
+func Test_TimeSeriesSplit(t *testing.T) {
+	X := ZeroTensor([]int{6}, false)
+	splits := (&TimeSeriesSplit{K: 3, Gap: 1, MaxTrainSize: 2}).Split(X, nil)
+
+	expectedTrain := [][]int{{0, 1}, {1, 2}, {2, 3}}
+	expectedTest := [][]int{{3}, {4}, {5}}
+	for k, split := range splits {
+		if !slices.Equal(ints(split.Train), expectedTrain[k]) || !slices.Equal(ints(split.Test), expectedTest[k]) {
+			t.Errorf("split %v: train %v test %v", k, split.Train.Data, split.Test.Data)
+		}
+	}
+}
+
+func Test_CrossValidate(t *testing.T) {
+	X := RangeTensor([]int{8, 1}, false)
+	y := RangeTensor([]int{8}, false)
+
+	// a model that predicts the mean training label
+	result := CrossValidate(X, y, NewKFold(4, false), func(XTrain, yTrain, XTest, yTest *Tensor) map[string]float64 {
+		mean := yTrain.Mean([]int{}, false, false).Data[0]
+		return map[string]float64{"size": float64(XTest.Shape[0]), "mae": math.Abs(mean - yTest.Mean([]int{}, false, false).Data[0])}
+	})
+
+	if len(result.Scores["mae"]) != 4 || result.Mean["size"] != 2 || result.Std["size"] != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if math.Abs(result.Scores["mae"][0]-4) > 1e-12 {
+		t.Errorf("expected a mean absolute error of 4 on the first fold, got %v", result.Scores["mae"][0])
+	}
+}
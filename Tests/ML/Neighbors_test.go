@@ -0,0 +1,177 @@
+package TG
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func Test_MetricDistances(t *testing.T) {
+	X := matrix([]float64{1, 0}, []float64{2, 2})
+	Y := matrix([]float64{0, 1}, []float64{-1, 0}, []float64{3, 3})
+
+	if D := MetricDistances(X, Y, MetricManhattan, false); !approxEqual(D.Data, []float64{2, 2, 5, 3, 5, 2}, 1e-12) {
+		t.Errorf("unexpected manhattan distances %v", D.Data)
+	}
+	if D := MetricDistances(X, Y, MetricCosine, false); !approxEqual(D.Data, []float64{1, 2, 1 - 1/math.Sqrt(2), 1 - 1/math.Sqrt(2), 1 + 1/math.Sqrt(2), 0}, 1e-12) {
+		t.Errorf("unexpected cosine distances %v", D.Data)
+	}
+	if D := MetricDistances(X, Y, MetricEuclidean, false); math.Abs(D.Data[0]-math.Sqrt(2)) > 1e-12 {
+		t.Errorf("unexpected euclidean distances %v", D.Data)
+	}
+}
+
+func Test_NearestNeighbors_AlgorithmsAgree(t *testing.T) {
+	g := NewGenerator(1)
+	X := g.NormalTensor([]int{300, 4}, 0, 1, false)
+	copy(X.Data[4:8], X.Data[0:4]) // <--- a duplicate sample, tied with the original
+	queries := g.NormalTensor([]int{50, 4}, 0, 1, false)
+	copy(queries.Data[0:4], X.Data[0:4])
+
+	for _, metric := range []DistanceMetric{MetricEuclidean, MetricManhattan, MetricCosine} {
+		brute := &NearestNeighbors{Metric: metric, Algorithm: AlgorithmBrute}
+		brute.Fit(X)
+		expectedD, expectedI := brute.KNeighbors(queries, 5)
+		radius := (expectedD.Data[2] + expectedD.Data[3]) / 2 // <--- between the third and fourth neighbors of the first query
+		radiusD, radiusI := brute.RadiusNeighbors(queries, radius)
+
+		// every brute force neighbor is the right distance away, and they are nearest first
+		full := MetricDistances(queries, X, metric, false)
+		for i := 0; i < 50; i++ {
+			for j := 0; j < 5; j++ {
+				if math.Abs(full.Data[i*300+int(expectedI.Data[i*5+j])]-expectedD.Data[i*5+j]) > 1e-12 || (j > 0 && expectedD.Data[i*5+j] < expectedD.Data[i*5+j-1]) {
+					t.Fatalf("metric %v: bad neighbors for query %v", metric, i)
+				}
+			}
+		}
+		if !slices.Equal(ints(&Tensor{Data: expectedI.Data[:2]}), []int{0, 1}) {
+			t.Errorf("metric %v: expected the tied samples 0 and 1 first, got %v", metric, expectedI.Data[:5])
+		}
+
+		for _, algorithm := range []NeighborsAlgorithm{AlgorithmKDTree, AlgorithmBallTree} {
+			index := &NearestNeighbors{Metric: metric, Algorithm: algorithm, LeafSize: 8}
+			index.Fit(X)
+			D, I := index.KNeighbors(queries, 5)
+			if !approxEqual(D.Data, expectedD.Data, 1e-9) || !slices.Equal(I.Data, expectedI.Data) {
+				t.Errorf("metric %v algorithm %v: k nearest neighbors differ from brute force", metric, algorithm)
+			}
+			rD, rI := index.RadiusNeighbors(queries, radius)
+			for i := range rI {
+				if !slices.Equal(rI[i], radiusI[i]) || !approxEqual(rD[i], radiusD[i], 1e-9) {
+					t.Errorf("metric %v algorithm %v: radius neighbors of query %v differ from brute force", metric, algorithm, i)
+					break
+				}
+			}
+		}
+	}
+}
+
+func Test_NearestNeighbors_FarFromOrigin(t *testing.T) {
+
+	// far from the origin, brute force and the trees still find exactly the same neighbors at the same distances
+	g := NewGenerator(2)
+	X, queries := g.NormalTensor([]int{400, 3}, 0, 1, false), g.NormalTensor([]int{100, 3}, 0, 1, false)
+	for _, A := range []*Tensor{X, queries} {
+		for i := range A.Data {
+			A.Data[i] += 1e7
+		}
+	}
+
+	brute := &NearestNeighbors{Metric: MetricEuclidean, Algorithm: AlgorithmBrute}
+	brute.Fit(X)
+	expectedD, expectedI := brute.KNeighbors(queries, 6)
+	for _, algorithm := range []NeighborsAlgorithm{AlgorithmKDTree, AlgorithmBallTree} {
+		index := &NearestNeighbors{Metric: MetricEuclidean, Algorithm: algorithm, LeafSize: 8}
+		index.Fit(X)
+		D, I := index.KNeighbors(queries, 6)
+		if !slices.Equal(D.Data, expectedD.Data) || !slices.Equal(I.Data, expectedI.Data) {
+			t.Errorf("algorithm %v: k nearest neighbors far from the origin differ from brute force", algorithm)
+		}
+	}
+}
+
+func Test_NearestNeighbors_Radius(t *testing.T) {
+	X := matrix([]float64{0}, []float64{1}, []float64{2}, []float64{5})
+	index := NewNearestNeighbors(MetricEuclidean)
+	index.Fit(X)
+
+	distances, indices := index.RadiusNeighbors(matrix([]float64{1.2}, []float64{10}), 1)
+	if !slices.Equal(indices[0], []int{1, 2}) || !approxEqual(distances[0], []float64{0.2, 0.8}, 1e-12) {
+		t.Errorf("expected neighbors [1 2] at [0.2 0.8], got %v at %v", indices[0], distances[0])
+	}
+	if len(indices[1]) != 0 {
+		t.Errorf("expected no neighbors within 1 of 10, got %v", indices[1])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic when k exceeds the number of samples")
+		}
+	}()
+	index.KNeighbors(X, 5)
+}
+
+func Test_KNNClassifier(t *testing.T) {
+	X, y := blobs(60, blobCenters, 1.5, 2)
+	for i := range y.Data {
+		y.Data[i] = 10 * (y.Data[i] + 1) // <--- labels 10, 20 and 30
+	}
+	XTrain, XTest, yTrain, yTest := TrainTestSplit(X, y, 0.25, true, NewGenerator(3))
+
+	model := NewKNNClassifier(5)
+	model.Fit(XTrain, yTrain)
+	if !approxEqual(model.Classes, []float64{10, 20, 30}, 0) {
+		t.Fatalf("unexpected classes %v", model.Classes)
+	}
+	if accuracy := model.Score(XTest, yTest); accuracy < 0.95 {
+		t.Errorf("expected an accuracy above 0.95, got %v", accuracy)
+	}
+	P := model.PredictProba(XTest)
+	for i := 0; i < XTest.Shape[0]; i++ {
+		if math.Abs(P.Data[3*i]+P.Data[3*i+1]+P.Data[3*i+2]-1) > 1e-12 {
+			t.Fatalf("probabilities of sample %v do not sum to 1", i)
+		}
+	}
+
+	// with distance weights a training sample is its own nearest neighbor, and decides its own label
+	weighted := &KNNClassifier{K: 7, Weights: WeightsDistance, Algorithm: AlgorithmBallTree, LeafSize: 10}
+	weighted.Fit(XTrain, yTrain)
+	if accuracy := weighted.Score(XTrain, yTrain); accuracy != 1 {
+		t.Errorf("expected a perfect training accuracy with distance weights, got %v", accuracy)
+	}
+}
+
+func Test_KNNRegressor(t *testing.T) {
+	X := matrix([]float64{0}, []float64{1}, []float64{2}, []float64{3})
+	y := ZeroTensor([]int{4}, false)
+	copy(y.Data, []float64{0, 10, 20, 30})
+
+	uniform := NewKNNRegressor(2)
+	uniform.Fit(X, y)
+	if predictions := uniform.Predict(matrix([]float64{0.4}, []float64{2.6})); !approxEqual(predictions.Data, []float64{5, 25}, 1e-12) {
+		t.Errorf("expected the means of the two nearest targets [5 25], got %v", predictions.Data)
+	}
+
+	// 1 / distance weights of 1 / 0.25 and 1 / 0.75 interpolate linearly between neighbors
+	weighted := &KNNRegressor{K: 2, Weights: WeightsDistance, Algorithm: AlgorithmKDTree, LeafSize: 1}
+	weighted.Fit(X, y)
+	if predictions := weighted.Predict(matrix([]float64{1.25}, []float64{3})); !approxEqual(predictions.Data, []float64{12.5, 30}, 1e-12) {
+		t.Errorf("expected [12.5 30], got %v", predictions.Data)
+	}
+
+	// several targets at once
+	Y := ZeroTensor([]int{4, 2}, false)
+	for i := 0; i < 4; i++ {
+		Y.Data[2*i], Y.Data[2*i+1] = y.Data[i], -y.Data[i]
+	}
+	uniform.Fit(X, Y)
+	if predictions := uniform.Predict(matrix([]float64{0.4})); !isShape(predictions.Shape, []int{1, 2}) || !approxEqual(predictions.Data, []float64{5, -5}, 1e-12) {
+		t.Errorf("expected [[5 -5]], got %v %v", predictions.Shape, predictions.Data)
+	}
+	// ties are broken by index, so the training samples predict [5 5 15 25]
+	if score := uniform.Score(X, Y); math.Abs(score-0.8) > 1e-12 {
+		t.Errorf("unexpected R2 %v", score)
+	}
+}
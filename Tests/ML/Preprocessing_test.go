@@ -0,0 +1,160 @@
+package TG
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// builds a [rows, columns] Tensor from rows
+func matrix(rows ...[]float64) *Tensor {
+	A := ZeroTensor([]int{len(rows), len(rows[0])}, false)
+	for i, row := range rows {
+		copy(A.Data[i*len(row):], row)
+	}
+	return A
+}
+
+func approxEqual(a, b []float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.IsNaN(a[i]) != math.IsNaN(b[i]) || (!math.IsNaN(a[i]) && math.Abs(a[i]-b[i]) > tol) {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_StandardScaler(t *testing.T) {
+	train := matrix([]float64{1, 5}, []float64{3, 5}, []float64{5, 5})
+	scaler := NewStandardScaler()
+	C := FitTransform(scaler, train)
+
+	std := math.Sqrt(8.0 / 3)
+	if !approxEqual(C.Data, []float64{-2 / std, 0, 0, 0, 2 / std, 0}, 1e-12) {
+		t.Errorf("unexpected standardization %v", C.Data)
+	}
+
+	// the statistics of the training set are applied to new data
+	test := matrix([]float64{3, 6}, []float64{math.NaN(), 4})
+	C = scaler.Transform(test)
+	if !approxEqual(C.Data, []float64{0, 1, math.NaN(), -1}, 1e-12) {
+		t.Errorf("unexpected transform of test data %v", C.Data)
+	}
+	if back := scaler.InverseTransform(C); !approxEqual(back.Data, test.Data, 1e-12) {
+		t.Errorf("inverse transform gave %v", back.Data)
+	}
+}
+
+func Test_MinMaxAndRobustScaler(t *testing.T) {
+	X := matrix([]float64{0}, []float64{5}, []float64{10})
+	C := FitTransform(NewMinMaxScaler(-1, 1), X)
+	if !approxEqual(C.Data, []float64{-1, 0, 1}, 1e-12) {
+		t.Errorf("min max scaling gave %v", C.Data)
+	}
+
+	X = matrix([]float64{1}, []float64{2}, []float64{3}, []float64{4}, []float64{100})
+	robust := NewRobustScaler()
+	C = FitTransform(robust, X)
+	if robust.Center[0] != 3 || robust.Scale[0] != 2 || !approxEqual(C.Data, []float64{-1, -0.5, 0, 0.5, 48.5}, 1e-12) {
+		t.Errorf("robust scaling gave center %v scale %v data %v", robust.Center, robust.Scale, C.Data)
+	}
+}
+
+func Test_SimpleImputer(t *testing.T) {
+	nan := math.NaN()
+	X := matrix([]float64{1, 7}, []float64{nan, 7}, []float64{3, nan}, []float64{8, 2})
+
+	C := FitTransform(NewSimpleImputer(ImputeMean), X)
+	if !approxEqual(C.Data, []float64{1, 7, 4, 7, 3, 16.0 / 3, 8, 2}, 1e-12) {
+		t.Errorf("mean imputation gave %v", C.Data)
+	}
+	C = FitTransform(NewSimpleImputer(ImputeMostFrequent), X)
+	if C.Data[2] != 1 || C.Data[5] != 7 {
+		t.Errorf("most frequent imputation gave %v", C.Data)
+	}
+	constant := &SimpleImputer{Strategy: ImputeConstant, FillValue: -1}
+	if C = FitTransform(constant, X); C.Data[2] != -1 || C.Data[5] != -1 {
+		t.Errorf("constant imputation gave %v", C.Data)
+	}
+}
+
+func Test_OneHotEncoder(t *testing.T) {
+	X := matrix([]float64{2, 0.5}, []float64{1, 1.5}, []float64{2, 2.5})
+	encoder := NewOneHotEncoder(0)
+	C := FitTransform(encoder, X)
+	if C.Shape[1] != 3 || !approxEqual(C.Data, []float64{0, 1, 0.5, 1, 0, 1.5, 0, 1, 2.5}, 0) {
+		t.Errorf("one hot encoding gave %v %v", C.Shape, C.Data)
+	}
+	if back := encoder.InverseTransform(C); !approxEqual(back.Data, X.Data, 0) {
+		t.Errorf("inverse transform gave %v", back.Data)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic on an unknown category")
+		}
+	}()
+	encoder.Transform(matrix([]float64{3, 0}))
+}
+
+func Test_LabelEncoder(t *testing.T) {
+	y := RangeTensor([]int{4}, false)
+	copy(y.Data, []float64{10, -2, 10, 7})
+	encoder := &LabelEncoder{}
+	codes := FitTransform(encoder, y)
+	if !approxEqual(codes.Data, []float64{2, 0, 2, 1}, 0) {
+		t.Errorf("label encoding gave %v", codes.Data)
+	}
+	if back := encoder.InverseTransform(codes); !approxEqual(back.Data, y.Data, 0) {
+		t.Errorf("inverse transform gave %v", back.Data)
+	}
+}
+
+func Test_PolynomialFeatures(t *testing.T) {
+	X := matrix([]float64{2, 3})
+	C := FitTransform(NewPolynomialFeatures(2), X)
+	if !approxEqual(C.Data, []float64{1, 2, 3, 4, 6, 9}, 0) {
+		t.Errorf("degree 2 features gave %v", C.Data)
+	}
+
+	interactions := &PolynomialFeatures{Degree: 3, InteractionOnly: true}
+	C = FitTransform(interactions, matrix([]float64{2, 3, 5}))
+	if !approxEqual(C.Data, []float64{2, 3, 5, 6, 10, 15, 30}, 0) {
+		t.Errorf("interaction features gave %v", C.Data)
+	}
+}
+
+func Test_Pipeline_Serialization(t *testing.T) {
+	nan := math.NaN()
+	train := matrix([]float64{1, 0}, []float64{nan, 1}, []float64{3, 0}, []float64{5, 1})
+	pipeline := NewPipeline(NewSimpleImputer(ImputeMedian), NewOneHotEncoder(1), NewStandardScaler())
+	expected := FitTransform(pipeline, train)
+
+	var buffer bytes.Buffer
+	if err := WritePipeline(&buffer, pipeline); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadPipeline(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Steps) != 3 {
+		t.Fatalf("loaded %v steps", len(loaded.Steps))
+	}
+	if got := loaded.Transform(train); !approxEqual(got.Data, expected.Data, 1e-12) {
+		t.Errorf("loaded pipeline gave %v, expected %v", got.Data, expected.Data)
+	}
+
+	// the imputer cannot be inverted
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic when inverting an imputer")
+		}
+	}()
+	loaded.InverseTransform(expected)
+}
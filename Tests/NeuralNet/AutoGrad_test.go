@@ -101,12 +101,12 @@ func Test_Gradify_With_Sliced_Data(t *testing.T) {
 	// Load Iris dataset
 	var Iris *Tensor = LoadCSV("iris_dataset.csv", true)
 
-	if Iris.Shape[0] != 151 || Iris.Shape[1] != 5 || Product(Iris.Shape) != len(Iris.Data) {
+	if Iris.Shape[0] != 150 || Iris.Shape[1] != 5 || Product(Iris.Shape) != len(Iris.Data) {
 		t.Errorf("LoadCSV() failed. Expected Output: [150, 5] --- Actual Output: %v", Iris.Shape)
 	}
 
 	// Seperate the targets from the features by slicing the Tensor
-	features, targets := Iris.Slice("1:, :4"), Iris.Slice("1:, 4:")
+	features, targets := Iris.Slice(":, :4"), Iris.Slice(":, 4:")
 
 	// Check that the shapes and num elements are correct
 	if features.Shape[0] != 150 || features.Shape[1] != 4 || Product(features.Shape) != len(features.Data) {
@@ -48,3 +48,35 @@ func Test_MLP_Init(t *testing.T) {
 	}
 
 }
+
+/*
+* @notice This test checks that LinearWithGenerator() and MLPWithGenerator() draw their weights and biases from the
+* Generator they are given, so that two models built from Generators with the same seed are identical.
+ */
+func Test_Linear_Generator(t *testing.T) {
+
+	A := LinearWithGenerator(4, 3, "relu", nil, NewGenerator(5))
+	B := LinearWithGenerator(4, 3, "relu", nil, NewGenerator(5))
+	if !sameValues(A.Weights, B.Weights) || !sameValues(A.Biases, B.Biases) {
+		t.Errorf("LinearWithGenerator() built different layers from the same seed")
+	}
+
+	first := MLPWithGenerator(3, []int{4, 2}, []string{"relu", "relu"}, NewGenerator(6))
+	second := MLPWithGenerator(3, []int{4, 2}, []string{"relu", "relu"}, NewGenerator(6))
+	for first != nil && second != nil {
+		if !sameValues(first.Weights, second.Weights) || !sameValues(first.Biases, second.Biases) {
+			t.Errorf("MLPWithGenerator() built different models from the same seed")
+		}
+		first, second = first.Next, second.Next
+	}
+}
+
+// reports whether two gradient tracked Tensors hold the same values
+func sameValues(A *Tensor, B *Tensor) bool {
+	for i := range A.DataReqGrad {
+		if A.DataReqGrad[i].Scalar != B.DataReqGrad[i].Scalar {
+			return false
+		}
+	}
+	return len(A.DataReqGrad) == len(B.DataReqGrad)
+}
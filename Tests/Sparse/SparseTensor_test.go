@@ -0,0 +1,144 @@
+package TG
+
+// SparseTensor_test.go contains tests for SparseTensor.go and SparseOps.go
+
+import (
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// returns a 4x5 dense matrix that is mostly zeros
+func sparseDense() *Tensor {
+	A := ZeroTensor([]int{4, 5}, false)
+	A.Data[1] = 3  // [0, 1]
+	A.Data[7] = -2 // [1, 2]
+	A.Data[10] = 5 // [2, 0]
+	A.Data[14] = 1 // [2, 4]
+	A.Data[18] = 7 // [3, 3]
+	return A
+}
+
+// checks that two tensors have the same shape and data
+func sameTensor(A *Tensor, B *Tensor) bool {
+	if !Same_Shape(A, B) {
+		return false
+	}
+	for i := range A.Data {
+		if A.Data[i] != B.Data[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_SparseConversions(t *testing.T) {
+
+	A := sparseDense()
+
+	if A.ToCOO().NNZ() != 5 || A.ToCSR().NNZ() != 5 || A.ToCSC().NNZ() != 5 {
+		t.Errorf("ToCOO() failed. Expected 5 nonzeros --- Actual Output: %v", A.ToCOO().NNZ())
+	}
+
+	// every round trip should reproduce the dense matrix
+	roundTrips := map[string]*Tensor{
+		"COO":         A.ToCOO().ToDense(),
+		"CSR":         A.ToCSR().ToDense(),
+		"CSC":         A.ToCSC().ToDense(),
+		"CSR->CSC":    A.ToCSR().ToCSC().ToDense(),
+		"CSC->CSR":    A.ToCSC().ToCSR().ToDense(),
+		"CSR->COO":    A.ToCSR().ToCOO().ToDense(),
+		"CSC->COO":    A.ToCSC().ToCOO().ToDense(),
+		"COO->CSC->D": A.ToCOO().ToCSC().ToDense(),
+	}
+	for name, B := range roundTrips {
+		if !sameTensor(A, B) {
+			t.Errorf("%v round trip failed. Expected Output: %v --- Actual Output: %v", name, A.Data, B.Data)
+		}
+	}
+
+	// duplicates are summed and unsorted triplets are ordered
+	S := NewCOOMatrix([]int{2, 2}, []int{1, 0, 1}, []int{1, 0, 1}, []float64{1, 2, 3}).ToCSR()
+	if S.NNZ() != 2 || S.Values[0] != 2 || S.Values[1] != 4 {
+		t.Errorf("ToCSR() failed to sum duplicates. Actual Output: %v", S.Values)
+	}
+}
+
+func Test_SpMV_SpMM(t *testing.T) {
+
+	A := sparseDense()
+	x := RangeTensor([]int{5}, false)
+	expected := MatMul(A, x, false)
+
+	for _, op := range []LinearOperator{A.ToCSR(), A.ToCSC(), A.ToCOO()} {
+		Sx := op.Apply(x)
+		for i := range Sx.Data {
+			if Sx.Data[i] != expected.Data[i] {
+				t.Errorf("Apply() failed. Expected Output: %v --- Actual Output: %v", expected.Data, Sx.Data)
+				break
+			}
+		}
+	}
+
+	B := RangeTensor([]int{5, 3}, false)
+	if !sameTensor(SpMM(A.ToCSR(), B), MatMul(A, B, false)) {
+		t.Errorf("SpMM() failed")
+	}
+}
+
+func Test_SparseElementwise(t *testing.T) {
+
+	A := sparseDense()
+	B := sparseDense().Scalar_Mult(-1, false)
+	B.Data[0] = 4
+
+	if !sameTensor(SparseAdd(A.ToCSR(), B.ToCSR()).ToDense(), Add(A, B, false)) {
+		t.Errorf("SparseAdd() failed")
+	}
+	if !sameTensor(SparseSubtract(A.ToCSR(), B.ToCSR()).ToDense(), Subtract(A, B, false)) {
+		t.Errorf("SparseSubtract() failed")
+	}
+	if !sameTensor(SparseMultiply(A.ToCSR(), B.ToCSR()).ToDense(), Multiply(A, B, false)) {
+		t.Errorf("SparseMultiply() failed")
+	}
+
+	// A + (-A) should not store any explicit zeros
+	if SparseAdd(A.ToCSR(), A.ToCSR().Scalar_Mult(-1)).NNZ() != 0 {
+		t.Errorf("SparseAdd() failed to drop zeros")
+	}
+}
+
+func Test_SparseTranspose_SliceRows(t *testing.T) {
+
+	A := sparseDense()
+
+	if !sameTensor(A.ToCSR().Transpose().ToDense(), A.Permute([]int{1, 0})) {
+		t.Errorf("Transpose() failed")
+	}
+
+	sliced := A.ToCSR().SliceRows(1, 3)
+	if !sameTensor(sliced.ToDense(), A.Slice("1:3, :")) {
+		t.Errorf("SliceRows() failed. Expected Output: %v --- Actual Output: %v", A.Slice("1:3, :").Data, sliced.ToDense().Data)
+	}
+}
+
+func Test_SparseSolve(t *testing.T) {
+
+	// sparse matrices can be passed directly to the iterative solvers
+	n := 50
+	rows, cols, values := []int{}, []int{}, []float64{}
+	for i := 0; i < n; i++ {
+		rows, cols, values = append(rows, i), append(cols, i), append(values, 2)
+		if i > 0 {
+			rows, cols, values = append(rows, i), append(cols, i-1), append(values, -1)
+			rows, cols, values = append(rows, i-1), append(cols, i), append(values, -1)
+		}
+	}
+	S := NewCOOMatrix([]int{n, n}, rows, cols, values).ToCSR()
+	b := OnesTensor([]int{n}, false)
+
+	result := ConjugateGradient(S, b, SolverOptions{})
+	if !result.Converged {
+		t.Errorf("ConjugateGradient() on CSRMatrix did not converge. Residuals: %v", result.Residuals)
+	}
+}
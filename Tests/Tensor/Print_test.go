@@ -0,0 +1,84 @@
+package TG
+
+// Print_test.go contains tests for Print.go
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// restores the default print options once a test completes
+func withPrintOptions(t *testing.T, options PrintOptions) {
+	previous := GetPrintOptions()
+	SetPrintOptions(options)
+	t.Cleanup(func() { SetPrintOptions(previous) })
+}
+
+func checkPrint(t *testing.T, name string, expected string, actual string) {
+	if expected != actual {
+		t.Errorf("%v failed. Expected:\n%v\n--- Actual:\n%v", name, expected, actual)
+	}
+}
+
+func Test_String(t *testing.T) {
+
+	A := RangeTensor([]int{2, 3}, false)
+	checkPrint(t, "Matrix", "Tensor([[0., 1., 2.],\n        [3., 4., 5.]], shape=[2 3])", A.String())
+
+	B := RangeTensor([]int{2, 2, 2}, false)
+	B.Data[1] = 0.5
+	B.Batched = true
+	expected := "Tensor([[[0.0, 0.5],\n         [2.0, 3.0]],\n\n        [[4.0, 5.0],\n         [6.0, 7.0]]], shape=[2 2 2], batched=true)"
+	checkPrint(t, "3D", expected, B.String())
+
+	C := &Tensor{Shape: []int{3}, Data: []float64{math.NaN(), math.Inf(-1), 1.25}}
+	checkPrint(t, "NaN and Inf", "Tensor([ nan, -inf, 1.25], shape=[3])", C.String())
+
+	D := &Tensor{Shape: []int{3}, Data: []float64{1e-7, 1, 2}}
+	checkPrint(t, "Scientific", "Tensor([1e-07, 1e+00, 2e+00], shape=[3])", D.String())
+
+	checkPrint(t, "Scalar", "Tensor(3.25, shape=[])", (&Tensor{Shape: []int{}, Data: []float64{3.25}}).String())
+	checkPrint(t, "Empty", "Tensor([], shape=[0 3])", (&Tensor{Shape: []int{0, 3}, Data: []float64{}}).String())
+	checkPrint(t, "Zero value", "Tensor(uninitialized, shape=[])", fmt.Sprint(&Tensor{}))
+	checkPrint(t, "Unfilled", "Tensor(uninitialized, shape=[2 3])", (&Tensor{Shape: []int{2, 3}, Data: []float64{1}}).String())
+}
+
+func Test_String_Summarized(t *testing.T) {
+
+	options := DefaultPrintOptions()
+	options.Threshold = 10
+	options.EdgeItems = 2
+	withPrintOptions(t, options)
+
+	A := RangeTensor([]int{5, 5}, false)
+	expected := "Tensor([[ 0.,  1., ...,  3.,  4.],\n        [ 5.,  6., ...,  8.,  9.],\n        ...,\n        [15., 16., ..., 18., 19.],\n        [20., 21., ..., 23., 24.]], shape=[5 5])"
+	checkPrint(t, "Summarized", expected, A.String())
+}
+
+func Test_String_LineWidth(t *testing.T) {
+
+	options := DefaultPrintOptions()
+	options.LineWidth = 20
+	withPrintOptions(t, options)
+
+	A := RangeTensor([]int{6}, false)
+	checkPrint(t, "Wrapped", "Tensor([0., 1., 2.,\n        3., 4., 5.], shape=[6])", A.String())
+}
+
+func Test_Format(t *testing.T) {
+
+	A := &Tensor{Shape: []int{2}, Data: []float64{1.23456, 2}}
+
+	checkPrint(t, "%v", A.String(), fmt.Sprintf("%v", A))
+	checkPrint(t, "%.2v", "Tensor([1.23, 2.00], shape=[2])", fmt.Sprintf("%.2v", A))
+	checkPrint(t, "%.1f", "Tensor([1.2, 2.0], shape=[2])", fmt.Sprintf("%.1f", A))
+	checkPrint(t, "%.1e", "Tensor([1.2e+00, 2.0e+00], shape=[2])", fmt.Sprintf("%.1e", A))
+
+	if !strings.HasPrefix(fmt.Sprintf("%d", A), "%!d") {
+		t.Errorf("Unsupported verb failed. Expected a bad verb marker --- Actual: %v", fmt.Sprintf("%d", A))
+	}
+}
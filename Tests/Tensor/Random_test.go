@@ -0,0 +1,152 @@
+package TG
+
+// Random_test.go contains tests for Random.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// checks that the mean and variance of samples are close to those expected
+func checkMoments(t *testing.T, name string, A *Tensor, mean float64, variance float64, tol float64) {
+	n := float64(len(A.Data))
+	sampleMean := A.Sum_All() / n
+
+	var sampleVar float64
+	for _, value := range A.Data {
+		sampleVar += (value - sampleMean) * (value - sampleMean)
+	}
+	sampleVar /= n
+
+	if math.Abs(sampleMean-mean) > tol*math.Max(1, math.Abs(mean)) || math.Abs(sampleVar-variance) > tol*math.Max(1, variance) {
+		t.Errorf("%v failed. Expected mean %v and variance %v --- Actual: %v and %v", name, mean, variance, sampleMean, sampleVar)
+	}
+}
+
+func Test_Generator_Reproducible(t *testing.T) {
+
+	A := NewGenerator(42).NormalTensor([]int{4, 5}, 0, 1, true)
+	B := NewGenerator(42).NormalTensor([]int{4, 5}, 0, 1, true)
+	C := NewGenerator(43).NormalTensor([]int{4, 5}, 0, 1, true)
+
+	for i := range A.Data {
+		if A.Data[i] != B.Data[i] {
+			t.Errorf("NormalTensor() failed. Generators with the same seed produced different samples")
+			break
+		}
+	}
+	if A.Data[0] == C.Data[0] && A.Data[1] == C.Data[1] {
+		t.Errorf("NormalTensor() failed. Generators with different seeds produced the same samples")
+	}
+
+	// the default generator is reproducible after SetSeed()
+	SetSeed(7)
+	D := RandFloat64Tensor([]int{10}, -1, 1, false)
+	SetSeed(7)
+	E := RandFloat64Tensor([]int{10}, -1, 1, false)
+	for i := range D.Data {
+		if D.Data[i] != E.Data[i] || D.Data[i] < -1 || D.Data[i] >= 1 {
+			t.Errorf("RandFloat64Tensor() failed. Expected reproducible samples in [-1, 1) --- Actual: %v, %v", D.Data[i], E.Data[i])
+		}
+	}
+}
+
+func Test_Distributions(t *testing.T) {
+
+	g := NewGenerator(1)
+	shape := []int{20000}
+
+	checkMoments(t, "NormalTensor()", g.NormalTensor(shape, 2, 3, false), 2, 9, 0.05)
+	checkMoments(t, "UniformTensor()", g.UniformTensor(shape, 0, 1, false), 0.5, 1.0/12, 0.02)
+	checkMoments(t, "BernoulliTensor()", g.BernoulliTensor(shape, 0.3, false), 0.3, 0.21, 0.02)
+	checkMoments(t, "ExponentialTensor()", g.ExponentialTensor(shape, 2, false), 0.5, 0.25, 0.05)
+	checkMoments(t, "PoissonTensor() small", g.PoissonTensor(shape, 3, false), 3, 3, 0.05)
+	checkMoments(t, "PoissonTensor() large", g.PoissonTensor(shape, 50, false), 50, 50, 0.05)
+	checkMoments(t, "GammaTensor()", g.GammaTensor(shape, 2, 3, false), 6, 18, 0.05)
+	checkMoments(t, "GammaTensor() shape < 1", g.GammaTensor(shape, 0.5, 1, false), 0.5, 0.5, 0.05)
+	checkMoments(t, "BetaTensor()", g.BetaTensor(shape, 2, 5, false), 2.0/7, 10.0/(49*8), 0.02)
+
+	truncated := g.TruncatedNormalTensor(shape, 0, 1, -0.5, 2, false)
+	for _, value := range truncated.Data {
+		if value < -0.5 || value > 2 {
+			t.Errorf("TruncatedNormalTensor() failed. Sample %v is outside [-0.5, 2]", value)
+			break
+		}
+	}
+
+	// far in either tail, where rejection would almost never accept, samples stay within the bounds with a mean near
+	// lower + 1 / lower (the mean of the tail beyond lower)
+	for _, bounds := range [][2]float64{{8, 9}, {-31, -30}} {
+		tail := g.TruncatedNormalTensor(shape, 0, 1, bounds[0], bounds[1], false)
+		edge := bounds[0]
+		if edge < 0 {
+			edge = bounds[1]
+		}
+		mean := 0.0
+		for _, value := range tail.Data {
+			if value < bounds[0] || value > bounds[1] {
+				t.Fatalf("TruncatedNormalTensor() failed. Sample %v is outside %v", value, bounds)
+			}
+			mean += value / float64(len(tail.Data))
+		}
+		if math.Abs(mean-(edge+1/edge)) > 0.01 {
+			t.Errorf("TruncatedNormalTensor() failed. Expected a mean near %v within %v --- Actual: %v", edge+1/edge, bounds, mean)
+		}
+	}
+
+	// each row of a multinomial sums to the number of trials, and class proportions follow the weights
+	probs := ZeroTensor([]int{2, 3}, false)
+	copy(probs.Data, []float64{1, 1, 2, 0, 1, 0})
+	counts := g.MultinomialTensor(4000, probs)
+	if counts.Data[3] != 0 || counts.Data[4] != 4000 || math.Abs(counts.Data[2]-2000) > 150 {
+		t.Errorf("MultinomialTensor() failed. Unexpected counts: %v", counts.Data)
+	}
+
+	samples := g.CategoricalTensor(probs, 5)
+	if len(samples.Shape) != 2 || samples.Shape[1] != 5 || samples.Data[5] != 1 {
+		t.Errorf("CategoricalTensor() failed. Unexpected samples: %v", samples.Data)
+	}
+}
+
+func Test_Permutation_Choice_Dropout(t *testing.T) {
+
+	g := NewGenerator(3)
+
+	seen := make(map[int]bool)
+	for _, i := range g.Choice(10, 10, false) {
+		seen[i] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("Choice() failed. Expected 10 distinct elements without replacement --- Actual: %v", len(seen))
+	}
+
+	// shuffling keeps each row intact
+	A := RangeTensor([]int{5, 2}, false)
+	shuffled := A.Shuffle(g)
+	for r := 0; r < 5; r++ {
+		if shuffled.Data[2*r+1] != shuffled.Data[2*r]+1 || int(shuffled.Data[2*r])%2 != 0 {
+			t.Errorf("Shuffle() failed. Rows were not kept intact: %v", shuffled.Data)
+			break
+		}
+	}
+	if shuffled.Sum_All() != A.Sum_All() {
+		t.Errorf("Shuffle() failed. Expected the same elements --- Actual: %v", shuffled.Data)
+	}
+
+	// dropout zeroes roughly p of the elements and scales the rest
+	dropped := OnesTensor([]int{10000}, false).Dropout(0.25, g)
+	zeros := 0
+	for _, value := range dropped.Data {
+		if value == 0 {
+			zeros++
+		} else if math.Abs(value-4.0/3) > 1e-12 {
+			t.Errorf("Dropout() failed. Expected kept elements to be scaled to 4/3 --- Actual: %v", value)
+			break
+		}
+	}
+	if math.Abs(float64(zeros)/10000-0.25) > 0.02 {
+		t.Errorf("Dropout() failed. Expected about 25%% of elements dropped --- Actual: %v", zeros)
+	}
+}
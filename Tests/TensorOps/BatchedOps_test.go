@@ -0,0 +1,134 @@
+package TG
+
+// BatchedOps_test.go contains tests for BatchedOps.go and WorkerPool.go
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// cancellingOp cancels its context the first time it is executed
+type cancellingOp struct{ cancel context.CancelFunc }
+
+func (op cancellingOp) Execute(tensors ...*Tensor) *Tensor {
+	op.cancel()
+	return tensors[0]
+}
+
+func Test_SetParallelism(t *testing.T) {
+
+	defer SetParallelism(0)
+
+	SetParallelism(3)
+	if Parallelism() != 3 {
+		t.Errorf("SetParallelism() failed. Expected Output: 3 --- Actual Output: %v", Parallelism())
+	}
+
+	SetParallelism(0)
+	if Parallelism() != runtime.GOMAXPROCS(0) {
+		t.Errorf("SetParallelism() failed. Expected Output: %v --- Actual Output: %v", runtime.GOMAXPROCS(0), Parallelism())
+	}
+}
+
+func Test_BatchedOperation_Order(t *testing.T) {
+
+	defer SetParallelism(0)
+
+	// each batch element must land in its own slot regardless of the parallelism level
+	for _, parallelism := range []int{1, 2, 8} {
+		SetParallelism(parallelism)
+
+		A := RangeTensor([]int{64, 3, 2}, true)
+		A.Data = RangeTensor([]int{64 * 3 * 2}, false).Data // <--- distinct values per batch element
+		B := OnesTensor([]int{64, 3, 2}, true)
+		C := Add(A, B, true)
+
+		if C.Shape[0] != 64 || C.Shape[1] != 3 || C.Shape[2] != 2 {
+			t.Errorf("BatchedOperation() failed. Expected Shape: [64 3 2] --- Actual Output: %v", C.Shape)
+		}
+		for i := range C.Data {
+			if C.Data[i] != float64(i+1) {
+				t.Errorf("BatchedOperation() failed with parallelism %v. Expected Output: %v --- Actual Output: %v", parallelism, i+1, C.Data[i])
+				break
+			}
+		}
+	}
+}
+
+func Test_BatchedOperationContext(t *testing.T) {
+
+	defer SetParallelism(0)
+	SetParallelism(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	A := RangeTensor([]int{10, 3}, true)
+
+	result, err := BatchedOperationContext(ctx, cancellingOp{cancel: cancel}, A)
+	if err == nil || result != nil {
+		t.Errorf("BatchedOperationContext() failed. Expected a cancellation error --- Actual Output: %v", err)
+	}
+
+	// an already cancelled context does no work
+	if _, err := BatchedOperationContext(ctx, EWAddition{}, A, A); err != context.Canceled {
+		t.Errorf("BatchedOperationContext() failed. Expected context.Canceled --- Actual Output: %v", err)
+	}
+}
+
+func Test_BatchedOperation_Panic(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("BatchedOperation() failed to propagate a panic from a batch element")
+		}
+	}()
+
+	// mismatched element shapes panic inside a worker, which must surface on the caller
+	A := RangeTensor([]int{8, 2, 2}, true)
+	B := RangeTensor([]int{8, 2, 3}, true)
+	Add(A, B, true)
+}
+
+func Test_Broadcast_Preallocated(t *testing.T) {
+
+	A := OnesTensor([]int{3, 4}, false)
+	B := RangeTensor([]int{5, 3, 4}, false)
+
+	C := A.Broadcast_Add(B)
+	if C.Shape[0] != 5 || C.Shape[1] != 3 || C.Shape[2] != 4 {
+		t.Errorf("Broadcast_Add() failed. Expected Shape: [5 3 4] --- Actual Output: %v", C.Shape)
+	}
+	if C.Sum_All() != B.Sum_All()+60 {
+		t.Errorf("Broadcast_Add() failed. Expected Output: %v --- Actual Output: %v", B.Sum_All()+60, C.Sum_All())
+	}
+}
+
+// doublingGradOp doubles each element, returning both the values and gradient tracked Values
+type doublingGradOp struct{}
+
+func (op doublingGradOp) Execute(tensors ...*Tensor) *Tensor {
+	A := tensors[0]
+	out := &Tensor{Shape: append([]int{}, A.Shape...), Data: make([]float64, len(A.Data)), DataReqGrad: make([]*Value, len(A.Data)), RequireGrad: true}
+	for i, value := range A.Data {
+		out.Data[i] = 2 * value
+		out.DataReqGrad[i] = NewValue(2*value, nil, "")
+	}
+	return out
+}
+
+func Test_BatchedOperation_KeepsGradients(t *testing.T) {
+
+	A := RangeTensor([]int{4, 2, 3}, true)
+	C := BatchedOperation(doublingGradOp{}, A)
+
+	if !C.RequireGrad || len(C.DataReqGrad) != len(C.Data) {
+		t.Fatalf("BatchedOperation() failed. Expected %v gradient tracked Values --- Actual Output: %v", len(C.Data), len(C.DataReqGrad))
+	}
+	for i := range C.Data {
+		if C.DataReqGrad[i] == nil || C.DataReqGrad[i].Scalar != C.Data[i] || C.Data[i] != 2*A.Data[i] {
+			t.Fatalf("BatchedOperation() failed. Element %v lost its gradient tracked Value", i)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package TG
+
+// Reductions_test.go contains tests for Reductions.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func Test_Cumulative(t *testing.T) {
+
+	// 1 2 3
+	// 4 0 6
+	A := tensorOf([]int{2, 3}, 1, 2, 3, 4, 0, 6)
+
+	checkSlice(t, "Cumsum(1)", A.Cumsum(1, false), []int{2, 3}, []float64{1, 3, 6, 4, 4, 10})
+	checkSlice(t, "Cumsum(0)", A.Cumsum(0, false), []int{2, 3}, []float64{1, 2, 3, 5, 2, 9})
+	checkSlice(t, "Cumprod(1)", A.Cumprod(1, false), []int{2, 3}, []float64{1, 2, 6, 4, 0, 0})
+	checkSlice(t, "Cummax(1)", A.Cummax(1, false), []int{2, 3}, []float64{1, 2, 3, 4, 4, 6})
+	checkSlice(t, "Diff(1)", A.Diff(1, false), []int{2, 2}, []float64{1, 1, -4, 6})
+	checkSlice(t, "Batched Cumsum()", A.Cumsum(0, true), []int{2, 3}, []float64{1, 3, 6, 4, 4, 10})
+}
+
+func Test_Reductions(t *testing.T) {
+
+	A := RangeTensor([]int{2, 3, 4}, false)
+
+	checkSlice(t, "Sum(0, 2)", A.Sum([]int{0, 2}, false, false), []int{3}, []float64{60, 92, 124})
+	checkSlice(t, "Sum(1) keepdims", A.Sum([]int{1}, true, false), []int{2, 1, 4}, []float64{12, 15, 18, 21, 48, 51, 54, 57})
+	checkSlice(t, "Sum() all", A.Sum(nil, false, false), []int{1}, []float64{276})
+	checkSlice(t, "Max(0, 1)", A.Max([]int{0, 1}, false, false), []int{4}, []float64{20, 21, 22, 23})
+	checkSlice(t, "Min(2) keepdims", A.Min([]int{2}, true, false), []int{2, 3, 1}, []float64{0, 4, 8, 12, 16, 20})
+
+	B := tensorOf([]int{2, 2}, 3, -4, 1, 2)
+	checkSlice(t, "Prod(1)", B.Prod([]int{1}, false, false), []int{2}, []float64{-12, 2})
+	checkSlice(t, "PNorm(2)", B.PNorm(2, []int{1}, false, false), []int{2}, []float64{5, math.Sqrt(5)})
+	checkSlice(t, "PNorm(1)", B.PNorm(1, nil, false, false), []int{1}, []float64{10})
+	checkSlice(t, "PNorm(Inf)", B.PNorm(math.Inf(1), []int{0}, false, false), []int{2}, []float64{3, 4})
+	checkSlice(t, "Var(0)", B.Var([]int{0}, false, false), []int{2}, []float64{1, 9})
+	checkSlice(t, "Batched Sum()", B.Sum(nil, false, true), []int{2, 1}, []float64{-1, 3})
+
+	// LogSumExp() should not overflow
+	C := tensorOf([]int{2}, 1000, 1000)
+	if lse := C.LogSumExp(nil, false, false).Data[0]; math.Abs(lse-(1000+math.Log(2))) > 1e-9 {
+		t.Errorf("LogSumExp() failed. Expected Output: %v --- Actual Output: %v", 1000+math.Log(2), lse)
+	}
+
+	// NaN aware reductions
+	D := tensorOf([]int{2, 2}, 1, math.NaN(), math.NaN(), math.NaN())
+	checkSlice(t, "NanSum(1)", D.NanSum([]int{1}, false, false), []int{2}, []float64{1, 0})
+	if mean := D.NanMean([]int{1}, false, false); mean.Data[0] != 1 || !math.IsNaN(mean.Data[1]) {
+		t.Errorf("NanMean() failed. Expected Output: [1 NaN] --- Actual Output: %v", mean.Data)
+	}
+}
+
+func Test_Rolling(t *testing.T) {
+
+	A := tensorOf([]int{2, 5}, 1, 2, 3, 4, 5, 2, 2, 2, 2, 8)
+
+	checkSlice(t, "RollingSum()", A.RollingSum(3, 1, false), []int{2, 3}, []float64{6, 9, 12, 6, 6, 12})
+	checkSlice(t, "RollingMean()", A.RollingMean(2, 1, false), []int{2, 4}, []float64{1.5, 2.5, 3.5, 4.5, 2, 2, 2, 5})
+	checkSlice(t, "RollingStd()", A.RollingStd(2, 1, false), []int{2, 4}, []float64{0.5, 0.5, 0.5, 0.5, 0, 0, 0, 3})
+	checkSlice(t, "RollingMean(0)", A.RollingMean(2, 0, false), []int{1, 5}, []float64{1.5, 2, 2.5, 3, 6.5})
+
+	// a NaN or Inf only affects the windows that contain it
+	nan := tensorOf([]int{6}, 1, math.NaN(), 2, 3, math.Inf(1), 4).RollingSum(2, 0, false)
+	if !math.IsNaN(nan.Data[0]) || !math.IsNaN(nan.Data[1]) || nan.Data[2] != 5 || !math.IsInf(nan.Data[3], 1) || !math.IsInf(nan.Data[4], 1) {
+		t.Errorf("expected [NaN NaN 5 +Inf +Inf], got %v", nan.Data)
+	}
+	inf := tensorOf([]int{5}, math.Inf(1), math.Inf(-1), 1, 2, 3).RollingSum(2, 0, false)
+	if !math.IsNaN(inf.Data[0]) || !math.IsInf(inf.Data[1], -1) || inf.Data[2] != 3 || inf.Data[3] != 5 {
+		t.Errorf("expected [NaN -Inf 3 5], got %v", inf.Data)
+	}
+
+	// large values leaving the window do not cancel away the small ones
+	checkSlice(t, "RollingSum() cancellation", tensorOf([]int{5}, 1e16, 1, -1e16, 1, 1).RollingSum(2, 0, false), []int{4}, []float64{1e16, -1e16 + 1, -1e16 + 1, 2})
+}
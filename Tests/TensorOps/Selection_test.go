@@ -0,0 +1,112 @@
+package TG
+
+// Selection_test.go contains tests for Selection.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// builds a Tensor from a shape and data
+func tensorOf(shape []int, data ...float64) *Tensor {
+	A := ZeroTensor(shape, false)
+	copy(A.Data, data)
+	return A
+}
+
+func Test_Comparisons(t *testing.T) {
+
+	A := tensorOf([]int{4}, 1, 2, 3, math.NaN())
+	B := tensorOf([]int{4}, 2, 2, 2, math.NaN())
+
+	checkSlice(t, "Greater()", Greater(A, B, false), []int{4}, []float64{0, 0, 1, 0})
+	checkSlice(t, "LessEqual()", LessEqual(A, B, false), []int{4}, []float64{1, 1, 0, 0})
+	checkSlice(t, "NotEqual()", NotEqual(A, B, false), []int{4}, []float64{1, 0, 1, 1})
+	checkSlice(t, "IsNaN()", IsNaN(A), []int{4}, []float64{0, 0, 0, 1})
+	checkSlice(t, "IsClose()", IsClose(A, B, 0, 1.5, false), []int{4}, []float64{1, 1, 1, 0})
+	checkSlice(t, "LogicalOr()", LogicalOr(Less(A, B, false), IsNaN(A), false), []int{4}, []float64{1, 0, 0, 1})
+
+	// batched comparisons
+	C := RangeTensor([]int{2, 3}, true)
+	checkSlice(t, "Batched Equal()", Equal(C, OnesTensor([]int{2, 3}, true), true), []int{2, 3}, []float64{0, 1, 0, 0, 1, 0})
+}
+
+func Test_Where_Masked(t *testing.T) {
+
+	A := RangeTensor([]int{2, 3}, false)
+	B := ConstTensor([]int{2, 3}, -1, false)
+	cond := Greater(A, ConstTensor([]int{2, 3}, 2, false), false)
+
+	checkSlice(t, "Where()", Where(cond, A, B, false), []int{2, 3}, []float64{-1, -1, -1, 3, 4, 5})
+	checkSlice(t, "MaskedSelect()", MaskedSelect(A, cond), []int{3}, []float64{3, 4, 5})
+	checkSlice(t, "MaskedFill()", A.MaskedFill(cond, 0, false), []int{2, 3}, []float64{0, 1, 2, 0, 0, 0})
+
+	// cleaning NaNs from data
+	D := tensorOf([]int{3}, 1, math.NaN(), 3)
+	checkSlice(t, "MaskedFill(IsNaN())", D.MaskedFill(IsNaN(D), 2, false), []int{3}, []float64{1, 2, 3})
+}
+
+func Test_Take_IndexSelect(t *testing.T) {
+
+	A := RangeTensor([]int{3, 4}, false)
+
+	checkSlice(t, "Take()", A.Take([]int{0, -1, 5, 5}), []int{4}, []float64{0, 11, 5, 5})
+	checkSlice(t, "IndexSelect(0)", A.IndexSelect(0, []int{2, 0}, false), []int{2, 4}, []float64{8, 9, 10, 11, 0, 1, 2, 3})
+	checkSlice(t, "IndexSelect(1)", A.IndexSelect(1, []int{-1, 1}, false), []int{3, 2}, []float64{3, 1, 7, 5, 11, 9})
+}
+
+func Test_Gather_Scatter(t *testing.T) {
+
+	A := RangeTensor([]int{3, 4}, false)
+	labels := tensorOf([]int{3, 1}, 2, 0, 3)
+
+	checkSlice(t, "Gather(1)", A.Gather(1, labels, false), []int{3, 1}, []float64{2, 4, 11})
+	checkSlice(t, "Gather(0)", A.Gather(0, tensorOf([]int{1, 4}, 2, 1, 0, -1), false), []int{1, 4}, []float64{8, 5, 2, 11})
+
+	// one hot encoding by scattering ones
+	onehot := ZeroTensor([]int{3, 4}, false).Scatter(1, labels, OnesTensor([]int{3, 1}, false), false)
+	checkSlice(t, "Scatter()", onehot, []int{3, 4}, []float64{0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 1})
+
+	// repeated indices accumulate
+	counts := ZeroTensor([]int{3}, false).ScatterAdd(0, tensorOf([]int{4}, 0, 2, 2, 2), OnesTensor([]int{4}, false), false)
+	checkSlice(t, "ScatterAdd()", counts, []int{3}, []float64{1, 0, 3})
+
+	// batched gather, with axis relative to each batch element
+	B := RangeTensor([]int{2, 3}, true)
+	checkSlice(t, "Batched Gather()", B.Gather(0, tensorOf([]int{2, 2}, 2, 0, 1, 1), true), []int{2, 2}, []float64{2, 0, 1, 1})
+}
+
+func Test_Selection_Gradients(t *testing.T) {
+
+	// builds a gradient tracked Tensor. Gradify() requires a Batched Tensor with an allocated DataReqGrad slice.
+	gradTensor := func(A *Tensor) *Tensor {
+		A.Batched = true
+		return Gradify(A)
+	}
+
+	// gather the predicted value of each label and sum them into a loss
+	A := gradTensor(RangeTensor([]int{2, 3}, false))
+	selected := A.Gather(1, tensorOf([]int{2, 1}, 1, 2), false)
+
+	loss := selected.DataReqGrad[0].Add(selected.DataReqGrad[1])
+	loss.Backward()
+
+	expected := []float64{0, 1, 0, 0, 0, 1}
+	for i, value := range A.DataReqGrad {
+		if value.Grad != expected[i] {
+			t.Errorf("Gather() gradient failed. Expected Grad[%v]: %v --- Actual: %v", i, expected[i], value.Grad)
+		}
+	}
+
+	// gradients flow through Where() only to the elements that were selected
+	B := gradTensor(OnesTensor([]int{1, 2}, false))
+	C := gradTensor(OnesTensor([]int{1, 2}, false))
+	D := Where(tensorOf([]int{1, 2}, 1, 0), B, C, false)
+	D.DataReqGrad[0].Add(D.DataReqGrad[1]).Backward()
+
+	if B.DataReqGrad[0].Grad != 1 || B.DataReqGrad[1].Grad != 0 || C.DataReqGrad[1].Grad != 1 {
+		t.Errorf("Where() gradient failed. Gradients should only reach the selected elements")
+	}
+}
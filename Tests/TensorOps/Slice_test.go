@@ -0,0 +1,91 @@
+package TG
+
+// Slice_test.go contains tests for the extended slice notation of Slice(), SliceBySpec() and SetSlice()
+
+import (
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// checks the shape and data of a sliced tensor
+func checkSlice(t *testing.T, slice string, A *Tensor, shape []int, data []float64) {
+	if len(A.Shape) != len(shape) {
+		t.Errorf("Slice(%q) failed. Expected Shape: %v --- Actual Shape: %v", slice, shape, A.Shape)
+		return
+	}
+	for i := range shape {
+		if A.Shape[i] != shape[i] {
+			t.Errorf("Slice(%q) failed. Expected Shape: %v --- Actual Shape: %v", slice, shape, A.Shape)
+			return
+		}
+	}
+	for i := range data {
+		if A.Data[i] != data[i] {
+			t.Errorf("Slice(%q) failed. Expected Data: %v --- Actual Data: %v", slice, data, A.Data)
+			return
+		}
+	}
+}
+
+func Test_Slice_Extended(t *testing.T) {
+
+	// 0 1 2 3
+	// 4 5 6 7
+	// 8 9 10 11
+	A := RangeTensor([]int{3, 4}, false)
+
+	checkSlice(t, "::2, ::-1", A.Slice("::2, ::-1"), []int{2, 4}, []float64{3, 2, 1, 0, 11, 10, 9, 8})
+	checkSlice(t, "-1", A.Slice("-1"), []int{4}, []float64{8, 9, 10, 11})
+	checkSlice(t, "1, -2", A.Slice("1, -2"), []int{1}, []float64{6})
+	checkSlice(t, "..., 1", A.Slice("..., 1"), []int{3}, []float64{1, 5, 9})
+	checkSlice(t, "None, 0", A.Slice("None, 0"), []int{1, 4}, []float64{0, 1, 2, 3})
+	checkSlice(t, ":, -3:10:2", A.Slice(":, -3:10:2"), []int{3, 2}, []float64{1, 3, 5, 7, 9, 11})
+	checkSlice(t, "2:0:-1, 3:-10:-2", A.Slice("2:0:-1, 3:-10:-2"), []int{2, 2}, []float64{11, 9, 7, 5})
+	checkSlice(t, "5:", A.Slice("5:"), []int{0, 4}, []float64{})
+
+	// the builder should match the string notation
+	checkSlice(t, "builder", A.SliceBySpec(NewSliceSpec().Every(2).Index(-1)), []int{2}, []float64{3, 11})
+
+	// ellipsis in the middle of a 3D tensor
+	B := RangeTensor([]int{2, 3, 4}, false)
+	checkSlice(t, "1, ..., ::3", B.Slice("1, ..., ::3"), []int{3, 2}, []float64{12, 15, 16, 19, 20, 23})
+
+	// invalid slices should return errors
+	for _, invalid := range []string{"::0", "a:2", "1:2:3:4", "1,,2"} {
+		if _, err := ParseSliceSpec(invalid); err == nil {
+			t.Errorf("ParseSliceSpec(%q) failed. Expected an error", invalid)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Slice() failed. Expected a panic for an out of bounds index")
+		}
+	}()
+	A.Slice("3")
+}
+
+func Test_SetSlice(t *testing.T) {
+
+	A := ZeroTensor([]int{3, 3}, false)
+
+	// broadcast a single value across a column
+	A.SetSlice(":, 1", OnesTensor([]int{1}, false))
+	checkSlice(t, ":, 1", A.Slice(":, 1"), []int{3}, []float64{1, 1, 1})
+
+	// assign a matching region in reverse order
+	A.SetSlice("0, ::-1", RangeTensor([]int{3}, false))
+	checkSlice(t, "0", A.Slice("0"), []int{3}, []float64{2, 1, 0})
+
+	if A.Sum_All() != 5 {
+		t.Errorf("SetSlice() failed. Expected Sum: 5 --- Actual Sum: %v", A.Sum_All())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SetSlice() failed. Expected a panic for mismatched shapes")
+		}
+	}()
+	A.SetSlice("0", OnesTensor([]int{2}, false))
+}
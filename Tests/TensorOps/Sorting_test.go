@@ -0,0 +1,73 @@
+package TG
+
+// Sorting_test.go contains tests for Sorting.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+func Test_Sort_Argsort(t *testing.T) {
+
+	// 3 1 2
+	// 0 5 1
+	A := tensorOf([]int{2, 3}, 3, 1, 2, 0, 5, 1)
+
+	checkSlice(t, "Sort(1)", A.Sort(1, false, false), []int{2, 3}, []float64{1, 2, 3, 0, 1, 5})
+	checkSlice(t, "Sort(0) descending", A.Sort(0, true, false), []int{2, 3}, []float64{3, 5, 2, 0, 1, 1})
+	checkSlice(t, "Argsort(1)", A.Argsort(1, false, false), []int{2, 3}, []float64{1, 2, 0, 0, 2, 1})
+
+	// argsort is stable in both directions, and NaNs go last
+	B := tensorOf([]int{5}, 2, math.NaN(), 1, 2, 1)
+	checkSlice(t, "Stable Argsort()", B.Argsort(0, false, false), []int{5}, []float64{2, 4, 0, 3, 1})
+	checkSlice(t, "Stable descending Argsort()", B.Argsort(0, true, false), []int{5}, []float64{0, 3, 2, 4, 1})
+
+	// gathering with the argsort should sort
+	checkSlice(t, "Gather(Argsort())", A.Gather(1, A.Argsort(1, true, false), false), []int{2, 3}, []float64{3, 2, 1, 5, 1, 0})
+
+	// batched sort of a batch of vectors
+	C := tensorOf([]int{2, 3}, 3, 1, 2, 0, 5, 1)
+	C.Batched = true
+	checkSlice(t, "Batched Sort()", C.Sort(0, false, true), []int{2, 3}, []float64{1, 2, 3, 0, 1, 5})
+}
+
+func Test_TopK_Argmax(t *testing.T) {
+
+	A := tensorOf([]int{2, 4}, 4, 9, 1, 9, 0, -1, 7, 3)
+
+	values, indices := A.TopK(2, 1, true, false)
+	checkSlice(t, "TopK() values", values, []int{2, 2}, []float64{9, 9, 7, 3})
+	checkSlice(t, "TopK() indices", indices, []int{2, 2}, []float64{1, 3, 2, 3})
+
+	values, indices = A.TopK(1, 0, false, false)
+	checkSlice(t, "TopK() smallest values", values, []int{1, 4}, []float64{0, -1, 1, 3})
+	checkSlice(t, "TopK() smallest indices", indices, []int{1, 4}, []float64{1, 1, 0, 1})
+
+	checkSlice(t, "Argmax(1)", A.Argmax(1, false), []int{2}, []float64{1, 2})
+	checkSlice(t, "Argmin(0)", A.Argmin(0, false), []int{4}, []float64{1, 1, 0, 1})
+	checkSlice(t, "ArgmaxVector()", ArgmaxVector(tensorOf([]int{3}, 1, 3, 2), false), []int{1}, []float64{1})
+
+	// batched argmax of a batch of vectors
+	B := tensorOf([]int{3, 2}, 1, 0, 0, 1, 5, 5)
+	checkSlice(t, "Batched Argmax()", B.Argmax(0, true), []int{3, 1}, []float64{0, 1, 0})
+	checkSlice(t, "Argmax() of a vector", tensorOf([]int{3}, 1, 3, 2).Argmax(0, false), []int{1}, []float64{1})
+}
+
+func Test_Unique_SearchSorted(t *testing.T) {
+
+	A := tensorOf([]int{2, 3}, 3, 1, 3, math.NaN(), 1, math.NaN())
+
+	values, counts, inverse := A.Unique()
+	if len(values.Data) != 3 || values.Data[0] != 1 || values.Data[1] != 3 || !math.IsNaN(values.Data[2]) {
+		t.Errorf("Unique() failed. Expected Values: [1 3 NaN] --- Actual Values: %v", values.Data)
+	}
+	checkSlice(t, "Unique() counts", counts, []int{3}, []float64{2, 2, 2})
+	checkSlice(t, "Unique() inverse", inverse, []int{2, 3}, []float64{1, 0, 1, 2, 0, 2})
+
+	sorted := tensorOf([]int{5}, 1, 2, 2, 3, 5)
+	queries := tensorOf([]int{2, 2}, 2, 0, 4, 6)
+	checkSlice(t, "SearchSorted() left", SearchSorted(sorted, queries, false, false), []int{2, 2}, []float64{1, 0, 4, 5})
+	checkSlice(t, "SearchSorted() right", SearchSorted(sorted, queries, true, false), []int{2, 2}, []float64{3, 0, 4, 5})
+}
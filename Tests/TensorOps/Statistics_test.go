@@ -0,0 +1,83 @@
+package TG
+
+// Statistics_test.go contains tests for Statistics.go
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/Holindauer/Tensor-Go/TensorGo"
+)
+
+// checks that two float64 values are equal within tol
+func closeTo(t *testing.T, name string, expected float64, actual float64, tol float64) {
+	if math.Abs(expected-actual) > tol {
+		t.Errorf("%v failed. Expected Output: %v --- Actual Output: %v", name, expected, actual)
+	}
+}
+
+func Test_Quantile(t *testing.T) {
+
+	A := tensorOf([]int{2, 4}, 4, 1, 3, 2, 10, 40, 20, 30)
+
+	checkSlice(t, "Median(1)", A.Median([]int{1}, false, false), []int{2}, []float64{2.5, 25})
+	checkSlice(t, "Quantile(0.25) linear", A.Quantile(0.25, []int{1}, false, QuantileLinear, false), []int{2}, []float64{1.75, 17.5})
+	checkSlice(t, "Quantile(0.25) lower", A.Quantile(0.25, []int{1}, false, QuantileLower, false), []int{2}, []float64{1, 10})
+	checkSlice(t, "Quantile(0.25) higher", A.Quantile(0.25, []int{1}, false, QuantileHigher, false), []int{2}, []float64{2, 20})
+	checkSlice(t, "Quantile(0.25) nearest", A.Quantile(0.25, []int{1}, false, QuantileNearest, false), []int{2}, []float64{2, 20})
+	checkSlice(t, "Quantile(0.25) midpoint", A.Quantile(0.25, []int{1}, false, QuantileMidpoint, false), []int{2}, []float64{1.5, 15})
+	checkSlice(t, "Percentile(100)", A.Percentile(100, nil, true, QuantileLinear, false), []int{1, 1}, []float64{40})
+}
+
+func Test_Histogram_Bincount(t *testing.T) {
+
+	A := tensorOf([]int{6}, 0, 1, 1, 2.5, 4, math.NaN())
+
+	counts, edges := A.Histogram(4, 0, 0)
+	checkSlice(t, "Histogram() counts", counts, []int{4}, []float64{1, 2, 1, 1})
+	checkSlice(t, "Histogram() edges", edges, []int{5}, []float64{0, 1, 2, 3, 4})
+
+	counts, _ = A.Histogram(2, 1, 2)
+	checkSlice(t, "Histogram() fixed range", counts, []int{2}, []float64{2, 0})
+
+	B := tensorOf([]int{5}, 0, 3, 3, 1, 3)
+	checkSlice(t, "Bincount()", B.Bincount(nil, 0), []int{4}, []float64{1, 1, 0, 3})
+	checkSlice(t, "Weighted Bincount()", B.Bincount(tensorOf([]int{5}, 1, 2, 3, 4, 5), 6), []int{6}, []float64{1, 4, 0, 10, 0, 0})
+}
+
+func Test_Cov_Corrcoef(t *testing.T) {
+
+	// feature 1 = 2 * feature 0, feature 2 = -feature 0
+	X := tensorOf([]int{3, 3}, 1, 2, -1, 2, 4, -2, 3, 6, -3)
+
+	C := Cov(X, 1)
+	checkSlice(t, "Cov()", C, []int{3, 3}, []float64{1, 2, -1, 2, 4, -2, -1, -2, 1})
+
+	R := Corrcoef(X)
+	expected := []float64{1, 1, -1, 1, 1, -1, -1, -1, 1}
+	for i := range expected {
+		closeTo(t, "Corrcoef()", expected[i], R.Data[i], 1e-12)
+	}
+}
+
+func Test_Moments(t *testing.T) {
+
+	A := tensorOf([]int{5}, 1, 2, 3, 4, 10)
+
+	// mean 4, m2 = 10, m3 = 36, m4 = 278.8
+	closeTo(t, "Skew_All()", 36/math.Pow(10, 1.5), A.Skew_All(), 1e-12)
+	closeTo(t, "Kurtosis_All()", 278.8/100-3, A.Kurtosis_All(), 1e-12)
+	closeTo(t, "Skew()", A.Skew_All(), A.Skew(nil, false, false).Data[0], 1e-12)
+	closeTo(t, "Kurtosis()", A.Kurtosis_All(), A.Kurtosis([]int{0}, false, false).Data[0], 1e-12)
+
+	// weighted moments along each axis
+	B := tensorOf([]int{2, 2}, 1, 3, 5, 7)
+	checkSlice(t, "WeightedMean(1)", B.WeightedMean(tensorOf([]int{2}, 1, 3), 1, false), []int{2}, []float64{2.5, 6.5})
+	checkSlice(t, "WeightedVar(1)", B.WeightedVar(tensorOf([]int{2}, 1, 3), 1, false), []int{2}, []float64{0.75, 0.75})
+	checkSlice(t, "WeightedMean(0)", B.WeightedMean(tensorOf([]int{2}, 1, 1), 0, false), []int{2}, []float64{3, 5})
+
+	// a vector collapses to a scalar of shape [1]
+	v := tensorOf([]int{3}, 1, 2, 4)
+	checkSlice(t, "Vector WeightedMean()", v.WeightedMean(tensorOf([]int{3}, 1, 1, 2), 0, false), []int{1}, []float64{2.75})
+	checkSlice(t, "Vector WeightedVar()", v.WeightedVar(tensorOf([]int{3}, 1, 1, 2), 0, false), []int{1}, []float64{1.6875})
+}